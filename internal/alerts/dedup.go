@@ -94,3 +94,11 @@ func (d *DedupStore) Clear() {
 
 	d.records = make(map[string]*AlertRecord)
 }
+
+// Forget removes the record for a key so the next alert with it is sent
+func (d *DedupStore) Forget(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.records, key)
+}
@@ -4,14 +4,16 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 )
 
-// DigestScheduler manages daily digest scheduling
+// DigestScheduler manages daily and weekly digest scheduling
 type DigestScheduler struct {
 	timezone   *time.Location
 	digestTime string // Format: "HH:MM"
+	weekday    *time.Weekday
 	generateFn func() (*DigestData, error)
 	sendFn     func(*DigestData) error
 
@@ -46,6 +48,25 @@ func NewDigestScheduler(timezone string, digestTime string, generateFn func() (*
 	}, nil
 }
 
+// NewWeeklyDigestScheduler creates a scheduler that sends the digest once a
+// week, on weekday
+func NewWeeklyDigestScheduler(timezone string, digestTime string, weekday time.Weekday, generateFn func() (*DigestData, error), sendFn func(*DigestData) error) (*DigestScheduler, error) {
+	d, err := NewDigestScheduler(timezone, digestTime, generateFn, sendFn)
+	if err != nil {
+		return nil, err
+	}
+	d.weekday = &weekday
+	return d, nil
+}
+
+// interval returns the time between two digests
+func (d *DigestScheduler) interval() time.Duration {
+	if d.weekday != nil {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
 // Start starts the digest scheduler
 func (d *DigestScheduler) Start() {
 	d.mu.Lock()
@@ -96,8 +117,8 @@ func (d *DigestScheduler) run() {
 			return
 		case <-timer.C:
 			d.sendDigest()
-			// Reset timer for next day
-			timer.Reset(24 * time.Hour)
+			// Reset timer for the next day or week
+			timer.Reset(d.interval())
 		}
 	}
 }
@@ -116,9 +137,14 @@ func (d *DigestScheduler) calculateNextDelay() time.Duration {
 	// Create target time for today
 	target := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, d.timezone)
 
-	// If target time has passed, schedule for tomorrow
+	// Weekly digests wait for their weekday
+	if d.weekday != nil {
+		target = target.AddDate(0, 0, (int(*d.weekday)-int(target.Weekday())+7)%7)
+	}
+
+	// If target time has passed, schedule for the next day or week
 	if target.Before(now) {
-		target = target.Add(24 * time.Hour)
+		target = target.Add(d.interval())
 	}
 
 	return time.Until(target)
@@ -198,7 +224,20 @@ func GenerateDigest(alerts []Alert, accountUsages []AccountUsage) *DigestData {
 func FormatDigest(digest *DigestData) string {
 	var result string
 
-	result = fmt.Sprintf("📈 *Daily Digest* - %s\n\n", digest.Date.Format("2006-01-02"))
+	switch {
+	case digest.Period == DigestWeekly && !digest.From.IsZero():
+		result = fmt.Sprintf("📈 *Weekly Digest* - %s – %s\n\n",
+			digest.From.Format("2006-01-02"), digest.To.AddDate(0, 0, -1).Format("2006-01-02"))
+	case digest.Period == DigestWeekly:
+		result = fmt.Sprintf("📈 *Weekly Digest* - %s\n\n", digest.Date.Format("2006-01-02"))
+	default:
+		result = fmt.Sprintf("📈 *Daily Digest* - %s\n\n", digest.Date.Format("2006-01-02"))
+	}
+
+	// Usage computed from persisted data
+	if !digest.From.IsZero() {
+		result += formatDigestUsage(digest)
+	}
 
 	// Alert summary
 	if len(digest.Alerts) > 0 {
@@ -224,5 +263,123 @@ func FormatDigest(digest *DigestData) string {
 		}
 	}
 
+	// Spend
+	if len(digest.Spend) > 0 {
+		if len(digest.TopAccounts) > 0 {
+			result += "\n"
+		}
+		total := 0.0
+		for _, spend := range digest.Spend {
+			total += spend.CostUSD
+		}
+		result += fmt.Sprintf("*Spend:* $%.2f\n", total)
+		for _, spend := range digest.Spend {
+			line := fmt.Sprintf("• %s: $%.2f (%d req)", spend.AccountID, spend.CostUSD, spend.Requests)
+			if spend.MonthlyBudgetUSD > 0 {
+				line += fmt.Sprintf(", month $%.2f / $%.2f", spend.MonthToDateUSD, spend.MonthlyBudgetUSD)
+			}
+			result += line + "\n"
+		}
+	}
+
+	return result
+}
+
+// maxDigestAccounts bounds the accounts listed in a formatted digest
+const maxDigestAccounts = 10
+
+// formatDigestUsage formats the totals, with trends against the previous
+// period, and the per-provider and per-account breakdown
+func formatDigestUsage(digest *DigestData) string {
+	prev := digest.Previous
+	if prev == nil {
+		prev = &DigestTotals{}
+	}
+	than := "the day before"
+	if digest.Period == DigestWeekly {
+		than = "the week before"
+	}
+
+	result := "*Usage:*\n"
+	result += fmt.Sprintf("• Requests: %d%s\n", digest.TotalRequests, formatTrend(float64(digest.TotalRequests), float64(prev.Requests)))
+	result += fmt.Sprintf("• Tokens: %s in / %s out%s\n",
+		formatCount(digest.InputTokens), formatCount(digest.OutputTokens),
+		formatTrend(float64(digest.InputTokens+digest.OutputTokens), float64(prev.InputTokens+prev.OutputTokens)))
+	result += fmt.Sprintf("• Switches: %d%s\n", digest.Switches, formatTrend(float64(digest.Switches), float64(prev.Switches)))
+	result += fmt.Sprintf("• Errors: %d%s%s\n", digest.Errors, formatErrorClasses(digest.ErrorsByClass), formatTrend(float64(digest.Errors), float64(prev.Errors)))
+	result += fmt.Sprintf("• Exhausted: %.1fh%s\n", digest.ExhaustedHours, formatTrend(digest.ExhaustedHours, prev.ExhaustedHours))
+	if digest.Previous != nil {
+		result += fmt.Sprintf("_Trends compare with %s._\n", than)
+	}
+	result += "\n"
+
+	if len(digest.Providers) > 0 {
+		result += "*Providers:*\n"
+		for _, p := range digest.Providers {
+			result += fmt.Sprintf("• %s (%d acc): %d req, %s tokens, exhausted %.1fh\n",
+				p.Provider, p.Accounts, p.Requests, formatCount(p.InputTokens+p.OutputTokens), p.ExhaustedHours)
+		}
+		result += "\n"
+	}
+
+	if len(digest.Accounts) > 0 {
+		result += "*Accounts:*\n"
+		for i, acc := range digest.Accounts {
+			if i == maxDigestAccounts {
+				result += fmt.Sprintf("• ... and %d more\n", len(digest.Accounts)-maxDigestAccounts)
+				break
+			}
+			line := fmt.Sprintf("• %s: %d req, peak %.1f%%", acc.AccountID, acc.Requests, acc.UsagePercent)
+			if acc.ExhaustedHours > 0 {
+				line += fmt.Sprintf(", exhausted %.1fh", acc.ExhaustedHours)
+			}
+			if acc.Resets > 0 {
+				line += fmt.Sprintf(", %.1f%% unused at %d reset(s)", acc.WastedPct, acc.Resets)
+			}
+			result += line + "\n"
+		}
+		result += "\n"
+	}
 	return result
 }
+
+// formatTrend prints the change from prev to cur, such as " (+25%)"
+func formatTrend(cur, prev float64) string {
+	switch {
+	case prev == 0 && cur == 0:
+		return ""
+	case prev == 0:
+		return " (new)"
+	default:
+		return fmt.Sprintf(" (%+.0f%%)", (cur-prev)/prev*100)
+	}
+}
+
+// formatErrorClasses prints error counts by class, such as " (network 2)"
+func formatErrorClasses(byClass map[string]int) string {
+	if len(byClass) == 0 {
+		return ""
+	}
+	classes := make([]string, 0, len(byClass))
+	for class := range byClass {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	parts := make([]string, 0, len(classes))
+	for _, class := range classes {
+		parts = append(parts, fmt.Sprintf("%s %d", class, byClass[class]))
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// formatCount prints a count with a K or M suffix
+func formatCount(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fK", float64(n)/1_000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
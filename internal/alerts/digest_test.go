@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewDigestScheduler(t *testing.T) {
@@ -208,3 +209,37 @@ func TestCalculateNextDelayPast(t *testing.T) {
 	assert.Greater(t, delay, time.Duration(0))
 	assert.Less(t, delay, 24*time.Hour)
 }
+
+func TestFormatDigestSpend(t *testing.T) {
+	digest := &DigestData{
+		Date: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		Spend: []AccountSpend{
+			{AccountID: "api-key", Requests: 4, CostUSD: 1.25, MonthToDateUSD: 9.5, MonthlyBudgetUSD: 20},
+			{AccountID: "codex-1", Requests: 10, CostUSD: 0.75},
+		},
+	}
+
+	result := FormatDigest(digest)
+
+	assert.Contains(t, result, "*Spend:* $2.00")
+	assert.Contains(t, result, "api-key: $1.25 (4 req), month $9.50 / $20.00")
+	assert.Contains(t, result, "codex-1: $0.75 (10 req)\n")
+}
+
+func TestCalculateNextDelayWeekly(t *testing.T) {
+	generateFn := func() (*DigestData, error) {
+		return &DigestData{}, nil
+	}
+	sendFn := func(*DigestData) error {
+		return nil
+	}
+
+	for _, weekday := range []time.Weekday{time.Sunday, time.Monday, time.Now().UTC().Weekday()} {
+		scheduler, err := NewWeeklyDigestScheduler("UTC", "12:00", weekday, generateFn, sendFn)
+		require.NoError(t, err)
+		delay := scheduler.calculateNextDelay()
+		assert.Greater(t, delay, time.Duration(0))
+		assert.LessOrEqual(t, delay, 7*24*time.Hour)
+		assert.Equal(t, weekday, time.Now().Add(delay).UTC().Weekday())
+	}
+}
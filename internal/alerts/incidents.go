@@ -0,0 +1,377 @@
+package alerts
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/telegram"
+)
+
+// IncidentStore persists alert incidents and mutes
+type IncidentStore interface {
+	SaveAlertIncident(incident *models.AlertIncident) error
+	GetAlertIncident(id string) (*models.AlertIncident, bool)
+	FindActiveAlertIncident(accountID, alertType string) (*models.AlertIncident, bool)
+	ListAlertIncidents(statuses ...models.IncidentStatus) ([]*models.AlertIncident, error)
+	SetAlertMute(mute *models.AlertMute) error
+	DeleteAlertMute(accountID, alertType string) error
+	ListAlertMutes(now time.Time) ([]*models.AlertMute, error)
+}
+
+// EscalationBot delivers escalated alerts to a chat other than the
+// configured one
+type EscalationBot interface {
+	SendAlertToChat(chatID int64, alert telegram.Alert) error
+}
+
+// Mute scopes accepted by MuteIncident
+const (
+	MuteScopeAccount = "account"
+	MuteScopeType    = "type"
+)
+
+// quotaAlertTypes are raised by CheckThresholds and resolve on their own
+// once a check no longer raises them
+var quotaAlertTypes = map[AlertType]bool{
+	AlertTypeThreshold:           true,
+	AlertTypeExhausted:           true,
+	AlertTypePredictedExhaustion: true,
+	AlertTypePoolExhaustion:      true,
+}
+
+// WithIncidentStore tracks alerts as incidents that can be acknowledged,
+// snoozed and muted
+func WithIncidentStore(st IncidentStore) ServiceOption {
+	return func(s *Service) {
+		s.incidents = st
+	}
+}
+
+// trackIncident opens or updates the incident of an alert and tags the
+// alert with it. It reports whether the alert should be sent: it is not
+// when the incident is acknowledged, snoozed or muted. A rise in severity
+// reopens an acknowledged incident.
+func (s *Service) trackIncident(alert *Alert) (bool, error) {
+	now := s.now()
+	incident, ok := s.incidents.FindActiveAlertIncident(alert.AccountID, string(alert.Type))
+	if !ok {
+		incident = &models.AlertIncident{
+			ID:         newIncidentID(),
+			AccountID:  alert.AccountID,
+			Type:       string(alert.Type),
+			Severity:   string(alert.Severity),
+			Status:     models.IncidentOpen,
+			OpenedAt:   now,
+			LastSeenAt: now,
+		}
+	}
+	if severityRank(alert.Severity) > severityRank(Severity(incident.Severity)) {
+		incident.Severity = string(alert.Severity)
+		incident.Status = models.IncidentOpen
+		incident.AckedAt = nil
+		incident.AckedBy = 0
+		incident.SnoozedUntil = nil
+	}
+	incident.Message = alert.Message
+	incident.LastSeenAt = now
+	incident.Count++
+	if err := s.incidents.SaveAlertIncident(incident); err != nil {
+		return false, err
+	}
+	alert.IncidentID = incident.ID
+
+	if incident.Status != models.IncidentOpen || incident.IsSnoozed(now) {
+		return false, nil
+	}
+	return !s.isMutedFor(alert.AccountID, string(alert.Type), now), nil
+}
+
+// isMutedFor reports whether a mute silences alertType on accountID
+func (s *Service) isMutedFor(accountID, alertType string, now time.Time) bool {
+	mutes, err := s.incidents.ListAlertMutes(now)
+	if err != nil {
+		return false
+	}
+	for _, mute := range mutes {
+		if mute.Matches(accountID, alertType, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveRecovered resolves the quota incidents of checked accounts and
+// pools that the latest check no longer raised, and announces the
+// resolution to whoever got the alert.
+func (s *Service) resolveRecovered(accounts []models.Account, quotas []models.QuotaInfo, raised []Alert, now time.Time) {
+	if s.incidents == nil {
+		return
+	}
+	checked := make(map[string]bool, len(quotas))
+	for _, quota := range quotas {
+		checked[quota.AccountID] = true
+	}
+	for _, account := range accounts {
+		if checked[account.ID] {
+			checked[PoolAccountID(string(account.Provider))] = true
+		}
+	}
+	still := make(map[string]bool, len(raised))
+	for _, alert := range raised {
+		still[alert.AccountID+"|"+string(alert.Type)] = true
+	}
+
+	active, err := s.incidents.ListAlertIncidents(models.IncidentOpen, models.IncidentAcknowledged)
+	if err != nil {
+		return
+	}
+	for _, incident := range active {
+		if !quotaAlertTypes[AlertType(incident.Type)] || !checked[incident.AccountID] || still[incident.AccountID+"|"+incident.Type] {
+			continue
+		}
+		s.resolveIncident(incident, Alert{
+			AccountID: incident.AccountID,
+			Type:      AlertType(incident.Type),
+			Severity:  Severity(incident.Severity),
+			Message:   incident.Message,
+		}, now)
+	}
+}
+
+// ResolveIncident resolves the active incident of the recovery alert's
+// account and type, such as an account passing its availability check
+// again, and sends the alert as the resolution notice. The recovery does
+// not open an incident of its own; without an active incident nothing is
+// sent. When incidents are not tracked the alert is processed as usual.
+func (s *Service) ResolveIncident(recovery Alert) error {
+	if s.incidents == nil {
+		return s.ProcessAlert(recovery)
+	}
+	if !s.config.Enabled {
+		return nil
+	}
+	incident, ok := s.incidents.FindActiveAlertIncident(recovery.AccountID, string(recovery.Type))
+	if !ok {
+		return nil
+	}
+	s.resolveIncident(incident, recovery, s.now())
+	return nil
+}
+
+// resolveIncident marks incident resolved and, unless it is snoozed or
+// muted, announces the resolution with the message of notice
+func (s *Service) resolveIncident(incident *models.AlertIncident, notice Alert, now time.Time) {
+	resolvedAt := now
+	incident.Status = models.IncidentResolved
+	incident.ResolvedAt = &resolvedAt
+	if err := s.incidents.SaveAlertIncident(incident); err != nil {
+		return
+	}
+	// A recurrence is a new incident and is sent right away
+	for _, severity := range []Severity{SeverityInfo, SeverityWarning, SeverityCritical} {
+		s.dedup.Forget(incident.AccountID + ":" + incident.Type + ":" + string(severity))
+	}
+	if incident.IsSnoozed(now) || s.isMutedFor(incident.AccountID, incident.Type, now) {
+		return
+	}
+	if notice.ID == "" {
+		notice.ID = generateAlertID()
+	}
+	notice.Message = fmt.Sprintf("Resolved after %s: %s", formatLead(now.Sub(incident.OpenedAt)), notice.Message)
+	notice.Timestamp = now
+	notice.IncidentID = ""
+	s.sendAlert(notice)
+}
+
+// checkEscalations notifies again about incidents left open for longer
+// than the escalation delay, also to the escalation chat when one is set.
+// Every incident escalates once.
+func (s *Service) checkEscalations(now time.Time) {
+	s.mu.RLock()
+	after := s.config.EscalateAfter
+	chatID := s.config.EscalationChatID
+	s.mu.RUnlock()
+	if s.incidents == nil || after <= 0 {
+		return
+	}
+
+	open, err := s.incidents.ListAlertIncidents(models.IncidentOpen)
+	if err != nil {
+		return
+	}
+	for _, incident := range open {
+		if incident.EscalatedAt != nil || now.Sub(incident.OpenedAt) < after || incident.IsSnoozed(now) ||
+			s.isMutedFor(incident.AccountID, incident.Type, now) {
+			continue
+		}
+		escalatedAt := now
+		incident.EscalatedAt = &escalatedAt
+		if err := s.incidents.SaveAlertIncident(incident); err != nil {
+			continue
+		}
+
+		alert := Alert{
+			ID:         generateAlertID(),
+			AccountID:  incident.AccountID,
+			Type:       AlertType(incident.Type),
+			Severity:   Severity(incident.Severity),
+			Message:    fmt.Sprintf("Not acknowledged for %s: %s", formatLead(now.Sub(incident.OpenedAt)), incident.Message),
+			Timestamp:  now,
+			IncidentID: incident.ID,
+		}
+		s.sendAlert(alert)
+		if escalation, ok := s.bot.(EscalationBot); ok && chatID != 0 {
+			_ = escalation.SendAlertToChat(chatID, toTelegramAlert(alert))
+		}
+	}
+}
+
+// escalationLoop checks for incidents to escalate every minute
+func (s *Service) escalationLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkEscalations(s.now())
+		}
+	}
+}
+
+// AcknowledgeIncident marks an incident as being handled by userID. It is
+// not notified or escalated again unless its severity rises.
+func (s *Service) AcknowledgeIncident(id string, userID int64) (*models.AlertIncident, error) {
+	incident, err := s.activeIncident(id)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	incident.Status = models.IncidentAcknowledged
+	incident.AckedAt = &now
+	incident.AckedBy = userID
+	if err := s.incidents.SaveAlertIncident(incident); err != nil {
+		return nil, err
+	}
+	return incident, nil
+}
+
+// SnoozeIncident pauses notifications for an incident for d
+func (s *Service) SnoozeIncident(id string, d time.Duration) (*models.AlertIncident, error) {
+	if d <= 0 {
+		return nil, fmt.Errorf("snooze duration must be positive")
+	}
+	incident, err := s.activeIncident(id)
+	if err != nil {
+		return nil, err
+	}
+	until := s.now().Add(d)
+	incident.SnoozedUntil = &until
+	if err := s.incidents.SaveAlertIncident(incident); err != nil {
+		return nil, err
+	}
+	return incident, nil
+}
+
+// MuteIncident mutes, for d, either every alert of the incident's account
+// (MuteScopeAccount) or every alert of its type (MuteScopeType).
+func (s *Service) MuteIncident(id, scope string, d time.Duration) (*models.AlertMute, error) {
+	if s.incidents == nil {
+		return nil, fmt.Errorf("alert incidents are not enabled")
+	}
+	incident, ok := s.incidents.GetAlertIncident(id)
+	if !ok {
+		return nil, fmt.Errorf("incident %q not found", id)
+	}
+	switch scope {
+	case MuteScopeAccount:
+		return s.Mute(incident.AccountID, "", d, "incident "+id)
+	case MuteScopeType:
+		return s.Mute("", incident.Type, d, "incident "+id)
+	default:
+		return nil, fmt.Errorf("unknown mute scope %q", scope)
+	}
+}
+
+// Mute silences alerts of alertType on accountID for d. An empty account
+// or type matches any.
+func (s *Service) Mute(accountID, alertType string, d time.Duration, reason string) (*models.AlertMute, error) {
+	if s.incidents == nil {
+		return nil, fmt.Errorf("alert incidents are not enabled")
+	}
+	if accountID == "" && alertType == "" {
+		return nil, fmt.Errorf("account or alert type is required; use MuteAlerts to mute everything")
+	}
+	if d <= 0 {
+		return nil, fmt.Errorf("mute duration must be positive")
+	}
+	now := s.now()
+	mute := &models.AlertMute{
+		AccountID: accountID,
+		Type:      alertType,
+		Until:     now.Add(d),
+		Reason:    reason,
+		CreatedAt: now,
+	}
+	if err := s.incidents.SetAlertMute(mute); err != nil {
+		return nil, err
+	}
+	return mute, nil
+}
+
+// Unmute removes the mute of accountID and alertType
+func (s *Service) Unmute(accountID, alertType string) error {
+	if s.incidents == nil {
+		return fmt.Errorf("alert incidents are not enabled")
+	}
+	return s.incidents.DeleteAlertMute(accountID, alertType)
+}
+
+// ListIncidents returns incidents in any of the given statuses, or all
+// incidents when none are given
+func (s *Service) ListIncidents(statuses ...models.IncidentStatus) ([]*models.AlertIncident, error) {
+	if s.incidents == nil {
+		return []*models.AlertIncident{}, nil
+	}
+	return s.incidents.ListAlertIncidents(statuses...)
+}
+
+func (s *Service) activeIncident(id string) (*models.AlertIncident, error) {
+	if s.incidents == nil {
+		return nil, fmt.Errorf("alert incidents are not enabled")
+	}
+	incident, ok := s.incidents.GetAlertIncident(id)
+	if !ok {
+		return nil, fmt.Errorf("incident %q not found", id)
+	}
+	if !incident.IsActive() {
+		return nil, fmt.Errorf("incident %q is already resolved", id)
+	}
+	return incident, nil
+}
+
+// severityRank orders severities from info to critical
+func severityRank(severity Severity) int {
+	switch severity {
+	case SeverityCritical:
+		return 2
+	case SeverityWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// newIncidentID returns a short random ID that fits in callback data
+func newIncidentID() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return "inc-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,260 @@
+package alerts
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/store"
+	"github.com/quotaguard/quotaguard/internal/telegram"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type escalationBot struct {
+	*MockBot
+	mu    sync.Mutex
+	chats []int64
+}
+
+func (b *escalationBot) SendAlertToChat(chatID int64, _ telegram.Alert) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.chats = append(b.chats, chatID)
+	return nil
+}
+
+func newIncidentService(t *testing.T, config Config, bot TelegramBot) (*Service, *store.MemoryStore, *time.Time) {
+	t.Helper()
+	st := store.NewMemoryStore()
+	config.Enabled = true
+	config.RateLimitPerMinute = 100
+	service := NewService(config, bot, WithIncidentStore(st), WithDedupWindow(time.Millisecond))
+	now := time.Now().UTC().Truncate(time.Second)
+	service.now = func() time.Time { return now }
+	return service, st, &now
+}
+
+func drain(s *Service) int {
+	n := 0
+	for {
+		select {
+		case <-s.alertChan:
+			n++
+		default:
+			return n
+		}
+	}
+}
+
+func TestIncidentLifecycle(t *testing.T) {
+	bot := NewMockBot()
+	service, st, now := newIncidentService(t, Config{}, bot)
+	accounts := []models.Account{{ID: "acc1", Provider: models.ProviderOpenAI}}
+	low := rpdQuota("acc1", 100, *now, nil)
+
+	alerts := service.CheckThresholds(accounts, []models.QuotaInfo{low})
+	require.Len(t, alerts, 1)
+	require.NoError(t, service.ProcessAlerts(alerts))
+	assert.Equal(t, 1, drain(service))
+
+	open, err := service.ListIncidents(models.IncidentOpen)
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	incident := open[0]
+	assert.Equal(t, "acc1", incident.AccountID)
+	assert.Equal(t, string(AlertTypeThreshold), incident.Type)
+
+	// The same condition updates the incident instead of opening another
+	time.Sleep(2 * time.Millisecond)
+	alerts = service.CheckThresholds(accounts, []models.QuotaInfo{low})
+	require.NoError(t, service.ProcessAlerts(alerts))
+	assert.Equal(t, incident.ID, mustIncident(t, st, "acc1").ID)
+	assert.Equal(t, 2, mustIncident(t, st, "acc1").Count)
+	assert.Equal(t, 1, drain(service))
+
+	// Acknowledged incidents are tracked but not sent
+	acked, err := service.AcknowledgeIncident(incident.ID, 42)
+	require.NoError(t, err)
+	assert.Equal(t, models.IncidentAcknowledged, acked.Status)
+	assert.Equal(t, int64(42), acked.AckedBy)
+	time.Sleep(2 * time.Millisecond)
+	require.NoError(t, service.ProcessAlerts(service.CheckThresholds(accounts, []models.QuotaInfo{low})))
+	assert.Equal(t, 0, drain(service))
+	assert.Equal(t, 3, mustIncident(t, st, "acc1").Count)
+
+	// A rise in severity reopens it
+	exhausted := rpdQuota("acc1", 0, *now, nil)
+	alerts = service.CheckThresholds(accounts, []models.QuotaInfo{exhausted})
+	require.NoError(t, service.ProcessAlerts(alerts))
+	reopened := mustIncident(t, st, "acc1")
+	assert.Equal(t, models.IncidentOpen, reopened.Status)
+	assert.Equal(t, string(SeverityCritical), reopened.Severity)
+	assert.Equal(t, 2, drain(service), "reopened threshold incident and new exhausted incident")
+
+	// Recovery resolves both and says so
+	*now = now.Add(20 * time.Minute)
+	assert.Empty(t, service.CheckThresholds(accounts, []models.QuotaInfo{rpdQuota("acc1", 900, *now, nil)}))
+	active, err := service.ListIncidents(models.IncidentOpen, models.IncidentAcknowledged)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+	resolved, ok := st.GetAlertIncident(incident.ID)
+	require.True(t, ok)
+	assert.Equal(t, models.IncidentResolved, resolved.Status)
+	require.NotNil(t, resolved.ResolvedAt)
+	sent := bot.GetAlerts()
+	require.Len(t, sent, 2)
+	assert.Contains(t, sent[0].Message, "Resolved after 20m")
+	assert.Empty(t, sent[0].IncidentID)
+
+	// Accounts missing from a check are left alone
+	require.NoError(t, service.ProcessAlerts(service.CheckThresholds(accounts, []models.QuotaInfo{low})))
+	drain(service)
+	service.CheckThresholds(accounts, nil)
+	_, ok = st.FindActiveAlertIncident("acc1", string(AlertTypeThreshold))
+	assert.True(t, ok)
+}
+
+func mustIncident(t *testing.T, st *store.MemoryStore, accountID string) *models.AlertIncident {
+	t.Helper()
+	incident, ok := st.FindActiveAlertIncident(accountID, string(AlertTypeThreshold))
+	require.True(t, ok)
+	return incident
+}
+
+func TestIncidentSnoozeAndMutes(t *testing.T) {
+	service, st, now := newIncidentService(t, Config{}, NewMockBot())
+	alert := func(accountID string) Alert {
+		return Alert{ID: generateAlertID(), AccountID: accountID, Type: AlertTypeThreshold, Severity: SeverityWarning, Message: "low"}
+	}
+
+	require.NoError(t, service.ProcessAlert(alert("acc1")))
+	assert.Equal(t, 1, drain(service))
+	incident, ok := st.FindActiveAlertIncident("acc1", string(AlertTypeThreshold))
+	require.True(t, ok)
+
+	_, err := service.SnoozeIncident(incident.ID, time.Hour)
+	require.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+	require.NoError(t, service.ProcessAlert(alert("acc1")))
+	assert.Equal(t, 0, drain(service))
+
+	// The snooze runs out
+	*now = now.Add(time.Hour)
+	require.NoError(t, service.ProcessAlert(alert("acc1")))
+	assert.Equal(t, 1, drain(service))
+
+	// Muting the type silences every account
+	mute, err := service.MuteIncident(incident.ID, MuteScopeType, 4*time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, mute.AccountID)
+	time.Sleep(2 * time.Millisecond)
+	require.NoError(t, service.ProcessAlert(alert("acc1")))
+	require.NoError(t, service.ProcessAlert(alert("acc2")))
+	assert.Equal(t, 0, drain(service))
+	// but not other types
+	require.NoError(t, service.ProcessAlert(Alert{AccountID: "acc1", Type: AlertTypeError, Severity: SeverityWarning}))
+	assert.Equal(t, 1, drain(service))
+
+	require.NoError(t, service.Unmute("", string(AlertTypeThreshold)))
+	_, err = service.MuteIncident(incident.ID, MuteScopeAccount, time.Hour)
+	require.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+	require.NoError(t, service.ProcessAlert(alert("acc1")))
+	require.NoError(t, service.ProcessAlert(alert("acc2")))
+	assert.Equal(t, 1, drain(service))
+
+	_, err = service.MuteIncident(incident.ID, "everything", time.Hour)
+	assert.Error(t, err)
+	_, err = service.Mute("", "", time.Hour, "")
+	assert.Error(t, err)
+	_, err = service.AcknowledgeIncident("inc-missing", 1)
+	assert.Error(t, err)
+}
+
+func TestIncidentEscalation(t *testing.T) {
+	bot := &escalationBot{MockBot: NewMockBot()}
+	service, st, now := newIncidentService(t, Config{EscalateAfter: 15 * time.Minute, EscalationChatID: -200}, bot)
+
+	require.NoError(t, service.ProcessAlert(Alert{AccountID: "acc1", Type: AlertTypeExhausted, Severity: SeverityCritical, Message: "Quota exhausted."}))
+	require.NoError(t, service.ProcessAlert(Alert{AccountID: "acc2", Type: AlertTypeExhausted, Severity: SeverityCritical, Message: "Quota exhausted."}))
+	drain(service)
+	acked, ok := st.FindActiveAlertIncident("acc2", string(AlertTypeExhausted))
+	require.True(t, ok)
+	_, err := service.AcknowledgeIncident(acked.ID, 7)
+	require.NoError(t, err)
+
+	service.checkEscalations(now.Add(10 * time.Minute))
+	assert.Empty(t, bot.GetAlerts())
+
+	service.checkEscalations(now.Add(16 * time.Minute))
+	sent := bot.GetAlerts()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "acc1", sent[0].AccountID)
+	assert.Equal(t, "Not acknowledged for 16m: Quota exhausted.", sent[0].Message)
+	assert.NotEmpty(t, sent[0].IncidentID)
+	assert.Equal(t, []int64{-200}, bot.chats)
+
+	// Every incident escalates once
+	service.checkEscalations(now.Add(30 * time.Minute))
+	assert.Len(t, bot.GetAlerts(), 1)
+}
+
+func TestIncidentsDisabled(t *testing.T) {
+	service := NewService(Config{Enabled: true}, NewMockBot())
+	require.NoError(t, service.ProcessAlert(Alert{AccountID: "acc1", Type: AlertTypeThreshold, Severity: SeverityWarning}))
+	assert.Equal(t, 1, drain(service))
+	require.NoError(t, service.ResolveIncident(Alert{AccountID: "acc1", Type: AlertTypeError, Severity: SeverityInfo}))
+	assert.Equal(t, 1, drain(service), "recoveries are sent as plain alerts")
+
+	incidents, err := service.ListIncidents()
+	require.NoError(t, err)
+	assert.Empty(t, incidents)
+	_, err = service.AcknowledgeIncident("inc-1", 1)
+	assert.Error(t, err)
+}
+
+func TestIncidentRecoveryResolvesErrorIncident(t *testing.T) {
+	bot := &escalationBot{MockBot: NewMockBot()}
+	service, st, now := newIncidentService(t, Config{EscalateAfter: 15 * time.Minute, EscalationChatID: -200}, bot)
+	unavailable := Alert{AccountID: "acc1", Type: AlertTypeError, Severity: SeverityCritical, Message: "Account unavailable: acc1."}
+	recovered := Alert{AccountID: "acc1", Type: AlertTypeError, Severity: SeverityInfo, Message: "Account recovered: acc1."}
+
+	require.NoError(t, service.ProcessAlert(unavailable))
+	assert.Equal(t, 1, drain(service))
+	first, ok := st.FindActiveAlertIncident("acc1", string(AlertTypeError))
+	require.True(t, ok)
+	_, err := service.AcknowledgeIncident(first.ID, 7)
+	require.NoError(t, err)
+
+	*now = now.Add(10 * time.Minute)
+	require.NoError(t, service.ResolveIncident(recovered))
+	resolved, ok := st.GetAlertIncident(first.ID)
+	require.True(t, ok)
+	assert.Equal(t, models.IncidentResolved, resolved.Status)
+	assert.Equal(t, "Account unavailable: acc1.", resolved.Message, "the recovery does not overwrite the incident")
+	_, ok = st.FindActiveAlertIncident("acc1", string(AlertTypeError))
+	assert.False(t, ok, "the recovery does not open an incident of its own")
+	sent := bot.GetAlerts()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "Resolved after 10m: Account recovered: acc1.", sent[0].Message)
+
+	// Nothing left to escalate
+	service.checkEscalations(now.Add(30 * time.Minute))
+	assert.Len(t, bot.GetAlerts(), 1)
+	assert.Empty(t, bot.chats)
+
+	// The next outage is a new incident and is sent despite the earlier ack
+	require.NoError(t, service.ProcessAlert(unavailable))
+	assert.Equal(t, 1, drain(service))
+	second, ok := st.FindActiveAlertIncident("acc1", string(AlertTypeError))
+	require.True(t, ok)
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Equal(t, models.IncidentOpen, second.Status)
+
+	// A recovery without an active incident sends nothing
+	require.NoError(t, service.ResolveIncident(recovered))
+	require.NoError(t, service.ResolveIncident(recovered))
+	assert.Len(t, bot.GetAlerts(), 2)
+}
@@ -0,0 +1,279 @@
+package alerts
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// DefaultPredictionWindow is how far back burn rate is measured by default
+const DefaultPredictionWindow = 30 * time.Minute
+
+// minBurnSpan is the shortest span of readings a burn rate is trusted over
+const minBurnSpan = time.Minute
+
+// reading is one observed remaining percent of a quota dimension
+type reading struct {
+	at           time.Time
+	remainingPct float64
+}
+
+// burnTracker keeps recent readings of every account dimension and derives
+// how fast each one is being used up.
+type burnTracker struct {
+	mu       sync.Mutex
+	window   time.Duration
+	readings map[string][]reading
+}
+
+func newBurnTracker(window time.Duration) *burnTracker {
+	return &burnTracker{
+		window:   window,
+		readings: make(map[string][]reading),
+	}
+}
+
+// observe records a sample and returns the burn rate of its dimension in
+// percent per hour. ok is false until the readings span minBurnSpan. A rise
+// in remaining percent is a reset and starts the readings over.
+func (t *burnTracker) observe(sample *models.QuotaSample) (perHour float64, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := sample.AccountID + "/" + sample.Dimension
+	list := t.readings[key]
+	if n := len(list); n == 0 || sample.CollectedAt.After(list[n-1].at) {
+		if n > 0 && sample.RemainingPct > list[n-1].remainingPct {
+			list = nil
+		}
+		list = append(list, reading{at: sample.CollectedAt, remainingPct: sample.RemainingPct})
+	}
+
+	cutoff := list[len(list)-1].at.Add(-t.window)
+	for len(list) > 1 && list[0].at.Before(cutoff) {
+		list = list[1:]
+	}
+	t.readings[key] = list
+
+	first, last := list[0], list[len(list)-1]
+	span := last.at.Sub(first.at)
+	if span < minBurnSpan {
+		return 0, false
+	}
+	return (first.remainingPct - last.remainingPct) / span.Hours(), true
+}
+
+// cleanup drops dimensions that have not been read for a whole window
+func (t *burnTracker) cleanup(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cutoff := now.Add(-t.window)
+	for key, list := range t.readings {
+		if len(list) == 0 || list[len(list)-1].at.Before(cutoff) {
+			delete(t.readings, key)
+		}
+	}
+}
+
+// forecast is the predicted exhaustion of an account's fastest-burning
+// dimension
+type forecast struct {
+	dimension    string
+	remainingPct float64
+	burnPerHour  float64
+	exhaustsIn   time.Duration
+	resetAt      *time.Time
+}
+
+// forecastQuota feeds a quota's readings to the tracker and returns the
+// dimension that runs out first. ok is false when nothing is being used up.
+func (t *burnTracker) forecastQuota(accountID string, quota *models.QuotaInfo, now time.Time) (forecast, bool) {
+	at := quota.CollectedAt
+	if at.IsZero() {
+		at = now
+	}
+
+	var best forecast
+	found := false
+	for _, sample := range models.QuotaSamples(accountID, quota, at) {
+		perHour, ok := t.observe(sample)
+		if !ok || perHour <= 0 {
+			continue
+		}
+		left := max(sample.RemainingPct, 0)
+		// Measured from the reading, so a stale quota counts down too
+		exhaustsIn := time.Duration(left/perHour*float64(time.Hour)) - now.Sub(at)
+		if !found || exhaustsIn < best.exhaustsIn {
+			best = forecast{
+				dimension:    sample.Dimension,
+				remainingPct: left,
+				burnPerHour:  perHour,
+				exhaustsIn:   max(exhaustsIn, 0),
+				resetAt:      sample.ResetAt,
+			}
+			found = true
+		}
+	}
+	return best, found
+}
+
+// checkPredictions returns alerts for accounts whose quota is predicted to
+// run out within the longest lead time before it resets, and for provider
+// pools that will run out as a whole before any of their accounts resets.
+func (s *Service) checkPredictions(accounts []models.Account, quotas []models.QuotaInfo, now time.Time) []Alert {
+	s.mu.RLock()
+	leadTimes := s.config.PredictionLeadTimes
+	s.mu.RUnlock()
+	if len(leadTimes) == 0 {
+		return nil
+	}
+	longest, shortest := leadTimes[0], leadTimes[0]
+	for _, lead := range leadTimes[1:] {
+		longest = max(longest, lead)
+		shortest = min(shortest, lead)
+	}
+	severityFor := func(exhaustsIn time.Duration) Severity {
+		if exhaustsIn <= shortest {
+			return SeverityCritical
+		}
+		return SeverityWarning
+	}
+
+	quotaByAccount := make(map[string]*models.QuotaInfo, len(quotas))
+	for i := range quotas {
+		quotaByAccount[quotas[i].AccountID] = &quotas[i]
+	}
+
+	type pool struct {
+		accounts     int
+		remainingPct float64
+		burnPerHour  float64
+		resetAt      *time.Time
+	}
+	pools := make(map[models.Provider]*pool)
+	providers := make([]models.Provider, 0)
+
+	var alerts []Alert
+	for _, account := range accounts {
+		quota, ok := quotaByAccount[account.ID]
+		if !ok {
+			continue
+		}
+		fc, burning := s.burn.forecastQuota(account.ID, quota, now)
+
+		p, ok := pools[account.Provider]
+		if !ok {
+			p = &pool{}
+			pools[account.Provider] = p
+			providers = append(providers, account.Provider)
+		}
+		p.accounts++
+		if reset := nearestResetAt(quota); reset != nil && reset.After(now) && (p.resetAt == nil || reset.Before(*p.resetAt)) {
+			p.resetAt = reset
+		}
+		if !burning {
+			p.remainingPct += max(quota.EffectiveRemainingPct, 0)
+			continue
+		}
+		p.remainingPct += fc.remainingPct
+		p.burnPerHour += fc.burnPerHour
+
+		// Exhausted quota already has its own alert
+		if quota.IsExhausted() || fc.exhaustsIn > longest || !beforeReset(now, fc.exhaustsIn, fc.resetAt) {
+			continue
+		}
+		resetText := ""
+		if fc.resetAt != nil {
+			resetText = fmt.Sprintf(" Resets in %s.", formatLead(fc.resetAt.Sub(now)))
+		}
+		alerts = append(alerts, Alert{
+			ID:        generateAlertID(),
+			AccountID: account.ID,
+			Type:      AlertTypePredictedExhaustion,
+			Severity:  severityFor(fc.exhaustsIn),
+			Message: fmt.Sprintf(
+				"Quota will run out in ~%s: %s at %.1f%%, using %.1f%%/h.%s",
+				formatLead(fc.exhaustsIn),
+				fc.dimension,
+				fc.remainingPct,
+				fc.burnPerHour,
+				resetText,
+			),
+			Current:   fc.remainingPct,
+			Timestamp: now,
+			Metadata: map[string]interface{}{
+				"provider":    string(account.Provider),
+				"tier":        account.Tier,
+				"dimension":   fc.dimension,
+				"exhausts_in": fc.exhaustsIn.String(),
+			},
+		})
+	}
+
+	sort.Slice(providers, func(i, j int) bool { return providers[i] < providers[j] })
+	for _, provider := range providers {
+		p := pools[provider]
+		// A pool of one account is covered by the account alert
+		if p.accounts < 2 || p.burnPerHour <= 0 {
+			continue
+		}
+		exhaustsIn := time.Duration(p.remainingPct / p.burnPerHour * float64(time.Hour))
+		if exhaustsIn > longest || !beforeReset(now, exhaustsIn, p.resetAt) {
+			continue
+		}
+		resetText := " No reset time is known."
+		if p.resetAt != nil {
+			resetText = fmt.Sprintf(" The earliest reset is in %s.", formatLead(p.resetAt.Sub(now)))
+		}
+		alerts = append(alerts, Alert{
+			ID:        generateAlertID(),
+			AccountID: PoolAccountID(string(provider)),
+			Type:      AlertTypePoolExhaustion,
+			Severity:  severityFor(exhaustsIn),
+			Message: fmt.Sprintf(
+				"All %d %s accounts will run out in ~%s.%s",
+				p.accounts,
+				provider,
+				formatLead(exhaustsIn),
+				resetText,
+			),
+			Current:   p.remainingPct / float64(p.accounts),
+			Timestamp: now,
+			Metadata: map[string]interface{}{
+				"provider":    string(provider),
+				"accounts":    p.accounts,
+				"exhausts_in": exhaustsIn.String(),
+			},
+		})
+	}
+	return alerts
+}
+
+// PoolAccountID is the account ID pool-level alerts of a provider carry
+func PoolAccountID(provider string) string {
+	return "pool:" + provider
+}
+
+// beforeReset reports whether exhaustion comes before the reset, if any
+func beforeReset(now time.Time, exhaustsIn time.Duration, resetAt *time.Time) bool {
+	return resetAt == nil || now.Add(exhaustsIn).Before(*resetAt)
+}
+
+// formatLead prints a duration rounded to minutes, such as "25m" or "3h5m"
+func formatLead(d time.Duration) string {
+	d = max(d.Round(time.Minute), time.Minute)
+	hours := int(d / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	}
+}
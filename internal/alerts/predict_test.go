@@ -0,0 +1,172 @@
+package alerts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rpdQuota(accountID string, remaining int64, collectedAt time.Time, resetAt *time.Time) models.QuotaInfo {
+	q := models.QuotaInfo{
+		AccountID:   accountID,
+		Provider:    models.ProviderOpenAI,
+		CollectedAt: collectedAt,
+		Dimensions: models.DimensionSlice{
+			{Type: models.DimensionRPD, Limit: 1000, Used: 1000 - remaining, Remaining: remaining, ResetAt: resetAt},
+		},
+	}
+	q.UpdateEffective()
+	return q
+}
+
+func TestBurnTracker(t *testing.T) {
+	tracker := newBurnTracker(30 * time.Minute)
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	sample := func(pct float64, at time.Time) *models.QuotaSample {
+		return &models.QuotaSample{AccountID: "acc1", Dimension: "RPD", RemainingPct: pct, CollectedAt: at}
+	}
+
+	_, ok := tracker.observe(sample(50, start))
+	assert.False(t, ok)
+	// The same collection seen again adds nothing
+	_, ok = tracker.observe(sample(50, start))
+	assert.False(t, ok)
+
+	rate, ok := tracker.observe(sample(45, start.Add(10*time.Minute)))
+	require.True(t, ok)
+	assert.InDelta(t, 30, rate, 1e-9)
+
+	// Readings older than the window are dropped
+	rate, ok = tracker.observe(sample(20, start.Add(40*time.Minute)))
+	require.True(t, ok)
+	assert.InDelta(t, 50, rate, 1e-9)
+
+	// A reset starts over
+	_, ok = tracker.observe(sample(100, start.Add(45*time.Minute)))
+	assert.False(t, ok)
+
+	tracker.cleanup(start.Add(2 * time.Hour))
+	assert.Empty(t, tracker.readings)
+}
+
+func TestCheckThresholdsPredictedExhaustion(t *testing.T) {
+	config := Config{
+		Thresholds:          []float64{85.0, 95.0},
+		Enabled:             true,
+		PredictionLeadTimes: []time.Duration{time.Hour, 15 * time.Minute},
+	}
+	service := NewService(config, NewMockBot())
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	resetAt := start.Add(3 * time.Hour)
+
+	accounts := []models.Account{{ID: "acc1", Provider: models.ProviderOpenAI}}
+
+	service.now = func() time.Time { return start }
+	alerts := service.CheckThresholds(accounts, []models.QuotaInfo{rpdQuota("acc1", 500, start, &resetAt)})
+	assert.Empty(t, alerts)
+
+	// 500 -> 300 in 10 minutes: 20%/10m, 30% left runs out in 15 minutes
+	now := start.Add(10 * time.Minute)
+	service.now = func() time.Time { return now }
+	alerts = service.CheckThresholds(accounts, []models.QuotaInfo{rpdQuota("acc1", 300, now, &resetAt)})
+	require.Len(t, alerts, 1)
+	assert.Equal(t, AlertTypePredictedExhaustion, alerts[0].Type)
+	assert.Equal(t, SeverityCritical, alerts[0].Severity)
+	assert.Equal(t, "Quota will run out in ~15m: RPD at 30.0%, using 120.0%/h. Resets in 2h50m.", alerts[0].Message)
+
+	// A slower burn within the longest lead time is a warning
+	service = NewService(config, NewMockBot())
+	service.now = func() time.Time { return start }
+	service.CheckThresholds(accounts, []models.QuotaInfo{rpdQuota("acc1", 500, start, &resetAt)})
+	service.now = func() time.Time { return now }
+	alerts = service.CheckThresholds(accounts, []models.QuotaInfo{rpdQuota("acc1", 420, now, &resetAt)})
+	require.Len(t, alerts, 1)
+	assert.Equal(t, SeverityWarning, alerts[0].Severity)
+
+	// Slower still is past the longest lead time
+	service = NewService(config, NewMockBot())
+	service.now = func() time.Time { return start }
+	service.CheckThresholds(accounts, []models.QuotaInfo{rpdQuota("acc1", 500, start, &resetAt)})
+	service.now = func() time.Time { return now }
+	assert.Empty(t, service.CheckThresholds(accounts, []models.QuotaInfo{rpdQuota("acc1", 450, now, &resetAt)}))
+
+	// Nothing is predicted when the quota resets first
+	soon := start.Add(20 * time.Minute)
+	service = NewService(config, NewMockBot())
+	service.now = func() time.Time { return start }
+	service.CheckThresholds(accounts, []models.QuotaInfo{rpdQuota("acc1", 500, start, &soon)})
+	service.now = func() time.Time { return now }
+	alerts = service.CheckThresholds(accounts, []models.QuotaInfo{rpdQuota("acc1", 300, now, &soon)})
+	assert.Empty(t, alerts)
+
+	// Without lead times prediction is off
+	service = NewService(Config{Thresholds: []float64{85.0, 95.0}, Enabled: true}, NewMockBot())
+	service.now = func() time.Time { return start }
+	service.CheckThresholds(accounts, []models.QuotaInfo{rpdQuota("acc1", 500, start, &resetAt)})
+	service.now = func() time.Time { return now }
+	assert.Empty(t, service.CheckThresholds(accounts, []models.QuotaInfo{rpdQuota("acc1", 300, now, &resetAt)}))
+}
+
+func TestCheckThresholdsPoolExhaustion(t *testing.T) {
+	config := Config{
+		Thresholds:          []float64{85.0, 95.0},
+		Enabled:             true,
+		PredictionLeadTimes: []time.Duration{time.Hour, 15 * time.Minute},
+	}
+	service := NewService(config, NewMockBot())
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	now := start.Add(10 * time.Minute)
+	resetAt := start.Add(3 * time.Hour)
+
+	accounts := []models.Account{
+		{ID: "acc1", Provider: models.ProviderOpenAI},
+		{ID: "acc2", Provider: models.ProviderOpenAI},
+	}
+
+	// acc1 burns 10%/10m and will run out on its own, but idle acc2 keeps
+	// the pool going: 70% lasts 70 minutes at 60%/h
+	service.now = func() time.Time { return start }
+	service.CheckThresholds(accounts, []models.QuotaInfo{
+		rpdQuota("acc1", 600, start, &resetAt),
+		rpdQuota("acc2", 200, start, &resetAt),
+	})
+	service.now = func() time.Time { return now }
+	alerts := service.CheckThresholds(accounts, []models.QuotaInfo{
+		rpdQuota("acc1", 500, now, &resetAt),
+		rpdQuota("acc2", 200, now, &resetAt),
+	})
+	require.Len(t, alerts, 1)
+	assert.Equal(t, AlertTypePredictedExhaustion, alerts[0].Type)
+
+	// acc2 starts burning as well: 55% left at 75%/h runs out in 44 minutes
+	later := now.Add(10 * time.Minute)
+	service.now = func() time.Time { return later }
+	alerts = service.CheckThresholds(accounts, []models.QuotaInfo{
+		rpdQuota("acc1", 450, later, &resetAt),
+		rpdQuota("acc2", 100, later, &resetAt),
+	})
+	var pool *Alert
+	for i := range alerts {
+		if alerts[i].Type == AlertTypePoolExhaustion {
+			pool = &alerts[i]
+		}
+	}
+	require.NotNil(t, pool)
+	assert.Equal(t, PoolAccountID("openai"), pool.AccountID)
+	assert.Equal(t, SeverityWarning, pool.Severity)
+	assert.Equal(t, "All 2 openai accounts will run out in ~44m. The earliest reset is in 2h40m.", pool.Message)
+
+	// Distinct pools never share a dedup key
+	other := Alert{AccountID: PoolAccountID("gemini"), Type: AlertTypePoolExhaustion, Severity: SeverityWarning}
+	assert.NotEqual(t, pool.AlertKey(), other.AlertKey())
+}
+
+func TestFormatLead(t *testing.T) {
+	assert.Equal(t, "1m", formatLead(10*time.Second))
+	assert.Equal(t, "25m", formatLead(25*time.Minute+10*time.Second))
+	assert.Equal(t, "3h", formatLead(3*time.Hour))
+	assert.Equal(t, "2h5m", formatLead(2*time.Hour+5*time.Minute))
+}
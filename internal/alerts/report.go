@@ -0,0 +1,335 @@
+package alerts
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// DigestPeriod is the span of complete UTC days a digest covers
+type DigestPeriod string
+
+const (
+	// DigestDaily covers the previous day
+	DigestDaily DigestPeriod = "daily"
+	// DigestWeekly covers the previous seven days
+	DigestWeekly DigestPeriod = "weekly"
+)
+
+// maxSampleGap is the longest a quota history sample is taken to describe
+// the quota; a longer gap means nothing was collected
+const maxSampleGap = time.Hour
+
+// ParseDigestPeriod parses "daily" or "weekly"
+func ParseDigestPeriod(s string) (DigestPeriod, error) {
+	switch period := DigestPeriod(strings.ToLower(strings.TrimSpace(s))); period {
+	case DigestDaily, DigestWeekly:
+		return period, nil
+	default:
+		return "", fmt.Errorf("unknown digest period %q (daily, weekly)", s)
+	}
+}
+
+// Days returns the number of days the period covers
+func (p DigestPeriod) Days() int {
+	if p == DigestWeekly {
+		return 7
+	}
+	return 1
+}
+
+// DigestSource is the persisted data a digest is computed from
+type DigestSource interface {
+	ListAccounts() []*models.Account
+	ListSpend(fromDay, toDay string) ([]*models.SpendEntry, error)
+	ListQuotaHistory(accountID string, from, to time.Time) ([]*models.QuotaSample, error)
+	ListAlertIncidents(statuses ...models.IncidentStatus) ([]*models.AlertIncident, error)
+	SummarizeRoutingEvents(from, to time.Time) (*models.RoutingEventSummary, error)
+}
+
+// BuildDigest computes the digest of the complete UTC days of period that
+// end before until's day, along with the totals of the period before it.
+func BuildDigest(src DigestSource, period DigestPeriod, until time.Time) (*DigestData, error) {
+	until = until.UTC()
+	to := time.Date(until.Year(), until.Month(), until.Day(), 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -period.Days())
+
+	digest, err := buildDigest(src, from, to)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := buildDigest(src, from.AddDate(0, 0, -period.Days()), from)
+	if err != nil {
+		return nil, err
+	}
+	digest.Period = period
+	digest.Previous = previous.totals()
+	return digest, nil
+}
+
+// buildDigest computes a digest of [from, to)
+func buildDigest(src DigestSource, from, to time.Time) (*DigestData, error) {
+	digest := &DigestData{
+		Date:          from,
+		From:          from,
+		To:            to,
+		ErrorsByClass: map[string]int{},
+		TopAccounts:   []AccountUsage{},
+		Accounts:      []AccountUsage{},
+		Providers:     []ProviderUsage{},
+		Alerts:        []AlertSummary{},
+	}
+	// Switches and errors come from the persisted routing decisions and
+	// the feedback reported for them
+	routing, err := src.SummarizeRoutingEvents(from, to)
+	if err != nil {
+		return nil, err
+	}
+	digest.Switches = routing.Switches
+	for reported, count := range routing.Failures {
+		digest.ErrorsByClass[feedbackErrorClass(reported)] += count
+		digest.Errors += count
+	}
+
+	entries, err := src.ListSpend(models.UsageDay(from), models.UsageDay(to.Add(-time.Nanosecond)))
+	if err != nil {
+		return nil, err
+	}
+	spend := make(map[string]*AccountUsage)
+	for _, entry := range entries {
+		usage, ok := spend[entry.AccountID]
+		if !ok {
+			usage = &AccountUsage{AccountID: entry.AccountID}
+			spend[entry.AccountID] = usage
+		}
+		usage.Requests += entry.Requests
+		usage.InputTokens += entry.InputTokens
+		usage.OutputTokens += entry.OutputTokens
+		usage.CostUSD += entry.CostUSD
+	}
+
+	accounts := src.ListAccounts()
+	known := make(map[string]bool, len(accounts))
+	for _, acc := range accounts {
+		known[acc.ID] = true
+		usage := AccountUsage{AccountID: acc.ID, Provider: string(acc.Provider)}
+		if s, ok := spend[acc.ID]; ok {
+			usage.Requests, usage.InputTokens, usage.OutputTokens, usage.CostUSD = s.Requests, s.InputTokens, s.OutputTokens, s.CostUSD
+		}
+		samples, err := src.ListQuotaHistory(acc.ID, from.Add(-maxSampleGap), to)
+		if err != nil {
+			return nil, err
+		}
+		applyHistory(&usage, samples, from, to)
+		digest.Accounts = append(digest.Accounts, usage)
+	}
+	// Spend of accounts removed since is still counted
+	for id, usage := range spend {
+		if !known[id] {
+			digest.Accounts = append(digest.Accounts, *usage)
+		}
+	}
+
+	sort.SliceStable(digest.Accounts, func(i, j int) bool {
+		a, b := digest.Accounts[i], digest.Accounts[j]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return a.AccountID < b.AccountID
+	})
+
+	providers := make(map[string]*ProviderUsage)
+	for _, usage := range digest.Accounts {
+		digest.TotalRequests += usage.Requests
+		digest.InputTokens += usage.InputTokens
+		digest.OutputTokens += usage.OutputTokens
+		digest.ExhaustedHours += usage.ExhaustedHours
+
+		name := usage.Provider
+		if name == "" {
+			name = string(models.ProviderOther)
+		}
+		p, ok := providers[name]
+		if !ok {
+			p = &ProviderUsage{Provider: name}
+			providers[name] = p
+		}
+		p.Accounts++
+		p.Requests += usage.Requests
+		p.InputTokens += usage.InputTokens
+		p.OutputTokens += usage.OutputTokens
+		p.CostUSD += usage.CostUSD
+		p.ExhaustedHours += usage.ExhaustedHours
+	}
+	for _, p := range providers {
+		digest.Providers = append(digest.Providers, *p)
+	}
+	sort.Slice(digest.Providers, func(i, j int) bool {
+		a, b := digest.Providers[i], digest.Providers[j]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return a.Provider < b.Provider
+	})
+
+	incidents, err := src.ListAlertIncidents()
+	if err != nil {
+		return nil, err
+	}
+	var opened []Alert
+	for _, incident := range incidents {
+		if !incident.OpenedAt.Before(from) && incident.OpenedAt.Before(to) {
+			opened = append(opened, Alert{Severity: Severity(incident.Severity), Timestamp: incident.OpenedAt})
+		}
+	}
+	digest.Alerts = GenerateDigest(opened, nil).Alerts
+
+	return digest, nil
+}
+
+// feedbackErrorClass maps the error a client reported in feedback, such as
+// "429" or "upstream timeout", to the poll error classes
+func feedbackErrorClass(reported string) string {
+	text := strings.ToLower(reported)
+	contains := func(words ...string) bool {
+		for _, word := range words {
+			if strings.Contains(text, word) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case contains("429", "rate limit", "rate_limit", "ratelimit", "too many"):
+		return string(models.PollErrorRateLimited)
+	case contains("401", "403", "unauthorized", "forbidden", "auth"):
+		return string(models.PollErrorAuth)
+	case contains("500", "502", "503", "504", "529", "5xx", "overloaded", "upstream", "server error"):
+		return string(models.PollErrorUpstream)
+	case contains("timeout", "deadline", "connection", "network", "eof"):
+		return string(models.PollErrorNetwork)
+	default:
+		return string(models.PollErrorUnknown)
+	}
+}
+
+// applyHistory derives from an account's quota history the peak usage,
+// the hours it had a dimension exhausted and the quota left unused when
+// its dimensions reset, within [from, to). Samples may start earlier so
+// the state at from is known.
+func applyHistory(usage *AccountUsage, samples []*models.QuotaSample, from, to time.Time) {
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].CollectedAt.Before(samples[j].CollectedAt) })
+
+	// The lowest dimension of every collection
+	type point struct {
+		at     time.Time
+		lowest float64
+	}
+	var points []point
+	lowest := 100.0
+	seen := false
+	byDimension := make(map[string]*models.QuotaSample)
+	var wasted float64
+	for _, sample := range samples {
+		if n := len(points); n > 0 && points[n-1].at.Equal(sample.CollectedAt) {
+			points[n-1].lowest = min(points[n-1].lowest, sample.RemainingPct)
+		} else {
+			points = append(points, point{at: sample.CollectedAt, lowest: sample.RemainingPct})
+		}
+		inRange := !sample.CollectedAt.Before(from) && sample.CollectedAt.Before(to)
+		if inRange {
+			lowest = min(lowest, sample.RemainingPct)
+			seen = true
+		}
+
+		if prev, ok := byDimension[sample.Dimension]; ok {
+			if at, reset := models.ResetBetween(prev, sample); reset && !at.Before(from) && at.Before(to) {
+				usage.Resets++
+				wasted += max(prev.RemainingPct, 0)
+			}
+		}
+		byDimension[sample.Dimension] = sample
+	}
+	if seen {
+		usage.UsagePercent = 100 - max(lowest, 0)
+	}
+	if usage.Resets > 0 {
+		usage.WastedPct = wasted / float64(usage.Resets)
+	}
+
+	var exhausted time.Duration
+	for i, p := range points {
+		if p.lowest > 0 {
+			continue
+		}
+		end := p.at.Add(maxSampleGap)
+		if i+1 < len(points) && points[i+1].at.Before(end) {
+			end = points[i+1].at
+		}
+		start := p.at
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			exhausted += end.Sub(start)
+		}
+	}
+	usage.ExhaustedHours = exhausted.Hours()
+}
+
+// totals returns the headline numbers of a digest
+func (d *DigestData) totals() *DigestTotals {
+	return &DigestTotals{
+		From:           d.From,
+		To:             d.To,
+		Requests:       d.TotalRequests,
+		InputTokens:    d.InputTokens,
+		OutputTokens:   d.OutputTokens,
+		Switches:       d.Switches,
+		Errors:         d.Errors,
+		ExhaustedHours: d.ExhaustedHours,
+	}
+}
+
+// digestCSVHeader is the header row of WriteDigestCSV
+var digestCSVHeader = []string{
+	"account_id", "provider", "requests", "input_tokens", "output_tokens", "cost_usd",
+	"peak_usage_pct", "exhausted_hours", "resets", "wasted_pct",
+}
+
+// WriteDigestCSV writes one row per account of a digest
+func WriteDigestCSV(w io.Writer, digest *DigestData) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(digestCSVHeader); err != nil {
+		return err
+	}
+	formatFloat := func(f float64) string { return strconv.FormatFloat(f, 'f', 2, 64) }
+	for _, usage := range digest.Accounts {
+		row := []string{
+			usage.AccountID,
+			usage.Provider,
+			strconv.FormatInt(usage.Requests, 10),
+			strconv.FormatInt(usage.InputTokens, 10),
+			strconv.FormatInt(usage.OutputTokens, 10),
+			strconv.FormatFloat(usage.CostUSD, 'f', 4, 64),
+			formatFloat(usage.UsagePercent),
+			formatFloat(usage.ExhaustedHours),
+			strconv.Itoa(usage.Resets),
+			formatFloat(usage.WastedPct),
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,225 @@
+package alerts
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDigestSource struct {
+	accounts  []*models.Account
+	spend     []*models.SpendEntry
+	history   []*models.QuotaSample
+	incidents []*models.AlertIncident
+	routing   []*models.RoutingEvent
+}
+
+func (f *fakeDigestSource) ListAccounts() []*models.Account { return f.accounts }
+
+func (f *fakeDigestSource) ListSpend(fromDay, toDay string) ([]*models.SpendEntry, error) {
+	var result []*models.SpendEntry
+	for _, entry := range f.spend {
+		if entry.Day >= fromDay && entry.Day <= toDay {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeDigestSource) ListQuotaHistory(accountID string, from, to time.Time) ([]*models.QuotaSample, error) {
+	var result []*models.QuotaSample
+	for _, sample := range f.history {
+		if sample.AccountID == accountID && !sample.CollectedAt.Before(from) && !sample.CollectedAt.After(to) {
+			result = append(result, sample)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeDigestSource) ListAlertIncidents(_ ...models.IncidentStatus) ([]*models.AlertIncident, error) {
+	return f.incidents, nil
+}
+
+func (f *fakeDigestSource) SummarizeRoutingEvents(from, to time.Time) (*models.RoutingEventSummary, error) {
+	summary := &models.RoutingEventSummary{Failures: map[string]int{}}
+	filter := models.RoutingEventFilter{Since: from, Until: to}
+	for _, event := range f.routing {
+		if !filter.Matches(event) {
+			continue
+		}
+		summary.Events++
+		if event.SwitchedFrom != "" {
+			summary.Switches++
+		}
+		if event.Outcome != nil && !event.Outcome.Success {
+			summary.Failures[event.Outcome.Error]++
+		}
+	}
+	return summary, nil
+}
+
+func newFakeDigestSource() *fakeDigestSource {
+	day := time.Date(2026, 5, 7, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+	firstReset, secondReset := at(2, 0), at(12, 0)
+	sample := func(h, m int, pct float64, resetAt *time.Time) *models.QuotaSample {
+		return &models.QuotaSample{AccountID: "acc1", Dimension: "RPD", RemainingPct: pct, ResetAt: resetAt, CollectedAt: at(h, m)}
+	}
+
+	return &fakeDigestSource{
+		accounts: []*models.Account{
+			{ID: "acc1", Provider: models.ProviderOpenAI},
+			{ID: "acc2", Provider: models.ProviderOpenAI},
+			{ID: "acc3", Provider: models.ProviderGemini},
+		},
+		spend: []*models.SpendEntry{
+			{AccountID: "acc1", Day: "2026-05-07", Requests: 100, InputTokens: 1000, OutputTokens: 500, CostUSD: 1},
+			{AccountID: "acc1", Day: "2026-05-06", Requests: 80},
+			{AccountID: "acc2", Day: "2026-05-07", Requests: 50},
+			{AccountID: "removed", Day: "2026-05-07", Requests: 10},
+		},
+		history: []*models.QuotaSample{
+			sample(-1, 30, 10, &firstReset),
+			sample(0, 0, 0, &firstReset),
+			sample(1, 0, 0, &firstReset),
+			// Exhausted until the reset, but nothing was collected for over
+			// an hour so only an hour counts
+			sample(2, 5, 100, &secondReset),
+			sample(10, 0, 40, &secondReset),
+			sample(12, 10, 100, nil),
+		},
+		incidents: []*models.AlertIncident{
+			{ID: "inc-1", Severity: string(SeverityCritical), OpenedAt: at(9, 0)},
+			{ID: "inc-2", Severity: string(SeverityWarning), OpenedAt: day.Add(-time.Hour)},
+		},
+		routing: []*models.RoutingEvent{
+			{AccountID: "acc1", CreatedAt: at(1, 0), Outcome: &models.RoutingOutcome{Success: true}},
+			{AccountID: "acc2", CreatedAt: at(3, 0), SwitchedFrom: "acc1"},
+			{AccountID: "acc1", CreatedAt: at(5, 0), SwitchedFrom: "acc2"},
+			{AccountID: "acc2", CreatedAt: at(12, 0), SwitchedFrom: "acc1", Outcome: &models.RoutingOutcome{Error: "429 Too Many Requests"}},
+			{AccountID: "acc1", CreatedAt: at(-2, 0), SwitchedFrom: "acc2"},
+			{AccountID: "acc3", CreatedAt: day.AddDate(0, 0, -6), Outcome: &models.RoutingOutcome{Error: "connection reset by peer"}},
+		},
+	}
+}
+
+func TestBuildDigestDaily(t *testing.T) {
+	src := newFakeDigestSource()
+	day := time.Date(2026, 5, 7, 0, 0, 0, 0, time.UTC)
+
+	digest, err := BuildDigest(src, DigestDaily, day.Add(34*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, DigestDaily, digest.Period)
+	assert.Equal(t, day, digest.From)
+	assert.Equal(t, day.AddDate(0, 0, 1), digest.To)
+	assert.Equal(t, int64(160), digest.TotalRequests)
+	assert.Equal(t, int64(1000), digest.InputTokens)
+	assert.Equal(t, 3, digest.Switches)
+	assert.Equal(t, 1, digest.Errors)
+	assert.Equal(t, map[string]int{"rate_limited": 1}, digest.ErrorsByClass)
+	assert.InDelta(t, 2, digest.ExhaustedHours, 1e-9)
+
+	require.Len(t, digest.Accounts, 4)
+	acc1 := digest.Accounts[0]
+	assert.Equal(t, "acc1", acc1.AccountID)
+	assert.InDelta(t, 100, acc1.UsagePercent, 1e-9)
+	assert.InDelta(t, 2, acc1.ExhaustedHours, 1e-9)
+	assert.Equal(t, 2, acc1.Resets)
+	assert.InDelta(t, 20, acc1.WastedPct, 1e-9)
+	assert.Equal(t, "removed", digest.Accounts[2].AccountID)
+	assert.Equal(t, "acc3", digest.Accounts[3].AccountID)
+
+	require.Len(t, digest.Providers, 3)
+	assert.Equal(t, ProviderUsage{Provider: "openai", Accounts: 2, Requests: 150, InputTokens: 1000, OutputTokens: 500, CostUSD: 1, ExhaustedHours: 2}, digest.Providers[0])
+	assert.Equal(t, "other", digest.Providers[1].Provider)
+
+	require.Len(t, digest.Alerts, 1)
+	assert.Equal(t, SeverityCritical, digest.Alerts[0].Severity)
+
+	require.NotNil(t, digest.Previous)
+	assert.Equal(t, int64(80), digest.Previous.Requests)
+	assert.Equal(t, 1, digest.Previous.Switches)
+
+	text := FormatDigest(digest)
+	assert.Contains(t, text, "Daily Digest* - 2026-05-07")
+	assert.Contains(t, text, "• Requests: 160 (+100%)")
+	assert.Contains(t, text, "• Errors: 1 (rate_limited 1) (new)")
+	assert.Contains(t, text, "• acc1: 100 req, peak 100.0%, exhausted 2.0h, 20.0% unused at 2 reset(s)")
+	assert.Contains(t, text, "compare with the day before")
+}
+
+func TestBuildDigestWeekly(t *testing.T) {
+	src := newFakeDigestSource()
+	until := time.Date(2026, 5, 8, 9, 0, 0, 0, time.UTC)
+
+	digest, err := BuildDigest(src, DigestWeekly, until)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), digest.From)
+	assert.Equal(t, int64(240), digest.TotalRequests)
+	assert.Equal(t, 2, digest.Errors)
+	assert.Equal(t, map[string]int{"rate_limited": 1, "network": 1}, digest.ErrorsByClass)
+	assert.Equal(t, 4, digest.Switches)
+	require.Len(t, digest.Alerts, 2)
+	assert.Equal(t, int64(0), digest.Previous.Requests)
+	assert.Equal(t, time.Date(2026, 4, 24, 0, 0, 0, 0, time.UTC), digest.Previous.From)
+
+	text := FormatDigest(digest)
+	assert.Contains(t, text, "Weekly Digest* - 2026-05-01 – 2026-05-07")
+	assert.Contains(t, text, "• Requests: 240 (new)")
+	assert.Contains(t, text, "compare with the week before")
+}
+
+func TestWriteDigestCSV(t *testing.T) {
+	digest, err := BuildDigest(newFakeDigestSource(), DigestDaily, time.Date(2026, 5, 8, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteDigestCSV(&buf, digest))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 5)
+	assert.Equal(t, "account_id,provider,requests,input_tokens,output_tokens,cost_usd,peak_usage_pct,exhausted_hours,resets,wasted_pct", lines[0])
+	assert.Equal(t, "acc1,openai,100,1000,500,1.0000,100.00,2.00,2,20.00", lines[1])
+}
+
+func TestFeedbackErrorClass(t *testing.T) {
+	for reported, class := range map[string]models.PollErrorClass{
+		"429":                       models.PollErrorRateLimited,
+		"rate_limit_exceeded":       models.PollErrorRateLimited,
+		"401 Unauthorized":          models.PollErrorAuth,
+		"503 Service Unavailable":   models.PollErrorUpstream,
+		"overloaded_error":          models.PollErrorUpstream,
+		"context deadline exceeded": models.PollErrorNetwork,
+		"":                          models.PollErrorUnknown,
+		"content filtered":          models.PollErrorUnknown,
+	} {
+		assert.Equal(t, string(class), feedbackErrorClass(reported), reported)
+	}
+}
+
+func TestParseDigestPeriod(t *testing.T) {
+	period, err := ParseDigestPeriod("Weekly")
+	require.NoError(t, err)
+	assert.Equal(t, DigestWeekly, period)
+	assert.Equal(t, 7, period.Days())
+	_, err = ParseDigestPeriod("monthly")
+	assert.Error(t, err)
+}
+
+func TestServiceDigestSource(t *testing.T) {
+	bot := NewMockBot()
+	service := NewService(Config{Enabled: true, DailyDigestEnabled: true}, bot)
+	service.now = func() time.Time { return time.Date(2026, 5, 8, 9, 0, 0, 0, time.UTC) }
+	service.SetDigestSource(newFakeDigestSource())
+
+	require.NoError(t, service.SendDailyDigest())
+	require.NoError(t, service.SendWeeklyDigest())
+	messages := bot.GetMessages()
+	require.Len(t, messages, 2)
+	assert.Contains(t, messages[0], "• Requests: 160")
+	assert.Contains(t, messages[1], "Weekly Digest")
+}
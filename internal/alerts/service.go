@@ -25,9 +25,26 @@ type Config struct {
 	Timezone           string
 	RateLimitPerMinute int
 	DailyDigestEnabled bool
-	Enabled            bool
-	ShutdownTimeout    time.Duration
-	MuteDuration       time.Duration
+	// WeeklyDigestEnabled sends a weekly digest with week-over-week trends
+	// at DailyDigestTime on WeeklyDigestDay
+	WeeklyDigestEnabled bool
+	WeeklyDigestDay     time.Weekday
+	Enabled             bool
+	ShutdownTimeout     time.Duration
+	MuteDuration        time.Duration
+	// PredictionLeadTimes enables predictive alerts. Quota predicted to run
+	// out within the longest lead time, before it resets, raises a warning;
+	// within the shortest one it is critical.
+	PredictionLeadTimes []time.Duration
+	// PredictionWindow is how far back burn rate is measured.
+	// Default: 30m
+	PredictionWindow time.Duration
+	// EscalateAfter notifies again about incidents nobody has acknowledged
+	// for this long. Zero disables escalation.
+	EscalateAfter time.Duration
+	// EscalationChatID also receives escalated incidents, if the bot can
+	// send to other chats
+	EscalationChatID int64
 }
 
 // Service manages alerts and notifications
@@ -37,7 +54,13 @@ type Service struct {
 	dedup     *DedupStore
 	throttler *Throttler
 	digest    *DigestScheduler
+	weekly    *DigestScheduler
 	muteState *MuteState
+	burn      *burnTracker
+	incidents IncidentStore
+	spendFn   func(day time.Time) ([]AccountSpend, error)
+	digestSrc DigestSource
+	now       func() time.Time
 
 	// Channels
 	alertChan   chan Alert
@@ -80,6 +103,9 @@ func NewService(config Config, bot TelegramBot, opts ...ServiceOption) *Service
 	if config.ShutdownTimeout == 0 {
 		config.ShutdownTimeout = 25 * time.Second
 	}
+	if config.PredictionWindow == 0 {
+		config.PredictionWindow = DefaultPredictionWindow
+	}
 
 	s := &Service{
 		config:      config,
@@ -87,6 +113,8 @@ func NewService(config Config, bot TelegramBot, opts ...ServiceOption) *Service
 		alertChan:   make(chan Alert, 100),
 		pendingChan: make(chan Alert, 1000),
 		dedupWindow: 30 * time.Minute,
+		burn:        newBurnTracker(config.PredictionWindow),
+		now:         time.Now,
 	}
 
 	for _, opt := range opts {
@@ -114,6 +142,16 @@ func NewService(config Config, bot TelegramBot, opts ...ServiceOption) *Service
 		// Fall back to defaults if timezone is invalid
 		s.digest, _ = NewDigestScheduler("UTC", "09:00", s.generateDigest, s.sendDigest)
 	}
+	s.weekly, err = NewWeeklyDigestScheduler(
+		s.config.Timezone,
+		s.config.DailyDigestTime,
+		s.config.WeeklyDigestDay,
+		s.generateWeeklyDigest,
+		s.sendDigest,
+	)
+	if err != nil {
+		s.weekly, _ = NewWeeklyDigestScheduler("UTC", "09:00", s.config.WeeklyDigestDay, s.generateWeeklyDigest, s.sendDigest)
+	}
 
 	return s
 }
@@ -134,11 +172,18 @@ func (s *Service) Start() {
 	s.wg.Add(2)
 	go s.processAlerts()
 	go s.cleanupLoop()
+	if s.incidents != nil && s.config.EscalateAfter > 0 {
+		s.wg.Add(1)
+		go s.escalationLoop()
+	}
 
 	// Start digest scheduler if enabled
 	if s.config.DailyDigestEnabled {
 		s.digest.Start()
 	}
+	if s.config.WeeklyDigestEnabled {
+		s.weekly.Start()
+	}
 }
 
 // Stop gracefully stops the alert service
@@ -160,6 +205,9 @@ func (s *Service) Stop() error {
 	if s.digest != nil {
 		s.digest.Stop()
 	}
+	if s.weekly != nil {
+		s.weekly.Stop()
+	}
 
 	// Flush pending alerts
 	s.flushPendingAlerts()
@@ -200,6 +248,7 @@ func (s *Service) CheckThresholds(accounts []models.Account, quotas []models.Quo
 	}
 
 	var alerts []Alert
+	now := s.now()
 
 	for _, account := range accounts {
 		// Find quota for this account
@@ -267,7 +316,7 @@ func (s *Service) CheckThresholds(accounts []models.Account, quotas []models.Quo
 				),
 				Threshold: highestExceededThreshold,
 				Current:   usedPercent,
-				Timestamp: time.Now(),
+				Timestamp: now,
 				Metadata: map[string]interface{}{
 					"provider": string(account.Provider),
 					"tier":     account.Tier,
@@ -290,7 +339,7 @@ func (s *Service) CheckThresholds(accounts []models.Account, quotas []models.Quo
 				Type:      AlertTypeExhausted,
 				Severity:  SeverityCritical,
 				Message:   "Quota exhausted." + resetText,
-				Timestamp: time.Now(),
+				Timestamp: now,
 				Metadata: map[string]interface{}{
 					"provider": string(account.Provider),
 					"tier":     account.Tier,
@@ -300,6 +349,8 @@ func (s *Service) CheckThresholds(accounts []models.Account, quotas []models.Quo
 		}
 	}
 
+	alerts = append(alerts, s.checkPredictions(accounts, quotas, now)...)
+	s.resolveRecovered(accounts, quotas, alerts, now)
 	return alerts
 }
 
@@ -331,6 +382,17 @@ func (s *Service) ProcessAlert(alert Alert) error {
 		return fmt.Errorf("alerts are muted for %v", s.muteState.RemainingMuteTime())
 	}
 
+	// Acknowledged, snoozed and muted incidents are tracked but not sent
+	if s.incidents != nil {
+		notify, err := s.trackIncident(&alert)
+		if err != nil {
+			return fmt.Errorf("failed to track incident: %w", err)
+		}
+		if !notify {
+			return nil
+		}
+	}
+
 	// Check deduplication
 	key := alert.AlertKey()
 	if s.dedup.IsDuplicate(key) {
@@ -414,6 +476,20 @@ func (s *Service) SendDailyDigest() error {
 	return s.sendDigest(digest)
 }
 
+// SendWeeklyDigest sends the weekly digest immediately
+func (s *Service) SendWeeklyDigest() error {
+	if !s.config.Enabled {
+		return nil
+	}
+
+	digest, err := s.generateWeeklyDigest()
+	if err != nil {
+		return err
+	}
+
+	return s.sendDigest(digest)
+}
+
 // ScheduleDaily schedules daily digest at the specified time
 func (s *Service) ScheduleDaily() {
 	if s.digest != nil && s.config.DailyDigestEnabled {
@@ -478,6 +554,7 @@ func (s *Service) cleanupLoop() {
 			return
 		case <-ticker.C:
 			s.dedup.Cleanup()
+			s.burn.cleanup(s.now())
 		}
 	}
 }
@@ -488,15 +565,19 @@ func (s *Service) sendAlert(alert Alert) {
 		return
 	}
 
-	tgAlert := telegram.Alert{
-		ID:        alert.ID,
-		Severity:  string(alert.Severity),
-		Message:   alert.Message,
-		AccountID: alert.AccountID,
-		Timestamp: alert.Timestamp,
-	}
+	_ = s.bot.SendAlert(toTelegramAlert(alert))
+}
 
-	_ = s.bot.SendAlert(tgAlert)
+// toTelegramAlert converts an alert for the bot
+func toTelegramAlert(alert Alert) telegram.Alert {
+	return telegram.Alert{
+		ID:         alert.ID,
+		Severity:   string(alert.Severity),
+		Message:    alert.Message,
+		AccountID:  alert.AccountID,
+		Timestamp:  alert.Timestamp,
+		IncidentID: alert.IncidentID,
+	}
 }
 
 // sendDigest sends a digest via the bot
@@ -524,18 +605,63 @@ func (s *Service) sendDigest(digest *DigestData) error {
 	return nil
 }
 
+// SetSpendSource sets the function that reports per-account spend for a day
+func (s *Service) SetSpendSource(fn func(day time.Time) ([]AccountSpend, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.spendFn = fn
+}
+
+// SetDigestSource computes digests from persisted usage, quota history,
+// routing decisions and incidents
+func (s *Service) SetDigestSource(src DigestSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.digestSrc = src
+}
+
 // generateDigest generates the daily digest
 func (s *Service) generateDigest() (*DigestData, error) {
-	// This would normally query actual data from the system
-	// For now, return an empty digest
-	return &DigestData{
-		Date:          time.Now(),
-		TotalRequests: 0,
-		Switches:      0,
-		Errors:        0,
-		TopAccounts:   []AccountUsage{},
-		Alerts:        []AlertSummary{},
-	}, nil
+	return s.buildDigest(DigestDaily)
+}
+
+// generateWeeklyDigest generates the weekly digest
+func (s *Service) generateWeeklyDigest() (*DigestData, error) {
+	return s.buildDigest(DigestWeekly)
+}
+
+// buildDigest computes the digest of the previous complete day or week.
+// Without a digest source only spend is reported.
+func (s *Service) buildDigest(period DigestPeriod) (*DigestData, error) {
+	now := s.now()
+
+	s.mu.RLock()
+	spendFn, src := s.spendFn, s.digestSrc
+	s.mu.RUnlock()
+
+	digest := &DigestData{
+		Date:        now,
+		Period:      period,
+		TopAccounts: []AccountUsage{},
+		Alerts:      []AlertSummary{},
+	}
+	if src != nil {
+		var err error
+		if digest, err = BuildDigest(src, period, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if spendFn != nil && period == DigestDaily {
+		// The digest covers the previous (complete) day
+		spend, err := spendFn(now.AddDate(0, 0, -1))
+		if err != nil {
+			return nil, err
+		}
+		digest.Spend = spend
+	}
+
+	return digest, nil
 }
 
 // flushPendingAlerts flushes any pending alerts before shutdown
@@ -499,6 +499,32 @@ func TestSendDailyDigest(t *testing.T) {
 	assert.Contains(t, messages[0], "Daily Digest")
 }
 
+func TestSendDailyDigestWithSpend(t *testing.T) {
+	bot := NewMockBot()
+	config := Config{
+		Timezone:           "UTC",
+		Enabled:            true,
+		DailyDigestEnabled: true,
+	}
+
+	service := NewService(config, bot)
+	var requestedDay time.Time
+	service.SetSpendSource(func(day time.Time) ([]AccountSpend, error) {
+		requestedDay = day
+		return []AccountSpend{
+			{AccountID: "api-key", Requests: 12, CostUSD: 3.5, MonthToDateUSD: 40, MonthlyBudgetUSD: 100},
+		}, nil
+	})
+
+	assert.NoError(t, service.SendDailyDigest())
+	assert.Equal(t, models.UsageDay(time.Now().AddDate(0, 0, -1)), models.UsageDay(requestedDay))
+
+	messages := bot.GetMessages()
+	assert.Len(t, messages, 1)
+	assert.Contains(t, messages[0], "*Spend:* $3.50")
+	assert.Contains(t, messages[0], "api-key: $3.50 (12 req), month $40.00 / $100.00")
+}
+
 func TestSendDailyDigestDisabled(t *testing.T) {
 	bot := NewMockBot()
 	config := Config{
@@ -28,6 +28,10 @@ const (
 	AlertTypeExhausted AlertType = "exhausted"
 	// AlertTypeError is for error alerts
 	AlertTypeError AlertType = "error"
+	// AlertTypePredictedExhaustion is for quota predicted to run out before it resets
+	AlertTypePredictedExhaustion AlertType = "predicted_exhaustion"
+	// AlertTypePoolExhaustion is for a provider pool predicted to run out as a whole
+	AlertTypePoolExhaustion AlertType = "pool_exhaustion"
 	// AlertTypeDailyDigest is for daily digest
 	AlertTypeDailyDigest AlertType = "daily_digest"
 )
@@ -43,6 +47,8 @@ type Alert struct {
 	Current   float64
 	Timestamp time.Time
 	Metadata  map[string]interface{}
+	// IncidentID is the incident the alert belongs to, if incidents are tracked
+	IncidentID string
 }
 
 // AlertKey creates a unique key for deduplication
@@ -57,29 +63,83 @@ type AlertRecord struct {
 	Count    int
 }
 
-// DigestData represents data for daily digest
+// DigestData represents data for a daily or weekly digest
 type DigestData struct {
-	Date          time.Time
-	TotalRequests int64
-	Switches      int
-	Errors        int
-	TopAccounts   []AccountUsage
-	Alerts        []AlertSummary
+	Date           time.Time      `json:"date"`
+	Period         DigestPeriod   `json:"period,omitempty"`
+	From           time.Time      `json:"from"`
+	To             time.Time      `json:"to"`
+	TotalRequests  int64          `json:"requests"`
+	InputTokens    int64          `json:"input_tokens"`
+	OutputTokens   int64          `json:"output_tokens"`
+	Switches       int            `json:"switches"`
+	Errors         int            `json:"errors"`
+	ErrorsByClass  map[string]int `json:"errors_by_class,omitempty"`
+	ExhaustedHours float64        `json:"exhausted_hours"`
+	TopAccounts    []AccountUsage `json:"top_accounts,omitempty"`
+	// Accounts holds every account, most requests first
+	Accounts  []AccountUsage  `json:"accounts"`
+	Providers []ProviderUsage `json:"providers"`
+	Alerts    []AlertSummary  `json:"alerts"`
+	Spend     []AccountSpend  `json:"spend,omitempty"`
+	// Previous holds the totals of the period before, for trends
+	Previous *DigestTotals `json:"previous,omitempty"`
+}
+
+// DigestTotals are the headline numbers of a digest period
+type DigestTotals struct {
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	Requests       int64     `json:"requests"`
+	InputTokens    int64     `json:"input_tokens"`
+	OutputTokens   int64     `json:"output_tokens"`
+	Switches       int       `json:"switches"`
+	Errors         int       `json:"errors"`
+	ExhaustedHours float64   `json:"exhausted_hours"`
+}
+
+// AccountSpend represents an account's spend in the digest period
+type AccountSpend struct {
+	AccountID        string  `json:"account_id"`
+	Requests         int64   `json:"requests"`
+	CostUSD          float64 `json:"cost_usd"`
+	MonthToDateUSD   float64 `json:"month_to_date_usd"`
+	MonthlyBudgetUSD float64 `json:"monthly_budget_usd"` // 0 = no cap
 }
 
 // AccountUsage represents account usage statistics
 type AccountUsage struct {
-	AccountID    string
-	Provider     string
-	UsagePercent float64
-	Requests     int64
+	AccountID    string  `json:"account_id"`
+	Provider     string  `json:"provider"`
+	UsagePercent float64 `json:"usage_percent"` // peak usage in the period
+	Requests     int64   `json:"requests"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+	// ExhaustedHours is how long any dimension of the account was at zero
+	ExhaustedHours float64 `json:"exhausted_hours"`
+	// Resets counts dimension resets; WastedPct is the average percent
+	// still left when one reset
+	Resets    int     `json:"resets"`
+	WastedPct float64 `json:"wasted_percent"`
+}
+
+// ProviderUsage sums the usage of a provider's accounts
+type ProviderUsage struct {
+	Provider       string  `json:"provider"`
+	Accounts       int     `json:"accounts"`
+	Requests       int64   `json:"requests"`
+	InputTokens    int64   `json:"input_tokens"`
+	OutputTokens   int64   `json:"output_tokens"`
+	CostUSD        float64 `json:"cost_usd"`
+	ExhaustedHours float64 `json:"exhausted_hours"`
 }
 
 // AlertSummary represents a summary of alerts
 type AlertSummary struct {
-	Severity Severity
-	Count    int
-	LastAt   time.Time
+	Severity Severity  `json:"severity"`
+	Count    int       `json:"count"`
+	LastAt   time.Time `json:"last_at"`
 }
 
 // ThresholdCheck represents a threshold check result
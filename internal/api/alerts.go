@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// AlertsResponse lists alert incidents and the mutes in effect
+type AlertsResponse struct {
+	Incidents []*models.AlertIncident `json:"incidents"`
+	Mutes     []*models.AlertMute     `json:"mutes"`
+}
+
+var incidentStatusFilters = map[string][]models.IncidentStatus{
+	"":             {models.IncidentOpen, models.IncidentAcknowledged},
+	"active":       {models.IncidentOpen, models.IncidentAcknowledged},
+	"open":         {models.IncidentOpen},
+	"acknowledged": {models.IncidentAcknowledged},
+	"resolved":     {models.IncidentResolved},
+	"all":          nil,
+}
+
+// handleListAlerts returns alert incidents, the active ones unless ?status
+// asks for open, acknowledged, resolved or all
+func (s *Server) handleListAlerts(c *gin.Context) {
+	statuses, ok := incidentStatusFilters[c.Query("status")]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be active, open, acknowledged, resolved or all"})
+		return
+	}
+
+	incidents, err := s.store.ListAlertIncidents(statuses...)
+	if err != nil {
+		s.logger.ErrorWithContext(c.Request.Context(), "failed to list alert incidents", "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load alert incidents"})
+		return
+	}
+	mutes, err := s.store.ListAlertMutes(time.Now())
+	if err != nil {
+		s.logger.ErrorWithContext(c.Request.Context(), "failed to list alert mutes", "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load alert mutes"})
+		return
+	}
+
+	resp := AlertsResponse{
+		Incidents: append([]*models.AlertIncident{}, incidents...),
+		Mutes:     append([]*models.AlertMute{}, mutes...),
+	}
+	c.JSON(http.StatusOK, resp)
+}
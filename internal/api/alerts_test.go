@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAlerts(t *testing.T) {
+	server, s := setupClientTestServer(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, s.SaveAlertIncident(&models.AlertIncident{
+		ID: "inc-1", AccountID: "codex-1", Type: "threshold", Severity: "warning",
+		Status: models.IncidentOpen, Count: 3, OpenedAt: now.Add(-time.Hour), LastSeenAt: now,
+	}))
+	resolvedAt := now.Add(-time.Minute)
+	require.NoError(t, s.SaveAlertIncident(&models.AlertIncident{
+		ID: "inc-2", AccountID: "gemini-1", Type: "exhausted", Severity: "critical",
+		Status: models.IncidentResolved, Count: 1, OpenedAt: now.Add(-2 * time.Hour), LastSeenAt: now.Add(-time.Hour),
+		ResolvedAt: &resolvedAt,
+	}))
+	require.NoError(t, s.SetAlertMute(&models.AlertMute{AccountID: "codex-2", Until: now.Add(time.Hour), CreatedAt: now}))
+
+	list := func(query string) AlertsResponse {
+		w := doJSON(server, "GET", "/alerts"+query, testAdminKey, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp AlertsResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	resp := list("")
+	require.Len(t, resp.Incidents, 1)
+	assert.Equal(t, "inc-1", resp.Incidents[0].ID)
+	assert.Equal(t, 3, resp.Incidents[0].Count)
+	require.Len(t, resp.Mutes, 1)
+	assert.Equal(t, "codex-2", resp.Mutes[0].AccountID)
+
+	resp = list("?status=resolved")
+	require.Len(t, resp.Incidents, 1)
+	assert.Equal(t, "inc-2", resp.Incidents[0].ID)
+	assert.Len(t, list("?status=all").Incidents, 2)
+
+	w := doJSON(server, "GET", "/alerts?status=closed", testAdminKey, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doJSON(server, "GET", "/alerts", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
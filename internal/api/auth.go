@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/quotaguard/quotaguard/internal/config"
 	"github.com/quotaguard/quotaguard/internal/logging"
+	"github.com/quotaguard/quotaguard/internal/models"
 )
 
 // Constants for header names
@@ -96,6 +97,152 @@ func APIKeyAuth(apiKeys []string, headerName string, logger *logging.Logger) gin
 	}
 }
 
+// NewAuthMiddleware builds the authentication middleware for the configured auth type.
+// jwt and hmac apply only when auth is enabled; otherwise API keys are used.
+func NewAuthMiddleware(cfg config.AuthConfig, clients ClientStore, logger *logging.Logger) (gin.HandlerFunc, error) {
+	if !cfg.Enabled {
+		return ClientKeyAuth(cfg.APIKeys, cfg.HeaderName, clients, logger), nil
+	}
+
+	switch strings.ToLower(cfg.Type) {
+	case "jwt":
+		verifier, err := NewJWTVerifier(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return JWTAuth(verifier, cfg.APIKeys, cfg.HeaderName, clients, logger), nil
+	case "hmac":
+		verifier, err := NewHMACVerifier(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return HMACAuth(verifier, cfg.APIKeys, cfg.HeaderName, clients, logger), nil
+	default:
+		return ClientKeyAuth(cfg.APIKeys, cfg.HeaderName, clients, logger), nil
+	}
+}
+
+// ClientStore looks up named API clients.
+type ClientStore interface {
+	GetAPIClient(id string) (*models.APIClient, bool)
+	GetAPIClientByKeyHash(hash string) (*models.APIClient, bool)
+	ListAPIClients() []*models.APIClient
+}
+
+// ClientKeyAuth creates a middleware that accepts both the static API keys from
+// the config and keys issued to named API clients. Static keys act as admin keys
+// with every scope; client keys get ClientKeyScopes and the client's own limits.
+// Authentication is bypassed only when no static keys and no clients exist.
+func ClientKeyAuth(apiKeys []string, headerName string, clients ClientStore, logger *logging.Logger) gin.HandlerFunc {
+	if headerName == "" {
+		headerName = DefaultAPIKeyHeader
+	}
+
+	return func(c *gin.Context) {
+		apiKey := c.GetHeader(headerName)
+
+		if identity, ok := authenticateAPIKey(c, apiKey, apiKeys, clients); ok {
+			if setIdentity(c, identity, clients) {
+				c.Next()
+			}
+			return
+		} else if c.IsAborted() {
+			return
+		}
+
+		if apiKey == "" && len(apiKeys) == 0 && (clients == nil || len(clients.ListAPIClients()) == 0) {
+			c.Next()
+			return
+		}
+
+		message := "Invalid API key"
+		if apiKey == "" {
+			message = "API key is required. Provide it in the '" + headerName + "' header"
+		}
+		abortUnauthorized(c, logger, message)
+	}
+}
+
+// authenticateAPIKey resolves a static admin key or a named client key to an identity.
+// It aborts the request when the key belongs to a disabled client.
+func authenticateAPIKey(c *gin.Context, apiKey string, apiKeys []string, clients ClientStore) (*Identity, bool) {
+	if apiKey == "" {
+		return nil, false
+	}
+
+	for _, key := range apiKeys {
+		if apiKey == key {
+			c.Set("api_key", apiKey)
+			return &Identity{
+				Subject:  "api_key:" + MaskAPIKeys([]string{apiKey})[0],
+				AuthType: AuthTypeAPIKey,
+				Scopes:   []string{ScopeAll},
+			}, true
+		}
+	}
+
+	if clients == nil {
+		return nil, false
+	}
+	client, ok := clients.GetAPIClientByKeyHash(models.HashAPIKey(apiKey))
+	if !ok {
+		return nil, false
+	}
+	if !client.Enabled {
+		abortClientDisabled(c)
+		return nil, false
+	}
+
+	c.Set("api_key", client.KeyPrefix)
+	return &Identity{
+		Subject:  client.ID,
+		ClientID: client.ID,
+		AuthType: AuthTypeClientKey,
+		Scopes:   ClientKeyScopes,
+	}, true
+}
+
+// requireAPIKey authenticates a key presented alongside a non-key auth scheme
+func requireAPIKey(c *gin.Context, apiKey string, apiKeys []string, clients ClientStore, logger *logging.Logger) {
+	identity, ok := authenticateAPIKey(c, apiKey, apiKeys, clients)
+	if c.IsAborted() {
+		return
+	}
+	if !ok {
+		abortUnauthorized(c, logger, "Invalid API key")
+		return
+	}
+	if setIdentity(c, identity, clients) {
+		c.Next()
+	}
+}
+
+// abortUnauthorized logs the failure and rejects the request with 401
+func abortUnauthorized(c *gin.Context, logger *logging.Logger, message string) {
+	logger.WarnWithContext(c.Request.Context(), "API authentication failed",
+		"reason", message,
+		"client_ip", c.ClientIP(),
+		"path", c.Request.URL.Path,
+		"method", c.Request.Method,
+	)
+	c.Set("auth_error", message)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{
+		Error:   "unauthorized",
+		Message: message,
+		Code:    http.StatusUnauthorized,
+	})
+}
+
+// ClientFromContext returns the named API client that authenticated the request.
+func ClientFromContext(c *gin.Context) (*models.APIClient, bool) {
+	value, exists := c.Get("api_client")
+	if !exists {
+		return nil, false
+	}
+	client, ok := value.(*models.APIClient)
+	return client, ok && client != nil
+}
+
 // OptionalAuth creates a middleware that performs optional API key authentication.
 // If a key is provided and valid, the request is authenticated.
 // If no key is provided or the key is invalid, the request continues as anonymous.
@@ -0,0 +1,280 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// APIClientRequest represents a request to create or update an API client.
+// Omitted fields keep their current value on update.
+type APIClientRequest struct {
+	ID                 string   `json:"id"`
+	Name               *string  `json:"name,omitempty"`
+	AllowedProviders   []string `json:"allowed_providers,omitempty"`
+	AllowedAccounts    []string `json:"allowed_accounts,omitempty"`
+	DefaultPolicy      *string  `json:"default_policy,omitempty"`
+	RateLimitRPM       *int     `json:"rate_limit_rpm,omitempty"`
+	DailyQuotaSharePct *float64 `json:"daily_quota_share_percent,omitempty"`
+	Enabled            *bool    `json:"enabled,omitempty"`
+}
+
+// APIClientKeyResponse is returned when a key is issued. The key is shown only once.
+type APIClientKeyResponse struct {
+	Client *models.APIClient `json:"client"`
+	APIKey string            `json:"api_key"`
+}
+
+// APIClientUsageResponse describes a client's usage against its daily share
+type APIClientUsageResponse struct {
+	ClientID        string  `json:"client_id"`
+	Day             string  `json:"day"`
+	Requests        int64   `json:"requests"`
+	ConsumedPct     float64 `json:"consumed_percent"`
+	BudgetPct       float64 `json:"budget_percent,omitempty"`
+	RemainingPct    float64 `json:"remaining_percent,omitempty"`
+	BudgetUnlimited bool    `json:"budget_unlimited"`
+}
+
+// handleListClients returns all API clients
+func (s *Server) handleListClients(c *gin.Context) {
+	clients := s.store.ListAPIClients()
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].ID < clients[j].ID
+	})
+	c.JSON(http.StatusOK, clients)
+}
+
+// handleGetClient returns a single API client
+func (s *Server) handleGetClient(c *gin.Context) {
+	client, ok := s.store.GetAPIClient(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "client not found"})
+		return
+	}
+	c.JSON(http.StatusOK, client)
+}
+
+// handleCreateClient registers a new API client and returns its key
+func (s *Server) handleCreateClient(c *gin.Context) {
+	var req APIClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.ID = strings.TrimSpace(req.ID)
+	if req.ID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id is required"})
+		return
+	}
+	// Without a static admin key, the first client would end open mode and
+	// leave no key that can manage clients
+	if len(s.apiConfig.Auth.APIKeys) == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "configure an admin key in api.auth.api_keys before creating clients"})
+		return
+	}
+	if _, exists := s.store.GetAPIClient(req.ID); exists {
+		c.JSON(http.StatusConflict, gin.H{"error": "client already exists"})
+		return
+	}
+	if err := s.validateClientRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, prefix, err := models.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	client := &models.APIClient{
+		ID:        req.ID,
+		Name:      req.ID,
+		KeyHash:   models.HashAPIKey(key),
+		KeyPrefix: prefix,
+		Enabled:   true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	applyClientRequest(client, &req)
+
+	if err := s.store.SetAPIClient(client); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.logger.InfoWithContext(c.Request.Context(), "api client created", "client_id", client.ID)
+	c.JSON(http.StatusCreated, APIClientKeyResponse{Client: client, APIKey: key})
+}
+
+// handleUpdateClient updates an API client's limits and restrictions
+func (s *Server) handleUpdateClient(c *gin.Context) {
+	client, ok := s.store.GetAPIClient(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "client not found"})
+		return
+	}
+
+	var req APIClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := s.validateClientRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	applyClientRequest(client, &req)
+	client.UpdatedAt = time.Now()
+
+	if err := s.store.SetAPIClient(client); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, client)
+}
+
+// handleDeleteClient removes an API client
+func (s *Server) handleDeleteClient(c *gin.Context) {
+	if !s.store.DeleteAPIClient(c.Param("id")) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "client not found"})
+		return
+	}
+	s.logger.InfoWithContext(c.Request.Context(), "api client deleted", "client_id", c.Param("id"))
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+// handleRotateClientKey issues a new key for an API client
+func (s *Server) handleRotateClientKey(c *gin.Context) {
+	client, ok := s.store.GetAPIClient(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "client not found"})
+		return
+	}
+
+	key, prefix, err := models.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	client.KeyHash = models.HashAPIKey(key)
+	client.KeyPrefix = prefix
+	client.UpdatedAt = time.Now()
+
+	if err := s.store.SetAPIClient(client); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, APIClientKeyResponse{Client: client, APIKey: key})
+}
+
+// handleGetClientUsage returns a client's usage for a day (default: today, UTC)
+func (s *Server) handleGetClientUsage(c *gin.Context) {
+	client, ok := s.store.GetAPIClient(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "client not found"})
+		return
+	}
+
+	day := c.DefaultQuery("day", models.UsageDay(time.Now()))
+	if _, err := time.Parse("2006-01-02", day); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "day must be in YYYY-MM-DD format"})
+		return
+	}
+
+	resp := APIClientUsageResponse{ClientID: client.ID, Day: day}
+	if usage, ok := s.store.GetClientUsage(client.ID, day); ok {
+		resp.Requests = usage.Requests
+		resp.ConsumedPct = usage.ConsumedPct
+	}
+	budget := client.DailyQuotaBudget(s.store.ListEnabledAccounts())
+	if budget <= 0 {
+		resp.BudgetUnlimited = true
+	} else {
+		resp.BudgetPct = budget
+		resp.RemainingPct = max(0, budget-resp.ConsumedPct)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// applyClientRequest copies the fields set in the request onto the client
+// validateClientRequest rejects unknown provider and policy names
+func (s *Server) validateClientRequest(req *APIClientRequest) error {
+	for _, p := range req.AllowedProviders {
+		if !models.Provider(p).IsKnown() {
+			return fmt.Errorf("unknown provider %q", p)
+		}
+	}
+	if req.DefaultPolicy != nil && *req.DefaultPolicy != "" {
+		known := false
+		if cfg := s.routerSvc.GetConfig(); cfg != nil {
+			_, known = cfg.Policies[*req.DefaultPolicy]
+		}
+		if !known {
+			return fmt.Errorf("unknown policy %q", *req.DefaultPolicy)
+		}
+	}
+	return nil
+}
+
+func applyClientRequest(client *models.APIClient, req *APIClientRequest) {
+	if req.Name != nil {
+		client.Name = *req.Name
+	}
+	if req.AllowedProviders != nil {
+		client.AllowedProviders = client.AllowedProviders[:0]
+		for _, p := range req.AllowedProviders {
+			client.AllowedProviders = append(client.AllowedProviders, models.Provider(p))
+		}
+	}
+	if req.AllowedAccounts != nil {
+		client.AllowedAccounts = req.AllowedAccounts
+	}
+	if req.DefaultPolicy != nil {
+		client.DefaultPolicy = *req.DefaultPolicy
+	}
+	if req.RateLimitRPM != nil {
+		client.RateLimitRPM = *req.RateLimitRPM
+	}
+	if req.DailyQuotaSharePct != nil {
+		client.DailyQuotaSharePct = *req.DailyQuotaSharePct
+	}
+	if req.Enabled != nil {
+		client.Enabled = *req.Enabled
+	}
+}
+
+// clientBudgetRemaining returns how much of its daily quota share a client has left.
+// The second value is false when the client has no daily share configured.
+func (s *Server) clientBudgetRemaining(client *models.APIClient) (float64, bool) {
+	budget := client.DailyQuotaBudget(s.store.ListEnabledAccounts())
+	if budget <= 0 {
+		return 0, false
+	}
+	consumed := 0.0
+	if usage, ok := s.store.GetClientUsage(client.ID, models.UsageDay(time.Now())); ok {
+		consumed = usage.ConsumedPct
+	}
+	return budget - consumed, true
+}
+
+// ownsReservation reports whether the requesting client may act on a reservation.
+// Admin requests may act on any reservation.
+func (s *Server) ownsReservation(c *gin.Context, reservationID string) bool {
+	client, ok := ClientFromContext(c)
+	if !ok {
+		return true
+	}
+	res, exists := s.reservation.Get(reservationID)
+	if !exists {
+		return false
+	}
+	return res.ClientID == client.ID
+}
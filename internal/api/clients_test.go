@@ -0,0 +1,333 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quotaguard/quotaguard/internal/collector"
+	"github.com/quotaguard/quotaguard/internal/config"
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/reservation"
+	"github.com/quotaguard/quotaguard/internal/router"
+	"github.com/quotaguard/quotaguard/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAdminKey = "admin-key"
+
+func setupClientTestServer(t *testing.T) (*Server, *store.MemoryStore) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	s := store.NewMemoryStore()
+	for _, acc := range []*models.Account{
+		{ID: "codex-1", Provider: models.ProviderOpenAI, Enabled: true, Priority: 5},
+		{ID: "codex-2", Provider: models.ProviderOpenAI, Enabled: true, Priority: 5},
+		{ID: "gemini-1", Provider: models.ProviderGemini, Enabled: true, Priority: 5},
+	} {
+		s.SetAccount(acc)
+		s.SetQuota(acc.ID, &models.QuotaInfo{
+			AccountID:             acc.ID,
+			Provider:              acc.Provider,
+			EffectiveRemainingPct: 80.0,
+			Dimensions:            models.DimensionSlice{{Type: models.DimensionRPM, Limit: 1000, Used: 200, Remaining: 800}},
+		})
+	}
+
+	apiCfg := config.APIConfig{Auth: config.AuthConfig{Enabled: true, APIKeys: []string{testAdminKey}}}
+	r := router.NewRouter(s, router.DefaultConfig())
+	rm := reservation.NewManager(s, reservation.DefaultConfig())
+	c := collector.NewPassiveCollector(s, 100, 0)
+
+	return NewServer(config.ServerConfig{Host: "localhost", HTTPPort: 8080}, apiCfg, s, r, rm, c), s
+}
+
+func doJSON(server *Server, method, path, apiKey string, body interface{}) *httptest.ResponseRecorder {
+	headers := map[string]string{}
+	if apiKey != "" {
+		headers[DefaultAPIKeyHeader] = apiKey
+	}
+	return doRequest(server, method, path, headers, body)
+}
+
+func doRequest(server *Server, method, path string, headers map[string]string, body interface{}) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	return w
+}
+
+func createTestClient(t *testing.T, server *Server, body map[string]interface{}) string {
+	t.Helper()
+	w := doJSON(server, "POST", "/clients", testAdminKey, body)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var resp APIClientKeyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotEmpty(t, resp.APIKey)
+	return resp.APIKey
+}
+
+func TestClientManagementAPI(t *testing.T) {
+	server, s := setupClientTestServer(t)
+
+	key := createTestClient(t, server, map[string]interface{}{
+		"id":                "batch",
+		"allowed_providers": []string{"openai"},
+		"rate_limit_rpm":    30,
+	})
+
+	client, ok := s.GetAPIClient("batch")
+	require.True(t, ok)
+	assert.Equal(t, models.HashAPIKey(key), client.KeyHash)
+	assert.Equal(t, 30, client.RateLimitRPM)
+
+	// The key hash is never exposed
+	w := doJSON(server, "GET", "/clients", testAdminKey, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), client.KeyHash)
+
+	w = doJSON(server, "POST", "/clients", testAdminKey, map[string]interface{}{"id": "batch"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = doJSON(server, "PUT", "/clients/batch", testAdminKey, map[string]interface{}{"default_policy": "cost"})
+	require.Equal(t, http.StatusOK, w.Code)
+	client, _ = s.GetAPIClient("batch")
+	assert.Equal(t, "cost", client.DefaultPolicy)
+	assert.Equal(t, 30, client.RateLimitRPM)
+
+	// Unknown provider and policy names are rejected without changing the client
+	for _, body := range []map[string]interface{}{
+		{"default_policy": "cheapest"},
+		{"allowed_providers": []string{"openai", "open-ai"}},
+	} {
+		w = doJSON(server, "PUT", "/clients/batch", testAdminKey, body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	client, _ = s.GetAPIClient("batch")
+	assert.Equal(t, "cost", client.DefaultPolicy)
+	assert.Equal(t, []models.Provider{models.ProviderOpenAI}, client.AllowedProviders)
+	w = doJSON(server, "POST", "/clients", testAdminKey, map[string]interface{}{"id": "typo", "allowed_providers": []string{"gemni"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Client keys cannot manage clients
+	w = doJSON(server, "GET", "/clients", key, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = doJSON(server, "POST", "/clients/batch/rotate", testAdminKey, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(server, "POST", "/router/select", key, map[string]interface{}{})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = doJSON(server, "DELETE", "/clients/batch", testAdminKey, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(server, "DELETE", "/clients/batch", testAdminKey, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRouterSelect_ClientRestrictions(t *testing.T) {
+	server, s := setupClientTestServer(t)
+	key := createTestClient(t, server, map[string]interface{}{
+		"id":               "gemini-team",
+		"allowed_accounts": []string{"gemini-1"},
+		"default_policy":   "cost",
+	})
+
+	w := doJSON(server, "POST", "/router/select", key, map[string]interface{}{"provider": "openai"})
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	w = doJSON(server, "POST", "/router/select", key, map[string]interface{}{"client_id": "spoofed"})
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp RouterSelectResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "gemini-1", resp.AccountID)
+
+	usage, ok := s.GetClientUsage("gemini-team", models.UsageDay(time.Now()))
+	require.True(t, ok)
+	assert.Equal(t, int64(1), usage.Requests)
+
+	// Disabled clients are rejected
+	disabled := false
+	w = doJSON(server, "PUT", "/clients/gemini-team", testAdminKey, APIClientRequest{Enabled: &disabled})
+	require.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(server, "POST", "/router/select", key, map[string]interface{}{})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRouterSelect_ClientProviderNotAllowed(t *testing.T) {
+	server, _ := setupClientTestServer(t)
+	key := createTestClient(t, server, map[string]interface{}{
+		"id":                "openai-only",
+		"allowed_providers": []string{"openai"},
+	})
+
+	w := doJSON(server, "POST", "/router/select", key, map[string]interface{}{"provider": "gemini"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRouterSelect_ClientRateLimit(t *testing.T) {
+	server, _ := setupClientTestServer(t)
+	key := createTestClient(t, server, map[string]interface{}{
+		"id":             "noisy",
+		"rate_limit_rpm": 2,
+	})
+
+	for i := 0; i < 2; i++ {
+		w := doJSON(server, "POST", "/router/select", key, map[string]interface{}{})
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+	w := doJSON(server, "POST", "/router/select", key, map[string]interface{}{})
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	// Admin keys are not affected by client limits
+	w = doJSON(server, "POST", "/router/select", testAdminKey, map[string]interface{}{})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestReservations_ClientDailyShare(t *testing.T) {
+	server, s := setupClientTestServer(t)
+	// 2 allowed accounts x 5% share = 10 account-percent per day; gemini-1 does not count
+	key := createTestClient(t, server, map[string]interface{}{
+		"id":                        "batch",
+		"allowed_providers":         []string{"openai"},
+		"daily_quota_share_percent": 5,
+	})
+
+	w := doJSON(server, "POST", "/reservations", key, map[string]interface{}{
+		"account_id": "gemini-1", "estimated_cost_percent": 1, "correlation_id": "c-0",
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = doJSON(server, "POST", "/reservations", key, map[string]interface{}{
+		"account_id": "codex-1", "estimated_cost_percent": 6, "correlation_id": "c-1",
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created CreateReservationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	w = doJSON(server, "POST", "/reservations", key, map[string]interface{}{
+		"account_id": "codex-2", "estimated_cost_percent": 6, "correlation_id": "c-2",
+	})
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	// Releasing with a lower actual cost frees budget again
+	w = doJSON(server, "POST", "/reservations/"+created.ReservationID+"/release", key, map[string]interface{}{
+		"actual_cost_percent": 2,
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(server, "POST", "/reservations", key, map[string]interface{}{
+		"account_id": "codex-2", "estimated_cost_percent": 6, "correlation_id": "c-3",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// Select is rejected once the share is used up
+	require.NoError(t, s.AddClientUsage("batch", models.UsageDay(time.Now()), 0, 10))
+	w = doJSON(server, "POST", "/router/select", key, map[string]interface{}{})
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	w = doJSON(server, "GET", "/clients/batch/usage", testAdminKey, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var usage APIClientUsageResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &usage))
+	assert.Equal(t, 10.0, usage.BudgetPct)
+	assert.InDelta(t, 18.0, usage.ConsumedPct, 0.001)
+	assert.Equal(t, 0.0, usage.RemainingPct)
+}
+
+func TestReservations_ClientOwnership(t *testing.T) {
+	server, _ := setupClientTestServer(t)
+	keyA := createTestClient(t, server, map[string]interface{}{"id": "team-a"})
+	keyB := createTestClient(t, server, map[string]interface{}{"id": "team-b"})
+
+	w := doJSON(server, "POST", "/reservations", keyA, map[string]interface{}{
+		"account_id": "codex-1", "estimated_cost_percent": 5, "correlation_id": "c-1",
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created CreateReservationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	w = doJSON(server, "GET", "/reservations/"+created.ReservationID, keyB, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doJSON(server, "POST", "/reservations/"+created.ReservationID+"/cancel", keyB, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doJSON(server, "GET", "/reservations/"+created.ReservationID, keyA, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(server, "POST", "/reservations/"+created.ReservationID+"/cancel", testAdminKey, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRouterFeedback_ClientRestrictions(t *testing.T) {
+	server, s := setupClientTestServer(t)
+	keyA := createTestClient(t, server, map[string]interface{}{"id": "team-a", "allowed_providers": []string{"openai"}})
+	keyB := createTestClient(t, server, map[string]interface{}{"id": "team-b"})
+
+	// A client cannot charge an account it may not use
+	w := doJSON(server, "POST", "/router/feedback", keyA, map[string]interface{}{
+		"account_id": "gemini-1", "success": true, "input_tokens": 100,
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = doJSON(server, "POST", "/router/feedback", keyA, map[string]interface{}{
+		"account_id": "missing", "success": true,
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = doJSON(server, "POST", "/reservations", keyA, map[string]interface{}{
+		"account_id": "codex-1", "estimated_cost_percent": 5, "correlation_id": "c-1",
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created CreateReservationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	// Ownership is checked even without an actual cost
+	w = doJSON(server, "POST", "/router/feedback", keyB, map[string]interface{}{
+		"account_id": "codex-1", "reservation_id": created.ReservationID, "success": false,
+	})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	res, ok := s.GetReservation(created.ReservationID)
+	require.True(t, ok)
+	assert.Equal(t, models.ReservationActive, res.Status)
+
+	// The owner's zero-cost feedback releases the reservation
+	w = doJSON(server, "POST", "/router/feedback", keyA, map[string]interface{}{
+		"account_id": "codex-1", "reservation_id": created.ReservationID, "success": false,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	res, ok = s.GetReservation(created.ReservationID)
+	require.True(t, ok)
+	assert.Equal(t, models.ReservationReleased, res.Status)
+	quota, ok := s.GetQuota("codex-1")
+	require.True(t, ok)
+	assert.Zero(t, quota.VirtualUsedPercent)
+}
+
+func TestClientManagementAPI_RequiresAdminKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := store.NewMemoryStore()
+	apiCfg := config.APIConfig{Auth: config.AuthConfig{Enabled: true}}
+	server := NewServer(config.ServerConfig{Host: "localhost", HTTPPort: 8080}, apiCfg, s,
+		router.NewRouter(s, router.DefaultConfig()), reservation.NewManager(s, reservation.DefaultConfig()),
+		collector.NewPassiveCollector(s, 100, 0))
+
+	// Open mode cannot hand out the first client key and lock everyone else out
+	w := doJSON(server, "POST", "/clients", "", map[string]interface{}{"id": "batch"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Empty(t, s.ListAPIClients())
+
+	w = doJSON(server, "GET", "/clients", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quotaguard/quotaguard/internal/alerts"
+)
+
+// handleDigest returns the daily or weekly usage digest as JSON or CSV.
+// ?date is the last day covered (default: yesterday, UTC); ?format=csv
+// returns one row per account.
+func (s *Server) handleDigest(c *gin.Context) {
+	period, err := alerts.ParseDigestPeriod(c.DefaultQuery("period", string(alerts.DigestDaily)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period must be daily or weekly"})
+		return
+	}
+	until := time.Now()
+	if date := c.Query("date"); date != "" {
+		day, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date must be YYYY-MM-DD"})
+			return
+		}
+		until = day.AddDate(0, 0, 1)
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+
+	digest, err := alerts.BuildDigest(s.store, period, until)
+	if err != nil {
+		s.logger.ErrorWithContext(c.Request.Context(), "failed to build digest", "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build digest"})
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, digest)
+		return
+	}
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename=quotaguard-"+string(period)+"-"+digest.From.Format("2006-01-02")+".csv")
+	c.Status(http.StatusOK)
+	if err := alerts.WriteDigestCSV(c.Writer, digest); err != nil {
+		s.logger.ErrorWithContext(c.Request.Context(), "failed to write digest", "error", err.Error())
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/alerts"
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigest(t *testing.T) {
+	server, s := setupClientTestServer(t)
+	yesterday := models.UsageDay(time.Now().UTC().AddDate(0, 0, -1))
+	require.NoError(t, s.AddSpend(&models.SpendEntry{AccountID: "codex-1", Day: yesterday, Requests: 7, InputTokens: 700, OutputTokens: 70}))
+	require.NoError(t, s.AddSpend(&models.SpendEntry{AccountID: "gemini-1", Day: "2026-01-02", Requests: 3}))
+
+	w := doJSON(server, "GET", "/digest", testAdminKey, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var digest alerts.DigestData
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &digest))
+	assert.Equal(t, alerts.DigestDaily, digest.Period)
+	assert.Equal(t, yesterday, digest.From.Format("2006-01-02"))
+	assert.Equal(t, int64(7), digest.TotalRequests)
+	require.Len(t, digest.Accounts, 3)
+	assert.Equal(t, "codex-1", digest.Accounts[0].AccountID)
+	require.NotNil(t, digest.Previous)
+
+	w = doJSON(server, "GET", "/digest?period=weekly&date=2026-01-04", testAdminKey, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	digest = alerts.DigestData{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &digest))
+	assert.Equal(t, "2025-12-29", digest.From.Format("2006-01-02"))
+	assert.Equal(t, int64(3), digest.TotalRequests)
+
+	w = doJSON(server, "GET", "/digest?format=csv", testAdminKey, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[1], "codex-1,openai,7,700,70,"))
+
+	for _, query := range []string{"?period=monthly", "?date=yesterday", "?format=xml"} {
+		w = doJSON(server, "GET", "/digest"+query, testAdminKey, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+	w = doJSON(server, "GET", "/digest", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quotaguard/quotaguard/internal/config"
+	"github.com/quotaguard/quotaguard/internal/logging"
+)
+
+// Headers used for HMAC request signing
+const (
+	HMACKeyIDHeader     = "X-QuotaGuard-Key-Id"
+	HMACTimestampHeader = "X-QuotaGuard-Timestamp"
+	HMACSignatureHeader = "X-QuotaGuard-Signature"
+)
+
+// hmacKey is a configured signing key
+type hmacKey struct {
+	secret []byte
+	scopes []string
+}
+
+// HMACVerifier validates signed requests and rejects replays within the skew window
+type HMACVerifier struct {
+	keys    map[string]hmacKey
+	maxSkew time.Duration
+	now     func() time.Time
+
+	mu    sync.Mutex
+	seen  map[string]time.Time // signature -> expiry
+	order []seenSignature      // signatures in the order they were seen
+}
+
+// seenSignature is a replay cache entry queued for expiry
+type seenSignature struct {
+	signature string
+	expiresAt time.Time
+}
+
+// NewHMACVerifier creates a verifier from the auth config
+func NewHMACVerifier(cfg config.AuthConfig) (*HMACVerifier, error) {
+	v := &HMACVerifier{
+		keys:    make(map[string]hmacKey),
+		maxSkew: cfg.HMAC.MaxSkew,
+		now:     time.Now,
+		seen:    make(map[string]time.Time),
+	}
+	if v.maxSkew <= 0 {
+		v.maxSkew = 5 * time.Minute
+	}
+
+	for _, key := range cfg.HMAC.Keys {
+		scopes := key.Scopes
+		if len(scopes) == 0 {
+			scopes = ClientKeyScopes
+		}
+		v.keys[key.ID] = hmacKey{secret: []byte(key.Secret), scopes: scopes}
+	}
+	if cfg.Secret != "" {
+		if _, exists := v.keys["default"]; !exists {
+			v.keys["default"] = hmacKey{secret: []byte(cfg.Secret), scopes: []string{ScopeAll}}
+		}
+	}
+	if len(v.keys) == 0 {
+		return nil, fmt.Errorf("hmac auth requires a secret or keys")
+	}
+	return v, nil
+}
+
+// SignRequest computes the hex signature for a request. The signed string is
+// "<timestamp>\n<METHOD>\n<request URI>\n<hex sha256 of body>".
+func SignRequest(secret []byte, timestamp, method, requestURI string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "\n" + method + "\n" + requestURI + "\n" + hex.EncodeToString(bodyHash[:])))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature headers of a request and returns the caller identity
+func (v *HMACVerifier) Verify(keyID, timestamp, signature, method, requestURI string, body []byte) (*Identity, error) {
+	if keyID == "" {
+		keyID = "default"
+	}
+	key, ok := v.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", keyID)
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp")
+	}
+	now := v.now()
+	signedAt := time.Unix(unix, 0)
+	if signedAt.Before(now.Add(-v.maxSkew)) || signedAt.After(now.Add(v.maxSkew)) {
+		return nil, fmt.Errorf("timestamp outside allowed window")
+	}
+
+	expected := SignRequest(key.secret, timestamp, method, requestURI, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, fmt.Errorf("invalid signature")
+	}
+
+	if !v.remember(keyID+":"+signature, signedAt.Add(v.maxSkew), now) {
+		return nil, fmt.Errorf("replayed request")
+	}
+
+	return &Identity{
+		Subject:  keyID,
+		ClientID: keyID,
+		AuthType: AuthTypeHMAC,
+		Scopes:   key.scopes,
+	}, nil
+}
+
+// remember records a signature and reports false if it was already seen.
+// Entries are expired from the front of the queue, so the cost per request
+// stays constant however many signatures are cached. Expiries differ by at
+// most the skew window, so an entry can outlive its expiry by that much
+// behind a later one; lookups check the expiry themselves.
+func (v *HMACVerifier) remember(signature string, expiresAt, now time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for len(v.order) > 0 && now.After(v.order[0].expiresAt) {
+		oldest := v.order[0]
+		if exp, ok := v.seen[oldest.signature]; ok && exp.Equal(oldest.expiresAt) {
+			delete(v.seen, oldest.signature)
+		}
+		v.order[0] = seenSignature{}
+		v.order = v.order[1:]
+	}
+	if exp, replay := v.seen[signature]; replay && !now.After(exp) {
+		return false
+	}
+	v.seen[signature] = expiresAt
+	v.order = append(v.order, seenSignature{signature: signature, expiresAt: expiresAt})
+	return true
+}
+
+// HMACAuth creates a middleware that authenticates HMAC-signed requests.
+// Static API keys in the API key header are still accepted as admin keys.
+func HMACAuth(verifier *HMACVerifier, apiKeys []string, headerName string, clients ClientStore, logger *logging.Logger) gin.HandlerFunc {
+	if headerName == "" {
+		headerName = DefaultAPIKeyHeader
+	}
+
+	return func(c *gin.Context) {
+		signature := c.GetHeader(HMACSignatureHeader)
+		if signature == "" {
+			if apiKey := c.GetHeader(headerName); apiKey != "" {
+				requireAPIKey(c, apiKey, apiKeys, clients, logger)
+				return
+			}
+			abortUnauthorized(c, logger, "Request signature is required")
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			data, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				abortUnauthorized(c, logger, "Failed to read request body")
+				return
+			}
+			body = data
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		identity, err := verifier.Verify(
+			c.GetHeader(HMACKeyIDHeader),
+			c.GetHeader(HMACTimestampHeader),
+			signature,
+			c.Request.Method,
+			c.Request.URL.RequestURI(),
+			body,
+		)
+		if err != nil {
+			abortUnauthorized(c, logger, "Invalid signature: "+err.Error())
+			return
+		}
+		if setIdentity(c, identity, clients) {
+			c.Next()
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedRequest(t *testing.T, keyID, secret, method, path string, body interface{}, at time.Time) *http.Request {
+	t.Helper()
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+
+	req, err := http.NewRequest(method, path, bytes.NewReader(data))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HMACKeyIDHeader, keyID)
+	req.Header.Set(HMACTimestampHeader, timestamp)
+	req.Header.Set(HMACSignatureHeader, SignRequest([]byte(secret), timestamp, method, path, data))
+	return req
+}
+
+func TestHMACVerifier(t *testing.T) {
+	v, err := NewHMACVerifier(config.AuthConfig{
+		HMAC: config.HMACConfig{
+			MaxSkew: time.Minute,
+			Keys:    []config.HMACKeyConfig{{ID: "batch", Secret: "s3cret", Scopes: []string{ScopeRouterSelect}}},
+		},
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	ts := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{"provider":"openai"}`)
+	sig := SignRequest([]byte("s3cret"), ts, "POST", "/router/select", body)
+
+	identity, err := v.Verify("batch", ts, sig, "POST", "/router/select", body)
+	require.NoError(t, err)
+	assert.Equal(t, "batch", identity.ClientID)
+	assert.Equal(t, AuthTypeHMAC, identity.AuthType)
+	assert.Equal(t, []string{ScopeRouterSelect}, identity.Scopes)
+
+	_, err = v.Verify("batch", ts, sig, "POST", "/router/select", body)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "replayed")
+
+	_, err = v.Verify("batch", ts, sig, "POST", "/router/select", []byte(`{"provider":"gemini"}`))
+	assert.Error(t, err)
+
+	old := strconv.FormatInt(now.Add(-2*time.Minute).Unix(), 10)
+	_, err = v.Verify("batch", old, SignRequest([]byte("s3cret"), old, "POST", "/router/select", body), "POST", "/router/select", body)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timestamp")
+
+	_, err = v.Verify("unknown", ts, sig, "POST", "/router/select", body)
+	assert.Error(t, err)
+}
+
+func TestHMACVerifier_ExpiresSeenSignatures(t *testing.T) {
+	v, err := NewHMACVerifier(config.AuthConfig{Secret: "s3cret", HMAC: config.HMACConfig{MaxSkew: time.Minute}})
+	require.NoError(t, err)
+
+	start := time.Now()
+	assert.True(t, v.remember("late", start.Add(2*time.Minute), start))
+	assert.True(t, v.remember("early", start.Add(time.Minute), start))
+	assert.False(t, v.remember("early", start.Add(time.Minute), start))
+
+	// "early" is queued behind "late" but is not a replay once expired
+	later := start.Add(90 * time.Second)
+	assert.True(t, v.remember("early", later.Add(time.Minute), later))
+	assert.False(t, v.remember("late", start.Add(2*time.Minute), later))
+
+	// Expired entries are dropped from the front without a full sweep
+	done := start.Add(5 * time.Minute)
+	assert.True(t, v.remember("fresh", done.Add(time.Minute), done))
+	assert.Len(t, v.seen, 1)
+	assert.Len(t, v.order, 1)
+}
+
+func TestHMACAuth_Server(t *testing.T) {
+	server, _ := setupAuthTestServer(t, config.AuthConfig{
+		Enabled: true,
+		Type:    "hmac",
+		Secret:  "admin-secret",
+		HMAC: config.HMACConfig{
+			Keys: []config.HMACKeyConfig{{ID: "batch", Secret: "batch-secret"}},
+		},
+	})
+
+	serve := func(req *http.Request) int {
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	now := time.Now()
+	req := signedRequest(t, "batch", "batch-secret", "POST", "/router/select", map[string]interface{}{}, now)
+	assert.Equal(t, http.StatusOK, serve(req))
+
+	// The same signed request cannot be replayed
+	req = signedRequest(t, "batch", "batch-secret", "POST", "/router/select", map[string]interface{}{}, now)
+	assert.Equal(t, http.StatusUnauthorized, serve(req))
+
+	// Keys without explicit scopes get client scopes only
+	assert.Equal(t, http.StatusForbidden, serve(signedRequest(t, "batch", "batch-secret", "GET", "/clients", nil, now)))
+	assert.Equal(t, http.StatusOK, serve(signedRequest(t, "default", "admin-secret", "GET", "/clients", nil, now)))
+
+	assert.Equal(t, http.StatusUnauthorized, serve(signedRequest(t, "batch", "wrong", "GET", "/quotas", nil, now)))
+	assert.Equal(t, http.StatusUnauthorized, doJSON(server, "GET", "/quotas", "", nil).Code)
+}
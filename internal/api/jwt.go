@@ -0,0 +1,277 @@
+package api
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quotaguard/quotaguard/internal/config"
+	"github.com/quotaguard/quotaguard/internal/logging"
+)
+
+// JWTVerifier validates HS256 and RS256 bearer tokens
+type JWTVerifier struct {
+	secret      []byte
+	keys        map[string]*rsa.PublicKey
+	audience    string
+	issuer      string
+	clientClaim string
+	scopesClaim string
+	clockSkew   time.Duration
+	now         func() time.Time
+}
+
+// jwk is a single RSA key from a JSON Web Key Set
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// NewJWTVerifier creates a verifier from the auth config, loading the JWKS file if set
+func NewJWTVerifier(cfg config.AuthConfig) (*JWTVerifier, error) {
+	v := &JWTVerifier{
+		secret:      []byte(cfg.Secret),
+		keys:        make(map[string]*rsa.PublicKey),
+		audience:    cfg.JWT.Audience,
+		issuer:      cfg.JWT.Issuer,
+		clientClaim: cfg.JWT.ClientClaim,
+		scopesClaim: cfg.JWT.ScopesClaim,
+		clockSkew:   cfg.JWT.ClockSkew,
+		now:         time.Now,
+	}
+	if v.clientClaim == "" {
+		v.clientClaim = "sub"
+	}
+	if v.scopesClaim == "" {
+		v.scopesClaim = "scope"
+	}
+
+	if cfg.JWT.JWKSFile != "" {
+		data, err := os.ReadFile(cfg.JWT.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("read jwks file: %w", err)
+		}
+		if err := v.loadJWKS(data); err != nil {
+			return nil, err
+		}
+	}
+	if len(v.secret) == 0 && len(v.keys) == 0 {
+		return nil, fmt.Errorf("jwt auth requires a secret or RSA keys")
+	}
+	return v, nil
+}
+
+func (v *JWTVerifier) loadJWKS(data []byte) error {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("parse jwks: %w", err)
+	}
+
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return fmt.Errorf("jwks key %q: invalid modulus: %w", key.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return fmt.Errorf("jwks key %q: invalid exponent: %w", key.Kid, err)
+		}
+		v.keys[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(v.keys) == 0 {
+		return fmt.Errorf("jwks file contains no RSA signing keys")
+	}
+	return nil
+}
+
+// Verify checks the token signature and claims and returns the caller identity
+func (v *JWTVerifier) Verify(token string) (*Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature encoding")
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+
+	switch header.Alg {
+	case "HS256":
+		if len(v.secret) == 0 {
+			return nil, fmt.Errorf("HS256 tokens are not accepted")
+		}
+		mac := hmac.New(sha256.New, v.secret)
+		mac.Write(signed)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return nil, fmt.Errorf("invalid signature")
+		}
+	case "RS256":
+		key, err := v.rsaKey(header.Kid)
+		if err != nil {
+			return nil, err
+		}
+		digest := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return nil, fmt.Errorf("invalid signature")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", header.Alg)
+	}
+
+	claims := map[string]interface{}{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("invalid claims: %w", err)
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return nil, err
+	}
+
+	client, _ := claims[v.clientClaim].(string)
+	if client == "" {
+		return nil, fmt.Errorf("missing %s claim", v.clientClaim)
+	}
+	return &Identity{
+		Subject:  client,
+		ClientID: client,
+		AuthType: AuthTypeJWT,
+		Scopes:   claimStrings(claims[v.scopesClaim]),
+	}, nil
+}
+
+func (v *JWTVerifier) rsaKey(kid string) (*rsa.PublicKey, error) {
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (v *JWTVerifier) checkClaims(claims map[string]interface{}) error {
+	now := v.now()
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("missing exp claim")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(v.clockSkew)) {
+		return fmt.Errorf("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(v.clockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("token not yet valid")
+	}
+	if v.issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.issuer {
+			return fmt.Errorf("invalid issuer")
+		}
+	}
+	if v.audience != "" {
+		found := false
+		for _, aud := range claimStrings(claims["aud"]) {
+			if aud == v.audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("invalid audience")
+		}
+	}
+	return nil
+}
+
+// JWTAuth creates a middleware that authenticates "Authorization: Bearer" tokens.
+// Static API keys in the API key header are still accepted as admin keys.
+func JWTAuth(verifier *JWTVerifier, apiKeys []string, headerName string, clients ClientStore, logger *logging.Logger) gin.HandlerFunc {
+	if headerName == "" {
+		headerName = DefaultAPIKeyHeader
+	}
+
+	return func(c *gin.Context) {
+		token := bearerToken(c.GetHeader("Authorization"))
+		if token == "" {
+			if apiKey := c.GetHeader(headerName); apiKey != "" {
+				requireAPIKey(c, apiKey, apiKeys, clients, logger)
+				return
+			}
+			abortUnauthorized(c, logger, "Bearer token is required")
+			return
+		}
+
+		identity, err := verifier.Verify(token)
+		if err != nil {
+			abortUnauthorized(c, logger, "Invalid token: "+err.Error())
+			return
+		}
+		if setIdentity(c, identity, clients) {
+			c.Next()
+		}
+	}
+}
+
+func bearerToken(header string) string {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// claimStrings reads a claim that may be a space-separated string or a list
+func claimStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
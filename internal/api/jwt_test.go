@@ -0,0 +1,193 @@
+package api
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quotaguard/quotaguard/internal/collector"
+	"github.com/quotaguard/quotaguard/internal/config"
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/reservation"
+	"github.com/quotaguard/quotaguard/internal/router"
+	"github.com/quotaguard/quotaguard/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testJWTSecret = "jwt-test-secret"
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signHS256(t *testing.T, secret string, claims map[string]interface{}) string {
+	t.Helper()
+	unsigned := encodeSegment(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	unsigned := encodeSegment(t, map[string]string{"alg": "RS256", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func testClaims(sub string, scopes string) map[string]interface{} {
+	return map[string]interface{}{
+		"sub":   sub,
+		"aud":   "quotaguard",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": scopes,
+	}
+}
+
+func TestJWTVerifier_HS256(t *testing.T) {
+	cfg := config.AuthConfig{Secret: testJWTSecret, JWT: config.JWTConfig{Audience: "quotaguard"}}
+	v, err := NewJWTVerifier(cfg)
+	require.NoError(t, err)
+
+	identity, err := v.Verify(signHS256(t, testJWTSecret, testClaims("ci-bot", "router:select quotas:read")))
+	require.NoError(t, err)
+	assert.Equal(t, "ci-bot", identity.ClientID)
+	assert.Equal(t, AuthTypeJWT, identity.AuthType)
+	assert.True(t, identity.HasScope(ScopeRouterSelect))
+	assert.False(t, identity.HasScope(ScopeAdminAccounts))
+
+	tests := []struct {
+		name   string
+		token  string
+		errMsg string
+	}{
+		{"wrong secret", signHS256(t, "other", testClaims("ci-bot", "")), "invalid signature"},
+		{"expired", signHS256(t, testJWTSecret, map[string]interface{}{
+			"sub": "ci-bot", "aud": "quotaguard", "exp": time.Now().Add(-time.Hour).Unix(),
+		}), "token expired"},
+		{"missing exp", signHS256(t, testJWTSecret, map[string]interface{}{"sub": "ci-bot", "aud": "quotaguard"}), "missing exp"},
+		{"wrong audience", signHS256(t, testJWTSecret, map[string]interface{}{
+			"sub": "ci-bot", "aud": []string{"other"}, "exp": time.Now().Add(time.Hour).Unix(),
+		}), "invalid audience"},
+		{"missing subject", signHS256(t, testJWTSecret, map[string]interface{}{
+			"aud": "quotaguard", "exp": time.Now().Add(time.Hour).Unix(),
+		}), "missing sub claim"},
+		{"alg none", encodeSegment(t, map[string]string{"alg": "none"}) + "." + encodeSegment(t, testClaims("ci-bot", "*")) + ".", "unsupported algorithm"},
+		{"malformed", "abc", "malformed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(tt.token)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestJWTVerifier_RS256WithJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	}
+	data, err := json.Marshal(jwks)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	v, err := NewJWTVerifier(config.AuthConfig{JWT: config.JWTConfig{JWKSFile: path, ScopesClaim: "scp"}})
+	require.NoError(t, err)
+
+	claims := testClaims("svc", "")
+	claims["scp"] = []string{"admin:*"}
+	identity, err := v.Verify(signRS256(t, key, "k1", claims))
+	require.NoError(t, err)
+	assert.True(t, identity.HasScope(ScopeAdminAccounts))
+	assert.False(t, identity.HasScope(ScopeRouterSelect))
+
+	_, err = v.Verify(signRS256(t, key, "unknown", claims))
+	assert.Error(t, err)
+
+	// HS256 is rejected when no secret is configured
+	_, err = v.Verify(signHS256(t, "", claims))
+	assert.Error(t, err)
+
+	_, err = NewJWTVerifier(config.AuthConfig{JWT: config.JWTConfig{JWKSFile: filepath.Join(t.TempDir(), "missing.json")}})
+	assert.Error(t, err)
+}
+
+func setupAuthTestServer(t *testing.T, auth config.AuthConfig) (*Server, *store.MemoryStore) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	require.NoError(t, auth.Validate())
+
+	s := store.NewMemoryStore()
+	s.SetAccount(&models.Account{ID: "acc-1", Provider: models.ProviderOpenAI, Enabled: true})
+	s.SetQuota("acc-1", &models.QuotaInfo{AccountID: "acc-1", Provider: models.ProviderOpenAI, EffectiveRemainingPct: 80.0})
+
+	r := router.NewRouter(s, router.DefaultConfig())
+	rm := reservation.NewManager(s, reservation.DefaultConfig())
+	c := collector.NewPassiveCollector(s, 100, 0)
+	return NewServer(config.ServerConfig{Host: "localhost", HTTPPort: 8080}, config.APIConfig{Auth: auth}, s, r, rm, c), s
+}
+
+func doBearer(server *Server, method, path, token string, body interface{}) int {
+	return doRequest(server, method, path, map[string]string{"Authorization": "Bearer " + token}, body).Code
+}
+
+func TestJWTAuth_RouteScopes(t *testing.T) {
+	server, s := setupAuthTestServer(t, config.AuthConfig{
+		Enabled: true,
+		Type:    "jwt",
+		Secret:  testJWTSecret,
+		APIKeys: []string{testAdminKey},
+		JWT:     config.JWTConfig{Audience: "quotaguard"},
+	})
+
+	selectOnly := signHS256(t, testJWTSecret, testClaims("ci-bot", ScopeRouterSelect))
+	assert.Equal(t, http.StatusOK, doBearer(server, "POST", "/router/select", selectOnly, map[string]interface{}{}))
+	assert.Equal(t, http.StatusForbidden, doBearer(server, "GET", "/quotas", selectOnly, nil))
+	assert.Equal(t, http.StatusForbidden, doBearer(server, "GET", "/clients", selectOnly, nil))
+
+	admin := signHS256(t, testJWTSecret, testClaims("ops", "admin:*"))
+	assert.Equal(t, http.StatusOK, doBearer(server, "GET", "/clients", admin, nil))
+
+	assert.Equal(t, http.StatusUnauthorized, doBearer(server, "POST", "/router/select", "garbage", map[string]interface{}{}))
+	assert.Equal(t, http.StatusUnauthorized, doJSON(server, "POST", "/router/select", "", map[string]interface{}{}).Code)
+
+	// Static API keys keep working as admin keys
+	assert.Equal(t, http.StatusOK, doJSON(server, "GET", "/clients", testAdminKey, nil).Code)
+
+	// A JWT subject that matches a named client inherits the client's restrictions
+	require.NoError(t, s.SetAPIClient(&models.APIClient{
+		ID:               "ci-bot",
+		KeyHash:          models.HashAPIKey("unused"),
+		AllowedProviders: []models.Provider{models.ProviderGemini},
+		Enabled:          true,
+	}))
+	assert.Equal(t, http.StatusForbidden, doBearer(server, "POST", "/router/select", selectOnly, map[string]interface{}{"provider": "openai"}))
+}
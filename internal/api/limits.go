@@ -2,6 +2,7 @@ package api
 
 import (
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -84,6 +85,75 @@ func rateLimitMiddleware(limiter *IPRateLimiter) gin.HandlerFunc {
 	}
 }
 
+// ClientRateLimiter limits requests per named API client using each client's own RPM
+type ClientRateLimiter struct {
+	limits map[string]*tokenBucket
+	mu     sync.Mutex
+}
+
+// newClientRateLimiter creates a new per-client rate limiter
+func newClientRateLimiter() *ClientRateLimiter {
+	return &ClientRateLimiter{
+		limits: make(map[string]*tokenBucket),
+	}
+}
+
+// allow checks if a request is allowed for the client at the given requests per minute
+func (l *ClientRateLimiter) allow(clientID string, rpm int) bool {
+	if rpm <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	bucket, exists := l.limits[clientID]
+	if !exists || bucket.capacity != float64(rpm) {
+		bucket = &tokenBucket{
+			tokens:     float64(rpm),
+			lastRefill: now,
+			capacity:   float64(rpm),
+		}
+		l.limits[clientID] = bucket
+	}
+
+	// Refill continuously at rpm tokens per minute
+	elapsed := now.Sub(bucket.lastRefill)
+	bucket.tokens = min(bucket.capacity, bucket.tokens+elapsed.Minutes()*bucket.capacity)
+	bucket.lastRefill = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true
+	}
+
+	return false
+}
+
+// clientRateLimitMiddleware applies the per-client rate limit after authentication
+func clientRateLimitMiddleware(limiter *ClientRateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client, ok := ClientFromContext(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if !limiter.allow(client.ID, client.RateLimitRPM) {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":       "rate limit exceeded",
+				"message":     "Client rate limit of " + strconv.Itoa(client.RateLimitRPM) + " requests per minute exceeded.",
+				"client_id":   client.ID,
+				"retry_after": (time.Minute / time.Duration(client.RateLimitRPM)).String(),
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // bodyLimitMiddleware limits the size of request bodies
 func bodyLimitMiddleware(maxSize int64) gin.HandlerFunc {
 	return func(c *gin.Context) {
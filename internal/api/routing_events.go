@@ -0,0 +1,146 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quotaguard/quotaguard/internal/logging"
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/router"
+)
+
+const (
+	defaultRoutingEventsLimit = 100
+	maxRoutingEventsLimit     = 1000
+)
+
+// RoutingEventsResponse lists stored routing decisions, newest first
+type RoutingEventsResponse struct {
+	Events []*models.RoutingEvent `json:"events"`
+}
+
+// handleListRoutingEvents returns stored routing decisions filtered by
+// ?account_id, ?client_id, ?correlation_id, ?since (RFC 3339 or a
+// duration such as 1h), ?until (RFC 3339) and ?limit. Named clients only
+// see their own decisions.
+func (s *Server) handleListRoutingEvents(c *gin.Context) {
+	now := time.Now()
+	filter := models.RoutingEventFilter{
+		AccountID:     c.Query("account_id"),
+		ClientID:      c.Query("client_id"),
+		CorrelationID: c.Query("correlation_id"),
+		Limit:         defaultRoutingEventsLimit,
+	}
+	if since := c.Query("since"); since != "" {
+		t, err := parseSince(since, now)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since must be RFC 3339 or a duration such as 1h"})
+			return
+		}
+		filter.Since = t
+	}
+	if until := c.Query("until"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "until must be RFC 3339"})
+			return
+		}
+		filter.Until = t
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 || n > maxRoutingEventsLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+			return
+		}
+		filter.Limit = n
+	}
+	if client, isClient := ClientFromContext(c); isClient {
+		filter.ClientID = client.ID
+	}
+
+	events, err := s.store.ListRoutingEvents(filter)
+	if err != nil {
+		s.logger.ErrorWithContext(c.Request.Context(), "failed to list routing events", "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load routing events"})
+		return
+	}
+	c.JSON(http.StatusOK, RoutingEventsResponse{Events: append([]*models.RoutingEvent{}, events...)})
+}
+
+// parseSince parses an RFC 3339 time or a duration back from now
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// recordRoutingEvent stores a successful selection under the request's
+// correlation ID so feedback can be joined to it later
+func (s *Server) recordRoutingEvent(c *gin.Context, req RouterSelectRequest, routerReq router.SelectRequest, resp *router.SelectResponse, at time.Time, latency time.Duration) {
+	policy := routerReq.Policy
+	if policy == "" {
+		if cfg := s.routerSvc.GetConfig(); cfg != nil {
+			policy = cfg.DefaultPolicy
+		}
+	}
+	event := &models.RoutingEvent{
+		CorrelationID:      logging.GetCorrelationID(c.Request.Context()),
+		CreatedAt:          at,
+		ClientID:           routerReq.ClientID,
+		SessionID:          req.SessionID,
+		Model:              req.Model,
+		RequestedProvider:  req.Provider,
+		RequiredDimensions: req.RequiredDims,
+		EstimatedCostPct:   req.EstimatedCost,
+		EstimatedTokens:    req.EstimatedTokens,
+		Policy:             policy,
+		AccountID:          resp.AccountID,
+		Provider:           string(resp.Provider),
+		Score:              resp.Score,
+		Reason:             resp.Reason,
+		AlternativeIDs:     resp.AlternativeIDs,
+		AffinityHit:        resp.AffinityHit,
+		LatencyMs:          float64(latency.Microseconds()) / 1000,
+		Scope:              resp.Scope,
+	}
+	if resp.PreviousAccountID != "" && resp.PreviousAccountID != resp.AccountID {
+		event.SwitchedFrom = resp.PreviousAccountID
+	}
+	if err := s.store.RecordRoutingEvent(event); err != nil {
+		s.logger.Warn("failed to record routing event", "account_id", resp.AccountID, "error", err.Error())
+	}
+}
+
+// recordRoutingOutcome joins feedback to the routing event with the same
+// correlation ID, taken from the request body or the X-Correlation-ID header
+func (s *Server) recordRoutingOutcome(c *gin.Context, req RouterFeedbackRequest) {
+	correlationID := req.CorrelationID
+	if correlationID == "" {
+		correlationID = c.GetHeader("X-Correlation-ID")
+	}
+	if correlationID == "" {
+		return
+	}
+	// A named client may only report on its own decisions
+	if client, isClient := ClientFromContext(c); isClient {
+		own, err := s.store.ListRoutingEvents(models.RoutingEventFilter{CorrelationID: correlationID, ClientID: client.ID, Limit: 1})
+		if err != nil || len(own) == 0 {
+			return
+		}
+	}
+	outcome := &models.RoutingOutcome{
+		Success:       req.Success,
+		Error:         req.Error,
+		ActualCostPct: req.ActualCost,
+		InputTokens:   req.InputTokens,
+		OutputTokens:  req.OutputTokens,
+		ReportedAt:    time.Now(),
+	}
+	if _, err := s.store.SetRoutingOutcome(correlationID, outcome); err != nil {
+		s.logger.Warn("failed to record routing outcome", "correlation_id", correlationID, "error", err.Error())
+	}
+}
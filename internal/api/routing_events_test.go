@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutingEvents(t *testing.T) {
+	server, _ := setupClientTestServer(t)
+	key := createTestClient(t, server, map[string]interface{}{"id": "batch"})
+
+	w := doRequest(server, "POST", "/router/select", map[string]string{DefaultAPIKeyHeader: testAdminKey, "X-Correlation-ID": "req-1"},
+		map[string]interface{}{"provider": "openai", "model": "gpt-4o", "estimated_tokens": 500})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var selected RouterSelectResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &selected))
+	assert.Equal(t, "req-1", selected.CorrelationID)
+
+	w = doJSON(server, "POST", "/router/select", key, map[string]interface{}{"provider": "gemini"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var clientSelected RouterSelectResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &clientSelected))
+	require.NotEmpty(t, clientSelected.CorrelationID)
+
+	// Feedback joins the decision by correlation ID
+	w = doJSON(server, "POST", "/router/feedback", testAdminKey, map[string]interface{}{
+		"account_id": selected.AccountID, "correlation_id": "req-1", "success": false, "error": "rate limited", "output_tokens": 20,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	// A client cannot report on someone else's decision
+	w = doJSON(server, "POST", "/router/feedback", key, map[string]interface{}{
+		"account_id": selected.AccountID, "correlation_id": "req-1", "success": true,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doJSON(server, "GET", "/routing/events", testAdminKey, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp RoutingEventsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Events, 2)
+	assert.Equal(t, "batch", resp.Events[0].ClientID)
+	event := resp.Events[1]
+	assert.Equal(t, "req-1", event.CorrelationID)
+	assert.Equal(t, selected.AccountID, event.AccountID)
+	assert.Equal(t, "openai", event.RequestedProvider)
+	assert.Equal(t, "gpt-4o", event.Model)
+	assert.Equal(t, int64(500), event.EstimatedTokens)
+	assert.Equal(t, "balanced", event.Policy)
+	assert.Equal(t, selected.Reason, event.Reason)
+	require.NotNil(t, event.Outcome)
+	assert.False(t, event.Outcome.Success)
+	assert.Equal(t, "rate limited", event.Outcome.Error)
+	assert.Equal(t, int64(20), event.Outcome.OutputTokens)
+
+	w = doJSON(server, "GET", "/routing/events?account_id="+selected.AccountID+"&since=1h&limit=5", testAdminKey, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	resp = RoutingEventsResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Events, 1)
+
+	// Named clients only see their own decisions
+	w = doJSON(server, "GET", "/routing/events", key, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	resp = RoutingEventsResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Events, 1)
+	assert.Equal(t, clientSelected.CorrelationID, resp.Events[0].CorrelationID)
+
+	for _, query := range []string{"?since=yesterday", "?until=2026-01-01", "?limit=0", "?limit=5000"} {
+		w = doJSON(server, "GET", "/routing/events"+query, testAdminKey, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestRoutingEvents_AffinityHitsDoNotSwitch(t *testing.T) {
+	server, _ := setupClientTestServer(t)
+
+	selectAccount := func(body map[string]interface{}) string {
+		t.Helper()
+		w := doJSON(server, "POST", "/router/select", testAdminKey, body)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp RouterSelectResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.AccountID
+	}
+
+	first := selectAccount(map[string]interface{}{"provider": "openai", "session_id": "s1"})
+	second := selectAccount(map[string]interface{}{"provider": "openai", "session_id": "s2", "exclude_accounts": []string{first}})
+	require.NotEqual(t, first, second)
+
+	// Interleaved sessions stay on their own accounts without moving the scope
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, first, selectAccount(map[string]interface{}{"provider": "openai", "session_id": "s1"}))
+		assert.Equal(t, second, selectAccount(map[string]interface{}{"provider": "openai", "session_id": "s2"}))
+	}
+
+	w := doJSON(server, "GET", "/routing/events", testAdminKey, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp RoutingEventsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Events, 8)
+	switches := 0
+	for _, event := range resp.Events {
+		if event.SwitchedFrom != "" {
+			switches++
+		}
+	}
+	assert.Equal(t, 1, switches, "only the excluded selection switched the scope")
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quotaguard/quotaguard/internal/collector"
+)
+
+// ActiveCollector exposes the active collector's polling plan and health
+type ActiveCollector interface {
+	Schedule() []collector.PollSchedule
+	Health() collector.CollectorHealth
+}
+
+// CollectorScheduleResponse lists when each account is polled next
+type CollectorScheduleResponse struct {
+	Active   bool                     `json:"active"`
+	Schedule []collector.PollSchedule `json:"schedule"`
+}
+
+// SetActiveCollector enables GET /collector/schedule and adds collector
+// state to /health
+func (s *Server) SetActiveCollector(active ActiveCollector) {
+	s.activeCollector = active
+}
+
+// handleCollectorSchedule returns the next poll time, interval and reason
+// for every enabled account.
+func (s *Server) handleCollectorSchedule(c *gin.Context) {
+	resp := CollectorScheduleResponse{Schedule: []collector.PollSchedule{}}
+	if s.activeCollector != nil {
+		resp.Active = true
+		resp.Schedule = append(resp.Schedule, s.activeCollector.Schedule()...)
+	}
+	c.JSON(http.StatusOK, resp)
+}
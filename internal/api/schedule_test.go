@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/collector"
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticCollector struct {
+	schedule []collector.PollSchedule
+	health   collector.CollectorHealth
+}
+
+func (s staticCollector) Schedule() []collector.PollSchedule { return s.schedule }
+func (s staticCollector) Health() collector.CollectorHealth  { return s.health }
+
+func TestCollectorSchedule(t *testing.T) {
+	server, _ := setupClientTestServer(t)
+
+	w := doJSON(server, "GET", "/collector/schedule", testAdminKey, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp CollectorScheduleResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.False(t, resp.Active)
+	assert.Empty(t, resp.Schedule)
+
+	next := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+	server.SetActiveCollector(staticCollector{schedule: []collector.PollSchedule{{
+		AccountID:       "codex-1",
+		NextPoll:        next,
+		IntervalSeconds: 60,
+		Reason:          collector.ScheduleReasonNormal,
+	}}})
+
+	w = doJSON(server, "GET", "/collector/schedule", testAdminKey, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	resp = CollectorScheduleResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Active)
+	require.Len(t, resp.Schedule, 1)
+	assert.Equal(t, "codex-1", resp.Schedule[0].AccountID)
+	assert.True(t, next.Equal(resp.Schedule[0].NextPoll))
+	assert.Equal(t, "normal", resp.Schedule[0].Reason)
+
+	w = doJSON(server, "GET", "/collector/schedule", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestHealth_Collector(t *testing.T) {
+	server, _ := setupClientTestServer(t)
+
+	w := doJSON(server, "GET", "/health", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.NotContains(t, resp, "collector")
+
+	server.SetActiveCollector(staticCollector{health: collector.CollectorHealth{
+		Status: collector.CollectorHealthDegraded,
+		Breakers: []collector.BreakerStatus{
+			{Scope: collector.BreakerScopeProvider, Key: "gemini", State: "open", Failures: 3},
+		},
+		FailingAccounts: map[models.PollErrorClass]int{models.PollErrorUpstream: 2},
+	}})
+
+	w = doJSON(server, "GET", "/health", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var health struct {
+		Collector collector.CollectorHealth `json:"collector"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &health))
+	assert.Equal(t, collector.CollectorHealthDegraded, health.Collector.Status)
+	require.Len(t, health.Collector.Breakers, 1)
+	assert.Equal(t, "gemini", health.Collector.Breakers[0].Key)
+	assert.Equal(t, 2, health.Collector.FailingAccounts[models.PollErrorUpstream])
+}
@@ -15,26 +15,32 @@ import (
 	"github.com/quotaguard/quotaguard/internal/errors"
 	"github.com/quotaguard/quotaguard/internal/logging"
 	"github.com/quotaguard/quotaguard/internal/metrics"
+	"github.com/quotaguard/quotaguard/internal/middleware"
 	"github.com/quotaguard/quotaguard/internal/models"
 	"github.com/quotaguard/quotaguard/internal/reservation"
 	"github.com/quotaguard/quotaguard/internal/router"
 	"github.com/quotaguard/quotaguard/internal/store"
+	"github.com/quotaguard/quotaguard/pkg/headers"
 )
 
 // Server represents the HTTP API server
 type Server struct {
-	router      *gin.Engine
-	config      config.ServerConfig
-	apiConfig   config.APIConfig
-	store       store.Store
-	routerSvc   router.Router
-	reservation *reservation.Manager
-	collector   *collector.PassiveCollector
-	metrics     *metrics.Metrics
-	logger      *logging.Logger
-	rateLimiter *IPRateLimiter
-	httpServer  *http.Server
-	tlsConfig   config.TLSConfig
+	router          *gin.Engine
+	config          config.ServerConfig
+	apiConfig       config.APIConfig
+	store           store.Store
+	routerSvc       router.Router
+	reservation     *reservation.Manager
+	collector       *collector.PassiveCollector
+	metrics         *metrics.Metrics
+	logger          *logging.Logger
+	rateLimiter     *IPRateLimiter
+	clientLimit     *ClientRateLimiter
+	auditStore      logging.AuditStore
+	activeCollector ActiveCollector
+	parsers         *headers.Registry
+	httpServer      *http.Server
+	tlsConfig       config.TLSConfig
 }
 
 // Router returns the gin router for testing purposes
@@ -72,6 +78,8 @@ func NewServer(cfg config.ServerConfig, apiCfg config.APIConfig, s store.Store,
 		metrics:     m,
 		logger:      logger,
 		rateLimiter: rateLimiter,
+		clientLimit: newClientRateLimiter(),
+		parsers:     headers.NewRegistry(),
 		tlsConfig:   cfg.TLS,
 	}
 	server.router.HandleMethodNotAllowed = true
@@ -91,6 +99,9 @@ func NewServer(cfg config.ServerConfig, apiCfg config.APIConfig, s store.Store,
 	// Add logging middleware for structured logs
 	server.router.Use(loggingMiddleware(logger))
 
+	// Record API access with the authenticated identity once an audit store is set
+	server.router.Use(server.auditMiddleware())
+
 	server.setupRoutes()
 	return server
 }
@@ -124,6 +135,28 @@ func loggingMiddleware(logger *logging.Logger) gin.HandlerFunc {
 	}
 }
 
+// Metrics returns the server's Prometheus metrics so other components can
+// report into the same registry
+func (s *Server) Metrics() *metrics.Metrics {
+	return s.metrics
+}
+
+// SetAuditStore enables audit events for API access
+func (s *Server) SetAuditStore(auditStore logging.AuditStore) {
+	s.auditStore = auditStore
+}
+
+// auditMiddleware records API access in the audit store when one is configured
+func (s *Server) auditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.auditStore == nil {
+			c.Next()
+			return
+		}
+		middleware.AuditMiddleware(s.auditStore)(c)
+	}
+}
+
 // setupRoutes configures all API routes
 func (s *Server) setupRoutes() {
 	// Prometheus metrics endpoint - NO authentication required
@@ -136,29 +169,52 @@ func (s *Server) setupRoutes() {
 	s.router.GET("/oauth/callback", handleOAuthCallback)
 	s.router.GET("/oauth/callback/:provider", handleOAuthCallback)
 
-	// Create auth middleware based on configuration
-	authMiddleware := APIKeyAuth(s.apiConfig.Auth.APIKeys, s.apiConfig.Auth.HeaderName, s.logger)
+	// Create auth middleware based on configuration; named API clients are
+	// looked up in the store and rate limited individually
+	var clients ClientStore
+	if s.store != nil {
+		clients = s.store
+	}
+	authMiddleware, err := NewAuthMiddleware(s.apiConfig.Auth, clients, s.logger)
+	if err != nil {
+		// Fail closed: a broken auth setup must not expose the API
+		s.logger.Error("failed to initialize authentication", "error", err.Error())
+		authMiddleware = func(c *gin.Context) {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "auth_misconfigured",
+				Message: "Authentication is misconfigured",
+				Code:    http.StatusInternalServerError,
+			})
+		}
+	}
+	clientLimit := clientRateLimitMiddleware(s.clientLimit)
 
 	// Router endpoints - require authentication
 	routerGroup := s.router.Group("")
-	routerGroup.Use(authMiddleware)
+	routerGroup.Use(authMiddleware, clientLimit)
 	{
-		routerGroup.POST("/router/select", s.handleRouterSelect)
-		routerGroup.POST("/router/feedback", s.handleRouterFeedback)
-		routerGroup.GET("/router/distribution", s.handleRouterDistribution)
+		routerGroup.POST("/router/select", RequireScope(ScopeRouterSelect), s.handleRouterSelect)
+		routerGroup.POST("/router/feedback", RequireScope(ScopeRouterFeedback), s.handleRouterFeedback)
+		routerGroup.GET("/router/distribution", RequireScope(ScopeRouterSelect), s.handleRouterDistribution)
+		routerGroup.GET("/snapshot", RequireScope(ScopeRouterSelect), s.handleSnapshot)
 	}
 
 	// Quota endpoints - require authentication
 	quotaGroup := s.router.Group("")
-	quotaGroup.Use(authMiddleware)
+	quotaGroup.Use(authMiddleware, clientLimit, RequireScope(ScopeQuotasRead))
 	{
 		quotaGroup.GET("/quotas", s.handleListQuotas)
 		quotaGroup.GET("/quotas/:account_id", s.handleGetQuota)
+		quotaGroup.GET("/spend", s.handleSpendReport)
+		quotaGroup.GET("/collector/schedule", s.handleCollectorSchedule)
+		quotaGroup.GET("/alerts", s.handleListAlerts)
+		quotaGroup.GET("/digest", s.handleDigest)
+		quotaGroup.GET("/routing/events", s.handleListRoutingEvents)
 	}
 
 	// Reservation endpoints - require authentication
 	reservationGroup := s.router.Group("")
-	reservationGroup.Use(authMiddleware)
+	reservationGroup.Use(authMiddleware, clientLimit, RequireScope(ScopeReservationsWrite))
 	{
 		reservationGroup.POST("/reservations", s.handleCreateReservation)
 		reservationGroup.POST("/reservations/:id/release", s.handleReleaseReservation)
@@ -166,12 +222,25 @@ func (s *Server) setupRoutes() {
 		reservationGroup.GET("/reservations/:id", s.handleGetReservation)
 	}
 
-	// Ingest endpoint - require authentication
+	// Ingest endpoint - writes account quota state
 	ingestGroup := s.router.Group("")
-	ingestGroup.Use(authMiddleware)
+	ingestGroup.Use(authMiddleware, RequireScope(ScopeAdminAccounts))
 	{
 		ingestGroup.POST("/ingest", s.handleIngest)
 	}
+
+	// API client management
+	clientGroup := s.router.Group("/clients")
+	clientGroup.Use(authMiddleware, RequireScope(ScopeAdminClients))
+	{
+		clientGroup.GET("", s.handleListClients)
+		clientGroup.POST("", s.handleCreateClient)
+		clientGroup.GET("/:id", s.handleGetClient)
+		clientGroup.PUT("/:id", s.handleUpdateClient)
+		clientGroup.DELETE("/:id", s.handleDeleteClient)
+		clientGroup.POST("/:id/rotate", s.handleRotateClientKey)
+		clientGroup.GET("/:id/usage", s.handleGetClientUsage)
+	}
 }
 
 // Run starts the HTTP or HTTPS server based on TLS configuration
@@ -318,11 +387,15 @@ func (s *Server) Shutdown(ctx context.Context) error {
 
 // handleHealth returns health status
 func (s *Server) handleHealth(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
+	resp := gin.H{
 		"status":    "healthy",
 		"timestamp": time.Now().UTC(),
 		"router":    s.routerSvc.IsHealthy(),
-	})
+	}
+	if s.activeCollector != nil {
+		resp["collector"] = s.activeCollector.Health()
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 // RouterSelectRequest represents a request to select an account
@@ -335,6 +408,8 @@ type RouterSelectRequest struct {
 	Exclude          []string `json:"exclude_accounts,omitempty"`
 	ExcludeProviders []string `json:"exclude_providers,omitempty"`
 	Model            string   `json:"model,omitempty"`
+	SessionID        string   `json:"session_id,omitempty"`
+	ClientID         string   `json:"client_id,omitempty"`
 }
 
 // RouterSelectResponse represents the response from select
@@ -344,6 +419,9 @@ type RouterSelectResponse struct {
 	Score          float64  `json:"score"`
 	Reason         string   `json:"reason"`
 	AlternativeIDs []string `json:"alternative_ids,omitempty"`
+	AffinityHit    bool     `json:"affinity_hit,omitempty"`
+	// CorrelationID identifies the decision; send it back with feedback
+	CorrelationID string `json:"correlation_id,omitempty"`
 }
 
 // handleRouterSelect handles account selection requests
@@ -361,6 +439,8 @@ func (s *Server) handleRouterSelect(c *gin.Context) {
 		Policy:          req.Policy,
 		Exclude:         req.Exclude,
 		Model:           req.Model,
+		SessionKey:      req.SessionID,
+		ClientID:        req.ClientID,
 	}
 
 	// Convert provider
@@ -378,7 +458,34 @@ func (s *Server) handleRouterSelect(c *gin.Context) {
 		routerReq.RequiredDims = append(routerReq.RequiredDims, models.DimensionType(d))
 	}
 
+	// Apply the named client's restrictions and defaults
+	client, isClient := ClientFromContext(c)
+	if isClient {
+		if routerReq.Provider != "" && !client.AllowsProvider(routerReq.Provider) {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("provider %s is not allowed for client %s", routerReq.Provider, client.ID)})
+			return
+		}
+		if routerReq.Policy == "" {
+			routerReq.Policy = client.DefaultPolicy
+		}
+		routerReq.ClientID = client.ID
+		for _, acc := range s.store.ListEnabledAccounts() {
+			if !client.AllowsAccount(acc) {
+				routerReq.Exclude = append(routerReq.Exclude, acc.ID)
+			}
+		}
+		if remaining, limited := s.clientBudgetRemaining(client); limited && remaining <= req.EstimatedCost {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":     "daily quota share exhausted",
+				"client_id": client.ID,
+			})
+			return
+		}
+	}
+
+	selectStart := time.Now()
 	resp, err := s.routerSvc.Select(c.Request.Context(), routerReq)
+	latency := time.Since(selectStart)
 	if err != nil {
 		s.logger.ErrorWithContext(c.Request.Context(), "router select failed",
 			"error", err.Error(),
@@ -388,8 +495,11 @@ func (s *Server) handleRouterSelect(c *gin.Context) {
 		return
 	}
 
-	// Record the switch
-	s.routerSvc.RecordSwitch(resp.AccountID)
+	// Record the switch within the request's anti-flapping scope; a session's
+	// sticky account serves that session only and does not move the scope
+	if !resp.AffinityHit {
+		s.routerSvc.RecordScopedSwitch(resp.Scope, resp.AccountID)
+	}
 	selectedAt := time.Now()
 	if acc, ok := s.store.GetAccount(resp.AccountID); ok && acc != nil {
 		group := groupForModel(acc.ProviderType, req.Model)
@@ -402,8 +512,18 @@ func (s *Server) handleRouterSelect(c *gin.Context) {
 		}
 	}
 
+	if isClient {
+		if err := s.store.AddClientUsage(client.ID, models.UsageDay(selectedAt), 1, 0); err != nil {
+			s.logger.Warn("failed to record client usage", "client_id", client.ID, "error", err.Error())
+		}
+	}
+
 	// Record router decision
-	s.metrics.RecordRouterDecision(req.Policy, "selected", string(resp.Provider))
+	s.recordRoutingEvent(c, req, routerReq, resp, selectedAt, latency)
+	s.metrics.RecordRouterDecision(routerReq.Policy, "selected", string(resp.Provider))
+	if req.SessionID != "" {
+		s.metrics.RecordRouterAffinity(resp.AffinityHit, string(resp.Provider))
+	}
 
 	c.JSON(http.StatusOK, RouterSelectResponse{
 		AccountID:      resp.AccountID,
@@ -411,6 +531,8 @@ func (s *Server) handleRouterSelect(c *gin.Context) {
 		Score:          resp.Score,
 		Reason:         resp.Reason,
 		AlternativeIDs: resp.AlternativeIDs,
+		AffinityHit:    resp.AffinityHit,
+		CorrelationID:  logging.GetCorrelationID(c.Request.Context()),
 	})
 }
 
@@ -463,8 +585,13 @@ type RouterFeedbackRequest struct {
 	AccountID     string  `json:"account_id" binding:"required"`
 	ReservationID string  `json:"reservation_id,omitempty"`
 	ActualCost    float64 `json:"actual_cost_percent,omitempty"`
+	InputTokens   int64   `json:"input_tokens,omitempty"`
+	OutputTokens  int64   `json:"output_tokens,omitempty"`
 	Success       bool    `json:"success"`
 	Error         string  `json:"error,omitempty"`
+	// CorrelationID of the select response; the X-Correlation-ID header
+	// is used when empty
+	CorrelationID string `json:"correlation_id,omitempty"`
 }
 
 // handleRouterFeedback handles routing feedback
@@ -475,15 +602,38 @@ func (s *Server) handleRouterFeedback(c *gin.Context) {
 		return
 	}
 
-	// Release reservation if provided
-	if req.ReservationID != "" && req.ActualCost > 0 {
-		if err := s.reservation.Release(req.ReservationID, req.ActualCost); err != nil {
+	client, isClient := ClientFromContext(c)
+	clientID := ""
+	if isClient {
+		acc, exists := s.store.GetAccount(req.AccountID)
+		if !exists || !client.AllowsAccount(acc) {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("account %s is not allowed for client %s", req.AccountID, client.ID)})
+			return
+		}
+		clientID = client.ID
+	}
+
+	// Release reservation if provided; without one, charge the actual cost directly
+	if req.ReservationID != "" {
+		if !s.ownsReservation(c, req.ReservationID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
+			return
+		}
+		if err := s.reservation.ReleaseWithTokens(req.ReservationID, req.ActualCost, req.InputTokens, req.OutputTokens); err != nil {
 			s.logger.ErrorWithContext(c.Request.Context(), "failed to release reservation",
 				"reservation_id", req.ReservationID,
 				"error", err.Error(),
 			)
 		}
+	} else {
+		if isClient && req.ActualCost > 0 {
+			if err := s.store.AddClientUsage(client.ID, models.UsageDay(time.Now()), 0, req.ActualCost); err != nil {
+				s.logger.Warn("failed to record client usage", "client_id", client.ID, "error", err.Error())
+			}
+		}
+		s.recordSpend(c, req.AccountID, clientID, req.InputTokens, req.OutputTokens)
 	}
+	s.recordRoutingOutcome(c, req)
 
 	c.JSON(http.StatusOK, gin.H{"status": "feedback recorded"})
 }
@@ -573,7 +723,32 @@ func (s *Server) handleCreateReservation(c *gin.Context) {
 		return
 	}
 
-	res, err := s.reservation.Create(c.Request.Context(), req.AccountID, req.EstimatedCostPct, req.CorrelationID)
+	if acc, exists := s.store.GetAccount(req.AccountID); exists && s.budgetExhausted(acc) {
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":      "monthly budget exhausted",
+			"account_id": acc.ID,
+		})
+		return
+	}
+
+	clientID := ""
+	if client, ok := ClientFromContext(c); ok {
+		acc, exists := s.store.GetAccount(req.AccountID)
+		if !exists || !client.AllowsAccount(acc) {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("account %s is not allowed for client %s", req.AccountID, client.ID)})
+			return
+		}
+		if remaining, limited := s.clientBudgetRemaining(client); limited && remaining < req.EstimatedCostPct {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":     "daily quota share exhausted",
+				"client_id": client.ID,
+			})
+			return
+		}
+		clientID = client.ID
+	}
+
+	res, err := s.reservation.CreateForClient(c.Request.Context(), req.AccountID, req.EstimatedCostPct, req.CorrelationID, clientID)
 	if err != nil {
 		s.logger.ErrorWithContext(c.Request.Context(), "failed to create reservation",
 			"account_id", req.AccountID,
@@ -605,13 +780,19 @@ func (s *Server) handleReleaseReservation(c *gin.Context) {
 
 	var req struct {
 		ActualCostPct float64 `json:"actual_cost_percent" binding:"required"`
+		InputTokens   int64   `json:"input_tokens,omitempty"`
+		OutputTokens  int64   `json:"output_tokens,omitempty"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !s.ownsReservation(c, id) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
+		return
+	}
 
-	if err := s.reservation.Release(id, req.ActualCostPct); err != nil {
+	if err := s.reservation.ReleaseWithTokens(id, req.ActualCostPct, req.InputTokens, req.OutputTokens); err != nil {
 		s.logger.ErrorWithContext(c.Request.Context(), "failed to release reservation",
 			"reservation_id", id,
 			"error", err.Error(),
@@ -633,6 +814,10 @@ func (s *Server) handleReleaseReservation(c *gin.Context) {
 // handleCancelReservation cancels a reservation
 func (s *Server) handleCancelReservation(c *gin.Context) {
 	id := c.Param("id")
+	if !s.ownsReservation(c, id) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
+		return
+	}
 
 	if err := s.reservation.Cancel(id); err != nil {
 		s.logger.ErrorWithContext(c.Request.Context(), "failed to cancel reservation",
@@ -658,21 +843,46 @@ func (s *Server) handleGetReservation(c *gin.Context) {
 	id := c.Param("id")
 
 	res, ok := s.reservation.Get(id)
-	if !ok {
+	if !ok || !s.ownsReservation(c, id) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
 		return
 	}
 	c.JSON(http.StatusOK, res)
 }
 
-// IngestRequest represents a quota update from external source
+// IngestRequest represents a quota update from external source.
+// Proxies can send the raw provider response headers instead of dimensions;
+// they are parsed with the provider's header parser.
 type IngestRequest struct {
-	AccountID             string             `json:"account_id" binding:"required"`
-	Provider              string             `json:"provider" binding:"required"`
-	EffectiveRemainingPct float64            `json:"effective_remaining_percent"`
-	Dimensions            []models.Dimension `json:"dimensions,omitempty"`
-	IsThrottled           bool               `json:"is_throttled"`
-	Source                string             `json:"source"`
+	AccountID             string              `json:"account_id" binding:"required"`
+	Provider              string              `json:"provider" binding:"required"`
+	EffectiveRemainingPct float64             `json:"effective_remaining_percent"`
+	Dimensions            []models.Dimension  `json:"dimensions,omitempty"`
+	Headers               map[string][]string `json:"headers,omitempty"`
+	IsThrottled           bool                `json:"is_throttled"`
+	Source                string              `json:"source"`
+}
+
+// parseIngestHeaders builds a quota from raw provider response headers,
+// merging any explicitly sent dimensions
+func (s *Server) parseIngestHeaders(req IngestRequest) (*models.QuotaInfo, error) {
+	h := make(http.Header, len(req.Headers))
+	for key, values := range req.Headers {
+		for _, v := range values {
+			h.Add(key, v)
+		}
+	}
+
+	quota, err := s.parsers.Parse(models.Provider(req.Provider), h, req.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Dimensions) > 0 {
+		quota.Dimensions = append(quota.Dimensions, req.Dimensions...)
+		quota.UpdateEffective()
+	}
+	quota.IsThrottled = quota.IsThrottled || req.IsThrottled
+	return quota, nil
 }
 
 // handleIngest handles quota updates from external sources
@@ -693,6 +903,19 @@ func (s *Server) handleIngest(c *gin.Context) {
 		CollectedAt:           time.Now(),
 	}
 
+	if len(req.Headers) > 0 {
+		parsed, err := s.parseIngestHeaders(req)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("failed to parse headers: %v", err)})
+			return
+		}
+		quota = parsed
+		req.EffectiveRemainingPct = quota.EffectiveRemainingPct
+		if req.Source == "" {
+			req.Source = string(models.SourceHeaders)
+		}
+	}
+
 	// Update store
 	s.store.SetQuota(req.AccountID, quota)
 
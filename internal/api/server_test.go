@@ -325,6 +325,44 @@ func TestHandleIngest(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "ingested")
 }
 
+func TestHandleIngest_AnthropicUnifiedHeaders(t *testing.T) {
+	server, s := setupTestServer()
+
+	body := IngestRequest{
+		AccountID: "claude-1",
+		Provider:  "anthropic",
+		Headers: map[string][]string{
+			"anthropic-ratelimit-unified-status":         {"allowed"},
+			"anthropic-ratelimit-unified-5h-utilization": {"0.25"},
+			"anthropic-ratelimit-unified-5h-reset":       {"1767225600"},
+			"anthropic-ratelimit-unified-7d-utilization": {"0.6"},
+		},
+	}
+	jsonBody, _ := json.Marshal(body)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/ingest", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	server.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	quota, ok := s.GetQuota("claude-1")
+	require.True(t, ok)
+	assert.Equal(t, models.SourceHeaders, quota.Source)
+	assert.Len(t, quota.Dimensions, 2)
+	assert.InDelta(t, 40.0, quota.EffectiveRemainingPct, 0.001)
+	assert.False(t, quota.IsThrottled)
+
+	// Unparseable headers are rejected
+	body.Headers = map[string][]string{"x-unrelated": {"1"}}
+	jsonBody, _ = json.Marshal(body)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/ingest", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	server.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestHandleIngestInvalid(t *testing.T) {
 	server, _ := setupTestServer()
 
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/snapshot"
+)
+
+// handleSnapshot returns a signed snapshot of the routable accounts with
+// their remaining quota and block state. Named clients only see accounts
+// they are allowed to use.
+func (s *Server) handleSnapshot(c *gin.Context) {
+	accounts := s.store.ListEnabledAccounts()
+	if client, ok := ClientFromContext(c); ok {
+		allowed := accounts[:0:0]
+		for _, acc := range accounts {
+			if client.AllowsAccount(acc) {
+				allowed = append(allowed, acc)
+			}
+		}
+		accounts = allowed
+	}
+
+	snap := snapshot.Build(accounts, s.store.ListQuotas(), func(acc *models.Account) bool {
+		return s.budgetExhausted(acc)
+	}, time.Now())
+	env, err := snapshot.Seal(snap, []byte(s.apiConfig.Snapshot.SigningKey))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, env)
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/snapshot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleSnapshot(t *testing.T) {
+	server, s := setupClientTestServer(t)
+	server.apiConfig.Snapshot.SigningKey = "snap-key"
+
+	blockedUntil := time.Now().Add(time.Hour)
+	require.NoError(t, s.SetAccountBlockedUntil("codex-2", &blockedUntil))
+
+	w := doJSON(server, "GET", "/snapshot", testAdminKey, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var env snapshot.Envelope
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+
+	snap, err := snapshot.Open(&env, []byte("snap-key"))
+	require.NoError(t, err)
+	require.Len(t, snap.Accounts, 3)
+	assert.Equal(t, "codex-1", snap.Accounts[0].ID)
+	require.NotNil(t, snap.Accounts[0].RemainingPct)
+	assert.Equal(t, 80.0, *snap.Accounts[0].RemainingPct)
+	assert.True(t, snap.Accounts[1].Blocked(time.Now()))
+
+	// Named clients only see accounts they may route to
+	key := createTestClient(t, server, map[string]interface{}{
+		"id":                "gemini-only",
+		"allowed_providers": []string{"gemini"},
+	})
+	w = doJSON(server, "GET", "/snapshot", key, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	env = snapshot.Envelope{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	snap, err = snapshot.Open(&env, []byte("snap-key"))
+	require.NoError(t, err)
+	require.Len(t, snap.Accounts, 1)
+	assert.Equal(t, "gemini-1", snap.Accounts[0].ID)
+
+	w = doJSON(server, "GET", "/snapshot", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
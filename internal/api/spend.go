@@ -0,0 +1,150 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// SpendReportResponse summarizes the spend ledger for a day range
+type SpendReportResponse struct {
+	From         string              `json:"from"`
+	To           string              `json:"to"`
+	GroupBy      string              `json:"group_by"`
+	Requests     int64               `json:"requests"`
+	InputTokens  int64               `json:"input_tokens"`
+	OutputTokens int64               `json:"output_tokens"`
+	CostUSD      float64             `json:"cost_usd"`
+	Groups       []models.SpendTotal `json:"groups"`
+	Budgets      []AccountBudget     `json:"budgets,omitempty"`
+}
+
+// AccountBudget reports month-to-date spend against an account's monthly cap
+type AccountBudget struct {
+	AccountID        string    `json:"account_id"`
+	MonthlyBudgetUSD float64   `json:"monthly_budget_usd"`
+	SpentUSD         float64   `json:"spent_usd"`
+	RemainingUSD     float64   `json:"remaining_usd"`
+	Exhausted        bool      `json:"exhausted"`
+	ResetAt          time.Time `json:"reset_at"`
+}
+
+var spendGroupKeys = map[string]func(*models.SpendEntry) string{
+	"account": func(e *models.SpendEntry) string { return e.AccountID },
+	"client":  func(e *models.SpendEntry) string { return e.ClientID },
+	"day":     func(e *models.SpendEntry) string { return e.Day },
+}
+
+// handleSpendReport returns spend grouped by account, client or day.
+// Named clients only see their own spend.
+func (s *Server) handleSpendReport(c *gin.Context) {
+	now := time.Now()
+	from := c.DefaultQuery("from", models.MonthStartDay(now))
+	to := c.DefaultQuery("to", models.UsageDay(now))
+	for _, day := range []string{from, to} {
+		if _, err := time.Parse("2006-01-02", day); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be YYYY-MM-DD"})
+			return
+		}
+	}
+	groupBy := c.DefaultQuery("group_by", "account")
+	key, ok := spendGroupKeys[groupBy]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_by must be account, client or day"})
+		return
+	}
+
+	entries, err := s.store.ListSpend(from, to)
+	if err != nil {
+		s.logger.ErrorWithContext(c.Request.Context(), "failed to list spend", "error", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load spend ledger"})
+		return
+	}
+
+	client, isClient := ClientFromContext(c)
+	if isClient {
+		own := entries[:0]
+		for _, entry := range entries {
+			if entry.ClientID == client.ID {
+				own = append(own, entry)
+			}
+		}
+		entries = own
+	}
+
+	resp := SpendReportResponse{
+		From:    from,
+		To:      to,
+		GroupBy: groupBy,
+		Groups:  models.GroupSpend(entries, key),
+	}
+	for _, entry := range entries {
+		resp.Requests += entry.Requests
+		resp.InputTokens += entry.InputTokens
+		resp.OutputTokens += entry.OutputTokens
+		resp.CostUSD += entry.CostUSD
+	}
+	if !isClient {
+		resp.Budgets = s.accountBudgets(now)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// accountBudgets returns month-to-date spend for every account with a cap
+func (s *Server) accountBudgets(now time.Time) []AccountBudget {
+	budgets := make([]AccountBudget, 0)
+	for _, acc := range s.store.ListAccounts() {
+		if !acc.HasBudget() {
+			continue
+		}
+		spent, err := s.store.GetAccountSpend(acc.ID, models.MonthStartDay(now), models.UsageDay(now))
+		if err != nil {
+			continue
+		}
+		remaining := acc.MonthlyBudget - spent
+		if remaining < 0 {
+			remaining = 0
+		}
+		budgets = append(budgets, AccountBudget{
+			AccountID:        acc.ID,
+			MonthlyBudgetUSD: acc.MonthlyBudget,
+			SpentUSD:         spent,
+			RemainingUSD:     remaining,
+			Exhausted:        remaining == 0,
+			ResetAt:          models.NextMonthStart(now),
+		})
+	}
+	sort.Slice(budgets, func(i, j int) bool { return budgets[i].AccountID < budgets[j].AccountID })
+	return budgets
+}
+
+// budgetExhausted reports whether an account has reached its monthly spend cap
+func (s *Server) budgetExhausted(acc *models.Account) bool {
+	if !acc.HasBudget() {
+		return false
+	}
+	now := time.Now()
+	spent, err := s.store.GetAccountSpend(acc.ID, models.MonthStartDay(now), models.UsageDay(now))
+	if err != nil {
+		return false
+	}
+	return spent >= acc.MonthlyBudget
+}
+
+// recordSpend prices a request's token usage and adds it to the spend ledger
+func (s *Server) recordSpend(c *gin.Context, accountID, clientID string, inputTokens, outputTokens int64) {
+	if inputTokens <= 0 && outputTokens <= 0 {
+		return
+	}
+	acc, ok := s.store.GetAccount(accountID)
+	if !ok {
+		return
+	}
+	if err := s.store.AddSpend(models.NewSpendEntry(acc, clientID, inputTokens, outputTokens, time.Now())); err != nil {
+		s.logger.WarnWithContext(c.Request.Context(), "failed to record spend", "account_id", accountID, "error", err.Error())
+	}
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpendTracking(t *testing.T) {
+	server, s := setupClientTestServer(t)
+	acc, _ := s.GetAccount("codex-1")
+	acc.InputCost = 0.01
+	acc.OutputCost = 0.02
+	acc.MonthlyBudget = 1
+	s.SetAccount(acc)
+	key := createTestClient(t, server, map[string]interface{}{"id": "batch"})
+
+	// 10K input + 5K output tokens = $0.20
+	w := doJSON(server, "POST", "/router/feedback", testAdminKey, map[string]interface{}{
+		"account_id": "codex-1", "success": true, "input_tokens": 10000, "output_tokens": 5000,
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(server, "POST", "/router/feedback", key, map[string]interface{}{
+		"account_id": "codex-1", "success": true, "input_tokens": 10000, "output_tokens": 5000,
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = doJSON(server, "GET", "/spend?group_by=client", testAdminKey, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var report SpendReportResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.InDelta(t, 0.4, report.CostUSD, 1e-9)
+	assert.Equal(t, int64(2), report.Requests)
+	require.Len(t, report.Groups, 2)
+	require.Len(t, report.Budgets, 1)
+	assert.Equal(t, "codex-1", report.Budgets[0].AccountID)
+	assert.InDelta(t, 0.6, report.Budgets[0].RemainingUSD, 1e-9)
+
+	// Named clients only see their own spend
+	w = doJSON(server, "GET", "/spend", key, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	report = SpendReportResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.InDelta(t, 0.2, report.CostUSD, 1e-9)
+	assert.Empty(t, report.Budgets)
+
+	w = doJSON(server, "GET", "/spend?group_by=model", testAdminKey, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doJSON(server, "GET", "/spend?from=yesterday", testAdminKey, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSpendTracking_HardCap(t *testing.T) {
+	server, s := setupClientTestServer(t)
+	acc, _ := s.GetAccount("codex-1")
+	acc.InputCost = 0.01
+	acc.MonthlyBudget = 1
+	acc.Priority = 10
+	s.SetAccount(acc)
+
+	w := doJSON(server, "POST", "/reservations", testAdminKey, map[string]interface{}{
+		"account_id": "codex-1", "estimated_cost_percent": 1, "correlation_id": "c-1",
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created CreateReservationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	// Releasing with 100K input tokens spends the whole $1 cap
+	w = doJSON(server, "POST", "/reservations/"+created.ReservationID+"/release", testAdminKey, map[string]interface{}{
+		"actual_cost_percent": 1, "input_tokens": 100000,
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = doJSON(server, "POST", "/reservations", testAdminKey, map[string]interface{}{
+		"account_id": "codex-1", "estimated_cost_percent": 1, "correlation_id": "c-2",
+	})
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	for i := 0; i < 3; i++ {
+		w = doJSON(server, "POST", "/router/select", testAdminKey, map[string]interface{}{"provider": "openai"})
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp RouterSelectResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "codex-2", resp.AccountID)
+	}
+
+	now := time.Now()
+	spent, err := s.GetAccountSpend("codex-1", models.MonthStartDay(now), models.UsageDay(now))
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0, spent, 1e-9)
+}
@@ -0,0 +1,385 @@
+// Package chart renders quota history line charts as PNG images. Drawing is
+// done in pure Go with a built-in bitmap font, so charts need no fonts or
+// external services at runtime.
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"sort"
+	"time"
+)
+
+// Default image size
+const (
+	DefaultWidth  = 960
+	DefaultHeight = 540
+)
+
+// Layout, in image pixels
+const (
+	textScale      = 2
+	marginLeft     = 72
+	marginRight    = 24
+	marginTop      = 48
+	axisLabelSpace = 32
+	legendRow      = 22
+	legendSwatch   = 16
+	legendGap      = 24
+	maxLegendChars = 32
+	xTicks         = 6
+)
+
+// MarkerKind is the kind of event a marker annotates.
+type MarkerKind string
+
+const (
+	// MarkerReset marks a quota window reset.
+	MarkerReset MarkerKind = "reset"
+	// MarkerSwitch marks the router switching accounts.
+	MarkerSwitch MarkerKind = "switch"
+)
+
+// Point is a series value at a time.
+type Point struct {
+	At    time.Time
+	Value float64
+}
+
+// Series is one named line of remaining percent values.
+type Series struct {
+	Name   string
+	Points []Point
+}
+
+// Marker annotates a point in time with a vertical line.
+type Marker struct {
+	At    time.Time
+	Kind  MarkerKind
+	Label string
+}
+
+// LineChart plots series of percentages between 0 and 100 over [From, To].
+type LineChart struct {
+	Title   string
+	From    time.Time
+	To      time.Time
+	Series  []Series
+	Markers []Marker
+	// Width and Height default to DefaultWidth and DefaultHeight.
+	Width  int
+	Height int
+}
+
+var (
+	colorBackground = color.RGBA{255, 255, 255, 255}
+	colorText       = color.RGBA{33, 37, 41, 255}
+	colorMuted      = color.RGBA{108, 117, 125, 255}
+	colorGrid       = color.RGBA{226, 230, 234, 255}
+	colorAxis       = color.RGBA{173, 181, 189, 255}
+	colorReset      = color.RGBA{46, 160, 67, 255}
+	colorSwitch     = color.RGBA{230, 126, 34, 255}
+
+	// seriesColors avoids the marker colors so lines and markers stay apart
+	seriesColors = []color.RGBA{
+		{31, 119, 180, 255},
+		{214, 39, 40, 255},
+		{148, 103, 189, 255},
+		{23, 190, 207, 255},
+		{140, 86, 75, 255},
+		{227, 119, 194, 255},
+		{188, 189, 34, 255},
+		{52, 73, 94, 255},
+	}
+)
+
+// legendItem is one entry of the legend below the plot
+type legendItem struct {
+	label  string
+	color  color.RGBA
+	dashed bool
+}
+
+// PNG renders the chart as a PNG image.
+func (c *LineChart) PNG() ([]byte, error) {
+	if !c.To.After(c.From) {
+		return nil, fmt.Errorf("chart: empty time range")
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.Render()); err != nil {
+		return nil, fmt.Errorf("chart: encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Render draws the chart into an image.
+func (c *LineChart) Render() *image.RGBA {
+	width, height := c.Width, c.Height
+	if width <= 0 {
+		width = DefaultWidth
+	}
+	if height <= 0 {
+		height = DefaultHeight
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fillRect(img, 0, 0, width, height, colorBackground)
+
+	items := c.legendItems()
+	plot := plotRect(width, height, layoutLegend(items, marginLeft, width-marginRight))
+
+	drawText(img, marginLeft, (marginTop-textHeight(textScale))/2, c.Title, textScale, colorText)
+	c.drawGrid(img, plot)
+	c.drawMarkers(img, plot)
+	if !c.drawSeries(img, plot) {
+		msg := "NO DATA"
+		drawText(img, plot.Min.X+(plot.Dx()-textWidth(msg, textScale))/2, plot.Min.Y+(plot.Dy()-textHeight(textScale))/2, msg, textScale, colorMuted)
+	}
+	drawLegend(img, items, marginLeft, width-marginRight, plot.Max.Y+axisLabelSpace)
+	return img
+}
+
+// plotRect returns the plot area of an image with legendRows legend rows
+func plotRect(width, height, legendRows int) image.Rectangle {
+	return image.Rect(marginLeft, marginTop, width-marginRight, height-axisLabelSpace-legendRows*legendRow-8)
+}
+
+// drawGrid draws the percent gridlines, time ticks and plot border
+func (c *LineChart) drawGrid(img *image.RGBA, plot image.Rectangle) {
+	for pct := 0; pct <= 100; pct += 25 {
+		y := c.y(plot, float64(pct))
+		drawLine(img, plot.Min.X, y, plot.Max.X, y, colorGrid)
+		label := fmt.Sprintf("%d%%", pct)
+		drawText(img, plot.Min.X-10-textWidth(label, textScale), y-textHeight(textScale)/2, label, textScale, colorMuted)
+	}
+
+	span := c.To.Sub(c.From)
+	layout := "15:04"
+	if span > 48*time.Hour {
+		layout = "02.01"
+	}
+	for i := 0; i <= xTicks; i++ {
+		at := c.From.Add(span * time.Duration(i) / xTicks)
+		x := c.x(plot, at)
+		drawLine(img, x, plot.Min.Y, x, plot.Max.Y, colorGrid)
+		label := at.Format(layout)
+		lx := x - textWidth(label, textScale)/2
+		if i == xTicks {
+			lx = plot.Max.X - textWidth(label, textScale)
+		} else if i == 0 {
+			lx = plot.Min.X
+		}
+		drawText(img, lx, plot.Max.Y+10, label, textScale, colorMuted)
+	}
+
+	drawRect(img, plot, colorAxis)
+}
+
+// drawMarkers draws a dashed vertical line with a letter for every marker
+func (c *LineChart) drawMarkers(img *image.RGBA, plot image.Rectangle) {
+	for _, m := range c.Markers {
+		if m.At.Before(c.From) || m.At.After(c.To) {
+			continue
+		}
+		col, letter := markerStyle(m.Kind)
+		x := c.x(plot, m.At)
+		for y := plot.Min.Y; y < plot.Max.Y; y += 8 {
+			drawLine(img, x, y, x, min(y+4, plot.Max.Y), col)
+		}
+		drawText(img, x+3, plot.Min.Y+3, letter, textScale, col)
+	}
+}
+
+// drawSeries draws every series and reports whether any point was drawn.
+// Lines break across gaps longer than 1/24 of the range so missing data
+// does not look like a steady value.
+func (c *LineChart) drawSeries(img *image.RGBA, plot image.Rectangle) bool {
+	maxGap := c.To.Sub(c.From) / 24
+	drawn := false
+	for i, s := range c.Series {
+		col := seriesColors[i%len(seriesColors)]
+		points := make([]Point, 0, len(s.Points))
+		for _, p := range s.Points {
+			if !p.At.Before(c.From) && !p.At.After(c.To) {
+				points = append(points, p)
+			}
+		}
+		sort.SliceStable(points, func(a, b int) bool { return points[a].At.Before(points[b].At) })
+
+		for j, p := range points {
+			drawn = true
+			x, y := c.x(plot, p.At), c.y(plot, p.Value)
+			joinPrev := j > 0 && p.At.Sub(points[j-1].At) <= maxGap
+			joinNext := j+1 < len(points) && points[j+1].At.Sub(p.At) <= maxGap
+			if joinPrev {
+				px, py := c.x(plot, points[j-1].At), c.y(plot, points[j-1].Value)
+				drawThickLine(img, px, py, x, y, col)
+			} else if !joinNext {
+				fillRect(img, x-2, y-2, 5, 5, col)
+			}
+		}
+	}
+	return drawn
+}
+
+// legendItems lists the series and the marker kinds in use
+func (c *LineChart) legendItems() []legendItem {
+	items := make([]legendItem, 0, len(c.Series)+2)
+	for i, s := range c.Series {
+		items = append(items, legendItem{label: truncate(s.Name, maxLegendChars), color: seriesColors[i%len(seriesColors)]})
+	}
+	kinds := map[MarkerKind]bool{}
+	for _, m := range c.Markers {
+		if !m.At.Before(c.From) && !m.At.After(c.To) {
+			kinds[m.Kind] = true
+		}
+	}
+	for _, kind := range []MarkerKind{MarkerReset, MarkerSwitch} {
+		if kinds[kind] {
+			col, letter := markerStyle(kind)
+			items = append(items, legendItem{label: letter + " " + string(kind), color: col, dashed: true})
+		}
+	}
+	return items
+}
+
+// x maps a time to a plot column
+func (c *LineChart) x(plot image.Rectangle, at time.Time) int {
+	span := c.To.Sub(c.From)
+	if span <= 0 {
+		return plot.Min.X
+	}
+	frac := float64(at.Sub(c.From)) / float64(span)
+	return plot.Min.X + int(math.Round(frac*float64(plot.Dx())))
+}
+
+// y maps a percentage to a plot row, clamped to [0, 100]
+func (c *LineChart) y(plot image.Rectangle, value float64) int {
+	value = math.Max(0, math.Min(100, value))
+	return plot.Max.Y - int(math.Round(value/100*float64(plot.Dy())))
+}
+
+func markerStyle(kind MarkerKind) (color.RGBA, string) {
+	if kind == MarkerSwitch {
+		return colorSwitch, "S"
+	}
+	return colorReset, "R"
+}
+
+// legendItemWidth returns the width an item takes in the legend
+func legendItemWidth(item legendItem) int {
+	return legendSwatch + 6 + textWidth(item.label, textScale) + legendGap
+}
+
+// layoutLegend returns how many rows the legend needs between left and right
+func layoutLegend(items []legendItem, left, right int) int {
+	if len(items) == 0 {
+		return 0
+	}
+	rows, x := 1, left
+	for _, item := range items {
+		w := legendItemWidth(item)
+		if x > left && x+w > right {
+			rows++
+			x = left
+		}
+		x += w
+	}
+	return rows
+}
+
+// drawLegend draws the legend items in rows starting at top
+func drawLegend(img *image.RGBA, items []legendItem, left, right, top int) {
+	x, y := left, top
+	for _, item := range items {
+		w := legendItemWidth(item)
+		if x > left && x+w > right {
+			x = left
+			y += legendRow
+		}
+		mid := y + textHeight(textScale)/2
+		if item.dashed {
+			for dx := 0; dx < legendSwatch; dx += 6 {
+				fillRect(img, x+dx, mid-1, 3, 3, item.color)
+			}
+		} else {
+			fillRect(img, x, mid-1, legendSwatch, 3, item.color)
+		}
+		drawText(img, x+legendSwatch+6, y, item.label, textScale, colorText)
+		x += w
+	}
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-2]) + ".."
+}
+
+func fillRect(img *image.RGBA, x, y, w, h int, c color.RGBA) {
+	r := image.Rect(x, y, x+w, y+h).Intersect(img.Bounds())
+	for py := r.Min.Y; py < r.Max.Y; py++ {
+		for px := r.Min.X; px < r.Max.X; px++ {
+			img.SetRGBA(px, py, c)
+		}
+	}
+}
+
+func drawRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	drawLine(img, r.Min.X, r.Min.Y, r.Max.X, r.Min.Y, c)
+	drawLine(img, r.Min.X, r.Max.Y, r.Max.X, r.Max.Y, c)
+	drawLine(img, r.Min.X, r.Min.Y, r.Min.X, r.Max.Y, c)
+	drawLine(img, r.Max.X, r.Min.Y, r.Max.X, r.Max.Y, c)
+}
+
+// drawThickLine draws a two pixel wide line
+func drawThickLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	drawLine(img, x0, y0, x1, y1, c)
+	drawLine(img, x0, y0+1, x1, y1+1, c)
+	drawLine(img, x0+1, y0, x1+1, y1, c)
+}
+
+// drawLine draws a one pixel line with Bresenham's algorithm
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	bounds := img.Bounds()
+	err := dx + dy
+	for {
+		if image.Pt(x0, y0).In(bounds) {
+			img.SetRGBA(x0, y0, c)
+		}
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
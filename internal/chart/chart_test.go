@@ -0,0 +1,105 @@
+package chart
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineChart_PNG(t *testing.T) {
+	from := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	c := &LineChart{
+		Title: "acc-1 / 24h",
+		From:  from,
+		To:    to,
+		Series: []Series{
+			{Name: "RPD", Points: []Point{{At: from.Add(time.Hour), Value: 90}, {At: from.Add(2 * time.Hour), Value: 60}, {At: from.Add(3 * time.Hour), Value: 150}}},
+			{Name: "a-very-long-dimension-name-that-needs-truncating", Points: []Point{{At: from.Add(12 * time.Hour), Value: 40}}},
+		},
+		Markers: []Marker{
+			{At: from.Add(6 * time.Hour), Kind: MarkerReset},
+			{At: from.Add(8 * time.Hour), Kind: MarkerSwitch},
+			{At: from.Add(-time.Hour), Kind: MarkerSwitch},
+		},
+	}
+
+	data, err := c.PNG()
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, DefaultWidth, img.Bounds().Dx())
+	assert.Equal(t, DefaultHeight, img.Bounds().Dy())
+
+	rendered := c.Render()
+	items := c.legendItems()
+	plot := plotRect(DefaultWidth, DefaultHeight, layoutLegend(items, marginLeft, DefaultWidth-marginRight))
+	// The 150% point is clamped to the top edge of the plot
+	assert.Equal(t, seriesColors[0], rendered.RGBAAt(c.x(plot, from.Add(3*time.Hour)), plot.Min.Y))
+	// The reset marker is dashed from the top of the plot
+	assert.Equal(t, colorReset, rendered.RGBAAt(c.x(plot, from.Add(6*time.Hour)), plot.Min.Y+1))
+
+	require.Len(t, items, 4)
+	assert.Equal(t, "a-very-long-dimension-name-tha..", items[1].label)
+	assert.Equal(t, "R reset", items[2].label)
+	assert.Equal(t, "S switch", items[3].label)
+}
+
+func TestLineChart_EmptyRange(t *testing.T) {
+	now := time.Now()
+	_, err := (&LineChart{From: now, To: now}).PNG()
+	assert.Error(t, err)
+
+	// No series still renders a chart
+	data, err := (&LineChart{From: now.Add(-time.Hour), To: now}).PNG()
+	require.NoError(t, err)
+	assert.NotEmpty(t, data)
+}
+
+func TestHistorySeries(t *testing.T) {
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	resetAt := start.Add(7 * time.Minute)
+	samples := []*models.QuotaSample{
+		{AccountID: "acc-1", Dimension: "RPM", RemainingPct: 40, ResetAt: &resetAt, CollectedAt: start},
+		{AccountID: "acc-1", Dimension: "TPD", RemainingPct: 70, CollectedAt: start},
+		{AccountID: "acc-1", Dimension: "RPM", RemainingPct: 45, CollectedAt: start.Add(10 * time.Minute)},
+		{AccountID: "acc-1", Dimension: "TPD", RemainingPct: 60, CollectedAt: start.Add(10 * time.Minute)},
+		{AccountID: "acc-1", Dimension: "TPD", RemainingPct: 95, CollectedAt: start.Add(20 * time.Minute)},
+		{AccountID: "acc-1", Dimension: "TPD", RemainingPct: 99, CollectedAt: start.Add(30 * time.Minute)},
+	}
+
+	series, markers := HistorySeries(samples, func(s *models.QuotaSample) string { return s.Dimension })
+	require.Len(t, series, 2)
+	assert.Equal(t, "RPM", series[0].Name)
+	assert.Len(t, series[0].Points, 2)
+	assert.Equal(t, "TPD", series[1].Name)
+	assert.Len(t, series[1].Points, 4)
+
+	// RPM refilled after its reset time; TPD jumped without one
+	require.Len(t, markers, 2)
+	assert.Equal(t, resetAt, markers[0].At)
+	assert.Equal(t, "RPM", markers[0].Label)
+	assert.Equal(t, start.Add(20*time.Minute), markers[1].At)
+	assert.Equal(t, MarkerReset, markers[1].Kind)
+}
+
+func TestCriticalSamples(t *testing.T) {
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	samples := []*models.QuotaSample{
+		{AccountID: "acc-1", Dimension: "RPM", RemainingPct: 40, CollectedAt: at},
+		{AccountID: "acc-1", Dimension: "TPD", RemainingPct: 30, CollectedAt: at},
+		{AccountID: "acc-2", Dimension: "RPM", RemainingPct: 80, CollectedAt: at},
+		{AccountID: "acc-1", Dimension: "RPM", RemainingPct: 20, CollectedAt: at.Add(time.Minute)},
+	}
+
+	critical := CriticalSamples(samples)
+	require.Len(t, critical, 3)
+	assert.Equal(t, "TPD", critical[0].Dimension)
+	assert.Equal(t, "acc-2", critical[1].AccountID)
+	assert.InDelta(t, 20, critical[2].RemainingPct, 1e-9)
+}
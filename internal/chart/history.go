@@ -0,0 +1,68 @@
+package chart
+
+import (
+	"sort"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// HistorySeries groups samples into one series per key, in the order keys
+// first appear, and returns a reset marker wherever a series refills.
+func HistorySeries(samples []*models.QuotaSample, key func(*models.QuotaSample) string) ([]Series, []Marker) {
+	index := make(map[string]int)
+	grouped := make([][]*models.QuotaSample, 0)
+	names := make([]string, 0)
+	for _, sample := range samples {
+		k := key(sample)
+		i, ok := index[k]
+		if !ok {
+			i = len(grouped)
+			index[k] = i
+			grouped = append(grouped, nil)
+			names = append(names, k)
+		}
+		grouped[i] = append(grouped[i], sample)
+	}
+
+	series := make([]Series, 0, len(grouped))
+	markers := make([]Marker, 0)
+	for i, group := range grouped {
+		sort.SliceStable(group, func(a, b int) bool { return group[a].CollectedAt.Before(group[b].CollectedAt) })
+		s := Series{Name: names[i], Points: make([]Point, 0, len(group))}
+		for j, sample := range group {
+			s.Points = append(s.Points, Point{At: sample.CollectedAt, Value: sample.RemainingPct})
+			if j == 0 {
+				continue
+			}
+			if at, ok := models.ResetBetween(group[j-1], sample); ok {
+				markers = append(markers, Marker{At: at, Kind: MarkerReset, Label: names[i]})
+			}
+		}
+		series = append(series, s)
+	}
+	return series, markers
+}
+
+// CriticalSamples keeps, for every account and collection time, only the
+// dimension with the least remaining quota. It turns per-dimension history
+// into one line per account.
+func CriticalSamples(samples []*models.QuotaSample) []*models.QuotaSample {
+	type slot struct {
+		accountID string
+		at        int64
+	}
+	index := make(map[slot]int)
+	result := make([]*models.QuotaSample, 0, len(samples))
+	for _, sample := range samples {
+		k := slot{sample.AccountID, sample.CollectedAt.UnixNano()}
+		if i, ok := index[k]; ok {
+			if sample.RemainingPct < result[i].RemainingPct {
+				result[i] = sample
+			}
+			continue
+		}
+		index[k] = len(result)
+		result = append(result, sample)
+	}
+	return result
+}
@@ -284,6 +284,29 @@ func TestSQLiteCleanerCleanupQuotaHistory(t *testing.T) {
 	assert.Equal(t, 1, count)
 }
 
+// TestSQLiteCleanerCleanupQuotaHistoryUTC keeps records the store wrote in
+// UTC while the local zone is ahead of it.
+func TestSQLiteCleanerCleanupQuotaHistoryUTC(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+5", 5*60*60)
+	defer func() { time.Local = local }()
+
+	db := createTestDB(t)
+	defer db.Close()
+
+	// Two hours inside the retention period
+	recentTime := time.Now().Add(-7*24*time.Hour + 2*time.Hour).UTC()
+	_, err := db.Exec(`
+		INSERT INTO quota_history (account_id, collected_at, quota_value)
+		VALUES (?, ?, ?)
+	`, "account1", recentTime, 100.0)
+	require.NoError(t, err)
+
+	result, err := NewSQLiteCleaner(db).CleanupQuotaHistory(7 * 24 * time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), result.DeletedCount)
+}
+
 // TestSQLiteCleanerCleanupRoutingEvents tests cleaning up routing events.
 func TestSQLiteCleanerCleanupRoutingEvents(t *testing.T) {
 	db := createTestDB(t)
@@ -34,7 +34,7 @@ var DefaultPolicies = []RetentionPolicy{
 	},
 	{
 		TableName:       "routing_events",
-		RetentionPeriod: 7 * 24 * time.Hour, // 7 days
+		RetentionPeriod: 14 * 24 * time.Hour, // 14 days
 		Enabled:         true,
 		UseSoftDelete:   false,
 	},
@@ -30,10 +30,11 @@ func (c *SQLiteCleaner) CleanupQuotaHistory(retentionPeriod time.Duration) (*Cle
 
 	cutoff := time.Now().Add(-retentionPeriod)
 
+	// quota_history stores UTC times like routing_events
 	result, err := c.db.Exec(`
 		DELETE FROM quota_history
 		WHERE collected_at < ?
-	`, cutoff)
+	`, cutoff.UTC())
 
 	if err != nil {
 		return &CleanupResult{
@@ -58,10 +59,12 @@ func (c *SQLiteCleaner) CleanupRoutingEvents(retentionPeriod time.Duration) (*Cl
 
 	cutoff := time.Now().Add(-retentionPeriod)
 
+	// routing_events stores UTC times, which only compare in order with a
+	// UTC cutoff
 	result, err := c.db.Exec(`
 		DELETE FROM routing_events
 		WHERE created_at < ?
-	`, cutoff)
+	`, cutoff.UTC())
 
 	if err != nil {
 		return &CleanupResult{
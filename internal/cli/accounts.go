@@ -23,6 +23,7 @@ func seedAccountsFromConfig(s store.Store, cfg *config.Config) error {
 			ConcurrencyLimit: acc.ConcurrencyLimit,
 			InputCost:        acc.InputCost,
 			OutputCost:       acc.OutputCost,
+			MonthlyBudget:    acc.MonthlyBudget,
 			CredentialsRef:   acc.CredentialsRef,
 		}
 		if err := account.Validate(); err != nil {
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/chart"
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/store"
+	"github.com/quotaguard/quotaguard/internal/telegram"
+)
+
+// maxChartAccounts bounds the lines of a provider or all-accounts chart;
+// the accounts that ran lowest in the period are kept
+const maxChartAccounts = 8
+
+// renderQuotaChart charts remaining quota over period up to now. An account
+// target is drawn per dimension; a provider or an empty target draws every
+// matching account at its lowest dimension. Resets and the routing switches
+// persisted for the charted accounts are marked.
+func renderQuotaChart(s store.Store, target string, period time.Duration, now time.Time) (*telegram.ChartImage, error) {
+	from := now.Add(-period)
+	target = strings.TrimSpace(target)
+
+	var accounts []*models.Account
+	single := false
+	if acc, ok := s.GetAccount(target); ok && target != "" {
+		accounts = []*models.Account{acc}
+		single = true
+	} else {
+		for _, acc := range s.ListAccounts() {
+			if target == "" || strings.EqualFold(string(acc.Provider), target) || strings.EqualFold(acc.ProviderType, target) {
+				accounts = append(accounts, acc)
+			}
+		}
+		if len(accounts) == 0 {
+			return nil, fmt.Errorf("unknown account or provider %q", target)
+		}
+	}
+
+	history := make(map[string][]*models.QuotaSample, len(accounts))
+	charted := make([]string, 0, len(accounts))
+	lowest := make(map[string]float64, len(accounts))
+	for _, acc := range accounts {
+		samples, err := s.ListQuotaHistory(acc.ID, from, now)
+		if err != nil {
+			return nil, err
+		}
+		if !single {
+			samples = chart.CriticalSamples(samples)
+		}
+		if len(samples) == 0 && !single {
+			continue
+		}
+		history[acc.ID] = samples
+		charted = append(charted, acc.ID)
+		lowest[acc.ID] = 100
+		for _, sample := range samples {
+			lowest[acc.ID] = min(lowest[acc.ID], sample.RemainingPct)
+		}
+	}
+	total := len(charted)
+	sort.SliceStable(charted, func(i, j int) bool {
+		if lowest[charted[i]] != lowest[charted[j]] {
+			return lowest[charted[i]] < lowest[charted[j]]
+		}
+		return charted[i] < charted[j]
+	})
+	if len(charted) > maxChartAccounts {
+		charted = charted[:maxChartAccounts]
+	}
+	sort.Strings(charted)
+
+	samples := make([]*models.QuotaSample, 0)
+	for _, id := range charted {
+		samples = append(samples, history[id]...)
+	}
+	key := func(sample *models.QuotaSample) string { return sample.AccountID }
+	if single {
+		key = func(sample *models.QuotaSample) string { return sample.Dimension }
+	}
+	series, markers := chart.HistorySeries(samples, key)
+
+	events, err := s.ListRoutingEvents(models.RoutingEventFilter{Since: from, Until: now.Add(time.Nanosecond)})
+	if err != nil {
+		return nil, err
+	}
+	inChart := make(map[string]bool, len(charted))
+	for _, id := range charted {
+		inChart[id] = true
+	}
+	switches := 0
+	for _, ev := range events {
+		if ev.SwitchedFrom == "" || (!inChart[ev.SwitchedFrom] && !inChart[ev.AccountID]) {
+			continue
+		}
+		markers = append(markers, chart.Marker{At: ev.CreatedAt, Kind: chart.MarkerSwitch, Label: ev.SwitchedFrom + " -> " + ev.AccountID})
+		switches++
+	}
+
+	name := target
+	if name == "" {
+		name = "all accounts"
+	}
+	periodLabel := formatChartPeriod(period)
+	c := &chart.LineChart{
+		Title:   fmt.Sprintf("%s - remaining %% - last %s", name, periodLabel),
+		From:    from,
+		To:      now,
+		Series:  series,
+		Markers: markers,
+	}
+	data, err := c.PNG()
+	if err != nil {
+		return nil, err
+	}
+
+	resets := len(markers) - switches
+	caption := fmt.Sprintf("📉 %s · %s\nСбросов: %d · Переключений: %d", name, periodLabel, resets, switches)
+	if total > len(charted) {
+		caption += fmt.Sprintf("\nПоказаны %d из %d аккаунтов с наименьшим остатком", len(charted), total)
+	}
+	if len(samples) == 0 {
+		caption += "\nИстории за этот период пока нет"
+	}
+	return &telegram.ChartImage{PNG: data, Caption: caption}, nil
+}
+
+// formatChartPeriod prints periods over two days as days ("7d") and
+// shorter ones as hours ("24h")
+func formatChartPeriod(period time.Duration) string {
+	if period > 48*time.Hour {
+		return fmt.Sprintf("%dd", int(period.Round(24*time.Hour)/(24*time.Hour)))
+	}
+	return fmt.Sprintf("%dh", int(period.Round(time.Hour)/time.Hour))
+}
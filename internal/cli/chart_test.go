@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderQuotaChart(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.SetAccount(&models.Account{ID: "openai-1", Provider: models.ProviderOpenAI, Enabled: true})
+	s.SetAccount(&models.Account{ID: "openai-2", Provider: models.ProviderOpenAI, Enabled: true})
+	s.SetAccount(&models.Account{ID: "gemini-1", Provider: models.ProviderGemini, Enabled: true})
+
+	now := time.Now()
+	for i, remaining := range []int64{80, 50, 20, 95} {
+		at := now.Add(time.Duration(i-4) * time.Hour)
+		for _, id := range []string{"openai-1", "openai-2"} {
+			s.SetQuota(id, &models.QuotaInfo{
+				AccountID:   id,
+				Provider:    models.ProviderOpenAI,
+				CollectedAt: at,
+				Dimensions: models.DimensionSlice{
+					{Type: models.DimensionRPD, Limit: 100, Remaining: remaining},
+					{Type: models.DimensionTPD, Limit: 100, Remaining: 90},
+				},
+			})
+		}
+	}
+	// Only decisions that moved a scope are switches
+	for _, ev := range []*models.RoutingEvent{
+		{AccountID: "openai-1", CreatedAt: now.Add(-2 * time.Hour), SwitchedFrom: "openai-2"},
+		{AccountID: "openai-1", CreatedAt: now.Add(-time.Hour)},
+		{AccountID: "openai-2", CreatedAt: now.Add(-48 * time.Hour), SwitchedFrom: "openai-1"},
+	} {
+		require.NoError(t, s.RecordRoutingEvent(ev))
+	}
+
+	img, err := renderQuotaChart(s, "openai-1", 24*time.Hour, now)
+	require.NoError(t, err)
+	_, err = png.Decode(bytes.NewReader(img.PNG))
+	require.NoError(t, err)
+	assert.Contains(t, img.Caption, "openai-1 · 24h")
+	assert.Contains(t, img.Caption, "Сбросов: 1 · Переключений: 1")
+
+	img, err = renderQuotaChart(s, "openai", 7*24*time.Hour, now)
+	require.NoError(t, err)
+	assert.Contains(t, img.Caption, "openai · 7d")
+	assert.Contains(t, img.Caption, "Переключений: 2")
+
+	// A provider without history still renders an empty chart
+	img, err = renderQuotaChart(s, "gemini", 24*time.Hour, now)
+	require.NoError(t, err)
+	assert.Contains(t, img.Caption, "Истории за этот период пока нет")
+
+	_, err = renderQuotaChart(s, "nope", 24*time.Hour, now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,375 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// clientsCmd represents the clients command
+var clientsCmd = &cobra.Command{
+	Use:     "clients",
+	Aliases: []string{"client"},
+	Short:   "Manage named API clients",
+	Long: `Manage named API clients stored in SQLite.
+
+Each client has its own API key, allowed providers and accounts, default
+routing policy, request rate limit and daily share of the account pool.
+
+Examples:
+  # Create a client limited to openai with 60 requests per minute
+  quotaguard clients add batch-job --providers openai --rpm 60 --daily-share 20
+
+  # Show today's usage
+  quotaguard clients usage batch-job`,
+}
+
+var clientsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List API clients",
+	Args:  cobra.NoArgs,
+	RunE:  runClientsList,
+}
+
+var clientsAddCmd = &cobra.Command{
+	Use:   "add <id>",
+	Short: "Create an API client and print its key",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runClientsAdd,
+}
+
+var clientsUpdateCmd = &cobra.Command{
+	Use:   "update <id>",
+	Short: "Update an API client's limits and restrictions",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runClientsUpdate,
+}
+
+var clientsRemoveCmd = &cobra.Command{
+	Use:     "remove <id>",
+	Aliases: []string{"rm", "delete"},
+	Short:   "Remove an API client",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runClientsRemove,
+}
+
+var clientsRotateCmd = &cobra.Command{
+	Use:   "rotate <id>",
+	Short: "Issue a new key for an API client",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runClientsRotate,
+}
+
+var clientsUsageCmd = &cobra.Command{
+	Use:   "usage <id>",
+	Short: "Show an API client's daily usage",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runClientsUsage,
+}
+
+var clientsFlags struct {
+	Name       string
+	Providers  []string
+	Accounts   []string
+	Policy     string
+	RPM        int
+	DailyShare float64
+	Enabled    bool
+	Day        string
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{clientsAddCmd, clientsUpdateCmd} {
+		cmd.Flags().StringVar(&clientsFlags.Name, "name", "", "Display name")
+		cmd.Flags().StringSliceVar(&clientsFlags.Providers, "providers", nil, "Allowed providers (empty = all)")
+		cmd.Flags().StringSliceVar(&clientsFlags.Accounts, "accounts", nil, "Allowed account IDs (empty = all)")
+		cmd.Flags().StringVar(&clientsFlags.Policy, "policy", "", "Default routing policy")
+		cmd.Flags().IntVar(&clientsFlags.RPM, "rpm", 0, "Request rate limit per minute (0 = unlimited)")
+		cmd.Flags().Float64Var(&clientsFlags.DailyShare, "daily-share", 0, "Daily share of total quota in percent (0 = unlimited)")
+		cmd.Flags().BoolVar(&clientsFlags.Enabled, "enabled", true, "Whether the client may authenticate")
+	}
+	clientsUsageCmd.Flags().StringVar(&clientsFlags.Day, "day", "", "Day in YYYY-MM-DD format (default: today, UTC)")
+
+	clientsCmd.AddCommand(clientsListCmd, clientsAddCmd, clientsUpdateCmd, clientsRemoveCmd, clientsRotateCmd, clientsUsageCmd)
+	RootCmd.AddCommand(clientsCmd)
+}
+
+func openClientStore() (*store.SQLiteStore, error) {
+	sqliteStore, err := store.NewSQLiteStore(globalFlags.DBPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SQLite store: %w", err)
+	}
+	return sqliteStore, nil
+}
+
+func runClientsList(cmd *cobra.Command, args []string) error {
+	s, err := openClientStore()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	clients := s.ListAPIClients()
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].ID < clients[j].ID
+	})
+
+	if globalFlags.JSON {
+		return writeClientsJSON(clients)
+	}
+	if len(clients) == 0 {
+		fmt.Println("No API clients configured.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tKEY\tPROVIDERS\tACCOUNTS\tPOLICY\tRPM\tDAILY SHARE\tENABLED")
+	for _, c := range clients {
+		providers := make([]string, 0, len(c.AllowedProviders))
+		for _, p := range c.AllowedProviders {
+			providers = append(providers, string(p))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s...\t%s\t%s\t%s\t%s\t%s\t%t\n",
+			c.ID,
+			c.Name,
+			c.KeyPrefix,
+			listOrAll(providers),
+			listOrAll(c.AllowedAccounts),
+			valueOr(c.DefaultPolicy, "-"),
+			limitOrUnlimited(float64(c.RateLimitRPM), "%.0f"),
+			limitOrUnlimited(c.DailyQuotaSharePct, "%.1f%%"),
+			c.Enabled,
+		)
+	}
+	return w.Flush()
+}
+
+func runClientsAdd(cmd *cobra.Command, args []string) error {
+	s, err := openClientStore()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	id := strings.TrimSpace(args[0])
+	if _, exists := s.GetAPIClient(id); exists {
+		return fmt.Errorf("client %s already exists", id)
+	}
+
+	key, prefix, err := models.GenerateAPIKey()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	client := &models.APIClient{
+		ID:        id,
+		Name:      id,
+		KeyHash:   models.HashAPIKey(key),
+		KeyPrefix: prefix,
+		Enabled:   true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	applyClientFlags(cmd, client)
+
+	if err := s.SetAPIClient(client); err != nil {
+		return fmt.Errorf("failed to save client: %w", err)
+	}
+
+	return printClientKey(client, key)
+}
+
+func runClientsUpdate(cmd *cobra.Command, args []string) error {
+	s, err := openClientStore()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	client, ok := s.GetAPIClient(args[0])
+	if !ok {
+		return fmt.Errorf("client %s not found", args[0])
+	}
+	applyClientFlags(cmd, client)
+	client.UpdatedAt = time.Now()
+
+	if err := s.SetAPIClient(client); err != nil {
+		return fmt.Errorf("failed to save client: %w", err)
+	}
+
+	fmt.Printf("Client %s updated\n", client.ID)
+	return nil
+}
+
+func runClientsRemove(cmd *cobra.Command, args []string) error {
+	s, err := openClientStore()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	if !s.DeleteAPIClient(args[0]) {
+		return fmt.Errorf("client %s not found", args[0])
+	}
+
+	fmt.Printf("Client %s removed\n", args[0])
+	return nil
+}
+
+func runClientsRotate(cmd *cobra.Command, args []string) error {
+	s, err := openClientStore()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	client, ok := s.GetAPIClient(args[0])
+	if !ok {
+		return fmt.Errorf("client %s not found", args[0])
+	}
+
+	key, prefix, err := models.GenerateAPIKey()
+	if err != nil {
+		return err
+	}
+	client.KeyHash = models.HashAPIKey(key)
+	client.KeyPrefix = prefix
+	client.UpdatedAt = time.Now()
+
+	if err := s.SetAPIClient(client); err != nil {
+		return fmt.Errorf("failed to save client: %w", err)
+	}
+
+	return printClientKey(client, key)
+}
+
+func runClientsUsage(cmd *cobra.Command, args []string) error {
+	s, err := openClientStore()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	client, ok := s.GetAPIClient(args[0])
+	if !ok {
+		return fmt.Errorf("client %s not found", args[0])
+	}
+
+	day := clientsFlags.Day
+	if day == "" {
+		day = models.UsageDay(time.Now())
+	}
+
+	usage, ok := s.GetClientUsage(client.ID, day)
+	if !ok {
+		usage = &models.ClientUsage{ClientID: client.ID, Day: day}
+	}
+	budget := client.DailyQuotaBudget(s.ListEnabledAccounts())
+
+	if globalFlags.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{
+			"usage":          usage,
+			"budget_percent": budget,
+		})
+	}
+
+	fmt.Printf("Client:   %s\n", client.ID)
+	fmt.Printf("Day:      %s\n", day)
+	fmt.Printf("Requests: %d\n", usage.Requests)
+	if budget > 0 {
+		fmt.Printf("Consumed: %.1f%% of %.1f%% daily budget\n", usage.ConsumedPct, budget)
+	} else {
+		fmt.Printf("Consumed: %.1f%% (no daily budget)\n", usage.ConsumedPct)
+	}
+	return nil
+}
+
+// applyClientFlags copies the flags set on the command onto the client
+func applyClientFlags(cmd *cobra.Command, client *models.APIClient) {
+	flags := cmd.Flags()
+	if flags.Changed("name") {
+		client.Name = clientsFlags.Name
+	}
+	if flags.Changed("providers") {
+		client.AllowedProviders = nil
+		for _, p := range clientsFlags.Providers {
+			if p = strings.TrimSpace(p); p != "" {
+				client.AllowedProviders = append(client.AllowedProviders, models.Provider(p))
+			}
+		}
+	}
+	if flags.Changed("accounts") {
+		client.AllowedAccounts = nil
+		for _, a := range clientsFlags.Accounts {
+			if a = strings.TrimSpace(a); a != "" {
+				client.AllowedAccounts = append(client.AllowedAccounts, a)
+			}
+		}
+	}
+	if flags.Changed("policy") {
+		client.DefaultPolicy = clientsFlags.Policy
+	}
+	if flags.Changed("rpm") {
+		client.RateLimitRPM = clientsFlags.RPM
+	}
+	if flags.Changed("daily-share") {
+		client.DailyQuotaSharePct = clientsFlags.DailyShare
+	}
+	if flags.Changed("enabled") {
+		client.Enabled = clientsFlags.Enabled
+	}
+}
+
+func printClientKey(client *models.APIClient, key string) error {
+	if globalFlags.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{
+			"client":  client,
+			"api_key": key,
+		})
+	}
+
+	fmt.Printf("Client:  %s\n", client.ID)
+	fmt.Printf("API key: %s\n", key)
+	fmt.Println("Store this key now; it cannot be shown again.")
+	return nil
+}
+
+func writeClientsJSON(clients []*models.APIClient) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(clients)
+}
+
+func listOrAll(values []string) string {
+	if len(values) == 0 {
+		return "all"
+	}
+	return strings.Join(values, ",")
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func limitOrUnlimited(value float64, format string) string {
+	if value <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf(format, value)
+}
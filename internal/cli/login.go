@@ -0,0 +1,314 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/cliproxy"
+	"github.com/quotaguard/quotaguard/internal/collector"
+	"github.com/quotaguard/quotaguard/internal/config"
+	"github.com/quotaguard/quotaguard/internal/store"
+	"github.com/quotaguard/quotaguard/internal/telegram"
+	"github.com/spf13/cobra"
+)
+
+// loginCmd logs in provider accounts without the Telegram bot
+var loginCmd = &cobra.Command{
+	Use:   "login <codex|antigravity|gemini|claude|qwen>",
+	Short: "Log in a provider account from the terminal",
+	Long: `Runs the same login flows as the Telegram bot: a device code for codex,
+the CLIProxy login for claude and qwen, and Google OAuth for antigravity and
+gemini. The CLIProxy auth file is written, the account is registered and its
+quota is fetched right away.
+
+For Google OAuth the callback is received on the loopback redirect URI
+(QUOTAGUARD_OAUTH_REDIRECT_URI, default http://localhost:1456/oauth-callback).
+On a headless server either forward that port over SSH or paste the URL the
+browser was redirected to.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLogin,
+}
+
+var loginFlags struct {
+	AuthPath string
+	Timeout  time.Duration
+	NoFetch  bool
+}
+
+func init() {
+	loginCmd.Flags().StringVar(&loginFlags.AuthPath, "auth-path", "", "CLIProxy auths directory (default: resolved like setup)")
+	loginCmd.Flags().DurationVar(&loginFlags.Timeout, "timeout", 15*time.Minute, "How long to wait for the login to finish")
+	loginCmd.Flags().BoolVar(&loginFlags.NoFetch, "no-fetch", false, "Skip the quota fetch after login")
+
+	RootCmd.AddCommand(loginCmd)
+}
+
+func runLogin(cmd *cobra.Command, args []string) error {
+	provider := normalizeLoginProvider(args[0])
+	switch provider {
+	case "codex", "antigravity", "gemini", "claude", "qwen":
+	default:
+		return fmt.Errorf("unsupported provider %q (use codex, antigravity, gemini, claude or qwen)", args[0])
+	}
+
+	sqliteStore, err := store.NewSQLiteStore(globalFlags.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to create SQLite store: %w", err)
+	}
+	defer sqliteStore.Close()
+
+	var manager *cliproxy.AccountManager
+	if authPath := cliproxy.ResolveAuthPath(loginFlags.AuthPath); authPath != "" {
+		manager = newAccountManager(sqliteStore, authPath)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, loginFlags.Timeout)
+	defer cancel()
+
+	out := cmd.OutOrStdout()
+	result, err := loginProvider(ctx, sqliteStore, manager, provider, cmd.InOrStdin(), out)
+	if err != nil {
+		return fmt.Errorf("%s login failed: %w", provider, err)
+	}
+	fmt.Fprintf(out, "Logged in %s account %s (%s)\n", result.Provider, result.AccountID, result.Email)
+
+	if loginFlags.NoFetch {
+		return nil
+	}
+	if err := fetchLoginQuota(ctx, sqliteStore, result.AccountID, out); err != nil {
+		fmt.Fprintf(out, "Warning: quota fetch failed: %v\n", err)
+	}
+	return nil
+}
+
+// loginProvider runs the login flow for provider and registers the account
+func loginProvider(ctx context.Context, s store.Store, manager *cliproxy.AccountManager, provider string, in io.Reader, out io.Writer) (*telegram.LoginResult, error) {
+	state, err := newOAuthState()
+	if err != nil {
+		return nil, err
+	}
+
+	switch provider {
+	case "codex":
+		session, authURL, code, err := startCodexDeviceAuth(state)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(out, "Open %s\nand enter the one-time code: %s\n", authURL, code)
+		if err := waitDeviceAuth(ctx, session); err != nil {
+			return nil, err
+		}
+		return completeCodexDeviceAuthLogin(s, manager, session, "")
+	case "claude", "qwen":
+		session, err := startTerminalCLIProxyLogin(state, provider, out)
+		if err != nil {
+			return nil, err
+		}
+		if err := waitDeviceAuth(ctx, session); err != nil {
+			return nil, err
+		}
+		return completeCLIProxyProviderLogin(s, manager, session, provider)
+	default:
+		// antigravity and gemini prefer CLIProxy and fall back to native OAuth
+		session, err := startTerminalCLIProxyLogin(state, provider, out)
+		if err == nil {
+			if err := waitDeviceAuth(ctx, session); err != nil {
+				return nil, err
+			}
+			return completeCLIProxyProviderLogin(s, manager, session, provider)
+		}
+		fmt.Fprintf(out, "CLIProxy login unavailable (%v), using Google OAuth\n", err)
+
+		spec, err := oauthProviderSpec(provider)
+		if err != nil {
+			return nil, err
+		}
+		code, err := waitGoogleOAuthCode(ctx, spec, state, in, out)
+		if err != nil {
+			return nil, err
+		}
+		return completeGoogleOAuthLogin(ctx, s, manager, spec, code, "cli_oauth")
+	}
+}
+
+// startTerminalCLIProxyLogin starts the CLIProxy login and prints how to
+// finish it. The serve relay is bypassed since this process owns the session.
+func startTerminalCLIProxyLogin(state, provider string, out io.Writer) (*deviceAuthSession, error) {
+	session, authURL, _, err := startCLIProxyProviderLogin(state, provider, false)
+	if err != nil {
+		return nil, err
+	}
+	if session.TunnelCommand != "" {
+		fmt.Fprintf(out, "If the browser runs on another machine, open an SSH tunnel first:\n  %s\n", session.TunnelCommand)
+	}
+	fmt.Fprintf(out, "Open %s\nWaiting for the login to finish...\n", authURL)
+	return session, nil
+}
+
+// waitDeviceAuth blocks until the login process exits or ctx is done
+func waitDeviceAuth(ctx context.Context, session *deviceAuthSession) error {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if completed, _ := session.result(); completed {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			if session.Cancel != nil {
+				session.Cancel()
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+type oauthCallbackResult struct {
+	code string
+	err  error
+}
+
+// waitGoogleOAuthCode prints the consent URL and returns the authorization
+// code from whichever arrives first: the loopback callback or a URL pasted
+// into in.
+func waitGoogleOAuthCode(ctx context.Context, spec providerOAuthSpec, state string, in io.Reader, out io.Writer) (string, error) {
+	results := make(chan oauthCallbackResult, 2)
+
+	redirectURI := oauthRedirectURI()
+	if redirect, err := url.Parse(redirectURI); err == nil && redirect.Host != "" {
+		listener, err := net.Listen("tcp", redirect.Host)
+		if err != nil {
+			fmt.Fprintf(out, "Cannot listen on %s (%v), paste the redirected URL instead\n", redirect.Host, err)
+		} else {
+			path := redirect.Path
+			if path == "" {
+				path = "/"
+			}
+			mux := http.NewServeMux()
+			mux.Handle(path, oauthCallbackHandler(state, results))
+			server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+			go func() { _ = server.Serve(listener) }()
+			defer server.Close()
+		}
+	}
+
+	fmt.Fprintf(out, "Open %s\n", buildGoogleOAuthURL(spec, state))
+	fmt.Fprintf(out, "Waiting for the callback on %s, or paste the URL the browser was redirected to:\n", redirectURI)
+
+	go func() {
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			code, err := parseOAuthCallbackInput(line, state)
+			if err != nil {
+				fmt.Fprintf(out, "%v, try again:\n", err)
+				continue
+			}
+			results <- oauthCallbackResult{code: code}
+			return
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case result := <-results:
+		return result.code, result.err
+	}
+}
+
+// oauthCallbackHandler receives the OAuth redirect and reports its code
+func oauthCallbackHandler(state string, results chan<- oauthCallbackResult) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if msg := r.URL.Query().Get("error"); msg != "" {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, renderOAuthRelayPage("Login failed", msg))
+			select {
+			case results <- oauthCallbackResult{err: fmt.Errorf("authorization denied: %s", msg)}:
+			default:
+			}
+			return
+		}
+
+		code, err := parseOAuthCallbackInput(r.URL.String(), state)
+		if err != nil {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, renderOAuthRelayPage("Callback error", err.Error()))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = io.WriteString(w, renderOAuthRelayPage("Login complete", "You can close this tab and return to the terminal."))
+		select {
+		case results <- oauthCallbackResult{code: code}:
+		default:
+		}
+	})
+}
+
+// parseOAuthCallbackInput extracts the authorization code from a callback
+// URL, checking its state, or accepts a bare code.
+func parseOAuthCallbackInput(input, expectedState string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", errors.New("empty input")
+	}
+	if !strings.Contains(input, "code=") {
+		if strings.Contains(input, "://") || strings.ContainsAny(input, "?&= ") {
+			return "", errors.New("no code in callback URL")
+		}
+		return input, nil
+	}
+
+	parsed, err := url.Parse(input)
+	if err != nil {
+		return "", fmt.Errorf("invalid callback URL: %w", err)
+	}
+	query := parsed.Query()
+	code := query.Get("code")
+	if code == "" {
+		return "", errors.New("no code in callback URL")
+	}
+	if state := query.Get("state"); expectedState != "" && state != expectedState {
+		return "", errors.New("state mismatch, restart login")
+	}
+	return code, nil
+}
+
+// fetchLoginQuota polls the new account's quota once and stores it
+func fetchLoginQuota(ctx context.Context, s store.Store, accountID string, out io.Writer) error {
+	fetcher := collector.NewProviderFetcher(s)
+	if cfg, err := config.NewLoader(globalFlags.Config).Load(); err == nil {
+		if err := fetcher.RegisterFetchers(cfg.Collector.Fetchers); err != nil {
+			return fmt.Errorf("failed to register fetchers: %w", err)
+		}
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	quota, err := fetcher.FetchQuota(fetchCtx, accountID)
+	if err != nil {
+		return err
+	}
+	s.SetQuota(accountID, quota)
+	fmt.Fprintf(out, "Quota: %.1f%% remaining\n", quota.EffectiveRemainingPct)
+	return nil
+}
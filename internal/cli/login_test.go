@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOAuthCallbackInput(t *testing.T) {
+	code, err := parseOAuthCallbackInput("http://localhost:1456/oauth-callback?state=abc&code=4%2F0Ab", "abc")
+	require.NoError(t, err)
+	require.Equal(t, "4/0Ab", code)
+
+	code, err = parseOAuthCallbackInput("  4/0Ab-bare  ", "abc")
+	require.NoError(t, err)
+	require.Equal(t, "4/0Ab-bare", code)
+
+	_, err = parseOAuthCallbackInput("http://localhost:1456/oauth-callback?state=other&code=x", "abc")
+	require.ErrorContains(t, err, "state mismatch")
+
+	_, err = parseOAuthCallbackInput("http://localhost:1456/oauth-callback?state=abc", "abc")
+	require.ErrorContains(t, err, "no code")
+}
+
+func TestOAuthCallbackHandler(t *testing.T) {
+	results := make(chan oauthCallbackResult, 2)
+	handler := oauthCallbackHandler("abc", results)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/oauth-callback?state=wrong&code=x", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Empty(t, results)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/oauth-callback?state=abc&code=good", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, oauthCallbackResult{code: "good"}, <-results)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/oauth-callback?error=access_denied", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	result := <-results
+	require.ErrorContains(t, result.err, "access_denied")
+}
+
+func TestWaitGoogleOAuthCode_PastedURL(t *testing.T) {
+	// Port 0 keeps the loopback listener off any real port
+	t.Setenv("QUOTAGUARD_OAUTH_REDIRECT_URI", "http://127.0.0.1:0/oauth-callback")
+
+	spec := providerOAuthSpec{ProviderType: "gemini", ClientID: "client-id", Scopes: geminiOAuthScopes}
+
+	in := strings.NewReader("not a url?\nhttp://127.0.0.1:0/oauth-callback?state=st&code=pasted\n")
+	var out bytes.Buffer
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	code, err := waitGoogleOAuthCode(ctx, spec, "st", in, &out)
+	require.NoError(t, err)
+	require.Equal(t, "pasted", code)
+	require.Contains(t, out.String(), "https://accounts.google.com/o/oauth2/auth?")
+}
+
+func TestRunLogin_UnsupportedProvider(t *testing.T) {
+	err := runLogin(loginCmd, []string{"copilot"})
+	require.ErrorContains(t, err, "unsupported provider")
+}
@@ -5,6 +5,33 @@ import (
 	"github.com/quotaguard/quotaguard/internal/router"
 )
 
+// buildRouterConfig converts the router section of the configuration
+func buildRouterConfig(cfg *config.Config) router.Config {
+	return router.Config{
+		WarningThreshold:    cfg.Router.Thresholds.Warning,
+		SwitchThreshold:     cfg.Router.Thresholds.Switch,
+		CriticalThreshold:   cfg.Router.Thresholds.Critical,
+		MinSafeThreshold:    cfg.Router.Thresholds.MinSafe,
+		MinDwellTime:        cfg.Router.AntiFlapping.MinDwellTime,
+		CooldownAfterSwitch: cfg.Router.AntiFlapping.CooldownAfterSwitch,
+		HysteresisMargin:    cfg.Router.AntiFlapping.HysteresisMargin,
+		FlapScope:           cfg.Router.AntiFlapping.Scope,
+		IgnoreEstimated:     cfg.Router.IgnoreEstimated,
+		AffinityTTL:         cfg.Router.Affinity.TTL,
+		AffinityMaxSessions: cfg.Router.Affinity.MaxSessions,
+		Weights: router.Weights{
+			Safety:      cfg.Router.Weights.Safety,
+			Refill:      cfg.Router.Weights.Refill,
+			Tier:        cfg.Router.Weights.Tier,
+			Reliability: cfg.Router.Weights.Reliability,
+			Cost:        cfg.Router.Weights.Cost,
+		},
+		DefaultPolicy:  "balanced",
+		Policies:       buildRouterPolicyMap(&cfg.Router),
+		FallbackChains: cfg.Router.FallbackChains,
+	}
+}
+
 func buildRouterPolicyMap(cfg *config.RouterConfig) map[string]router.Weights {
 	policies := make(map[string]router.Weights)
 	if cfg == nil {
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// routeEventsCmd lists stored routing decisions
+var routeEventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Show recorded routing decisions",
+	Long: `Show routing decisions recorded by the server, newest first, with the
+feedback reported for them.
+
+Examples:
+  # Decisions of the last hour
+  quotaguard route events --since 1h
+
+  # Decisions that picked one account
+  quotaguard route events --account openai-1 --limit 20`,
+	Args: cobra.NoArgs,
+	RunE: runRouteEvents,
+}
+
+var routeEventsFlags struct {
+	Account     string
+	Client      string
+	Correlation string
+	Since       string
+	Limit       int
+}
+
+func init() {
+	routeEventsCmd.Flags().StringVar(&routeEventsFlags.Account, "account", "", "Only decisions that picked this account")
+	routeEventsCmd.Flags().StringVar(&routeEventsFlags.Client, "client", "", "Only decisions for this API client")
+	routeEventsCmd.Flags().StringVar(&routeEventsFlags.Correlation, "correlation-id", "", "Only the decision with this correlation ID")
+	routeEventsCmd.Flags().StringVar(&routeEventsFlags.Since, "since", "24h", "Duration (e.g. 1h) or RFC 3339 time to start from")
+	routeEventsCmd.Flags().IntVar(&routeEventsFlags.Limit, "limit", 50, "Maximum number of decisions")
+
+	routeCmd.AddCommand(routeEventsCmd)
+}
+
+func runRouteEvents(cmd *cobra.Command, args []string) error {
+	filter := models.RoutingEventFilter{
+		AccountID:     routeEventsFlags.Account,
+		ClientID:      routeEventsFlags.Client,
+		CorrelationID: routeEventsFlags.Correlation,
+		Limit:         routeEventsFlags.Limit,
+	}
+	if routeEventsFlags.Since != "" {
+		since, err := parseSinceFlag(routeEventsFlags.Since, time.Now())
+		if err != nil {
+			return err
+		}
+		filter.Since = since
+	}
+
+	s, err := openClientStore()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	events, err := s.ListRoutingEvents(filter)
+	if err != nil {
+		return fmt.Errorf("failed to list routing events: %w", err)
+	}
+
+	if globalFlags.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(events)
+	}
+	if len(events) == 0 {
+		fmt.Println("No routing decisions recorded.")
+		return nil
+	}
+	return writeRoutingEventsTable(os.Stdout, events)
+}
+
+// parseSinceFlag parses a duration back from now or an RFC 3339 time
+func parseSinceFlag(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q: use a duration such as 1h or an RFC 3339 time", value)
+	}
+	return t, nil
+}
+
+func writeRoutingEventsTable(out io.Writer, events []*models.RoutingEvent) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tCLIENT\tMODEL\tPOLICY\tACCOUNT\tSCORE\tLATENCY\tOUTCOME\tREASON")
+	for _, e := range events {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%.2f\t%.1fms\t%s\t%s\n",
+			e.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+			valueOr(e.ClientID, "-"),
+			valueOr(e.Model, "-"),
+			valueOr(e.Policy, "-"),
+			e.AccountID,
+			e.Score,
+			e.LatencyMs,
+			routingOutcomeLabel(e.Outcome),
+			e.Reason,
+		)
+	}
+	return w.Flush()
+}
+
+func routingOutcomeLabel(outcome *models.RoutingOutcome) string {
+	switch {
+	case outcome == nil:
+		return "pending"
+	case outcome.Success:
+		return "ok"
+	case outcome.Error != "":
+		return "error: " + outcome.Error
+	default:
+		return "failed"
+	}
+}
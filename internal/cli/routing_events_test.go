@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteRoutingEventsTable(t *testing.T) {
+	events := []*models.RoutingEvent{
+		{CreatedAt: time.Now(), ClientID: "batch", Model: "gpt-4o", Policy: "balanced", AccountID: "openai-1", Score: 0.87, LatencyMs: 1.25, Reason: "best score"},
+		{CreatedAt: time.Now(), AccountID: "openai-2", Outcome: &models.RoutingOutcome{Error: "429"}},
+		{CreatedAt: time.Now(), AccountID: "gemini-1", Outcome: &models.RoutingOutcome{Success: true}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeRoutingEventsTable(&buf, events))
+	out := buf.String()
+	assert.Contains(t, out, "batch")
+	assert.Contains(t, out, "0.87")
+	assert.Contains(t, out, "1.2ms")
+	assert.Contains(t, out, "pending")
+	assert.Contains(t, out, "error: 429")
+	assert.Contains(t, out, "ok")
+}
+
+func TestParseSinceFlag(t *testing.T) {
+	now := time.Date(2026, 5, 8, 12, 0, 0, 0, time.UTC)
+	since, err := parseSinceFlag("2h", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-2*time.Hour), since)
+
+	since, err = parseSinceFlag("2026-05-01T00:00:00Z", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), since)
+
+	_, err = parseSinceFlag("yesterday", now)
+	assert.Error(t, err)
+}
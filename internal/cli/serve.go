@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
@@ -14,14 +15,18 @@ import (
 
 	"github.com/quotaguard/quotaguard/internal/alerts"
 	"github.com/quotaguard/quotaguard/internal/api"
+	"github.com/quotaguard/quotaguard/internal/cleanup"
 	"github.com/quotaguard/quotaguard/internal/cliproxy"
 	"github.com/quotaguard/quotaguard/internal/collector"
 	"github.com/quotaguard/quotaguard/internal/config"
+	"github.com/quotaguard/quotaguard/internal/logging"
+	"github.com/quotaguard/quotaguard/internal/middleware"
 	"github.com/quotaguard/quotaguard/internal/models"
 	"github.com/quotaguard/quotaguard/internal/reservation"
 	"github.com/quotaguard/quotaguard/internal/router"
 	"github.com/quotaguard/quotaguard/internal/store"
 	"github.com/quotaguard/quotaguard/internal/telegram"
+	"github.com/quotaguard/quotaguard/pkg/headers"
 	"github.com/spf13/cobra"
 )
 
@@ -139,12 +144,25 @@ func runServe(cmd *cobra.Command, args []string) error {
 		log.Printf("OAuth import warning: %v", oauthErr)
 	}
 	if authsPath != "" {
-		accountManager = cliproxy.NewAccountManager(sqliteStore, authsPath, 5*time.Minute)
-		newCount, updatedCount, err := accountManager.ScanAndSync()
+		accountManager = newAccountManager(sqliteStore, authsPath)
+		accountManager.SetRemovalCallback(func(removed []*models.Account) {
+			ids := make([]string, 0, len(removed))
+			for _, acc := range removed {
+				ids = append(ids, acc.ID)
+			}
+			log.Printf("Auth files removed, accounts disabled: %s", strings.Join(ids, ", "))
+			if cfg.Telegram.Enabled && settingsStore != nil {
+				if chatID := settingsStore.GetInt(store.SettingTelegramChatID, 0); chatID != 0 {
+					msg := fmt.Sprintf("🗑 Auth files removed, %d account(s) disabled: `%s`", len(ids), strings.Join(ids, "`, `"))
+					telegram.Notify(cfg.Telegram.BotToken, int64(chatID), msg)
+				}
+			}
+		})
+		newCount, updatedCount, removedCount, err := accountManager.ScanAndSync()
 		if err != nil {
 			log.Printf("Auto-discovery warning: %v", err)
 		} else {
-			log.Printf("Auto-discovery enabled: %s (new=%d updated=%d, oauth_new=%d oauth_updated=%d)", authsPath, newCount, updatedCount, oauthNew, oauthUpdated)
+			log.Printf("Auto-discovery enabled: %s (new=%d updated=%d removed=%d, oauth_new=%d oauth_updated=%d)", authsPath, newCount, updatedCount, removedCount, oauthNew, oauthUpdated)
 			if cfg.Telegram.Enabled && settingsStore != nil {
 				if chatID := settingsStore.GetInt(store.SettingTelegramChatID, 0); chatID != 0 {
 					msg := fmt.Sprintf("🔄 Auto-import: %d new, %d updated (oauth: %d new, %d updated)", newCount, updatedCount, oauthNew, oauthUpdated)
@@ -165,6 +183,10 @@ func runServe(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if cfg.CLIProxy.StateSync.Enabled {
+		startStateSync(cfg, sqliteStore, settingsStore, authsPath)
+	}
+
 	if globalFlags.Verbose {
 		log.Printf("Database initialized at: %s", globalFlags.DBPath)
 	}
@@ -181,27 +203,24 @@ func runServe(cmd *cobra.Command, args []string) error {
 		}
 	}()
 
-	// Create router service with converted config
-	routerConfig := router.Config{
-		WarningThreshold:    cfg.Router.Thresholds.Warning,
-		SwitchThreshold:     cfg.Router.Thresholds.Switch,
-		CriticalThreshold:   cfg.Router.Thresholds.Critical,
-		MinSafeThreshold:    cfg.Router.Thresholds.MinSafe,
-		MinDwellTime:        cfg.Router.AntiFlapping.MinDwellTime,
-		CooldownAfterSwitch: cfg.Router.AntiFlapping.CooldownAfterSwitch,
-		HysteresisMargin:    cfg.Router.AntiFlapping.HysteresisMargin,
-		IgnoreEstimated:     cfg.Router.IgnoreEstimated,
-		Weights: router.Weights{
-			Safety:      cfg.Router.Weights.Safety,
-			Refill:      cfg.Router.Weights.Refill,
-			Tier:        cfg.Router.Weights.Tier,
-			Reliability: cfg.Router.Weights.Reliability,
-			Cost:        cfg.Router.Weights.Cost,
-		},
-		DefaultPolicy:  "balanced",
-		Policies:       buildRouterPolicyMap(&cfg.Router),
-		FallbackChains: cfg.Router.FallbackChains,
+	if cfg.Cleanup.Enabled {
+		cleanupMgr := cleanup.NewManager(cleanup.Config{
+			Interval: cfg.Cleanup.Interval,
+			RetentionPolicies: []cleanup.RetentionPolicy{{
+				TableName:       "routing_events",
+				RetentionPeriod: cfg.Cleanup.RoutingEventsRetention,
+				Enabled:         true,
+			}},
+		}, sqliteStore.DB(), nil)
+		if err := cleanupMgr.Start(context.Background()); err != nil {
+			log.Printf("Failed to start cleanup: %v", err)
+		} else {
+			defer func() { _ = cleanupMgr.Stop() }()
+		}
 	}
+
+	// Create router service with converted config
+	routerConfig := buildRouterConfig(cfg)
 	if err := applySettingsToRouterConfig(settingsStore, &routerConfig); err != nil {
 		return fmt.Errorf("failed to apply settings to router config: %w", err)
 	}
@@ -224,11 +243,27 @@ func runServe(cmd *cobra.Command, args []string) error {
 		cfg.Collector.Passive.FlushInterval,
 	)
 
+	// Follow CLIProxy request logs (if enabled)
+	if cfg.Collector.Passive.LogTail.Enabled {
+		tailer := cliproxy.NewLogTailer(sqliteStore, headers.NewRegistry(), cfg.Collector.Passive.LogTail)
+		if err := tailer.Start(context.Background()); err != nil {
+			log.Printf("CLIProxy log tail warning: %v", err)
+		} else {
+			log.Printf("Following CLIProxy request log: %s", cfg.Collector.Passive.LogTail.Path)
+		}
+	}
+
+	// Create API server
+	server := api.NewServer(cfg.Server, cfg.API, sqliteStore, routerSvc, reservationMgr, passiveCollector)
+
 	// Create active collector (if enabled)
 	var activeCollector *collector.ActiveCollector
 	var providerFetcher collector.QuotaFetcher
 	if cfg.Collector.Mode == "active" || cfg.Collector.Mode == "hybrid" {
 		fetcher := collector.NewProviderFetcher(sqliteStore)
+		if err := fetcher.RegisterFetchers(cfg.Collector.Fetchers); err != nil {
+			return fmt.Errorf("failed to register fetchers: %w", err)
+		}
 		providerFetcher = fetcher
 		activeCfg := collector.Config{
 			Interval:      cfg.Collector.Active.DefaultInterval,
@@ -242,14 +277,25 @@ func runServe(cmd *cobra.Command, args []string) error {
 			WorkerCount:   envInt("QUOTAGUARD_COLLECTOR_WORKERS", 8),
 			Jitter:        envDuration("QUOTAGUARD_COLLECTOR_JITTER", 250*time.Millisecond),
 		}
-		activeCollector = collector.NewActiveCollector(sqliteStore, fetcher, activeCfg, nil)
+		activeCollector = collector.NewActiveCollector(sqliteStore, fetcher, activeCfg, server.Metrics())
 		if err := activeCollector.Start(context.Background()); err != nil {
 			log.Printf("Active collector warning: %v", err)
 		}
 	}
 
-	// Create API server
-	server := api.NewServer(cfg.Server, cfg.API, sqliteStore, routerSvc, reservationMgr, passiveCollector)
+	if activeCollector != nil {
+		server.SetActiveCollector(activeCollector)
+	}
+	if cfg.API.Auth.Audit {
+		auditPath := filepath.Join(filepath.Dir(globalFlags.DBPath), "audit.db")
+		auditStore, err := logging.NewSQLiteAuditStore(auditPath)
+		if err != nil {
+			log.Printf("Audit log warning: %v", err)
+		} else {
+			server.SetAuditStore(auditStore)
+			defer auditStore.Close()
+		}
+	}
 
 	tgBot, err := setupTelegramBot(cfg, settingsStore, sqliteStore, routerSvc, accountManager, loader, routerConfig)
 	if err != nil {
@@ -272,8 +318,20 @@ func runServe(cmd *cobra.Command, args []string) error {
 			Timezone:           cfg.Alerts.Timezone,
 			RateLimitPerMinute: cfg.Alerts.RateLimitPerMinute,
 			ShutdownTimeout:    cfg.Alerts.ShutdownTimeout,
+			EscalateAfter:      cfg.Alerts.Escalation.After,
+			EscalationChatID:   cfg.Alerts.Escalation.ChatID,
 		}
-		alertSvc = alerts.NewService(alertCfg, tgBot)
+		if cfg.Alerts.WeeklyDigestEnabled {
+			alertCfg.WeeklyDigestEnabled = true
+			alertCfg.WeeklyDigestDay, _ = cfg.Alerts.WeeklyDigestWeekday()
+		}
+		if cfg.Alerts.Prediction.Enabled {
+			alertCfg.PredictionLeadTimes = cfg.Alerts.Prediction.LeadTimes
+			alertCfg.PredictionWindow = cfg.Alerts.Prediction.Window
+		}
+		alertSvc = alerts.NewService(alertCfg, tgBot, alerts.WithIncidentStore(sqliteStore))
+		alertSvc.SetSpendSource(digestSpendSource(sqliteStore))
+		alertSvc.SetDigestSource(sqliteStore)
 		alertSvc.Start()
 		tgBot.SetThresholdsCallback(func(warning, switchVal, critical float64) error {
 			if routerSvc != nil {
@@ -294,8 +352,39 @@ func runServe(cmd *cobra.Command, args []string) error {
 			return nil
 		})
 		tgBot.SetAlertsCallback(func() ([]telegram.ActiveAlert, error) {
-			return []telegram.ActiveAlert{}, nil
+			incidents, err := alertSvc.ListIncidents(models.IncidentOpen, models.IncidentAcknowledged)
+			if err != nil {
+				return nil, err
+			}
+			active := make([]telegram.ActiveAlert, 0, len(incidents))
+			for _, incident := range incidents {
+				message := incident.AccountID + ": " + incident.Message
+				if incident.Status == models.IncidentAcknowledged {
+					message += " (ack)"
+				}
+				active = append(active, telegram.ActiveAlert{
+					ID:       incident.ID,
+					Severity: incident.Severity,
+					Message:  message,
+					Time:     incident.OpenedAt,
+				})
+			}
+			return active, nil
 		})
+		tgBot.SetIncidentCallbacks(
+			func(incidentID string, userID int64) error {
+				_, err := alertSvc.AcknowledgeIncident(incidentID, userID)
+				return err
+			},
+			func(incidentID string, duration time.Duration) error {
+				_, err := alertSvc.SnoozeIncident(incidentID, duration)
+				return err
+			},
+			func(incidentID, scope string, duration time.Duration) error {
+				_, err := alertSvc.MuteIncident(incidentID, scope, duration)
+				return err
+			},
+		)
 
 		alertCtx, cancel := context.WithCancel(context.Background())
 		alertCancel = cancel
@@ -360,6 +449,50 @@ func validateTLSConfig(tls config.TLSConfig) error {
 }
 
 // setupGracefulShutdown handles graceful shutdown of all components
+// startStateSync pushes QuotaGuard's account enable/disable decisions back
+// to CLIProxy and reports conflicting changes made there.
+func startStateSync(cfg *config.Config, s store.Store, settings store.SettingsStore, authsPath string) {
+	syncCfg := cfg.CLIProxy.StateSync
+	var backend cliproxy.StateBackend
+	if syncCfg.Mode == "api" {
+		client := middleware.NewCLIProxyAPIClient(syncCfg.ManagementURL, middleware.WithAPIKey(syncCfg.ManagementKey))
+		backend = cliproxy.NewAPIStateBackend(client)
+	} else {
+		if authsPath == "" {
+			log.Printf("CLIProxy state sync disabled: no auths path")
+			return
+		}
+		backend = cliproxy.NewFileStateBackend(authsPath)
+	}
+
+	syncer := cliproxy.NewStateSyncer(s, backend, syncCfg)
+	syncer.SetConflictCallback(func(conflicts []cliproxy.StateConflict) {
+		state := func(disabled bool) string {
+			if disabled {
+				return "disabled"
+			}
+			return "enabled"
+		}
+		lines := make([]string, 0, len(conflicts))
+		for _, c := range conflicts {
+			lines = append(lines, fmt.Sprintf("`%s`: QuotaGuard %s, CLIProxy %s → %s",
+				c.AccountID, state(c.QuotaGuardDisabled), state(c.CLIProxyDisabled), c.Resolution))
+		}
+		log.Printf("CLIProxy state conflicts: %s", strings.Join(lines, "; "))
+		if cfg.Telegram.Enabled && settings != nil {
+			if chatID := settings.GetInt(store.SettingTelegramChatID, 0); chatID != 0 {
+				msg := "⚠️ CLIProxy state conflicts:\n" + strings.Join(lines, "\n")
+				telegram.Notify(cfg.Telegram.BotToken, int64(chatID), msg)
+			}
+		}
+	})
+
+	if err := syncer.Start(context.Background()); err != nil {
+		log.Printf("CLIProxy state sync warning: %v", err)
+	}
+	log.Printf("CLIProxy state sync enabled (mode=%s, conflict_policy=%s)", syncCfg.Mode, syncCfg.ConflictPolicy)
+}
+
 func setupGracefulShutdown(server *api.Server, bot *telegram.Bot, active *collector.ActiveCollector, alertsSvc *alerts.Service, alertsCancel context.CancelFunc, timeout time.Duration) {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -443,6 +576,33 @@ func startAlertLoop(ctx context.Context, svc *alerts.Service, s store.Store, int
 	}()
 }
 
+// digestSpendSource reports each account's spend for a day from the ledger,
+// with month-to-date totals for accounts that have a monthly cap.
+func digestSpendSource(s store.Store) func(day time.Time) ([]alerts.AccountSpend, error) {
+	return func(day time.Time) ([]alerts.AccountSpend, error) {
+		entries, err := s.ListSpend(models.UsageDay(day), models.UsageDay(day))
+		if err != nil {
+			return nil, err
+		}
+
+		totals := models.GroupSpend(entries, func(e *models.SpendEntry) string { return e.AccountID })
+		result := make([]alerts.AccountSpend, 0, len(totals))
+		for _, total := range totals {
+			spend := alerts.AccountSpend{
+				AccountID: total.Key,
+				Requests:  total.Requests,
+				CostUSD:   total.CostUSD,
+			}
+			if acc, ok := s.GetAccount(total.Key); ok && acc.HasBudget() {
+				spend.MonthlyBudgetUSD = acc.MonthlyBudget
+				spend.MonthToDateUSD, _ = s.GetAccountSpend(acc.ID, models.MonthStartDay(day), models.UsageDay(day))
+			}
+			result = append(result, spend)
+		}
+		return result, nil
+	}
+}
+
 func startAccountAvailabilityLoop(
 	ctx context.Context,
 	svc *alerts.Service,
@@ -494,7 +654,7 @@ func startAccountAvailabilityLoop(
 								"Account recovered: %s (%s). Availability check passed.",
 								acc.ID, acc.ProviderType,
 							)
-							_ = svc.ProcessAlert(alerts.Alert{
+							_ = svc.ResolveIncident(alerts.Alert{
 								ID:        availabilityAlertID(acc.ID),
 								AccountID: acc.ID,
 								Type:      alerts.AlertTypeError,
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/quotaguard/quotaguard/internal/cleanup"
 	"github.com/quotaguard/quotaguard/internal/cliproxy"
 	"github.com/quotaguard/quotaguard/internal/store"
 	"github.com/spf13/cobra"
@@ -43,12 +44,26 @@ func runSetup(cmd *cobra.Command, args []string) error {
 	}
 	defer sqliteStore.Close()
 
-	manager := cliproxy.NewAccountManager(sqliteStore, authPath, 5*time.Minute)
-	newCount, updatedCount, err := manager.ScanAndSync()
+	manager := newAccountManager(sqliteStore, authPath)
+	newCount, updatedCount, removedCount, err := manager.ScanAndSync()
 	if err != nil {
 		return fmt.Errorf("scan failed: %w", err)
 	}
 
-	fmt.Printf("Sync complete: %d new, %d updated\n", newCount, updatedCount)
+	fmt.Printf("Sync complete: %d new, %d updated, %d removed\n", newCount, updatedCount, removedCount)
 	return nil
 }
+
+// newAccountManager creates an auth file manager. With a SQLite store,
+// accounts whose auth file disappears are soft-deleted so they can be
+// restored when the file returns.
+func newAccountManager(s store.Store, authPath string) *cliproxy.AccountManager {
+	manager := cliproxy.NewAccountManager(s, authPath, 5*time.Minute)
+	if sqliteStore, ok := s.(*store.SQLiteStore); ok {
+		manager.SetSoftDeleter(cleanup.NewSQLiteSoftDeleter(sqliteStore.DB(), cleanup.SoftDeleteConfig{
+			Enabled:         true,
+			HardDeleteAfter: 30 * 24 * time.Hour,
+		}))
+	}
+	return manager
+}
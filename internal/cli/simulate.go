@@ -0,0 +1,216 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/config"
+	"github.com/quotaguard/quotaguard/internal/router"
+	"github.com/quotaguard/quotaguard/internal/simulate"
+	"github.com/spf13/cobra"
+)
+
+// simulateCmd replays recorded requests against routing policies
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Replay recorded traffic against routing policies",
+	Long: `Replay recorded requests in time order against a simulated quota model
+and the real router, starting from a snapshot of account state. Reports
+503s, account switches, exhaustion events and quota wasted at resets for
+every policy or weights combination, so router weights can be tuned on
+real traffic.
+
+Requests are JSON lines (or a JSON array) with "at", "provider", "model",
+"estimated_cost_percent", "estimated_tokens", "session_id" and "client_id".
+Output of "quotaguard route events --json" can be replayed as is.
+
+Examples:
+  # Compare all configured policies
+  quotaguard simulate --requests traffic.jsonl --snapshot state.json
+
+  # Compare one policy with custom weights
+  quotaguard simulate --requests traffic.jsonl --snapshot state.json \
+    --policy balanced --weights safety=0.6,refill=0.2,tier=0.1,reliability=0.1`,
+	Args: cobra.NoArgs,
+	RunE: runSimulate,
+}
+
+var simulateFlags struct {
+	Requests      string
+	Snapshot      string
+	Policies      []string
+	Weights       []string
+	ResetEvery    time.Duration
+	RefillPerHour float64
+	DefaultCost   float64
+}
+
+func init() {
+	model := simulate.DefaultModel()
+	simulateCmd.Flags().StringVar(&simulateFlags.Requests, "requests", "", "Recorded requests (JSON lines)")
+	simulateCmd.Flags().StringVar(&simulateFlags.Snapshot, "snapshot", "", "Starting state, as served by GET /snapshot")
+	simulateCmd.Flags().StringSliceVar(&simulateFlags.Policies, "policy", nil, "Policy to replay; repeatable (default: all configured policies)")
+	simulateCmd.Flags().StringArrayVar(&simulateFlags.Weights, "weights", nil, "Custom weights such as safety=0.6,refill=0.2; repeatable")
+	simulateCmd.Flags().DurationVar(&simulateFlags.ResetEvery, "reset-every", model.ResetEvery, "Quota window length; 0 disables resets")
+	simulateCmd.Flags().Float64Var(&simulateFlags.RefillPerHour, "refill-per-hour", model.RefillPerHour, "Quota regained per hour, in percent")
+	simulateCmd.Flags().Float64Var(&simulateFlags.DefaultCost, "default-cost", model.DefaultCostPct, "Quota used by a request without an estimate, in percent")
+	_ = simulateCmd.MarkFlagRequired("requests")
+	_ = simulateCmd.MarkFlagRequired("snapshot")
+
+	RootCmd.AddCommand(simulateCmd)
+}
+
+func runSimulate(cmd *cobra.Command, args []string) error {
+	requests, err := readSimulateFile(simulateFlags.Requests, simulate.ReadRequests)
+	if err != nil {
+		return err
+	}
+	snap, err := readSimulateFile(simulateFlags.Snapshot, simulate.ReadSnapshot)
+	if err != nil {
+		return err
+	}
+
+	routerConfig := router.DefaultConfig()
+	if cfg, err := config.NewLoader(globalFlags.Config).Load(); err == nil {
+		routerConfig = buildRouterConfig(cfg)
+	} else {
+		fmt.Fprintf(os.Stderr, "Using default router settings: %v\n", err)
+	}
+
+	scenarios, err := simulateScenarios(routerConfig, simulateFlags.Policies, simulateFlags.Weights)
+	if err != nil {
+		return err
+	}
+
+	model := simulate.Model{
+		DefaultCostPct: simulateFlags.DefaultCost,
+		ResetEvery:     simulateFlags.ResetEvery,
+		RefillPerHour:  simulateFlags.RefillPerHour,
+	}
+	results := make([]*simulate.Result, 0, len(scenarios))
+	for _, scenario := range scenarios {
+		result, err := simulate.Run(snap, requests, model, routerConfig, scenario)
+		if err != nil {
+			return fmt.Errorf("scenario %s: %w", scenario.Name, err)
+		}
+		results = append(results, result)
+	}
+
+	if globalFlags.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	}
+	return writeSimulateResults(os.Stdout, results, globalFlags.Verbose)
+}
+
+func readSimulateFile[T any](path string, read func(io.Reader) (T, error)) (T, error) {
+	var zero T
+	f, err := os.Open(path)
+	if err != nil {
+		return zero, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	value, err := read(f)
+	if err != nil {
+		return zero, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return value, nil
+}
+
+// simulateScenarios lists the scenarios to replay: the named policies, or
+// every configured policy when none is named, followed by custom weights
+func simulateScenarios(cfg router.Config, policies, weights []string) ([]simulate.Scenario, error) {
+	if len(policies) == 0 && len(weights) == 0 {
+		for name := range cfg.Policies {
+			policies = append(policies, name)
+		}
+		sort.Strings(policies)
+	}
+
+	scenarios := make([]simulate.Scenario, 0, len(policies)+len(weights))
+	for _, policy := range policies {
+		if _, ok := cfg.Policies[policy]; !ok {
+			return nil, fmt.Errorf("unknown policy %q", policy)
+		}
+		scenarios = append(scenarios, simulate.Scenario{Name: policy, Policy: policy})
+	}
+	for i, value := range weights {
+		w, err := parseWeightsFlag(value)
+		if err != nil {
+			return nil, err
+		}
+		scenarios = append(scenarios, simulate.Scenario{Name: fmt.Sprintf("weights-%d", i+1), Weights: &w})
+	}
+	return scenarios, nil
+}
+
+// parseWeightsFlag parses "safety=0.6,refill=0.2,..."; omitted weights are zero
+func parseWeightsFlag(value string) (router.Weights, error) {
+	var w router.Weights
+	for _, part := range strings.Split(value, ",") {
+		key, raw, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return w, fmt.Errorf("invalid --weights %q: expected name=value pairs", value)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil || v < 0 {
+			return w, fmt.Errorf("invalid --weights %q: %s must be a non-negative number", value, key)
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "safety":
+			w.Safety = v
+		case "refill":
+			w.Refill = v
+		case "tier":
+			w.Tier = v
+		case "reliability":
+			w.Reliability = v
+		case "cost":
+			w.Cost = v
+		default:
+			return w, fmt.Errorf("invalid --weights %q: unknown weight %q", value, key)
+		}
+	}
+	return w, nil
+}
+
+func writeSimulateResults(out io.Writer, results []*simulate.Result, verbose bool) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SCENARIO\tREQUESTS\tSERVED\t503s\tSWITCHES\tEXHAUSTIONS\tRESETS\tWASTED")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%.1f%%\n",
+			r.Scenario, r.Requests, r.Served, r.Rejected, r.Switches, len(r.Exhaustions), r.Resets, r.WastedPct)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if !verbose {
+		return nil
+	}
+
+	for _, r := range results {
+		fmt.Fprintf(out, "\n%s:\n", r.Scenario)
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "  ACCOUNT\tREQUESTS\tCONSUMED\tEXHAUSTIONS\tWASTED\tREMAINING")
+		for _, acc := range r.Accounts {
+			fmt.Fprintf(w, "  %s\t%d\t%.1f%%\t%d\t%.1f%%\t%.1f%%\n",
+				acc.AccountID, acc.Requests, acc.ConsumedPct, acc.Exhaustions, acc.WastedPct, acc.RemainingPct)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		for _, e := range r.Exhaustions {
+			fmt.Fprintf(out, "  exhausted %s at %s\n", e.AccountID, e.At.UTC().Format(time.RFC3339))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/router"
+	"github.com/quotaguard/quotaguard/internal/simulate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWeightsFlag(t *testing.T) {
+	w, err := parseWeightsFlag("safety=0.6, refill=0.2,Tier=0.1,reliability=0.1")
+	require.NoError(t, err)
+	assert.Equal(t, router.Weights{Safety: 0.6, Refill: 0.2, Tier: 0.1, Reliability: 0.1}, w)
+
+	for _, value := range []string{"safety", "safety=x", "safety=-1", "speed=1"} {
+		_, err := parseWeightsFlag(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestSimulateScenarios(t *testing.T) {
+	cfg := router.DefaultConfig()
+
+	scenarios, err := simulateScenarios(cfg, nil, nil)
+	require.NoError(t, err)
+	names := make([]string, 0, len(scenarios))
+	for _, s := range scenarios {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{"balanced", "cost", "performance", "safety"}, names)
+
+	scenarios, err = simulateScenarios(cfg, []string{"safety"}, []string{"safety=1"})
+	require.NoError(t, err)
+	require.Len(t, scenarios, 2)
+	assert.Equal(t, "safety", scenarios[0].Policy)
+	assert.Equal(t, "weights-1", scenarios[1].Name)
+	require.NotNil(t, scenarios[1].Weights)
+
+	_, err = simulateScenarios(cfg, []string{"missing"}, nil)
+	assert.Error(t, err)
+}
+
+func TestWriteSimulateResults(t *testing.T) {
+	results := []*simulate.Result{{
+		Scenario:    "balanced",
+		Requests:    10,
+		Served:      8,
+		Rejected:    2,
+		Switches:    1,
+		Exhaustions: []simulate.Exhaustion{{AccountID: "openai-1", At: time.Date(2026, 5, 1, 3, 0, 0, 0, time.UTC)}},
+		Resets:      2,
+		WastedPct:   42.5,
+		Accounts:    []simulate.AccountResult{{AccountID: "openai-1", Requests: 8, ConsumedPct: 80}},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeSimulateResults(&buf, results, false))
+	assert.Contains(t, buf.String(), "42.5%")
+	assert.NotContains(t, buf.String(), "exhausted openai-1")
+
+	buf.Reset()
+	require.NoError(t, writeSimulateResults(&buf, results, true))
+	assert.Contains(t, buf.String(), "exhausted openai-1 at 2026-05-01T03:00:00Z")
+	assert.Contains(t, buf.String(), "80.0%")
+}
@@ -69,6 +69,7 @@ type deviceAuthSession struct {
 	CreatedAt        time.Time
 	Cancel           context.CancelFunc
 	LocalCallbackURL string
+	TunnelCommand    string
 
 	mu        sync.RWMutex
 	completed bool
@@ -104,6 +105,7 @@ func setupTelegramBot(cfg *config.Config, settings store.SettingsStore, s store.
 	options := &telegram.BotOptions{
 		BotAPI:   apiClient,
 		Settings: settings,
+		Admins:   cfg.Telegram.Admins,
 	}
 	if cfg.Telegram.RateLimit.MessagesPerMinute > 0 {
 		options.RateLimiter = telegram.NewRateLimiter(cfg.Telegram.RateLimit.MessagesPerMinute)
@@ -339,6 +341,10 @@ func setupTelegramBot(cfg *config.Config, settings store.SettingsStore, s store.
 		return []telegram.ActiveAlert{}, nil
 	})
 
+	bot.SetChartCallback(func(target string, period time.Duration) (*telegram.ChartImage, error) {
+		return renderQuotaChart(s, target, period, time.Now())
+	})
+
 	bot.SetAccountCheckConfigCallbacks(
 		func() (*telegram.AccountCheckConfig, error) {
 			interval, timeout := resolveAccountCheckConfig(
@@ -528,7 +534,7 @@ func setupTelegramBot(cfg *config.Config, settings store.SettingsStore, s store.
 				if err != nil {
 					return nil, err
 				}
-				session, authURL, instructions, err := startCLIProxyProviderLogin(state, "claude")
+				session, authURL, instructions, err := startCLIProxyProviderLogin(state, "claude", true)
 				if err != nil {
 					return nil, err
 				}
@@ -548,7 +554,7 @@ func setupTelegramBot(cfg *config.Config, settings store.SettingsStore, s store.
 				if err != nil {
 					return nil, err
 				}
-				session, authURL, instructions, err := startCLIProxyProviderLogin(state, normalizeLoginProvider(provider))
+				session, authURL, instructions, err := startCLIProxyProviderLogin(state, normalizeLoginProvider(provider), true)
 				if err == nil {
 					deviceAuthMu.Lock()
 					deviceAuthSessions[state] = session
@@ -572,21 +578,11 @@ func setupTelegramBot(cfg *config.Config, settings store.SettingsStore, s store.
 			if err != nil {
 				return nil, err
 			}
-			redirectURI := oauthRedirectURI()
-			values := url.Values{}
-			values.Set("access_type", "offline")
-			values.Set("client_id", spec.ClientID)
-			values.Set("prompt", "consent")
-			values.Set("redirect_uri", redirectURI)
-			values.Set("response_type", "code")
-			values.Set("scope", strings.Join(spec.Scopes, " "))
-			values.Set("state", state)
-
 			return &telegram.LoginURLPayload{
 				Provider: provider,
 				State:    state,
 				Mode:     "oauth",
-				URL:      googleOAuthAuthURL + "?" + values.Encode(),
+				URL:      buildGoogleOAuthURL(spec, state),
 				Instructions: "Откроется Google OAuth. После редиректа скопируй URL из адресной строки " +
 					"и отправь его сюда.",
 			}, nil
@@ -655,81 +651,7 @@ func setupTelegramBot(cfg *config.Config, settings store.SettingsStore, s store.
 			if err != nil {
 				return nil, err
 			}
-			token, err := exchangeGoogleOAuthCode(context.Background(), spec, code)
-			if err != nil {
-				return nil, err
-			}
-			email, err := fetchGoogleUserEmail(context.Background(), token.AccessToken)
-			if err != nil {
-				return nil, err
-			}
-			accountID, existingID := findOrBuildAccountID(s, spec.ProviderType, email)
-			if existingID != "" {
-				accountID = existingID
-				if token.RefreshToken == "" {
-					if existingCreds, ok := s.GetAccountCredentials(existingID); ok && existingCreds != nil {
-						token.RefreshToken = strings.TrimSpace(existingCreds.RefreshToken)
-					}
-				}
-			}
-			authPath := buildProviderAuthPath(accountManager, spec.ProviderType, email)
-			now := time.Now()
-			account := &models.Account{
-				ID:             accountID,
-				Provider:       spec.Provider,
-				ProviderType:   spec.ProviderType,
-				Enabled:        true,
-				Priority:       spec.Priority,
-				CredentialsRef: authPath,
-				OAuthCredsPath: authPath,
-				UpdatedAt:      now,
-			}
-			if existing, ok := s.GetAccount(accountID); ok && existing != nil {
-				account.Priority = existing.Priority
-				account.Enabled = existing.Enabled
-				account.Tier = existing.Tier
-				account.CreatedAt = existing.CreatedAt
-			}
-			s.SetAccount(account)
-
-			rawPayload := map[string]interface{}{
-				"type":          spec.ProviderType,
-				"email":         email,
-				"access_token":  token.AccessToken,
-				"refresh_token": token.RefreshToken,
-				"token_uri":     googleOAuthTokenURL,
-				"client_id":     spec.ClientID,
-				"client_secret": spec.ClientSecret,
-				"expiry_date":   token.ExpiryDateMs,
-				"timestamp":     now.UnixMilli(),
-				"expires_in":    token.ExpiresIn,
-				"expired":       now.Add(time.Duration(token.ExpiresIn) * time.Second).Format(time.RFC3339),
-				"auth_method":   "telegram_oauth",
-			}
-			rawJSON, _ := json.Marshal(rawPayload)
-			creds := &models.AccountCredentials{
-				Type:         spec.ProviderType,
-				Email:        email,
-				AccessToken:  token.AccessToken,
-				RefreshToken: token.RefreshToken,
-				ClientID:     spec.ClientID,
-				ClientSecret: spec.ClientSecret,
-				TokenURI:     googleOAuthTokenURL,
-				ExpiryDateMs: token.ExpiryDateMs,
-				SourcePath:   authPath,
-				Raw:          string(rawJSON),
-			}
-			if err := s.SetAccountCredentials(accountID, creds); err != nil {
-				return nil, err
-			}
-			if err := persistProviderAuthFile(authPath, spec.ProviderType, email, token, spec); err != nil {
-				return nil, err
-			}
-			return &telegram.LoginResult{
-				AccountID: accountID,
-				Email:     email,
-				Provider:  spec.ProviderType,
-			}, nil
+			return completeGoogleOAuthLogin(context.Background(), s, accountManager, spec, code, "telegram_oauth")
 		},
 	)
 
@@ -817,9 +739,9 @@ func setupTelegramBot(cfg *config.Config, settings store.SettingsStore, s store.
 			}
 		} else {
 			if path != "" {
-				accountManager = cliproxy.NewAccountManager(s, path, 5*time.Minute)
+				accountManager = newAccountManager(s, path)
 			}
-			n, u, err := accountManager.ScanAndSync()
+			n, u, _, err := accountManager.ScanAndSync()
 			if err != nil {
 				return 0, 0, err
 			}
@@ -859,7 +781,10 @@ func setupTelegramBot(cfg *config.Config, settings store.SettingsStore, s store.
 			MinDwellTime:        newCfg.Router.AntiFlapping.MinDwellTime,
 			CooldownAfterSwitch: newCfg.Router.AntiFlapping.CooldownAfterSwitch,
 			HysteresisMargin:    newCfg.Router.AntiFlapping.HysteresisMargin,
+			FlapScope:           newCfg.Router.AntiFlapping.Scope,
 			IgnoreEstimated:     newCfg.Router.IgnoreEstimated,
+			AffinityTTL:         newCfg.Router.Affinity.TTL,
+			AffinityMaxSessions: newCfg.Router.Affinity.MaxSessions,
 			Weights: router.Weights{
 				Safety:      newCfg.Router.Weights.Safety,
 				Refill:      newCfg.Router.Weights.Refill,
@@ -1111,7 +1036,11 @@ func startCodexDeviceAuth(state string) (*deviceAuthSession, string, string, err
 	return nil, "", "", fmt.Errorf("failed to initialize codex device auth, no URL/code emitted")
 }
 
-func startCLIProxyProviderLogin(state, provider string) (*deviceAuthSession, string, string, error) {
+// startCLIProxyProviderLogin runs the CLIProxy login for provider. With relay
+// set, the callback goes through QUOTAGUARD_PUBLIC_BASE_URL when configured;
+// callers outside the serve process pass false since only it can forward
+// the callback.
+func startCLIProxyProviderLogin(state, provider string, relay bool) (*deviceAuthSession, string, string, error) {
 	binPath := strings.TrimSpace(firstNonEmptyEnv("QUOTAGUARD_CLIPROXY_BIN_PATH"))
 	if binPath == "" {
 		binPath = "/opt/cliproxyplus/cli-proxy-api-plus"
@@ -1204,11 +1133,19 @@ func startCLIProxyProviderLogin(state, provider string) (*deviceAuthSession, str
 			}
 		}
 		if authURL != "" {
-			rewrittenURL, localCallbackURL, relayEnabled, rewriteErr := rewriteProviderAuthURLForRelay(authURL, provider, state)
-			if rewriteErr == nil && rewrittenURL != "" {
-				authURL = rewrittenURL
+			var (
+				relayEnabled bool
+				rewriteErr   error
+			)
+			if relay {
+				var rewrittenURL, localCallbackURL string
+				rewrittenURL, localCallbackURL, relayEnabled, rewriteErr = rewriteProviderAuthURLForRelay(authURL, provider, state)
+				if rewriteErr == nil && rewrittenURL != "" {
+					authURL = rewrittenURL
+				}
+				session.LocalCallbackURL = localCallbackURL
 			}
-			session.LocalCallbackURL = localCallbackURL
+			session.TunnelCommand = tunnelCmd
 
 			instructions := "Открой URL для авторизации."
 			if relayEnabled {
@@ -1386,7 +1323,7 @@ func completeCLIProxyProviderLogin(
 	}
 
 	if accountManager != nil {
-		if _, _, err := accountManager.ScanAndSync(); err != nil {
+		if _, _, _, err := accountManager.ScanAndSync(); err != nil {
 			return nil, err
 		}
 	}
@@ -1425,6 +1362,106 @@ func completeCLIProxyProviderLogin(
 	}, nil
 }
 
+// buildGoogleOAuthURL returns the consent URL for the native Google OAuth flow
+func buildGoogleOAuthURL(spec providerOAuthSpec, state string) string {
+	values := url.Values{}
+	values.Set("access_type", "offline")
+	values.Set("client_id", spec.ClientID)
+	values.Set("prompt", "consent")
+	values.Set("redirect_uri", oauthRedirectURI())
+	values.Set("response_type", "code")
+	values.Set("scope", strings.Join(spec.Scopes, " "))
+	values.Set("state", state)
+	return googleOAuthAuthURL + "?" + values.Encode()
+}
+
+// completeGoogleOAuthLogin exchanges an authorization code, registers the
+// account and writes its CLIProxy auth file.
+func completeGoogleOAuthLogin(
+	ctx context.Context,
+	s store.Store,
+	accountManager *cliproxy.AccountManager,
+	spec providerOAuthSpec,
+	code string,
+	authMethod string,
+) (*telegram.LoginResult, error) {
+	token, err := exchangeGoogleOAuthCode(ctx, spec, code)
+	if err != nil {
+		return nil, err
+	}
+	email, err := fetchGoogleUserEmail(ctx, token.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	accountID, existingID := findOrBuildAccountID(s, spec.ProviderType, email)
+	if existingID != "" {
+		accountID = existingID
+		if token.RefreshToken == "" {
+			if existingCreds, ok := s.GetAccountCredentials(existingID); ok && existingCreds != nil {
+				token.RefreshToken = strings.TrimSpace(existingCreds.RefreshToken)
+			}
+		}
+	}
+	authPath := buildProviderAuthPath(accountManager, spec.ProviderType, email)
+	now := time.Now()
+	account := &models.Account{
+		ID:             accountID,
+		Provider:       spec.Provider,
+		ProviderType:   spec.ProviderType,
+		Enabled:        true,
+		Priority:       spec.Priority,
+		CredentialsRef: authPath,
+		OAuthCredsPath: authPath,
+		UpdatedAt:      now,
+	}
+	if existing, ok := s.GetAccount(accountID); ok && existing != nil {
+		account.Priority = existing.Priority
+		account.Enabled = existing.Enabled
+		account.Tier = existing.Tier
+		account.CreatedAt = existing.CreatedAt
+	}
+	s.SetAccount(account)
+
+	rawPayload := map[string]interface{}{
+		"type":          spec.ProviderType,
+		"email":         email,
+		"access_token":  token.AccessToken,
+		"refresh_token": token.RefreshToken,
+		"token_uri":     googleOAuthTokenURL,
+		"client_id":     spec.ClientID,
+		"client_secret": spec.ClientSecret,
+		"expiry_date":   token.ExpiryDateMs,
+		"timestamp":     now.UnixMilli(),
+		"expires_in":    token.ExpiresIn,
+		"expired":       now.Add(time.Duration(token.ExpiresIn) * time.Second).Format(time.RFC3339),
+		"auth_method":   authMethod,
+	}
+	rawJSON, _ := json.Marshal(rawPayload)
+	creds := &models.AccountCredentials{
+		Type:         spec.ProviderType,
+		Email:        email,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		ClientID:     spec.ClientID,
+		ClientSecret: spec.ClientSecret,
+		TokenURI:     googleOAuthTokenURL,
+		ExpiryDateMs: token.ExpiryDateMs,
+		SourcePath:   authPath,
+		Raw:          string(rawJSON),
+	}
+	if err := s.SetAccountCredentials(accountID, creds); err != nil {
+		return nil, err
+	}
+	if err := persistProviderAuthFile(authPath, spec.ProviderType, email, token, spec); err != nil {
+		return nil, err
+	}
+	return &telegram.LoginResult{
+		AccountID: accountID,
+		Email:     email,
+		Provider:  spec.ProviderType,
+	}, nil
+}
+
 func rewriteProviderAuthURLForRelay(authURL, provider, sid string) (string, string, bool, error) {
 	parsedAuthURL, err := url.Parse(strings.TrimSpace(authURL))
 	if err != nil || parsedAuthURL == nil || parsedAuthURL.Scheme == "" || parsedAuthURL.Host == "" {
@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/quotaguard/quotaguard/internal/cleanup"
 	"github.com/quotaguard/quotaguard/internal/models"
 	"github.com/quotaguard/quotaguard/internal/store"
 )
@@ -30,6 +33,7 @@ type AuthFile struct {
 	ExpiresIn    int64  `json:"expires_in,omitempty"`
 	RefreshToken string `json:"refresh_token,omitempty"`
 	Timestamp    int64  `json:"timestamp"`
+	Disabled     bool   `json:"disabled,omitempty"`
 	Path         string `json:"-"`
 	Token        struct {
 		AccessToken  string   `json:"access_token,omitempty"`
@@ -175,7 +179,7 @@ func ConvertToAccount(auth AuthFile) *models.Account {
 		ID:             accountID,
 		Provider:       provider,
 		ProviderType:   auth.Type,
-		Enabled:        true,
+		Enabled:        !auth.Disabled,
 		Priority:       getDefaultPriority(provider),
 		CredentialsRef: auth.Path,
 	}
@@ -236,6 +240,18 @@ func sanitizeAccountID(email string) string {
 }
 
 // getDefaultPriority returns default priority based on provider
+// keepAccountSettings carries operator-managed settings over from the stored
+// account, since auth files only describe identity and credentials
+func keepAccountSettings(account, existing *models.Account) {
+	account.Enabled = existing.Enabled
+	account.Priority = existing.Priority
+	account.Tier = existing.Tier
+	account.ConcurrencyLimit = existing.ConcurrencyLimit
+	account.InputCost = existing.InputCost
+	account.OutputCost = existing.OutputCost
+	account.MonthlyBudget = existing.MonthlyBudget
+}
+
 func getDefaultPriority(provider models.Provider) int {
 	switch provider {
 	case models.ProviderAnthropic:
@@ -251,12 +267,24 @@ func getDefaultPriority(provider models.Provider) int {
 	}
 }
 
+// accountsTable is the soft-delete table name for accounts
+const accountsTable = "accounts"
+
+// defaultWatchDebounce is how long the auth directory must be quiet before
+// watcher events trigger a sync. Tools that rewrite auth files by removing
+// and recreating them would otherwise look like a removal.
+const defaultWatchDebounce = time.Second
+
 // AccountManager manages auto-discovery of CLIProxyAPI accounts
 type AccountManager struct {
-	store     store.Store
-	authsPath string
-	lastScan  time.Time
-	interval  time.Duration
+	store       store.Store
+	authsPath   string
+	lastScan    time.Time
+	interval    time.Duration
+	debounce    time.Duration
+	softDeleter *cleanup.SQLiteSoftDeleter
+	onRemoved   func(removed []*models.Account)
+	syncMu      sync.Mutex
 }
 
 // NewAccountManager creates a new account manager
@@ -269,14 +297,35 @@ func NewAccountManager(s store.Store, authsPath string, scanInterval time.Durati
 		store:     s,
 		authsPath: authsPath,
 		interval:  scanInterval,
+		debounce:  defaultWatchDebounce,
 	}
 }
 
-// ScanAndSync scans auth files and syncs to database
-func (am *AccountManager) ScanAndSync() (newCount, updatedCount int, err error) {
+// SetSoftDeleter makes accounts whose auth file disappears disabled and
+// soft-deleted instead of deleted, so they come back if the file returns.
+func (am *AccountManager) SetSoftDeleter(sd *cleanup.SQLiteSoftDeleter) {
+	am.syncMu.Lock()
+	defer am.syncMu.Unlock()
+	am.softDeleter = sd
+}
+
+// SetRemovalCallback sets a function called with the accounts a sync
+// removed because their auth files disappeared.
+func (am *AccountManager) SetRemovalCallback(fn func(removed []*models.Account)) {
+	am.syncMu.Lock()
+	defer am.syncMu.Unlock()
+	am.onRemoved = fn
+}
+
+// ScanAndSync scans auth files and syncs to database. Accounts imported from
+// the auth directory whose file is gone are removed.
+func (am *AccountManager) ScanAndSync() (newCount, updatedCount, removedCount int, err error) {
+	am.syncMu.Lock()
+	defer am.syncMu.Unlock()
+
 	auths, err := DiscoverAuthFiles(am.authsPath)
 	if err != nil {
-		return 0, 0, err
+		return 0, 0, 0, err
 	}
 
 	// Get existing accounts
@@ -285,29 +334,34 @@ func (am *AccountManager) ScanAndSync() (newCount, updatedCount int, err error)
 	for _, acc := range existingAccounts {
 		existingMap[acc.ID] = acc
 	}
+	deleted := am.softDeletedAccounts()
 
-	// Track which accounts we've seen
+	// Track which auth files we've seen
 	seen := make(map[string]bool)
 
 	for _, auth := range auths {
 		accountID := sanitizeAccountID(auth.Type + "_" + auth.Email)
 		legacyID := sanitizeAccountID(auth.Email)
-		seen[accountID] = true
+		seen[auth.Path] = true
 
 		account := ConvertToAccount(auth)
 		creds := ConvertToCredentials(auth)
 
 		if existing, ok := existingMap[accountID]; ok {
 			// Update existing account
-			account.Enabled = existing.Enabled
-			account.Priority = existing.Priority
+			keepAccountSettings(account, existing)
+			if original, ok := deleted[accountID]; ok {
+				// The auth file came back; undo the soft delete
+				if err := am.softDeleter.RestoreDeleted(accountsTable, accountID); err == nil {
+					account.Enabled = original.Enabled
+				}
+			}
 			am.store.SetAccount(account)
 			_ = am.store.SetAccountCredentials(account.ID, creds)
 			updatedCount++
 		} else if legacy, ok := existingMap[legacyID]; ok && legacy.CredentialsRef == auth.Path {
 			// Migrate legacy account ID to provider-specific ID
-			account.Enabled = legacy.Enabled
-			account.Priority = legacy.Priority
+			keepAccountSettings(account, legacy)
 			am.store.SetAccount(account)
 			_ = am.store.SetAccountCredentials(account.ID, creds)
 			am.store.DeleteAccount(legacy.ID)
@@ -320,8 +374,75 @@ func (am *AccountManager) ScanAndSync() (newCount, updatedCount int, err error)
 		}
 	}
 
+	removed := am.removeMissing(existingAccounts, seen, deleted)
+
 	am.lastScan = time.Now()
-	return newCount, updatedCount, nil
+	if len(removed) > 0 && am.onRemoved != nil {
+		am.onRemoved(removed)
+	}
+	return newCount, updatedCount, len(removed), nil
+}
+
+// removeMissing removes accounts whose auth file in the watched directory
+// no longer exists. Accounts from config or other directories are left
+// alone, and nothing is removed while the directory itself is unavailable.
+func (am *AccountManager) removeMissing(accounts []*models.Account, seen map[string]bool, deleted map[string]*models.Account) []*models.Account {
+	if info, err := os.Stat(am.authsPath); err != nil || !info.IsDir() {
+		return nil
+	}
+	dir := filepath.Clean(am.authsPath)
+
+	var removed []*models.Account
+	for _, acc := range accounts {
+		ref := acc.CredentialsRef
+		if ref == "" || seen[ref] || filepath.Dir(filepath.Clean(ref)) != dir {
+			continue
+		}
+		if _, ok := deleted[acc.ID]; ok {
+			continue
+		}
+		if _, err := os.Stat(ref); err == nil {
+			// Still there but unreadable, likely mid-write
+			continue
+		}
+
+		if am.softDeleter != nil && am.softDeleter.IsEnabled() {
+			data, _ := json.Marshal(acc)
+			if err := am.softDeleter.MarkAsDeleted(accountsTable, acc.ID, string(data)); err != nil {
+				continue
+			}
+			disabled := *acc
+			disabled.Enabled = false
+			am.store.SetAccount(&disabled)
+		} else {
+			am.store.DeleteAccount(acc.ID)
+			_ = am.store.DeleteAccountCredentials(acc.ID)
+			am.store.DeleteQuota(acc.ID)
+		}
+		removed = append(removed, acc)
+	}
+
+	sort.Slice(removed, func(i, j int) bool { return removed[i].ID < removed[j].ID })
+	return removed
+}
+
+// softDeletedAccounts returns the soft-deleted accounts by ID with their
+// state at deletion time.
+func (am *AccountManager) softDeletedAccounts() map[string]*models.Account {
+	result := make(map[string]*models.Account)
+	if am.softDeleter == nil {
+		return result
+	}
+	records, err := am.softDeleter.GetDeletedRecords(accountsTable)
+	if err != nil {
+		return result
+	}
+	for _, record := range records {
+		acc := &models.Account{ID: record.RecordID, Enabled: true}
+		_ = json.Unmarshal([]byte(record.OriginalData), acc)
+		result[record.RecordID] = acc
+	}
+	return result
 }
 
 // WatchAuths starts a file watcher for auth directory changes.
@@ -342,6 +463,15 @@ func (am *AccountManager) WatchAuths(ctx context.Context) error {
 
 	go func() {
 		defer watcher.Close()
+
+		var timer *time.Timer
+		var settled <-chan time.Time
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+
 		for {
 			select {
 			case <-ctx.Done():
@@ -350,9 +480,17 @@ func (am *AccountManager) WatchAuths(ctx context.Context) error {
 				if !ok {
 					return
 				}
-				if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Rename) {
-					_, _, _ = am.ScanAndSync()
+				if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Rename) || event.Has(fsnotify.Remove) {
+					if timer == nil {
+						timer = time.NewTimer(am.debounce)
+					} else {
+						timer.Reset(am.debounce)
+					}
+					settled = timer.C
 				}
+			case <-settled:
+				settled = nil
+				_, _, _, _ = am.ScanAndSync()
 			case <-watcher.Errors:
 				// Ignore watcher errors; periodic scan can still handle updates.
 			}
@@ -364,7 +502,7 @@ func (am *AccountManager) WatchAuths(ctx context.Context) error {
 
 // StartAutoSync performs an initial scan and starts periodic and watcher-based sync.
 func (am *AccountManager) StartAutoSync(ctx context.Context) error {
-	if _, _, err := am.ScanAndSync(); err != nil {
+	if _, _, _, err := am.ScanAndSync(); err != nil {
 		return err
 	}
 	if err := am.WatchAuths(ctx); err != nil {
@@ -383,7 +521,7 @@ func (am *AccountManager) StartAutoSync(ctx context.Context) error {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				_, _, _ = am.ScanAndSync()
+				_, _, _, _ = am.ScanAndSync()
 			}
 		}
 	}()
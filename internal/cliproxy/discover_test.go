@@ -1,12 +1,14 @@
 package cliproxy
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/quotaguard/quotaguard/internal/cleanup"
 	"github.com/quotaguard/quotaguard/internal/models"
 	"github.com/quotaguard/quotaguard/internal/store"
 	"github.com/stretchr/testify/assert"
@@ -242,21 +244,37 @@ func TestAccountManager_ScanAndSync(t *testing.T) {
 	manager := NewAccountManager(memStore, tmpDir, time.Minute)
 
 	// First scan - should create account
-	newCount, updatedCount, err := manager.ScanAndSync()
+	newCount, updatedCount, removedCount, err := manager.ScanAndSync()
 	require.NoError(t, err)
 	assert.Equal(t, 1, newCount)
 	assert.Equal(t, 0, updatedCount)
+	assert.Equal(t, 0, removedCount)
 
 	// Verify account was created
 	account, ok := memStore.GetAccount("antigravity_manager_test_at_example_com")
 	assert.True(t, ok)
 	assert.Equal(t, models.ProviderAnthropic, account.Provider)
 
+	// Settings made after discovery survive a rescan
+	account.Tier = "pro"
+	account.InputCost = 0.003
+	account.OutputCost = 0.015
+	account.MonthlyBudget = 50
+	memStore.SetAccount(account)
+
 	// Second scan - should update (no change)
-	newCount, updatedCount, err = manager.ScanAndSync()
+	newCount, updatedCount, removedCount, err = manager.ScanAndSync()
 	require.NoError(t, err)
 	assert.Equal(t, 0, newCount)
 	assert.Equal(t, 1, updatedCount) // Account is updated even if unchanged
+	assert.Equal(t, 0, removedCount)
+
+	account, ok = memStore.GetAccount("antigravity_manager_test_at_example_com")
+	require.True(t, ok)
+	assert.Equal(t, "pro", account.Tier)
+	assert.Equal(t, 0.003, account.InputCost)
+	assert.Equal(t, 0.015, account.OutputCost)
+	assert.Equal(t, 50.0, account.MonthlyBudget)
 }
 
 func TestAccountManager_ScanAndSync_EmptyDir(t *testing.T) {
@@ -267,10 +285,11 @@ func TestAccountManager_ScanAndSync_EmptyDir(t *testing.T) {
 	memStore := store.NewMemoryStore()
 	manager := NewAccountManager(memStore, tmpDir, time.Minute)
 
-	newCount, updatedCount, err := manager.ScanAndSync()
+	newCount, updatedCount, removedCount, err := manager.ScanAndSync()
 	require.NoError(t, err)
 	assert.Equal(t, 0, newCount)
 	assert.Equal(t, 0, updatedCount)
+	assert.Equal(t, 0, removedCount)
 }
 
 func TestAccountManager_GetAuthPath(t *testing.T) {
@@ -293,7 +312,142 @@ func TestAccountManager_GetLastScan(t *testing.T) {
 	assert.True(t, manager.GetLastScan().IsZero())
 
 	// After scan - should have a time
-	_, _, err = manager.ScanAndSync()
+	_, _, _, err = manager.ScanAndSync()
 	require.NoError(t, err)
 	assert.False(t, manager.GetLastScan().IsZero())
 }
+
+func TestAccountManager_ScanAndSync_RemovesMissing(t *testing.T) {
+	tmpDir := t.TempDir()
+	authPath := filepath.Join(tmpDir, "codex-gone@example.com.json")
+	require.NoError(t, os.WriteFile(authPath, []byte(`{"access_token":"token","email":"gone@example.com","type":"codex"}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "gemini-kept@example.com.json"), []byte(`{"access_token":"token","email":"kept@example.com","type":"gemini"}`), 0644))
+
+	memStore := store.NewMemoryStore()
+	memStore.SetAccount(&models.Account{ID: "from-config", Provider: models.ProviderOpenAI, Enabled: true})
+	manager := NewAccountManager(memStore, tmpDir, time.Minute)
+
+	var notified []*models.Account
+	manager.SetRemovalCallback(func(removed []*models.Account) {
+		notified = append(notified, removed...)
+	})
+
+	_, _, _, err := manager.ScanAndSync()
+	require.NoError(t, err)
+
+	require.NoError(t, os.Remove(authPath))
+	newCount, updatedCount, removedCount, err := manager.ScanAndSync()
+	require.NoError(t, err)
+	assert.Equal(t, 0, newCount)
+	assert.Equal(t, 1, updatedCount)
+	assert.Equal(t, 1, removedCount)
+	require.Len(t, notified, 1)
+	assert.Equal(t, "codex_gone_at_example_com", notified[0].ID)
+
+	// Without a soft deleter the account is deleted outright
+	_, ok := memStore.GetAccount("codex_gone_at_example_com")
+	assert.False(t, ok)
+	_, ok = memStore.GetAccount("from-config")
+	assert.True(t, ok)
+	_, ok = memStore.GetAccount("gemini_kept_at_example_com")
+	assert.True(t, ok)
+
+	// A missing directory is not treated as every file being removed
+	require.NoError(t, os.RemoveAll(tmpDir))
+	_, _, removedCount, err = manager.ScanAndSync()
+	require.NoError(t, err)
+	assert.Equal(t, 0, removedCount)
+	_, ok = memStore.GetAccount("gemini_kept_at_example_com")
+	assert.True(t, ok)
+}
+
+func TestAccountManager_ScanAndSync_SoftDeletes(t *testing.T) {
+	tmpDir := t.TempDir()
+	authsDir := filepath.Join(tmpDir, "auths")
+	require.NoError(t, os.Mkdir(authsDir, 0755))
+	authPath := filepath.Join(authsDir, "codex-soft@example.com.json")
+	authContent := []byte(`{"access_token":"token","email":"soft@example.com","type":"codex"}`)
+	require.NoError(t, os.WriteFile(authPath, authContent, 0644))
+
+	sqliteStore, err := store.NewSQLiteStore(filepath.Join(tmpDir, "quotaguard.db"))
+	require.NoError(t, err)
+	defer sqliteStore.Close()
+
+	softDeleter := cleanup.NewSQLiteSoftDeleter(sqliteStore.DB(), cleanup.SoftDeleteConfig{Enabled: true, HardDeleteAfter: time.Hour})
+	manager := NewAccountManager(sqliteStore, authsDir, time.Minute)
+	manager.SetSoftDeleter(softDeleter)
+
+	_, _, _, err = manager.ScanAndSync()
+	require.NoError(t, err)
+	const id = "codex_soft_at_example_com"
+
+	require.NoError(t, os.Remove(authPath))
+	_, _, removedCount, err := manager.ScanAndSync()
+	require.NoError(t, err)
+	assert.Equal(t, 1, removedCount)
+
+	acc, ok := sqliteStore.GetAccount(id)
+	require.True(t, ok)
+	assert.False(t, acc.Enabled)
+	count, err := softDeleter.CountDeletedRecords("accounts")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// Already soft-deleted accounts are not reported again
+	_, _, removedCount, err = manager.ScanAndSync()
+	require.NoError(t, err)
+	assert.Equal(t, 0, removedCount)
+
+	// The file returning restores the account
+	require.NoError(t, os.WriteFile(authPath, authContent, 0644))
+	_, updatedCount, _, err := manager.ScanAndSync()
+	require.NoError(t, err)
+	assert.Equal(t, 1, updatedCount)
+	acc, ok = sqliteStore.GetAccount(id)
+	require.True(t, ok)
+	assert.True(t, acc.Enabled)
+	count, err = softDeleter.CountDeletedRecords("accounts")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestAccountManager_WatchAuthsDebouncesRemoval(t *testing.T) {
+	tmpDir := t.TempDir()
+	authPath := filepath.Join(tmpDir, "codex-watch@example.com.json")
+	authContent := []byte(`{"access_token":"token","email":"watch@example.com","type":"codex"}`)
+	require.NoError(t, os.WriteFile(authPath, authContent, 0644))
+
+	memStore := store.NewMemoryStore()
+	manager := NewAccountManager(memStore, tmpDir, time.Minute)
+	manager.debounce = 100 * time.Millisecond
+	removed := make(chan string, 4)
+	manager.SetRemovalCallback(func(accounts []*models.Account) {
+		for _, acc := range accounts {
+			removed <- acc.ID
+		}
+	})
+	_, _, _, err := manager.ScanAndSync()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, manager.WatchAuths(ctx))
+
+	// A file rewritten by remove and create is not treated as removed
+	require.NoError(t, os.Remove(authPath))
+	require.NoError(t, os.WriteFile(authPath, authContent, 0644))
+	time.Sleep(300 * time.Millisecond)
+	_, ok := memStore.GetAccount("codex_watch_at_example_com")
+	assert.True(t, ok)
+	assert.Empty(t, removed)
+
+	require.NoError(t, os.Remove(authPath))
+	select {
+	case id := <-removed:
+		assert.Equal(t, "codex_watch_at_example_com", id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("removal was not detected")
+	}
+	_, ok = memStore.GetAccount("codex_watch_at_example_com")
+	assert.False(t, ok)
+}
@@ -0,0 +1,436 @@
+package cliproxy
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/config"
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/store"
+	"github.com/quotaguard/quotaguard/pkg/headers"
+)
+
+// maxFingerprintBytes bounds how much of the first line identifies a file
+const maxFingerprintBytes = 4096
+
+// RequestLogEntry is one line of the CLIProxy JSON request log
+type RequestLogEntry struct {
+	Timestamp       time.Time       `json:"timestamp"`
+	AuthFile        string          `json:"auth_file"`
+	AuthType        string          `json:"auth_type"`
+	Email           string          `json:"email"`
+	AccountID       string          `json:"account_id"`
+	Provider        string          `json:"provider"`
+	Model           string          `json:"model"`
+	Status          int             `json:"status"`
+	ResponseHeaders LogHeaders      `json:"response_headers"`
+	Usage           RequestLogUsage `json:"usage"`
+}
+
+// RequestLogUsage holds token counts in either Anthropic or OpenAI naming
+type RequestLogUsage struct {
+	InputTokens      int64 `json:"input_tokens"`
+	OutputTokens     int64 `json:"output_tokens"`
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+}
+
+// Tokens returns input and output tokens whichever naming was used
+func (u RequestLogUsage) Tokens() (input, output int64) {
+	input, output = u.InputTokens, u.OutputTokens
+	if input == 0 {
+		input = u.PromptTokens
+	}
+	if output == 0 {
+		output = u.CompletionTokens
+	}
+	return input, output
+}
+
+// LogHeaders accepts header values logged either as strings or string lists
+type LogHeaders http.Header
+
+// UnmarshalJSON implements json.Unmarshaler
+func (h *LogHeaders) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	result := make(http.Header, len(raw))
+	for key, value := range raw {
+		var list []string
+		if err := json.Unmarshal(value, &list); err == nil {
+			for _, v := range list {
+				result.Add(key, v)
+			}
+			continue
+		}
+		var single string
+		if err := json.Unmarshal(value, &single); err != nil {
+			return err
+		}
+		result.Add(key, single)
+	}
+	*h = LogHeaders(result)
+	return nil
+}
+
+// LogTailer follows the CLIProxy request log and turns upstream response
+// headers into quota and token usage into spend, checkpointing its offset
+// so restarts resume where they stopped.
+type LogTailer struct {
+	store       store.Store
+	parsers     *headers.Registry
+	path        string
+	interval    time.Duration
+	startAtEnd  bool
+	recordSpend bool
+
+	mu          sync.Mutex
+	file        *os.File
+	offset      int64
+	fingerprint string
+	pending     []byte
+	saved       models.LogCheckpoint
+	authIndex   map[string]string // auth file base name -> account ID
+}
+
+// NewLogTailer creates a follower for the configured request log
+func NewLogTailer(s store.Store, parsers *headers.Registry, cfg config.LogTailConfig) *LogTailer {
+	interval := cfg.PollInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return &LogTailer{
+		store:       s,
+		parsers:     parsers,
+		path:        cfg.Path,
+		interval:    interval,
+		startAtEnd:  cfg.StartAtEnd,
+		recordSpend: cfg.RecordSpend,
+	}
+}
+
+// Start processes what is already in the log and keeps following it
+func (t *LogTailer) Start(ctx context.Context) error {
+	if _, err := t.Poll(); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				_ = t.Close()
+				return
+			case <-ticker.C:
+				if _, err := t.Poll(); err != nil && os.Getenv("QUOTAGUARD_COLLECTOR_DEBUG") == "1" {
+					log.Printf("cliproxy log tail: %v", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Poll reads lines appended since the last call and returns how many were
+// processed. A file replaced by rotation is drained before switching to the
+// new one; a file truncated in place is read again from the start.
+func (t *LogTailer) Poll() (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.authIndex = nil
+	if t.file == nil {
+		if err := t.open(true); err != nil {
+			if os.IsNotExist(err) {
+				return 0, nil
+			}
+			return 0, err
+		}
+	}
+
+	processed, err := t.drain()
+	if err != nil {
+		return processed, err
+	}
+
+	info, err := os.Stat(t.path)
+	if os.IsNotExist(err) {
+		// Rotated away and not recreated yet; keep the drained handle
+		return processed, nil
+	}
+	if err != nil {
+		return processed, err
+	}
+	current, err := t.file.Stat()
+	if err != nil {
+		return processed, err
+	}
+
+	switch {
+	case !os.SameFile(current, info):
+		_ = t.file.Close()
+		t.file = nil
+		if err := t.open(false); err != nil {
+			return processed, err
+		}
+	case info.Size() < t.offset+int64(len(t.pending)), t.rewritten():
+		if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+			return processed, err
+		}
+		t.offset = 0
+		t.pending = nil
+		t.fingerprint = ""
+	default:
+		return processed, nil
+	}
+
+	more, err := t.drain()
+	return processed + more, err
+}
+
+// Close releases the log file
+func (t *LogTailer) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.file == nil {
+		return nil
+	}
+	err := t.file.Close()
+	t.file = nil
+	return err
+}
+
+// rewritten reports whether the file was truncated and refilled past the
+// old offset between polls, which the size alone cannot show
+func (t *LogTailer) rewritten() bool {
+	if t.fingerprint == "" {
+		return false
+	}
+	current := fileFingerprint(t.file)
+	return current != "" && current != t.fingerprint
+}
+
+// open opens the log, resuming from the checkpoint when it still
+// describes the same file
+func (t *LogTailer) open(resume bool) error {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	fingerprint := fileFingerprint(f)
+	var offset int64
+	if resume {
+		if cp, ok := t.store.GetLogCheckpoint(t.path); ok {
+			if cp.Fingerprint != "" && cp.Fingerprint == fingerprint && cp.Offset <= info.Size() {
+				offset = cp.Offset
+			}
+		} else if t.startAtEnd {
+			offset = info.Size()
+		}
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return err
+	}
+
+	t.file = f
+	t.offset = offset
+	t.fingerprint = fingerprint
+	t.pending = nil
+	return nil
+}
+
+// drain reads to EOF, handles every complete line and saves the checkpoint
+func (t *LogTailer) drain() (int, error) {
+	buf := make([]byte, 32*1024)
+	processed := 0
+	for {
+		n, err := t.file.Read(buf)
+		if n > 0 {
+			t.pending = append(t.pending, buf[:n]...)
+			for {
+				idx := bytes.IndexByte(t.pending, '\n')
+				if idx < 0 {
+					break
+				}
+				line := t.pending[:idx]
+				t.offset += int64(idx + 1)
+				if t.handleLine(line) {
+					processed++
+				}
+				t.pending = t.pending[idx+1:]
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return processed, err
+		}
+	}
+	if len(t.pending) == 0 {
+		t.pending = nil
+	}
+
+	if t.fingerprint == "" && t.offset > 0 {
+		t.fingerprint = fileFingerprint(t.file)
+	}
+	cp := models.LogCheckpoint{Path: t.path, Fingerprint: t.fingerprint, Offset: t.offset}
+	if cp == t.saved {
+		return processed, nil
+	}
+	if err := t.store.SetLogCheckpoint(&cp); err != nil {
+		return processed, err
+	}
+	t.saved = cp
+	return processed, nil
+}
+
+// handleLine applies one log entry; malformed and unknown-account lines
+// are skipped
+func (t *LogTailer) handleLine(line []byte) bool {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return false
+	}
+
+	var entry RequestLogEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		if os.Getenv("QUOTAGUARD_COLLECTOR_DEBUG") == "1" {
+			log.Printf("cliproxy log tail: skip malformed line: %v", err)
+		}
+		return false
+	}
+
+	acc, ok := t.resolveAccount(entry)
+	if !ok {
+		if os.Getenv("QUOTAGUARD_COLLECTOR_DEBUG") == "1" {
+			log.Printf("cliproxy log tail: skip line for unknown account auth_file=%q email=%q", entry.AuthFile, entry.Email)
+		}
+		return false
+	}
+
+	at := entry.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	if len(entry.ResponseHeaders) > 0 {
+		// Replaying an old log must not overwrite fresher polled quota
+		current, ok := t.store.GetQuota(acc.ID)
+		if !ok || !current.CollectedAt.After(at) {
+			if quota := t.parseHeaders(acc, http.Header(entry.ResponseHeaders)); quota != nil {
+				quota.CollectedAt = at
+				t.store.SetQuota(acc.ID, quota)
+			}
+		}
+	}
+
+	if entry.Status < http.StatusBadRequest {
+		_ = t.store.RecordAccountActivity(acc.ID, "", at)
+		// Spend is opt-in: /router/feedback usually charges the same request
+		if input, output := entry.Usage.Tokens(); t.recordSpend && (input > 0 || output > 0) {
+			_ = t.store.AddSpend(models.NewSpendEntry(acc, "", input, output, at))
+		}
+	}
+	return true
+}
+
+// parseHeaders detects the upstream from the headers themselves, since an
+// account's provider may front another vendor's API, then falls back to
+// the account's provider parser.
+func (t *LogTailer) parseHeaders(acc *models.Account, h http.Header) *models.QuotaInfo {
+	quota, _, err := t.parsers.AutoDetect(h, acc.ID)
+	if err != nil {
+		quota, err = t.parsers.Parse(acc.Provider, h, acc.ID)
+	}
+	if err != nil || quota == nil || len(quota.Dimensions) == 0 {
+		return nil
+	}
+	quota.Provider = acc.Provider
+	return quota
+}
+
+// resolveAccount maps a log entry to an account the same way auth files
+// are imported: by explicit ID, by auth file, or by auth type and email.
+func (t *LogTailer) resolveAccount(entry RequestLogEntry) (*models.Account, bool) {
+	if entry.AccountID != "" {
+		if acc, ok := t.store.GetAccount(entry.AccountID); ok {
+			return acc, true
+		}
+	}
+
+	if entry.AuthFile != "" {
+		if t.authIndex == nil {
+			t.authIndex = make(map[string]string)
+			for _, acc := range t.store.ListAccounts() {
+				if acc.CredentialsRef != "" {
+					t.authIndex[filepath.Base(acc.CredentialsRef)] = acc.ID
+				}
+			}
+		}
+		if id, ok := t.authIndex[filepath.Base(entry.AuthFile)]; ok {
+			if acc, ok := t.store.GetAccount(id); ok {
+				return acc, true
+			}
+		}
+	}
+
+	if entry.Email != "" {
+		authType := strings.ToLower(strings.TrimSpace(entry.AuthType))
+		if authType == "" {
+			authType = strings.ToLower(strings.TrimSpace(entry.Provider))
+		}
+		for _, id := range []string{sanitizeAccountID(authType + "_" + entry.Email), sanitizeAccountID(entry.Email)} {
+			if acc, ok := t.store.GetAccount(id); ok {
+				return acc, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// fileFingerprint hashes the first complete line of f, or returns "" when
+// the file does not have one yet
+func fileFingerprint(f *os.File) string {
+	buf := make([]byte, maxFingerprintBytes)
+	n, err := f.ReadAt(buf, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return ""
+	}
+	idx := bytes.IndexByte(buf[:n], '\n')
+	if idx < 0 {
+		if n < maxFingerprintBytes {
+			return ""
+		}
+		idx = n
+	}
+	sum := sha256.Sum256(buf[:idx])
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,269 @@
+package cliproxy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/config"
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/store"
+	"github.com/quotaguard/quotaguard/pkg/headers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const codexLogLine = `{"timestamp":"2026-03-01T10:00:00Z","auth_file":"/srv/cliproxy/auths/codex-dev@example.com.json","model":"gpt-5","status":200,` +
+	`"response_headers":{"x-ratelimit-limit-requests":"100","x-ratelimit-remaining-requests":["40"],"x-ratelimit-reset-requests":"30s"},` +
+	`"usage":{"prompt_tokens":1000,"completion_tokens":500}}`
+
+func setupLogTail(t *testing.T) (*store.MemoryStore, string) {
+	t.Helper()
+	s := store.NewMemoryStore()
+	s.SetAccount(&models.Account{
+		ID:             sanitizeAccountID("codex_dev@example.com"),
+		Provider:       models.ProviderOpenAI,
+		Enabled:        true,
+		InputCost:      0.01,
+		CredentialsRef: "/opt/cliproxyplus/auths/codex-dev@example.com.json",
+	})
+	s.SetAccount(&models.Account{
+		ID:       sanitizeAccountID("gemini_ops@example.com"),
+		Provider: models.ProviderGemini,
+		Enabled:  true,
+	})
+	return s, filepath.Join(t.TempDir(), "requests.log")
+}
+
+func newTestTailer(s store.Store, path string) *LogTailer {
+	return NewLogTailer(s, headers.NewRegistry(), config.LogTailConfig{Path: path, RecordSpend: true})
+}
+
+func appendLog(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	defer f.Close()
+	for _, line := range lines {
+		_, err := f.WriteString(line)
+		require.NoError(t, err)
+	}
+}
+
+func spendRequests(t *testing.T, s store.Store, accountID string) int64 {
+	t.Helper()
+	entries, err := s.ListSpend("2026-01-01", "2026-12-31")
+	require.NoError(t, err)
+	var total int64
+	for _, e := range entries {
+		if e.AccountID == accountID {
+			total += e.Requests
+		}
+	}
+	return total
+}
+
+func TestLogTailer_ParsesEntries(t *testing.T) {
+	s, path := setupLogTail(t)
+	codexID := sanitizeAccountID("codex_dev@example.com")
+	geminiID := sanitizeAccountID("gemini_ops@example.com")
+
+	appendLog(t, path,
+		codexLogLine+"\n",
+		`{"timestamp":"2026-03-01T10:00:05Z","auth_type":"gemini","email":"ops@example.com","status":200,"usage":{"input_tokens":10,"output_tokens":20}}`+"\n",
+		`{"timestamp":"2026-03-01T10:00:06Z","auth_file":"unknown.json","status":200}`+"\n",
+		"not json\n",
+		`{"timestamp":"2026-03-01T10:00:07Z","account_id":"`+codexID+`","status":500}`, // no newline yet
+	)
+
+	tailer := newTestTailer(s, path)
+	defer tailer.Close()
+	processed, err := tailer.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, 2, processed)
+
+	quota, ok := s.GetQuota(codexID)
+	require.True(t, ok)
+	assert.Equal(t, models.ProviderOpenAI, quota.Provider)
+	assert.InDelta(t, 40.0, quota.EffectiveRemainingPct, 0.01)
+
+	entries, err := s.ListSpend("2026-03-01", "2026-03-01")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	for _, e := range entries {
+		switch e.AccountID {
+		case codexID:
+			assert.Equal(t, int64(1000), e.InputTokens)
+			assert.Equal(t, int64(500), e.OutputTokens)
+			assert.InDelta(t, 0.01, e.CostUSD, 1e-9)
+		case geminiID:
+			assert.Equal(t, int64(10), e.InputTokens)
+		default:
+			t.Fatalf("unexpected spend entry %+v", e)
+		}
+	}
+
+	activity, ok := s.GetAccountActivity(geminiID)
+	require.True(t, ok)
+	require.NotNil(t, activity.AccountLastUse)
+
+	// The partial line is handled once it is complete; failed requests add no spend
+	appendLog(t, path, "\n")
+	processed, err = tailer.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, int64(1), spendRequests(t, s, codexID))
+}
+
+func TestLogTailer_RecordSpend(t *testing.T) {
+	s, path := setupLogTail(t)
+	codexID := sanitizeAccountID("codex_dev@example.com")
+	appendLog(t, path,
+		codexLogLine+"\n",
+		`{"timestamp":"2026-03-01T10:00:01Z","account_id":"`+codexID+`","status":200}`+"\n",
+		`{"timestamp":"2026-03-01T10:00:02Z","account_id":"`+codexID+`"}`+"\n",
+	)
+
+	// Off by default: feedback already charges proxied requests
+	tailer := NewLogTailer(s, headers.NewRegistry(), config.LogTailConfig{Path: path})
+	processed, err := tailer.Poll()
+	require.NoError(t, err)
+	tailer.Close()
+	assert.Equal(t, 3, processed)
+	assert.Zero(t, spendRequests(t, s, codexID))
+	activity, ok := s.GetAccountActivity(codexID)
+	require.True(t, ok)
+	assert.NotNil(t, activity.AccountLastUse, "activity is still recorded")
+
+	// Enabled, lines without tokens add no spend
+	s, path = setupLogTail(t)
+	appendLog(t, path,
+		codexLogLine+"\n",
+		`{"timestamp":"2026-03-01T10:00:01Z","account_id":"`+codexID+`","status":200}`+"\n",
+		`{"timestamp":"2026-03-01T10:00:02Z","account_id":"`+codexID+`"}`+"\n",
+	)
+	tailer = newTestTailer(s, path)
+	defer tailer.Close()
+	_, err = tailer.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), spendRequests(t, s, codexID))
+}
+
+func TestLogTailer_ResumesFromCheckpoint(t *testing.T) {
+	s, path := setupLogTail(t)
+	codexID := sanitizeAccountID("codex_dev@example.com")
+	appendLog(t, path, codexLogLine+"\n", codexLogLine+"\n")
+
+	first := newTestTailer(s, path)
+	processed, err := first.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, 2, processed)
+	require.NoError(t, first.Close())
+
+	appendLog(t, path, codexLogLine+"\n")
+	second := newTestTailer(s, path)
+	defer second.Close()
+	processed, err = second.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, int64(3), spendRequests(t, s, codexID))
+
+	cp, ok := s.GetLogCheckpoint(path)
+	require.True(t, ok)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, info.Size(), cp.Offset)
+	assert.NotEmpty(t, cp.Fingerprint)
+}
+
+func TestLogTailer_Rotation(t *testing.T) {
+	s, path := setupLogTail(t)
+	codexID := sanitizeAccountID("codex_dev@example.com")
+	appendLog(t, path, codexLogLine+"\n")
+
+	tailer := newTestTailer(s, path)
+	defer tailer.Close()
+	_, err := tailer.Poll()
+	require.NoError(t, err)
+
+	// Rename rotation: the writer finishes the old file, then starts a new one
+	appendLog(t, path, codexLogLine+"\n")
+	require.NoError(t, os.Rename(path, path+".1"))
+	appendLog(t, path+".1", codexLogLine+"\n")
+	appendLog(t, path, `{"timestamp":"2026-03-02T00:00:00Z","account_id":"`+codexID+`","status":200,"usage":{"input_tokens":10}}`+"\n")
+
+	processed, err := tailer.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, 3, processed)
+	assert.Equal(t, int64(4), spendRequests(t, s, codexID))
+
+	// Truncation in place starts over
+	require.NoError(t, os.Truncate(path, 0))
+	appendLog(t, path, codexLogLine+"\n")
+	processed, err = tailer.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, int64(5), spendRequests(t, s, codexID))
+}
+
+func TestLogTailer_NewFileIgnoresStaleCheckpoint(t *testing.T) {
+	s, path := setupLogTail(t)
+	codexID := sanitizeAccountID("codex_dev@example.com")
+	require.NoError(t, s.SetLogCheckpoint(&models.LogCheckpoint{Path: path, Fingerprint: "deadbeef", Offset: 10}))
+	appendLog(t, path, codexLogLine+"\n")
+
+	tailer := newTestTailer(s, path)
+	defer tailer.Close()
+	processed, err := tailer.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, int64(1), spendRequests(t, s, codexID))
+}
+
+func TestLogTailer_StartAtEndAndMissingFile(t *testing.T) {
+	s, path := setupLogTail(t)
+
+	tailer := NewLogTailer(s, headers.NewRegistry(), config.LogTailConfig{Path: path, StartAtEnd: true, PollInterval: time.Second})
+	defer tailer.Close()
+	processed, err := tailer.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, 0, processed)
+
+	appendLog(t, path, codexLogLine+"\n")
+	fresh := NewLogTailer(s, headers.NewRegistry(), config.LogTailConfig{Path: path, StartAtEnd: true})
+	defer fresh.Close()
+	processed, err = fresh.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, 0, processed)
+
+	appendLog(t, path, codexLogLine+"\n")
+	processed, err = fresh.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+}
+
+func TestLogTailer_OldLinesKeepFresherQuota(t *testing.T) {
+	s, path := setupLogTail(t)
+	codexID := sanitizeAccountID("codex_dev@example.com")
+	polledAt := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	s.SetQuota(codexID, &models.QuotaInfo{
+		AccountID:             codexID,
+		Provider:              models.ProviderOpenAI,
+		EffectiveRemainingPct: 90,
+		CollectedAt:           polledAt,
+	})
+
+	// The logged headers are from the day before the poll
+	appendLog(t, path, codexLogLine+"\n")
+	tailer := newTestTailer(s, path)
+	defer tailer.Close()
+	processed, err := tailer.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+
+	quota, ok := s.GetQuota(codexID)
+	require.True(t, ok)
+	assert.Equal(t, polledAt, quota.CollectedAt)
+	assert.InDelta(t, 90.0, quota.EffectiveRemainingPct, 0.01)
+}
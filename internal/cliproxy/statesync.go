@@ -0,0 +1,337 @@
+package cliproxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/config"
+	"github.com/quotaguard/quotaguard/internal/middleware"
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/store"
+)
+
+// Conflict policies for StateSyncer
+const (
+	ConflictPolicyCLIProxy   = "cliproxy"
+	ConflictPolicyQuotaGuard = "quotaguard"
+	ConflictPolicySkip       = "skip"
+)
+
+// StateBackend reads and writes the disabled flag CLIProxy uses to take an
+// auth out of its rotation.
+type StateBackend interface {
+	// Load returns the disabled flag of each account's auth keyed by account
+	// ID. Accounts CLIProxy does not know about are left out.
+	Load(ctx context.Context, accounts []*models.Account) (map[string]bool, error)
+	// Store sets the disabled flag of an account's auth.
+	Store(ctx context.Context, acc *models.Account, disabled bool) error
+}
+
+// StateConflict is an account changed in CLIProxy since the last sync while
+// QuotaGuard's decision differs.
+type StateConflict struct {
+	AccountID          string    `json:"account_id"`
+	QuotaGuardDisabled bool      `json:"quotaguard_disabled"`
+	CLIProxyDisabled   bool      `json:"cliproxy_disabled"`
+	Resolution         string    `json:"resolution"`
+	DetectedAt         time.Time `json:"detected_at"`
+}
+
+// StateSyncResult summarizes one sync pass.
+type StateSyncResult struct {
+	Pushed    int
+	Pulled    int
+	Conflicts []StateConflict
+}
+
+// StateSyncer keeps the enabled state of imported accounts the same in
+// QuotaGuard and CLIProxy. Accounts QuotaGuard disables or blocks are
+// disabled in CLIProxy; changes made in CLIProxy are compared with the state
+// both sides agreed on at the last sync to tell them apart from QuotaGuard's.
+type StateSyncer struct {
+	store    store.Store
+	backend  StateBackend
+	policy   string
+	interval time.Duration
+
+	mu         sync.Mutex
+	synced     map[string]bool // agreed disabled state after the last sync
+	unresolved map[string]bool // conflicts already reported under the skip policy
+	onConflict func([]StateConflict)
+}
+
+// NewStateSyncer creates a syncer writing through backend.
+func NewStateSyncer(s store.Store, backend StateBackend, cfg config.StateSyncConfig) *StateSyncer {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	policy := cfg.ConflictPolicy
+	if policy == "" {
+		policy = ConflictPolicyCLIProxy
+	}
+	return &StateSyncer{
+		store:      s,
+		backend:    backend,
+		policy:     policy,
+		interval:   interval,
+		synced:     make(map[string]bool),
+		unresolved: make(map[string]bool),
+	}
+}
+
+// SetConflictCallback sets a function called with newly detected conflicts.
+func (ss *StateSyncer) SetConflictCallback(fn func([]StateConflict)) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.onConflict = fn
+}
+
+// Start runs a sync pass and then keeps syncing on the configured interval.
+// An error from the first pass is returned, but syncing continues.
+func (ss *StateSyncer) Start(ctx context.Context) error {
+	_, err := ss.Sync(ctx)
+
+	go func() {
+		ticker := time.NewTicker(ss.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := ss.Sync(ctx); err != nil {
+					log.Printf("cliproxy state sync: %v", err)
+				}
+			}
+		}
+	}()
+
+	return err
+}
+
+// Sync reconciles every account CLIProxy knows about. With no previous sync
+// to compare against, a disabled flag on either side wins.
+func (ss *StateSyncer) Sync(ctx context.Context) (*StateSyncResult, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	accounts := ss.store.ListAccounts()
+	remote, err := ss.backend.Load(ctx, accounts)
+	if err != nil {
+		return nil, fmt.Errorf("load cliproxy state: %w", err)
+	}
+
+	now := time.Now()
+	result := &StateSyncResult{}
+	var lastErr error
+	for _, acc := range accounts {
+		remoteDisabled, ok := remote[acc.ID]
+		if !ok {
+			continue
+		}
+		desired := desiredDisabled(acc, now)
+		last, known := ss.synced[acc.ID]
+
+		switch {
+		case desired == remoteDisabled:
+			ss.synced[acc.ID] = desired
+			delete(ss.unresolved, acc.ID)
+		case !known && desired, known && desired != last:
+			// QuotaGuard changed, or disabled on first sight
+			if err := ss.backend.Store(ctx, acc, desired); err != nil {
+				lastErr = err
+				continue
+			}
+			ss.synced[acc.ID] = desired
+			result.Pushed++
+		case !known:
+			ss.pull(acc, remoteDisabled)
+			ss.synced[acc.ID] = remoteDisabled
+			result.Pulled++
+		default:
+			conflict := StateConflict{
+				AccountID:          acc.ID,
+				QuotaGuardDisabled: desired,
+				CLIProxyDisabled:   remoteDisabled,
+				Resolution:         ss.policy,
+				DetectedAt:         now,
+			}
+			switch ss.policy {
+			case ConflictPolicyQuotaGuard:
+				if err := ss.backend.Store(ctx, acc, desired); err != nil {
+					lastErr = err
+					continue
+				}
+				ss.synced[acc.ID] = desired
+				result.Pushed++
+			case ConflictPolicySkip:
+				if ss.unresolved[acc.ID] {
+					continue
+				}
+				ss.unresolved[acc.ID] = true
+			default:
+				ss.pull(acc, remoteDisabled)
+				ss.synced[acc.ID] = remoteDisabled
+				result.Pulled++
+			}
+			result.Conflicts = append(result.Conflicts, conflict)
+		}
+	}
+
+	if len(result.Conflicts) > 0 && ss.onConflict != nil {
+		sort.Slice(result.Conflicts, func(i, j int) bool {
+			return result.Conflicts[i].AccountID < result.Conflicts[j].AccountID
+		})
+		ss.onConflict(result.Conflicts)
+	}
+	return result, lastErr
+}
+
+// pull applies CLIProxy's state to the QuotaGuard account. Enabling clears
+// any temporary block so the account is routable again.
+func (ss *StateSyncer) pull(acc *models.Account, disabled bool) {
+	updated := *acc
+	updated.Enabled = !disabled
+	updated.UpdatedAt = time.Now()
+	if !disabled {
+		updated.BlockedUntil = nil
+	}
+	ss.store.SetAccount(&updated)
+	if !disabled && acc.BlockedUntil != nil {
+		_ = ss.store.SetAccountBlockedUntil(acc.ID, nil)
+	}
+}
+
+// desiredDisabled reports whether QuotaGuard keeps the account out of routing
+func desiredDisabled(acc *models.Account, now time.Time) bool {
+	return !acc.Enabled || (acc.BlockedUntil != nil && acc.BlockedUntil.After(now))
+}
+
+// FileStateBackend keeps the state as a "disabled" field in the auth JSON
+// files CLIProxy reads.
+type FileStateBackend struct {
+	authsPath string
+}
+
+// NewFileStateBackend creates a backend for auth files in authsPath.
+func NewFileStateBackend(authsPath string) *FileStateBackend {
+	return &FileStateBackend{authsPath: authsPath}
+}
+
+// Load implements StateBackend.
+func (b *FileStateBackend) Load(_ context.Context, accounts []*models.Account) (map[string]bool, error) {
+	dir := filepath.Clean(b.authsPath)
+	result := make(map[string]bool)
+	for _, acc := range accounts {
+		ref := acc.CredentialsRef
+		if ref == "" || filepath.Dir(filepath.Clean(ref)) != dir {
+			continue
+		}
+		data, err := os.ReadFile(ref)
+		if err != nil {
+			continue
+		}
+		var marker struct {
+			Disabled bool `json:"disabled"`
+		}
+		if json.Unmarshal(data, &marker) != nil {
+			continue
+		}
+		result[acc.ID] = marker.Disabled
+	}
+	return result, nil
+}
+
+// Store implements StateBackend. The file is replaced atomically and keeps
+// every other field.
+func (b *FileStateBackend) Store(_ context.Context, acc *models.Account, disabled bool) error {
+	path := acc.CredentialsRef
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	if disabled {
+		fields["disabled"] = json.RawMessage("true")
+	} else {
+		delete(fields, "disabled")
+	}
+	out, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// No .json suffix so discovery never picks up the temp file
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(out, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// APIStateBackend keeps the state through the CLIProxy management API,
+// matching accounts to auth files by file name.
+type APIStateBackend struct {
+	client *middleware.CLIProxyAPIClient
+}
+
+// NewAPIStateBackend creates a backend using client.
+func NewAPIStateBackend(client *middleware.CLIProxyAPIClient) *APIStateBackend {
+	return &APIStateBackend{client: client}
+}
+
+// Load implements StateBackend.
+func (b *APIStateBackend) Load(ctx context.Context, accounts []*models.Account) (map[string]bool, error) {
+	files, err := b.client.ListAuthFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]bool, len(files))
+	for _, f := range files {
+		byName[f.Name] = f.Disabled
+	}
+
+	result := make(map[string]bool)
+	for _, acc := range accounts {
+		if acc.CredentialsRef == "" {
+			continue
+		}
+		if disabled, ok := byName[filepath.Base(acc.CredentialsRef)]; ok {
+			result[acc.ID] = disabled
+		}
+	}
+	return result, nil
+}
+
+// Store implements StateBackend.
+func (b *APIStateBackend) Store(ctx context.Context, acc *models.Account, disabled bool) error {
+	return b.client.SetAuthFileDisabled(ctx, filepath.Base(acc.CredentialsRef), disabled)
+}
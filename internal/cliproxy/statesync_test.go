@@ -0,0 +1,222 @@
+package cliproxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/config"
+	"github.com/quotaguard/quotaguard/internal/middleware"
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStateSync(t *testing.T, policy string) (*store.MemoryStore, *StateSyncer, string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "codex-sync@example.com.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"type":"codex","email":"sync@example.com","access_token":"tok"}`), 0600))
+
+	s := store.NewMemoryStore()
+	s.SetAccount(&models.Account{ID: "codex_sync", Provider: models.ProviderOpenAI, Enabled: true, CredentialsRef: path})
+	s.SetAccount(&models.Account{ID: "from-config", Provider: models.ProviderOpenAI, Enabled: false})
+
+	syncer := NewStateSyncer(s, NewFileStateBackend(dir), config.StateSyncConfig{ConflictPolicy: policy})
+	return s, syncer, path
+}
+
+func readAuthFields(t *testing.T, path string) map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	return fields
+}
+
+func setEnabled(s store.Store, id string, enabled bool) {
+	acc, _ := s.GetAccount(id)
+	acc.Enabled = enabled
+	s.SetAccount(acc)
+}
+
+func TestStateSyncer_PushesQuotaGuardChanges(t *testing.T) {
+	s, syncer, path := setupStateSync(t, "")
+	ctx := context.Background()
+
+	result, err := syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Pushed)
+	assert.NotContains(t, readAuthFields(t, path), "disabled")
+
+	setEnabled(s, "codex_sync", false)
+	result, err = syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Pushed)
+	fields := readAuthFields(t, path)
+	assert.Equal(t, true, fields["disabled"])
+	assert.Equal(t, "tok", fields["access_token"])
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// A temporary block is pushed and lifted once it expires
+	acc, _ := s.GetAccount("codex_sync")
+	acc.Enabled = true
+	blockedUntil := time.Now().Add(50 * time.Millisecond)
+	acc.BlockedUntil = &blockedUntil
+	s.SetAccount(acc)
+	result, err = syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Pushed)
+	assert.Equal(t, true, readAuthFields(t, path)["disabled"])
+
+	time.Sleep(60 * time.Millisecond)
+	result, err = syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Pushed)
+	assert.NotContains(t, readAuthFields(t, path), "disabled")
+
+	// No temp files are left next to the auth file
+	auths, err := DiscoverAuthFiles(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, auths, 1)
+}
+
+func TestStateSyncer_PullsCLIProxyChanges(t *testing.T) {
+	s, syncer, path := setupStateSync(t, ConflictPolicyCLIProxy)
+	ctx := context.Background()
+	var reported []StateConflict
+	syncer.SetConflictCallback(func(conflicts []StateConflict) {
+		reported = append(reported, conflicts...)
+	})
+
+	_, err := syncer.Sync(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, NewFileStateBackend(filepath.Dir(path)).Store(ctx, &models.Account{CredentialsRef: path}, true))
+	result, err := syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Pulled)
+	require.Len(t, reported, 1)
+	assert.Equal(t, StateConflict{
+		AccountID:          "codex_sync",
+		QuotaGuardDisabled: false,
+		CLIProxyDisabled:   true,
+		Resolution:         ConflictPolicyCLIProxy,
+		DetectedAt:         reported[0].DetectedAt,
+	}, reported[0])
+
+	acc, _ := s.GetAccount("codex_sync")
+	assert.False(t, acc.Enabled)
+
+	// Both sides in agreement afterwards
+	result, err = syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, result.Conflicts)
+	assert.Equal(t, 0, result.Pulled+result.Pushed)
+}
+
+func TestStateSyncer_ConflictPolicies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("quotaguard wins", func(t *testing.T) {
+		s, syncer, path := setupStateSync(t, ConflictPolicyQuotaGuard)
+		_, err := syncer.Sync(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, NewFileStateBackend(filepath.Dir(path)).Store(ctx, &models.Account{CredentialsRef: path}, true))
+		result, err := syncer.Sync(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Pushed)
+		assert.Len(t, result.Conflicts, 1)
+		assert.NotContains(t, readAuthFields(t, path), "disabled")
+		acc, _ := s.GetAccount("codex_sync")
+		assert.True(t, acc.Enabled)
+	})
+
+	t.Run("skip reports once", func(t *testing.T) {
+		s, syncer, path := setupStateSync(t, ConflictPolicySkip)
+		_, err := syncer.Sync(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, NewFileStateBackend(filepath.Dir(path)).Store(ctx, &models.Account{CredentialsRef: path}, true))
+		result, err := syncer.Sync(ctx)
+		require.NoError(t, err)
+		assert.Len(t, result.Conflicts, 1)
+		result, err = syncer.Sync(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, result.Conflicts)
+
+		acc, _ := s.GetAccount("codex_sync")
+		assert.True(t, acc.Enabled)
+		assert.Equal(t, true, readAuthFields(t, path)["disabled"])
+
+		// Resolved by hand in QuotaGuard
+		setEnabled(s, "codex_sync", false)
+		result, err = syncer.Sync(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, result.Conflicts)
+		assert.Equal(t, 0, result.Pulled+result.Pushed)
+	})
+}
+
+func TestStateSyncer_FirstSyncDisabledWins(t *testing.T) {
+	s, syncer, path := setupStateSync(t, ConflictPolicyQuotaGuard)
+	ctx := context.Background()
+	require.NoError(t, NewFileStateBackend(filepath.Dir(path)).Store(ctx, &models.Account{CredentialsRef: path}, true))
+
+	result, err := syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Pulled)
+	assert.Empty(t, result.Conflicts)
+	acc, _ := s.GetAccount("codex_sync")
+	assert.False(t, acc.Enabled)
+}
+
+func TestStateSyncer_APIBackend(t *testing.T) {
+	disabled := map[string]bool{"codex-sync@example.com.json": false}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer mgmt-key", r.Header.Get("Authorization"))
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v0/management/auth-files":
+			files := []middleware.ManagedAuthFile{}
+			for name, d := range disabled {
+				files = append(files, middleware.ManagedAuthFile{Name: name, Disabled: d})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"files": files})
+		case r.Method == http.MethodPatch && r.URL.Path == "/v0/management/auth-files/status":
+			var req struct {
+				Name     string `json:"name"`
+				Disabled bool   `json:"disabled"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			disabled[req.Name] = req.Disabled
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	s := store.NewMemoryStore()
+	s.SetAccount(&models.Account{ID: "codex_sync", Provider: models.ProviderOpenAI, Enabled: true, CredentialsRef: "/opt/cliproxyplus/auths/codex-sync@example.com.json"})
+	backend := NewAPIStateBackend(middleware.NewCLIProxyAPIClient(server.URL, middleware.WithAPIKey("mgmt-key")))
+	syncer := NewStateSyncer(s, backend, config.StateSyncConfig{})
+
+	_, err := syncer.Sync(context.Background())
+	require.NoError(t, err)
+
+	setEnabled(s, "codex_sync", false)
+	result, err := syncer.Sync(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Pushed)
+	assert.True(t, disabled["codex-sync@example.com.json"])
+}
@@ -31,18 +31,19 @@ type ActiveCollector struct {
 	retryBackoff  time.Duration
 	workerCount   int
 	jitter        time.Duration
+	minIntervals  map[models.Provider]time.Duration
 
 	// Metrics
 	metrics *metrics.Metrics
 
-	// Circuit breaker
-	cb        *CircuitBreaker
-	cbEnabled bool
+	// Circuit breakers per provider, and per account for auth failures
+	providerBreakers *breakerSet
+	accountBreakers  *breakerSet
+	cbEnabled        bool
 
-	// Adaptive interval control
-	mu              sync.RWMutex
-	currentInterval time.Duration
-	lastQuotaPct    float64
+	// Per-account polling schedule
+	mu       sync.RWMutex
+	schedule *pollScheduler
 
 	// Control
 	running bool
@@ -59,6 +60,10 @@ type CircuitBreaker struct {
 	lastFailureTime  time.Time
 	state            CircuitState
 	metrics          *metrics.Metrics
+
+	// scope and key label the breaker state gauge
+	scope string
+	key   string
 }
 
 // CircuitState represents the state of the circuit breaker
@@ -73,6 +78,20 @@ const (
 	CircuitHalfOpen
 )
 
+// String returns the state name used in health and metrics output
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
 // NewCircuitBreaker creates a new circuit breaker
 func NewCircuitBreaker(failureThreshold int, timeout time.Duration, m *metrics.Metrics) *CircuitBreaker {
 	return &CircuitBreaker{
@@ -93,7 +112,7 @@ func (cb *CircuitBreaker) Allow() bool {
 		return true
 	case CircuitOpen:
 		if time.Since(cb.lastFailureTime) > cb.timeout {
-			cb.state = CircuitHalfOpen
+			cb.transition(CircuitHalfOpen)
 			return true
 		}
 		return false
@@ -110,7 +129,7 @@ func (cb *CircuitBreaker) RecordSuccess() {
 
 	cb.failures = 0
 	oldState := cb.state
-	cb.state = CircuitClosed
+	cb.transition(CircuitClosed)
 
 	if oldState != CircuitClosed && cb.metrics != nil {
 		cb.metrics.RecordCollector("circuit_breaker", "closed", "active")
@@ -127,7 +146,7 @@ func (cb *CircuitBreaker) RecordFailure() {
 
 	if cb.failures >= cb.failureThreshold {
 		oldState := cb.state
-		cb.state = CircuitOpen
+		cb.transition(CircuitOpen)
 		if oldState != CircuitOpen && cb.metrics != nil {
 			cb.metrics.RecordCollector("circuit_breaker", "opened", "active")
 		}
@@ -141,6 +160,35 @@ func (cb *CircuitBreaker) State() CircuitState {
 	return cb.state
 }
 
+// Status reports the breaker for health output
+func (cb *CircuitBreaker) Status() BreakerStatus {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	status := BreakerStatus{
+		Scope:    cb.scope,
+		Key:      cb.key,
+		State:    cb.state.String(),
+		Failures: cb.failures,
+	}
+	if cb.state == CircuitOpen {
+		retryAt := cb.lastFailureTime.Add(cb.timeout)
+		status.RetryAt = &retryAt
+	}
+	return status
+}
+
+// transition changes state and updates the state gauge; callers hold mu
+func (cb *CircuitBreaker) transition(state CircuitState) {
+	if cb.state == state {
+		return
+	}
+	cb.state = state
+	if cb.metrics != nil && cb.scope != "" {
+		cb.metrics.SetCollectorBreakerState(cb.scope, cb.key, int(state))
+	}
+}
+
 // Config holds configuration for the active collector
 type Config struct {
 	Interval      time.Duration
@@ -153,6 +201,9 @@ type Config struct {
 	CBTimeout     time.Duration
 	WorkerCount   int
 	Jitter        time.Duration
+	// ProviderMinIntervals is the shortest interval between polls of one
+	// account per provider. Nil uses the built-in politeness limits.
+	ProviderMinIntervals map[models.Provider]time.Duration
 }
 
 // DefaultConfig returns default configuration
@@ -173,23 +224,29 @@ func DefaultConfig() Config {
 
 // NewActiveCollector creates a new active collector
 func NewActiveCollector(s store.Store, fetcher QuotaFetcher, cfg Config, m *metrics.Metrics) *ActiveCollector {
+	minIntervals := cfg.ProviderMinIntervals
+	if minIntervals == nil {
+		minIntervals = defaultProviderMinIntervals
+	}
 	ac := &ActiveCollector{
-		store:           s,
-		fetcher:         fetcher,
-		interval:        cfg.Interval,
-		adaptive:        cfg.Adaptive,
-		timeout:         cfg.Timeout,
-		retryAttempts:   cfg.RetryAttempts,
-		retryBackoff:    cfg.RetryBackoff,
-		workerCount:     cfg.WorkerCount,
-		jitter:          cfg.Jitter,
-		cbEnabled:       cfg.CBEnabled,
-		currentInterval: cfg.Interval,
-		metrics:         m,
+		store:         s,
+		fetcher:       fetcher,
+		interval:      cfg.Interval,
+		adaptive:      cfg.Adaptive,
+		timeout:       cfg.Timeout,
+		retryAttempts: cfg.RetryAttempts,
+		retryBackoff:  cfg.RetryBackoff,
+		workerCount:   cfg.WorkerCount,
+		jitter:        cfg.Jitter,
+		minIntervals:  minIntervals,
+		cbEnabled:     cfg.CBEnabled,
+		schedule:      newPollScheduler(),
+		metrics:       m,
 	}
 
 	if cfg.CBEnabled {
-		ac.cb = NewCircuitBreaker(cfg.CBThreshold, cfg.CBTimeout, m)
+		ac.providerBreakers = newBreakerSet(BreakerScopeProvider, cfg.CBThreshold, cfg.CBTimeout, m)
+		ac.accountBreakers = newBreakerSet(BreakerScopeAccount, cfg.CBThreshold, cfg.CBTimeout, m)
 	}
 
 	return ac
@@ -239,15 +296,13 @@ func (ac *ActiveCollector) IsRunning() bool {
 	return ac.running
 }
 
-// pollLoop is the main polling loop
+// pollLoop is the main polling loop. It wakes when the earliest account
+// is due, and at least once per base interval to pick up new accounts.
 func (ac *ActiveCollector) pollLoop(ctx context.Context) {
 	defer ac.wg.Done()
 
-	// Do initial poll
-	ac.poll(ctx)
-
-	ticker := time.NewTicker(ac.getInterval())
-	defer ticker.Stop()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
 
 	for {
 		select {
@@ -255,36 +310,64 @@ func (ac *ActiveCollector) pollLoop(ctx context.Context) {
 			return
 		case <-ac.stopCh:
 			return
-		case <-ticker.C:
+		case <-timer.C:
 			ac.poll(ctx)
-			// Update ticker if interval changed
-			if ac.adaptive {
-				ticker.Reset(ac.getInterval())
-			}
+			timer.Reset(ac.nextWait(time.Now()))
 		}
 	}
 }
 
-// poll fetches quota for all enabled accounts
-func (ac *ActiveCollector) poll(ctx context.Context) {
-	if ac.cbEnabled && !ac.cb.Allow() {
-		return
+// nextWait returns how long the loop sleeps before checking for due accounts
+func (ac *ActiveCollector) nextWait(now time.Time) time.Duration {
+	wait := ac.interval
+	if next, ok := ac.schedule.nextWake(); ok {
+		if until := next.Sub(now); until < wait {
+			wait = until
+		}
+	}
+	if floor := ac.minInterval(); wait < floor {
+		wait = floor
 	}
+	return wait
+}
 
+// poll fetches quota for the enabled accounts that are due. Accounts whose
+// provider or own breaker is open are pushed back instead of polled.
+func (ac *ActiveCollector) poll(ctx context.Context) {
 	accounts := ac.store.ListEnabledAccounts()
+	now := time.Now()
+	ac.schedule.sync(accounts, now)
 	if len(accounts) == 0 {
 		return
 	}
 
-	successCount := 0
-	failCount := 0
+	byID := make(map[string]*models.Account, len(accounts))
+	for _, acc := range accounts {
+		byID[acc.ID] = acc
+	}
+	var due []*models.Account
+	for _, id := range ac.schedule.due(now) {
+		acc, ok := byID[id]
+		if !ok {
+			continue
+		}
+		if !ac.breakersAllow(acc) {
+			ac.schedule.postpone(acc.ID, now.Add(ac.interval), ScheduleReasonCircuitOpen)
+			continue
+		}
+		due = append(due, acc)
+	}
+	if len(due) == 0 {
+		return
+	}
+
 	if ac.workerCount <= 0 {
 		ac.workerCount = 4
 	}
 
 	jobs := make(chan *models.Account)
+	outcomes := make(chan pollOutcome, len(due))
 	var wg sync.WaitGroup
-	var mu sync.Mutex
 
 	for i := 0; i < ac.workerCount; i++ {
 		wg.Add(1)
@@ -292,6 +375,7 @@ func (ac *ActiveCollector) poll(ctx context.Context) {
 			defer wg.Done()
 			for acc := range jobs {
 				if acc.BlockedUntil != nil && time.Now().Before(*acc.BlockedUntil) {
+					ac.schedule.postpone(acc.ID, *acc.BlockedUntil, ScheduleReasonBlocked)
 					if os.Getenv("QUOTAGUARD_COLLECTOR_DEBUG") == "1" {
 						log.Printf("collector: skip account=%s provider=%s reason=blocked_until", acc.ID, acc.Provider)
 					}
@@ -302,62 +386,136 @@ func (ac *ActiveCollector) poll(ctx context.Context) {
 				}
 				quota, err := ac.fetchWithRetry(ctx, acc.ID)
 				if err != nil {
-					if rl := new(RateLimitError); errors.As(err, &rl) {
-						blockedUntil := time.Now().Add(rl.RetryAfter)
-						_ = ac.store.SetAccountBlockedUntil(acc.ID, &blockedUntil)
-					}
-					mu.Lock()
-					failCount++
-					mu.Unlock()
-					if ac.metrics != nil {
-						ac.metrics.RecordCollector("fetch", "failure", "active")
-					}
+					class := ClassifyError(err)
+					ac.recordFailure(acc, class, err)
+					ac.scheduleFailure(acc, err)
+					outcomes <- pollOutcome{account: acc, class: class}
 					if os.Getenv("QUOTAGUARD_COLLECTOR_DEBUG") == "1" {
-						log.Printf("collector: fetch failed account=%s provider=%s err=%v", acc.ID, acc.Provider, err)
+						log.Printf("collector: fetch failed account=%s provider=%s class=%s err=%v", acc.ID, acc.Provider, class, err)
 					}
 					continue
 				}
 
-				mu.Lock()
-				successCount++
-				mu.Unlock()
 				ac.store.SetQuota(acc.ID, quota)
+				ac.recordSuccess(acc, quota)
+				ac.scheduleSuccess(acc, quota)
+				outcomes <- pollOutcome{account: acc}
 				if os.Getenv("QUOTAGUARD_COLLECTOR_DEBUG") == "1" {
 					log.Printf("collector: quota updated account=%s provider=%s remaining=%.2f%%", acc.ID, acc.Provider, quota.EffectiveRemainingPct)
 				}
-				if ac.metrics != nil {
-					ac.metrics.RecordCollector("fetch", "success", "active")
-					ac.metrics.RecordQuotaUtilization(acc.ID, string(acc.Provider), "all", quota.EffectiveRemainingPct)
-				}
 			}
 		}(i)
 	}
 
-	for _, acc := range accounts {
+	for _, acc := range due {
 		jobs <- acc
 	}
 	close(jobs)
 	wg.Wait()
+	close(outcomes)
+
+	successCount, failCount := ac.updateBreakers(outcomes)
 
-	// Update circuit breaker
-	if ac.cbEnabled {
-		if failCount > 0 && successCount == 0 {
-			ac.cb.RecordFailure()
-		} else if successCount > 0 {
-			ac.cb.RecordSuccess()
+	if ac.metrics != nil {
+		ac.metrics.RecordCollector("poll", "success", "active")
+	}
+	if os.Getenv("QUOTAGUARD_COLLECTOR_DEBUG") == "1" {
+		log.Printf("collector: poll complete due=%d success=%d fail=%d", len(due), successCount, failCount)
+	}
+}
+
+// pollOutcome is the result of polling one account; class is empty on success
+type pollOutcome struct {
+	account *models.Account
+	class   models.PollErrorClass
+}
+
+// breakersAllow reports whether neither the account's provider breaker nor
+// its own auth breaker is open.
+func (ac *ActiveCollector) breakersAllow(acc *models.Account) bool {
+	if !ac.cbEnabled {
+		return true
+	}
+	if !ac.providerBreakers.get(string(acc.Provider)).Allow() {
+		return false
+	}
+	if cb, ok := ac.accountBreakers.lookup(acc.ID); ok && !cb.Allow() {
+		return false
+	}
+	return true
+}
+
+// updateBreakers feeds a round's outcomes to the breakers. A provider
+// breaker counts a failure only when none of its accounts succeeded, and
+// ignores auth and rate-limit errors, which belong to single accounts.
+func (ac *ActiveCollector) updateBreakers(outcomes <-chan pollOutcome) (successCount, failCount int) {
+	type tally struct{ success, failure int }
+	providers := map[models.Provider]*tally{}
+
+	for outcome := range outcomes {
+		acc := outcome.account
+		t := providers[acc.Provider]
+		if t == nil {
+			t = &tally{}
+			providers[acc.Provider] = t
+		}
+
+		if outcome.class == "" {
+			successCount++
+			t.success++
+			if ac.cbEnabled {
+				if cb, ok := ac.accountBreakers.lookup(acc.ID); ok {
+					cb.RecordSuccess()
+				}
+			}
+			continue
+		}
+
+		failCount++
+		if countsAgainstProvider(outcome.class) {
+			t.failure++
+		}
+		if ac.cbEnabled && outcome.class == models.PollErrorAuth {
+			ac.accountBreakers.get(acc.ID).RecordFailure()
 		}
 	}
 
-	// Update adaptive interval
-	if ac.adaptive {
-		ac.updateAdaptiveInterval(accounts)
+	if !ac.cbEnabled {
+		return successCount, failCount
 	}
+	for provider, t := range providers {
+		cb := ac.providerBreakers.get(string(provider))
+		if t.success > 0 {
+			cb.RecordSuccess()
+		} else if t.failure > 0 {
+			cb.RecordFailure()
+		}
+	}
+	return successCount, failCount
+}
 
+// recordFailure stores the classified error on the account
+func (ac *ActiveCollector) recordFailure(acc *models.Account, class models.PollErrorClass, err error) {
+	pollErr := &models.PollError{Class: class, Message: err.Error(), At: time.Now()}
+	if storeErr := ac.store.SetAccountPollError(acc.ID, pollErr); storeErr != nil {
+		log.Printf("collector: failed to record poll error account=%s: %v", acc.ID, storeErr)
+	}
 	if ac.metrics != nil {
-		ac.metrics.RecordCollector("poll", "success", "active")
+		ac.metrics.RecordCollector("fetch", "failure", "active")
+		ac.metrics.RecordCollectorError(string(acc.Provider), string(class))
 	}
-	if os.Getenv("QUOTAGUARD_COLLECTOR_DEBUG") == "1" {
-		log.Printf("collector: poll complete success=%d fail=%d", successCount, failCount)
+}
+
+// recordSuccess clears a previously recorded error
+func (ac *ActiveCollector) recordSuccess(acc *models.Account, quota *models.QuotaInfo) {
+	if acc.LastPollError != nil {
+		if err := ac.store.SetAccountPollError(acc.ID, nil); err != nil {
+			log.Printf("collector: failed to clear poll error account=%s: %v", acc.ID, err)
+		}
+	}
+	if ac.metrics != nil {
+		ac.metrics.RecordCollector("fetch", "success", "active")
+		ac.metrics.RecordQuotaUtilization(acc.ID, string(acc.Provider), "all", quota.EffectiveRemainingPct)
 	}
 }
 
@@ -381,6 +539,10 @@ func (ac *ActiveCollector) fetchWithRetry(ctx context.Context, accountID string)
 		if rl := new(RateLimitError); errors.As(err, &rl) {
 			return nil, rl
 		}
+		// Retrying cannot fix rejected credentials
+		if ClassifyError(err) == models.PollErrorAuth {
+			return nil, err
+		}
 
 		lastErr = err
 	}
@@ -388,74 +550,150 @@ func (ac *ActiveCollector) fetchWithRetry(ctx context.Context, accountID string)
 	return nil, fmt.Errorf("failed after %d attempts: %w", ac.retryAttempts+1, lastErr)
 }
 
-// updateAdaptiveInterval adjusts polling interval based on quota levels
-func (ac *ActiveCollector) updateAdaptiveInterval(accounts []*models.Account) {
-	if len(accounts) == 0 {
-		return
-	}
+// scheduleSuccess plans the next poll of an account after a fetch
+func (ac *ActiveCollector) scheduleSuccess(acc *models.Account, quota *models.QuotaInfo) {
+	now := time.Now()
+	prev, _ := ac.schedule.item(acc.ID)
+	interval, reason := ac.adaptiveInterval(acc, quota, prev, now)
+	pct := quota.EffectiveRemainingPct
+	ac.schedule.reschedule(acc.ID, now, interval, reason, 0, &pct)
+}
 
-	// Calculate average effective remaining percentage
-	var totalPct float64
-	count := 0
+// scheduleFailure backs off exponentially, or waits out a provider rate limit
+func (ac *ActiveCollector) scheduleFailure(acc *models.Account, err error) {
+	now := time.Now()
+	prev, _ := ac.schedule.item(acc.ID)
+	failures := prev.Failures + 1
 
-	for _, acc := range accounts {
-		quota, ok := ac.store.GetQuota(acc.ID)
-		if !ok {
-			continue
+	steps := failures - 1
+	if steps > maxBackoff {
+		steps = maxBackoff
+	}
+	interval := ac.clampInterval(ac.interval * time.Duration(1<<steps))
+	reason := ScheduleReasonBackoff
+
+	if rl := new(RateLimitError); errors.As(err, &rl) {
+		blockedUntil := now.Add(rl.RetryAfter)
+		_ = ac.store.SetAccountBlockedUntil(acc.ID, &blockedUntil)
+		if rl.RetryAfter > interval {
+			interval = rl.RetryAfter
+			reason = ScheduleReasonBlocked
 		}
-		totalPct += quota.EffectiveRemainingPct
-		count++
 	}
+	if floor := ac.minIntervals[acc.Provider]; interval < floor {
+		interval = floor
+	}
+	ac.schedule.reschedule(acc.ID, now, interval, reason, failures, nil)
+}
 
-	if count == 0 {
-		return
+// adaptiveInterval picks an account's next poll interval from its own
+// remaining quota, its burn rate since the last poll, recent routing
+// activity and time to reset, within the provider's politeness limit.
+func (ac *ActiveCollector) adaptiveInterval(acc *models.Account, quota *models.QuotaInfo, prev scheduleItem, now time.Time) (time.Duration, string) {
+	if !ac.adaptive {
+		return ac.interval, ScheduleReasonFixed
+	}
+
+	// Lower quota = more frequent polling
+	pct := quota.EffectiveRemainingPct
+	var interval time.Duration
+	var reason string
+	switch {
+	case pct < 20:
+		interval, reason = ac.interval/4, ScheduleReasonCritical
+	case pct < 50:
+		interval, reason = ac.interval/2, ScheduleReasonLow
+	case pct < 80:
+		interval, reason = ac.interval, ScheduleReasonNormal
+	default:
+		interval, reason = ac.interval*2, ScheduleReasonHealthy
 	}
 
-	avgPct := totalPct / float64(count)
+	// Poll at least four times before the quota runs out at the current pace
+	if prev.hasPct && prev.LastPoll != nil {
+		elapsed := now.Sub(*prev.LastPoll)
+		if drop := prev.lastPct - pct; drop > 0 && elapsed > 0 {
+			untilEmpty := time.Duration(pct / drop * float64(elapsed))
+			if quarter := untilEmpty / 4; quarter < interval {
+				interval, reason = quarter, ScheduleReasonBurning
+			}
+		}
+	}
 
-	ac.mu.Lock()
-	defer ac.mu.Unlock()
+	// Accounts the router is using change faster than idle ones
+	if activity, ok := ac.store.GetAccountActivity(acc.ID); ok && activity.AccountLastUse != nil {
+		switch since := now.Sub(*activity.AccountLastUse); {
+		case since < activeWindow:
+			interval /= 2
+		case since > idleWindow:
+			interval *= 2
+		}
+	} else {
+		interval *= 2
+	}
+	interval = ac.clampInterval(interval)
 
-	ac.lastQuotaPct = avgPct
+	// Catch the refill right after a low account's window resets
+	if pct < 50 && quota.CriticalDimension != nil && quota.CriticalDimension.ResetAt != nil {
+		untilReset := quota.CriticalDimension.ResetAt.Sub(now) + time.Second
+		if untilReset > 0 && untilReset < interval {
+			interval, reason = untilReset, ScheduleReasonReset
+		}
+	}
 
-	// Adjust interval based on quota level
-	// Lower quota = more frequent polling
-	switch {
-	case avgPct < 20: // Critical - poll frequently
-		ac.currentInterval = ac.interval / 4
-	case avgPct < 50: // Warning - poll more often
-		ac.currentInterval = ac.interval / 2
-	case avgPct < 80: // Normal
-		ac.currentInterval = ac.interval
-	default: // Healthy - poll less frequently
-		ac.currentInterval = ac.interval * 2
+	if floor := ac.minIntervals[acc.Provider]; interval < floor {
+		interval = floor
 	}
+	return interval, reason
+}
 
-	// Clamp interval
-	if ac.currentInterval < 5*time.Second {
-		ac.currentInterval = 5 * time.Second
+// minInterval is the shortest adaptive interval: a quarter of the base
+// interval, but never below 5s unless the base itself is that short.
+func (ac *ActiveCollector) minInterval() time.Duration {
+	floor := ac.interval / 4
+	if floor > 5*time.Second {
+		floor = 5 * time.Second
 	}
-	if ac.currentInterval > 5*time.Minute {
-		ac.currentInterval = 5 * time.Minute
+	if floor <= 0 {
+		floor = time.Millisecond
 	}
+	return floor
 }
 
-// getInterval returns the current polling interval
-func (ac *ActiveCollector) getInterval() time.Duration {
-	ac.mu.RLock()
-	defer ac.mu.RUnlock()
-	return ac.currentInterval
+// clampInterval keeps an adaptive interval between the minimum and the
+// larger of 5m and four base intervals.
+func (ac *ActiveCollector) clampInterval(d time.Duration) time.Duration {
+	ceiling := ac.interval * 4
+	if ceiling < 5*time.Minute {
+		ceiling = 5 * time.Minute
+	}
+	if floor := ac.minInterval(); d < floor {
+		return floor
+	}
+	if d > ceiling {
+		return ceiling
+	}
+	return d
 }
 
-// GetInterval returns the current polling interval (public)
-func (ac *ActiveCollector) GetInterval() time.Duration {
-	return ac.getInterval()
+// Schedule returns the planned next poll of every enabled account
+func (ac *ActiveCollector) Schedule() []PollSchedule {
+	return ac.schedule.snapshot()
 }
 
-// GetCircuitBreakerState returns the circuit breaker state
+// GetCircuitBreakerState returns the most severe provider breaker state
 func (ac *ActiveCollector) GetCircuitBreakerState() CircuitState {
-	if !ac.cbEnabled || ac.cb == nil {
+	if !ac.cbEnabled || ac.providerBreakers == nil {
 		return CircuitClosed
 	}
-	return ac.cb.State()
+	worst := CircuitClosed
+	for _, cb := range ac.providerBreakers.all() {
+		switch cb.State() {
+		case CircuitOpen:
+			return CircuitOpen
+		case CircuitHalfOpen:
+			worst = CircuitHalfOpen
+		}
+	}
+	return worst
 }
@@ -3,9 +3,12 @@ package collector
 import (
 	"context"
 	"errors"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/quotaguard/quotaguard/internal/metrics"
 	"github.com/quotaguard/quotaguard/internal/models"
 	"github.com/quotaguard/quotaguard/internal/store"
 	"github.com/stretchr/testify/assert"
@@ -111,7 +114,7 @@ func TestNewActiveCollector(t *testing.T) {
 	assert.Equal(t, fetcher, ac.fetcher)
 	assert.Equal(t, cfg.Interval, ac.interval)
 	assert.True(t, ac.cbEnabled)
-	assert.NotNil(t, ac.cb)
+	assert.NotNil(t, ac.providerBreakers)
 }
 
 func TestActiveCollector_StartStop(t *testing.T) {
@@ -265,38 +268,209 @@ func TestActiveCollector_AdaptiveInterval(t *testing.T) {
 	cfg.Adaptive = true
 	ac := NewActiveCollector(s, fetcher, cfg, nil)
 
-	// Test critical level (< 20%)
-	s.SetAccount(&models.Account{ID: "acc-1", Enabled: true})
-	s.SetQuota("acc-1", &models.QuotaInfo{
-		AccountID:             "acc-1",
-		EffectiveRemainingPct: 10.0,
+	now := time.Now()
+	acc := &models.Account{ID: "acc-1", Enabled: true}
+	s.SetAccount(acc)
+	// Routed 30 minutes ago: neither active nor idle
+	require.NoError(t, s.RecordAccountActivity("acc-1", "", now.Add(-30*time.Minute)))
+
+	tests := []struct {
+		pct      float64
+		interval time.Duration
+		reason   string
+	}{
+		{10, 15 * time.Second, ScheduleReasonCritical},
+		{30, 30 * time.Second, ScheduleReasonLow},
+		{60, 60 * time.Second, ScheduleReasonNormal},
+		{90, 120 * time.Second, ScheduleReasonHealthy},
+	}
+	for _, tt := range tests {
+		quota := &models.QuotaInfo{AccountID: "acc-1", EffectiveRemainingPct: tt.pct}
+		interval, reason := ac.adaptiveInterval(acc, quota, scheduleItem{}, now)
+		assert.Equal(t, tt.interval, interval, "pct %.0f", tt.pct)
+		assert.Equal(t, tt.reason, reason, "pct %.0f", tt.pct)
+	}
+}
+
+func TestActiveCollector_AdaptiveIntervalPerAccount(t *testing.T) {
+	s := store.NewMemoryStore()
+	cfg := DefaultConfig()
+	cfg.Interval = 60 * time.Second
+	cfg.Adaptive = true
+	ac := NewActiveCollector(s, &MockQuotaFetcher{}, cfg, nil)
+	now := time.Now()
+
+	t.Run("activity", func(t *testing.T) {
+		busy := &models.Account{ID: "busy", Enabled: true}
+		idle := &models.Account{ID: "idle", Enabled: true}
+		require.NoError(t, s.RecordAccountActivity("busy", "", now.Add(-time.Minute)))
+		require.NoError(t, s.RecordAccountActivity("idle", "", now.Add(-2*time.Hour)))
+		quota := &models.QuotaInfo{EffectiveRemainingPct: 60}
+
+		interval, _ := ac.adaptiveInterval(busy, quota, scheduleItem{}, now)
+		assert.Equal(t, 30*time.Second, interval)
+		interval, _ = ac.adaptiveInterval(idle, quota, scheduleItem{}, now)
+		assert.Equal(t, 120*time.Second, interval)
+
+		never := &models.Account{ID: "never", Enabled: true}
+		interval, _ = ac.adaptiveInterval(never, quota, scheduleItem{}, now)
+		assert.Equal(t, 120*time.Second, interval)
+	})
+
+	t.Run("burn rate", func(t *testing.T) {
+		acc := &models.Account{ID: "burn", Enabled: true}
+		require.NoError(t, s.RecordAccountActivity("burn", "", now.Add(-30*time.Minute)))
+		last := now.Add(-time.Minute)
+		prev := scheduleItem{PollSchedule: PollSchedule{LastPoll: &last}, lastPct: 90, hasPct: true}
+
+		// 90% -> 85% in a minute empties in 17 minutes: poll every ~4m15s,
+		// which is above the healthy interval, so nothing changes
+		interval, reason := ac.adaptiveInterval(acc, &models.QuotaInfo{EffectiveRemainingPct: 85}, prev, now)
+		assert.Equal(t, 120*time.Second, interval)
+		assert.Equal(t, ScheduleReasonHealthy, reason)
+
+		// 90% -> 70% in a minute empties in 3.5 minutes: poll every 52.5s
+		interval, reason = ac.adaptiveInterval(acc, &models.QuotaInfo{EffectiveRemainingPct: 70}, prev, now)
+		assert.Equal(t, ScheduleReasonBurning, reason)
+		assert.InDelta(t, float64(52500*time.Millisecond), float64(interval), float64(time.Second))
 	})
-	ac.updateAdaptiveInterval([]*models.Account{{ID: "acc-1", Enabled: true}})
-	assert.Equal(t, 15*time.Second, ac.GetInterval())
 
-	// Test warning level (20-50%)
-	s.SetQuota("acc-1", &models.QuotaInfo{
-		AccountID:             "acc-1",
-		EffectiveRemainingPct: 30.0,
+	t.Run("reset soon", func(t *testing.T) {
+		acc := &models.Account{ID: "reset", Enabled: true}
+		require.NoError(t, s.RecordAccountActivity("reset", "", now.Add(-30*time.Minute)))
+		resetAt := now.Add(5 * time.Second)
+		quota := &models.QuotaInfo{
+			EffectiveRemainingPct: 30,
+			CriticalDimension:     &models.Dimension{ResetAt: &resetAt},
+		}
+
+		interval, reason := ac.adaptiveInterval(acc, quota, scheduleItem{}, now)
+		assert.Equal(t, 6*time.Second, interval)
+		assert.Equal(t, ScheduleReasonReset, reason)
 	})
-	ac.updateAdaptiveInterval([]*models.Account{{ID: "acc-1", Enabled: true}})
-	assert.Equal(t, 30*time.Second, ac.GetInterval())
 
-	// Test normal level (50-80%)
-	s.SetQuota("acc-1", &models.QuotaInfo{
-		AccountID:             "acc-1",
-		EffectiveRemainingPct: 60.0,
+	t.Run("provider politeness", func(t *testing.T) {
+		acc := &models.Account{ID: "polite", Provider: models.ProviderQwen, Enabled: true}
+		require.NoError(t, s.RecordAccountActivity("polite", "", now))
+
+		interval, reason := ac.adaptiveInterval(acc, &models.QuotaInfo{EffectiveRemainingPct: 5}, scheduleItem{}, now)
+		assert.Equal(t, time.Minute, interval)
+		assert.Equal(t, ScheduleReasonCritical, reason)
 	})
-	ac.updateAdaptiveInterval([]*models.Account{{ID: "acc-1", Enabled: true}})
-	assert.Equal(t, 60*time.Second, ac.GetInterval())
 
-	// Test healthy level (> 80%)
-	s.SetQuota("acc-1", &models.QuotaInfo{
-		AccountID:             "acc-1",
-		EffectiveRemainingPct: 90.0,
+	t.Run("fixed when not adaptive", func(t *testing.T) {
+		fixed := NewActiveCollector(s, &MockQuotaFetcher{}, Config{Interval: time.Minute}, nil)
+		interval, reason := fixed.adaptiveInterval(&models.Account{ID: "x"}, &models.QuotaInfo{EffectiveRemainingPct: 5}, scheduleItem{}, now)
+		assert.Equal(t, time.Minute, interval)
+		assert.Equal(t, ScheduleReasonFixed, reason)
 	})
-	ac.updateAdaptiveInterval([]*models.Account{{ID: "acc-1", Enabled: true}})
-	assert.Equal(t, 120*time.Second, ac.GetInterval())
+}
+
+func TestActiveCollector_ScheduleFailureBackoff(t *testing.T) {
+	s := store.NewMemoryStore()
+	acc := &models.Account{ID: "acc-1", Enabled: true}
+	s.SetAccount(acc)
+
+	cfg := DefaultConfig()
+	cfg.Interval = 10 * time.Second
+	ac := NewActiveCollector(s, &MockQuotaFetcher{}, cfg, nil)
+	ac.schedule.sync([]*models.Account{acc}, time.Now())
+
+	for _, want := range []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second} {
+		ac.scheduleFailure(acc, errors.New("boom"))
+		item, ok := ac.schedule.item("acc-1")
+		require.True(t, ok)
+		assert.Equal(t, want.Seconds(), item.IntervalSeconds)
+		assert.Equal(t, ScheduleReasonBackoff, item.Reason)
+	}
+
+	ac.scheduleFailure(acc, &RateLimitError{RetryAfter: 10 * time.Minute})
+	item, _ := ac.schedule.item("acc-1")
+	assert.Equal(t, ScheduleReasonBlocked, item.Reason)
+	assert.Equal(t, 4, item.Failures)
+	stored, _ := s.GetAccount("acc-1")
+	require.NotNil(t, stored.BlockedUntil)
+
+	ac.scheduleSuccess(acc, &models.QuotaInfo{EffectiveRemainingPct: 60})
+	item, _ = ac.schedule.item("acc-1")
+	assert.Equal(t, 0, item.Failures)
+}
+
+func TestActiveCollector_PollsOnlyDueAccounts(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.SetAccount(&models.Account{ID: "acc-1", Enabled: true})
+	s.SetAccount(&models.Account{ID: "acc-2", Enabled: true})
+
+	var mu sync.Mutex
+	calls := map[string]int{}
+	fetcher := &MockQuotaFetcher{
+		fetchFunc: func(ctx context.Context, accountID string) (*models.QuotaInfo, error) {
+			mu.Lock()
+			calls[accountID]++
+			mu.Unlock()
+			return &models.QuotaInfo{AccountID: accountID, EffectiveRemainingPct: 90}, nil
+		},
+	}
+
+	cfg := DefaultConfig()
+	cfg.Interval = time.Minute
+	cfg.Jitter = 0
+	ac := NewActiveCollector(s, fetcher, cfg, nil)
+
+	ac.poll(context.Background())
+	assert.Equal(t, map[string]int{"acc-1": 1, "acc-2": 1}, calls)
+
+	// Nothing is due until the schedule says so
+	ac.poll(context.Background())
+	assert.Equal(t, map[string]int{"acc-1": 1, "acc-2": 1}, calls)
+
+	ac.schedule.postpone("acc-2", time.Now().Add(-time.Second), ScheduleReasonInitial)
+	ac.poll(context.Background())
+	assert.Equal(t, map[string]int{"acc-1": 1, "acc-2": 2}, calls)
+
+	schedule := ac.Schedule()
+	require.Len(t, schedule, 2)
+	for _, entry := range schedule {
+		assert.NotNil(t, entry.LastPoll)
+		assert.True(t, entry.NextPoll.After(time.Now()))
+	}
+
+	// Removed accounts leave the schedule
+	require.True(t, s.DeleteAccount("acc-1"))
+	ac.poll(context.Background())
+	schedule = ac.Schedule()
+	require.Len(t, schedule, 1)
+	assert.Equal(t, "acc-2", schedule[0].AccountID)
+}
+
+func TestPollScheduler_Order(t *testing.T) {
+	ps := newPollScheduler()
+	now := time.Now()
+	accounts := []*models.Account{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	ps.sync(accounts, now)
+
+	assert.Equal(t, []string{"a", "b", "c"}, sortedStrings(ps.due(now)))
+
+	ps.reschedule("a", now, 3*time.Minute, ScheduleReasonNormal, 0, nil)
+	ps.reschedule("b", now, time.Minute, ScheduleReasonNormal, 0, nil)
+	ps.reschedule("c", now, 2*time.Minute, ScheduleReasonNormal, 0, nil)
+
+	next, ok := ps.nextWake()
+	require.True(t, ok)
+	assert.Equal(t, now.Add(time.Minute), next)
+	assert.Empty(t, ps.due(now))
+	assert.Equal(t, []string{"b", "c"}, ps.due(now.Add(2*time.Minute)))
+
+	snapshot := ps.snapshot()
+	require.Len(t, snapshot, 3)
+	assert.Equal(t, "b", snapshot[0].AccountID)
+	assert.Equal(t, "a", snapshot[2].AccountID)
+}
+
+func sortedStrings(values []string) []string {
+	out := append([]string(nil), values...)
+	sort.Strings(out)
+	return out
 }
 
 func TestActiveCollector_CircuitBreaker(t *testing.T) {
@@ -333,6 +507,111 @@ func TestActiveCollector_CircuitBreaker(t *testing.T) {
 	}
 }
 
+func TestActiveCollector_ProviderBreakers(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.SetAccount(&models.Account{ID: "codex-1", Provider: models.ProviderOpenAI, Enabled: true})
+	s.SetAccount(&models.Account{ID: "gem-1", Provider: models.ProviderGemini, Enabled: true})
+	s.SetAccount(&models.Account{ID: "gem-2", Provider: models.ProviderGemini, Enabled: true})
+	s.SetAccount(&models.Account{ID: "codex-revoked", Provider: models.ProviderOpenAI, Enabled: true})
+
+	fetcher := &MockQuotaFetcher{
+		fetchFunc: func(ctx context.Context, accountID string) (*models.QuotaInfo, error) {
+			switch accountID {
+			case "gem-1", "gem-2":
+				return nil, &StatusError{Operation: "gemini", StatusCode: 503}
+			case "codex-revoked":
+				return nil, &StatusError{Operation: "codex usage", StatusCode: 401}
+			default:
+				return &models.QuotaInfo{AccountID: accountID, EffectiveRemainingPct: 90}, nil
+			}
+		},
+	}
+
+	cfg := DefaultConfig()
+	cfg.CBThreshold = 2
+	cfg.RetryAttempts = 0
+	cfg.ProviderMinIntervals = map[models.Provider]time.Duration{}
+	ac := NewActiveCollector(s, fetcher, cfg, metrics.NewMetrics("test"))
+
+	pollAll := func() {
+		for _, acc := range s.ListEnabledAccounts() {
+			ac.schedule.postpone(acc.ID, time.Now().Add(-time.Second), ScheduleReasonInitial)
+		}
+		ac.poll(context.Background())
+	}
+	pollAll()
+	pollAll()
+
+	// A broken Gemini endpoint trips its own breaker even though Codex is healthy
+	gemini, ok := ac.providerBreakers.lookup(string(models.ProviderGemini))
+	require.True(t, ok)
+	assert.Equal(t, CircuitOpen, gemini.State())
+	openai, _ := ac.providerBreakers.lookup(string(models.ProviderOpenAI))
+	assert.Equal(t, CircuitClosed, openai.State())
+
+	// Auth failures trip the account breaker, not the provider one
+	revoked, ok := ac.accountBreakers.lookup("codex-revoked")
+	require.True(t, ok)
+	assert.Equal(t, CircuitOpen, revoked.State())
+
+	acc, _ := s.GetAccount("gem-1")
+	require.NotNil(t, acc.LastPollError)
+	assert.Equal(t, models.PollErrorUpstream, acc.LastPollError.Class)
+	acc, _ = s.GetAccount("codex-revoked")
+	require.NotNil(t, acc.LastPollError)
+	assert.Equal(t, models.PollErrorAuth, acc.LastPollError.Class)
+	acc, _ = s.GetAccount("codex-1")
+	assert.Nil(t, acc.LastPollError)
+
+	// Open breakers push accounts back instead of polling them
+	pollAll()
+	item, _ := ac.schedule.item("gem-1")
+	assert.Equal(t, ScheduleReasonCircuitOpen, item.Reason)
+	item, _ = ac.schedule.item("codex-revoked")
+	assert.Equal(t, ScheduleReasonCircuitOpen, item.Reason)
+
+	health := ac.Health()
+	assert.Equal(t, CollectorHealthDegraded, health.Status)
+	assert.Equal(t, 2, health.FailingAccounts[models.PollErrorUpstream])
+	assert.Equal(t, 1, health.FailingAccounts[models.PollErrorAuth])
+	keys := []string{}
+	for _, b := range health.Breakers {
+		keys = append(keys, b.Scope+"/"+b.Key+"="+b.State)
+	}
+	assert.ElementsMatch(t, []string{"provider/gemini=open", "provider/openai=closed", "account/codex-revoked=open"}, keys)
+	assert.Equal(t, CircuitOpen, ac.GetCircuitBreakerState())
+}
+
+func TestActiveCollector_ClearsPollErrorOnSuccess(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.SetAccount(&models.Account{ID: "acc-1", Provider: models.ProviderQwen, Enabled: true})
+
+	fail := true
+	fetcher := &MockQuotaFetcher{
+		fetchFunc: func(ctx context.Context, accountID string) (*models.QuotaInfo, error) {
+			if fail {
+				return nil, &ParseError{Message: "qwen response missing quota"}
+			}
+			return &models.QuotaInfo{AccountID: accountID, EffectiveRemainingPct: 50}, nil
+		},
+	}
+	cfg := DefaultConfig()
+	cfg.RetryAttempts = 0
+	ac := NewActiveCollector(s, fetcher, cfg, nil)
+
+	ac.poll(context.Background())
+	acc, _ := s.GetAccount("acc-1")
+	require.NotNil(t, acc.LastPollError)
+	assert.Equal(t, models.PollErrorParse, acc.LastPollError.Class)
+
+	fail = false
+	ac.schedule.postpone("acc-1", time.Now().Add(-time.Second), ScheduleReasonInitial)
+	ac.poll(context.Background())
+	acc, _ = s.GetAccount("acc-1")
+	assert.Nil(t, acc.LastPollError)
+	assert.Equal(t, CollectorHealthOK, ac.Health().Status)
+}
+
 func TestActiveCollector_GetCircuitBreakerState_Disabled(t *testing.T) {
 	s := store.NewMemoryStore()
 	fetcher := &MockQuotaFetcher{}
@@ -0,0 +1,151 @@
+package collector
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/metrics"
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// Breaker scopes
+const (
+	BreakerScopeProvider = "provider"
+	BreakerScopeAccount  = "account"
+)
+
+// Collector health states
+const (
+	CollectorHealthOK       = "ok"
+	CollectorHealthDegraded = "degraded"
+	CollectorHealthDown     = "down"
+)
+
+// BreakerStatus describes one collector circuit breaker
+type BreakerStatus struct {
+	Scope    string     `json:"scope"`
+	Key      string     `json:"key"`
+	State    string     `json:"state"`
+	Failures int        `json:"failures"`
+	RetryAt  *time.Time `json:"retry_at,omitempty"`
+}
+
+// CollectorHealth summarizes the active collector for /health
+type CollectorHealth struct {
+	Status          string                        `json:"status"`
+	Breakers        []BreakerStatus               `json:"breakers"`
+	FailingAccounts map[models.PollErrorClass]int `json:"failing_accounts"`
+}
+
+// breakerSet lazily creates one circuit breaker per key
+type breakerSet struct {
+	mu        sync.Mutex
+	scope     string
+	threshold int
+	timeout   time.Duration
+	metrics   *metrics.Metrics
+	breakers  map[string]*CircuitBreaker
+}
+
+func newBreakerSet(scope string, threshold int, timeout time.Duration, m *metrics.Metrics) *breakerSet {
+	return &breakerSet{
+		scope:     scope,
+		threshold: threshold,
+		timeout:   timeout,
+		metrics:   m,
+		breakers:  make(map[string]*CircuitBreaker),
+	}
+}
+
+// get returns the breaker for key, creating a closed one if needed
+func (bs *breakerSet) get(key string) *CircuitBreaker {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	cb, ok := bs.breakers[key]
+	if !ok {
+		cb = NewCircuitBreaker(bs.threshold, bs.timeout, bs.metrics)
+		cb.scope = bs.scope
+		cb.key = key
+		bs.breakers[key] = cb
+		if bs.metrics != nil {
+			bs.metrics.SetCollectorBreakerState(bs.scope, key, int(CircuitClosed))
+		}
+	}
+	return cb
+}
+
+// lookup returns the breaker for key without creating one
+func (bs *breakerSet) lookup(key string) (*CircuitBreaker, bool) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	cb, ok := bs.breakers[key]
+	return cb, ok
+}
+
+// all returns the breakers ordered by key
+func (bs *breakerSet) all() []*CircuitBreaker {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	keys := make([]string, 0, len(bs.breakers))
+	for key := range bs.breakers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	result := make([]*CircuitBreaker, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, bs.breakers[key])
+	}
+	return result
+}
+
+// Health reports breaker states and accounts whose last poll failed. The
+// collector is down when every provider breaker is open and degraded when
+// any breaker is not closed or any account is failing.
+func (ac *ActiveCollector) Health() CollectorHealth {
+	health := CollectorHealth{
+		Status:          CollectorHealthOK,
+		Breakers:        []BreakerStatus{},
+		FailingAccounts: map[models.PollErrorClass]int{},
+	}
+
+	for _, acc := range ac.store.ListEnabledAccounts() {
+		if acc.LastPollError != nil {
+			health.FailingAccounts[acc.LastPollError.Class]++
+		}
+	}
+	if len(health.FailingAccounts) > 0 {
+		health.Status = CollectorHealthDegraded
+	}
+
+	if !ac.cbEnabled {
+		return health
+	}
+
+	providers, open := 0, 0
+	for _, cb := range ac.providerBreakers.all() {
+		status := cb.Status()
+		health.Breakers = append(health.Breakers, status)
+		providers++
+		if status.State == CircuitOpen.String() {
+			open++
+		}
+		if status.State != CircuitClosed.String() {
+			health.Status = CollectorHealthDegraded
+		}
+	}
+	for _, cb := range ac.accountBreakers.all() {
+		// Closed account breakers are noise; only report the tripped ones
+		if status := cb.Status(); status.State != CircuitClosed.String() {
+			health.Breakers = append(health.Breakers, status)
+			health.Status = CollectorHealthDegraded
+		}
+	}
+	if providers > 0 && open == providers {
+		health.Status = CollectorHealthDown
+	}
+
+	return health
+}
@@ -0,0 +1,112 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// StatusError is a non-2xx response from a provider endpoint.
+type StatusError struct {
+	Operation  string
+	StatusCode int
+	Detail     string
+}
+
+func (e *StatusError) Error() string {
+	if e.Detail != "" {
+		return fmt.Sprintf("%s status %d: %s", e.Operation, e.StatusCode, e.Detail)
+	}
+	return fmt.Sprintf("%s status %d", e.Operation, e.StatusCode)
+}
+
+// CredentialsError means an account's credentials are missing or expired.
+type CredentialsError struct {
+	Message string
+}
+
+func (e *CredentialsError) Error() string {
+	return e.Message
+}
+
+func credentialsErrorf(format string, args ...interface{}) error {
+	return &CredentialsError{Message: fmt.Sprintf(format, args...)}
+}
+
+// ParseError means a provider answered but the response held no usable quota.
+type ParseError struct {
+	Message string
+	Err     error
+}
+
+func (e *ParseError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ClassifyError maps a fetch error to the class recorded on the account.
+func ClassifyError(err error) models.PollErrorClass {
+	if err == nil {
+		return ""
+	}
+
+	if rl := new(RateLimitError); errors.As(err, &rl) {
+		return models.PollErrorRateLimited
+	}
+	if ce := new(CredentialsError); errors.As(err, &ce) {
+		return models.PollErrorAuth
+	}
+	if se := new(StatusError); errors.As(err, &se) {
+		switch {
+		case se.StatusCode == http.StatusUnauthorized, se.StatusCode == http.StatusForbidden:
+			return models.PollErrorAuth
+		case se.StatusCode == http.StatusBadRequest && se.Operation == "oauth":
+			// Token endpoints answer 400 invalid_grant for revoked refresh tokens
+			return models.PollErrorAuth
+		case se.StatusCode == http.StatusTooManyRequests:
+			return models.PollErrorRateLimited
+		case se.StatusCode >= 500:
+			return models.PollErrorUpstream
+		default:
+			return models.PollErrorUnknown
+		}
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, new(*ParseError)) || errors.As(err, &syntaxErr) || errors.As(err, &typeErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return models.PollErrorParse
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) || errors.As(err, new(*url.Error)) {
+		return models.PollErrorNetwork
+	}
+
+	return models.PollErrorUnknown
+}
+
+// countsAgainstProvider reports whether a failure says something about the
+// provider endpoint rather than about one account.
+func countsAgainstProvider(class models.PollErrorClass) bool {
+	switch class {
+	case models.PollErrorAuth, models.PollErrorRateLimited:
+		return false
+	default:
+		return true
+	}
+}
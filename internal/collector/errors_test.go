@@ -0,0 +1,48 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+	var syntaxErr error = json.Unmarshal([]byte("{"), &struct{}{})
+
+	tests := []struct {
+		name string
+		err  error
+		want models.PollErrorClass
+	}{
+		{"nil", nil, ""},
+		{"rate limit", &RateLimitError{Message: "codex rate limit"}, models.PollErrorRateLimited},
+		{"missing credentials", credentialsErrorf("missing refresh_token"), models.PollErrorAuth},
+		{"unauthorized", &StatusError{Operation: "codex usage", StatusCode: 401}, models.PollErrorAuth},
+		{"forbidden", &StatusError{Operation: "antigravity", StatusCode: 403}, models.PollErrorAuth},
+		{"revoked refresh token", &StatusError{Operation: "oauth", StatusCode: 400, Detail: "invalid_grant"}, models.PollErrorAuth},
+		{"upstream", &StatusError{Operation: "gemini", StatusCode: 503}, models.PollErrorUpstream},
+		{"other 4xx", &StatusError{Operation: "qwen", StatusCode: 404}, models.PollErrorUnknown},
+		{"wrapped upstream", fmt.Errorf("failed after 3 attempts: %w", &StatusError{Operation: "qwen", StatusCode: 502}), models.PollErrorUpstream},
+		{"parse", &ParseError{Message: "antigravity quota not found"}, models.PollErrorParse},
+		{"json syntax", syntaxErr, models.PollErrorParse},
+		{"timeout", context.DeadlineExceeded, models.PollErrorNetwork},
+		{"url error", &url.Error{Op: "Get", URL: "https://example.com", Err: errors.New("connection refused")}, models.PollErrorNetwork},
+		{"unknown", errors.New("boom"), models.PollErrorUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClassifyError(tt.err))
+		})
+	}
+}
+
+func TestStatusError_Message(t *testing.T) {
+	assert.Equal(t, "codex usage status 500", (&StatusError{Operation: "codex usage", StatusCode: 500}).Error())
+	assert.Equal(t, "oauth status 400: invalid_grant", (&StatusError{Operation: "oauth", StatusCode: 400, Detail: "invalid_grant"}).Error())
+}
@@ -0,0 +1,347 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/config"
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// maxFetcherBody bounds the response size read from declared endpoints.
+const maxFetcherBody = 1 << 20
+
+// HTTPFetcher polls a quota endpoint declared in configuration and maps
+// the JSON response to dimensions.
+type HTTPFetcher struct {
+	cfg        config.FetcherConfig
+	url        *template.Template
+	body       *template.Template
+	headers    map[string]*template.Template
+	dimensions []fetcherDimension
+	client     httpDoer
+}
+
+type fetcherDimension struct {
+	cfg       config.FetcherDimensionConfig
+	items     []interface{}
+	name      []interface{}
+	limit     []interface{}
+	used      []interface{}
+	remaining []interface{}
+	reset     []interface{}
+}
+
+// fetcherTemplateData is exposed to url, body and header templates.
+type fetcherTemplateData struct {
+	*models.AccountCredentials
+	Account *models.Account
+}
+
+// NewHTTPFetcher compiles a validated fetcher definition.
+func NewHTTPFetcher(cfg config.FetcherConfig, client httpDoer) (*HTTPFetcher, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("fetcher %q: %w", cfg.Name, err)
+	}
+
+	f := &HTTPFetcher{
+		cfg:     cfg,
+		headers: make(map[string]*template.Template, len(cfg.Headers)),
+		client:  client,
+	}
+
+	var err error
+	if f.url, err = parseFetcherTemplate(cfg.Name+".url", cfg.URL); err != nil {
+		return nil, err
+	}
+	if cfg.Body != "" {
+		if f.body, err = parseFetcherTemplate(cfg.Name+".body", cfg.Body); err != nil {
+			return nil, err
+		}
+	}
+	for key, value := range cfg.Headers {
+		if f.headers[key], err = parseFetcherTemplate(cfg.Name+".headers."+key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, dc := range cfg.Dimensions {
+		d := fetcherDimension{cfg: dc}
+		paths := []struct {
+			expr string
+			dst  *[]interface{}
+		}{
+			{dc.Items, &d.items},
+			{dc.NamePath, &d.name},
+			{dc.Limit, &d.limit},
+			{dc.Used, &d.used},
+			{dc.Remaining, &d.remaining},
+			{dc.Reset, &d.reset},
+		}
+		for _, p := range paths {
+			if p.expr == "" {
+				continue
+			}
+			if *p.dst, err = compilePath(p.expr); err != nil {
+				return nil, fmt.Errorf("fetcher %q dimensions[%d]: %w", cfg.Name, i, err)
+			}
+		}
+		f.dimensions = append(f.dimensions, d)
+	}
+
+	return f, nil
+}
+
+func parseFetcherTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("fetcher template %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// Name returns the credentials type the fetcher serves.
+func (f *HTTPFetcher) Name() string {
+	return f.cfg.Name
+}
+
+// Fetch requests the endpoint for an account and builds its quota.
+func (f *HTTPFetcher) Fetch(ctx context.Context, acc *models.Account, creds *models.AccountCredentials) (*models.QuotaInfo, error) {
+	req, err := f.newRequest(ctx, acc, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, rateLimitErrorFromHeaders(resp.Header, f.cfg.Name+" rate limit")
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFetcherBody))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &StatusError{Operation: f.cfg.Name, StatusCode: resp.StatusCode}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var payload interface{}
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, &ParseError{Message: f.cfg.Name + ": invalid JSON response", Err: err}
+	}
+
+	return f.quotaFromPayload(acc, payload, time.Now())
+}
+
+func (f *HTTPFetcher) newRequest(ctx context.Context, acc *models.Account, creds *models.AccountCredentials) (*http.Request, error) {
+	data := fetcherTemplateData{AccountCredentials: creds, Account: acc}
+
+	rawURL, err := executeTemplate(f.url, data)
+	if err != nil {
+		return nil, err
+	}
+	var body io.Reader
+	if f.body != nil {
+		text, err := executeTemplate(f.body, data)
+		if err != nil {
+			return nil, err
+		}
+		body = strings.NewReader(text)
+	}
+
+	secret := fetcherSecret(creds, f.cfg.Auth.Field)
+	if f.cfg.Auth.Style != "none" && secret == "" {
+		return nil, credentialsErrorf("missing %s", f.cfg.Auth.Field)
+	}
+	if f.cfg.Auth.Style == "query" {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid url: %w", f.cfg.Name, err)
+		}
+		q := u.Query()
+		q.Set(f.cfg.Auth.Name, secret)
+		u.RawQuery = q.Encode()
+		rawURL = u.String()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, f.cfg.Method, rawURL, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, tmpl := range f.headers {
+		value, err := executeTemplate(tmpl, data)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(key, value)
+	}
+
+	switch f.cfg.Auth.Style {
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+secret)
+	case "header":
+		req.Header.Set(f.cfg.Auth.Name, secret)
+	}
+	return req, nil
+}
+
+func executeTemplate(tmpl *template.Template, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func fetcherSecret(creds *models.AccountCredentials, field string) string {
+	switch field {
+	case "api_key":
+		return strings.TrimSpace(creds.APIKey)
+	case "session_token":
+		return strings.TrimSpace(creds.SessionToken)
+	default:
+		return strings.TrimSpace(creds.AccessToken)
+	}
+}
+
+// quotaFromPayload applies the dimension mappings to a decoded response.
+func (f *HTTPFetcher) quotaFromPayload(acc *models.Account, payload interface{}, now time.Time) (*models.QuotaInfo, error) {
+	var dims models.DimensionSlice
+	for _, d := range f.dimensions {
+		items := []interface{}{payload}
+		if d.items != nil {
+			value, ok := lookupPath(payload, d.items)
+			arr, isArr := value.([]interface{})
+			if !ok || !isArr {
+				continue
+			}
+			items = arr
+		}
+		for _, item := range items {
+			if dim, ok := d.build(item, f.cfg.Confidence, now); ok {
+				dims = append(dims, dim)
+			}
+		}
+	}
+	if len(dims) == 0 {
+		return nil, &ParseError{Message: f.cfg.Name + " response has no quota values"}
+	}
+
+	quota := models.NewQuotaInfo()
+	quota.Provider = acc.Provider
+	quota.AccountID = acc.ID
+	quota.Tier = acc.Tier
+	quota.Dimensions = dims
+	quota.Source = models.SourcePolling
+	quota.Confidence = f.cfg.Confidence
+	quota.CollectedAt = now
+	quota.UpdateEffective()
+	return quota, nil
+}
+
+// build reads one dimension from item. Missing values are derived from the
+// other two (limit = used + remaining), and items without a limit are skipped.
+func (d fetcherDimension) build(item interface{}, confidence float64, now time.Time) (models.Dimension, bool) {
+	number := func(steps []interface{}) (float64, bool) {
+		if steps == nil {
+			return 0, false
+		}
+		value, ok := lookupPath(item, steps)
+		if !ok {
+			return 0, false
+		}
+		return readFloatOK(value)
+	}
+
+	limit, hasLimit := number(d.limit)
+	used, hasUsed := number(d.used)
+	remaining, hasRemaining := number(d.remaining)
+	switch {
+	case !hasLimit && hasUsed && hasRemaining:
+		limit = used + remaining
+	case !hasLimit:
+		return models.Dimension{}, false
+	case !hasRemaining && hasUsed:
+		remaining = limit - used
+	case !hasRemaining:
+		return models.Dimension{}, false
+	}
+	if limit <= 0 {
+		return models.Dimension{}, false
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+	if remaining > limit {
+		remaining = limit
+	}
+
+	name := d.cfg.Name
+	if d.name != nil {
+		if value, ok := lookupPath(item, d.name); ok {
+			if s := fmt.Sprint(value); s != "" {
+				name = s
+			}
+		}
+	}
+
+	var resetAt *time.Time
+	if d.reset != nil {
+		if value, ok := lookupPath(item, d.reset); ok {
+			resetAt = parseResetValue(value, now)
+		}
+	}
+
+	return models.Dimension{
+		Name:       name,
+		Type:       models.DimensionType(d.cfg.Type),
+		Limit:      int64(limit),
+		Used:       int64(limit) - int64(remaining),
+		Remaining:  int64(remaining),
+		ResetAt:    resetAt,
+		Semantics:  models.WindowSemantics(d.cfg.Semantics),
+		Source:     models.SourcePolling,
+		Confidence: confidence,
+	}, true
+}
+
+// parseResetValue reads a reset given as a timestamp string, a duration
+// string or a unix/relative number.
+func parseResetValue(value interface{}, now time.Time) *time.Time {
+	switch v := value.(type) {
+	case json.Number:
+		return parseResetTime(v.String())
+	case float64:
+		return parseResetTime(strconv.FormatInt(int64(v), 10))
+	case string:
+		v = strings.TrimSpace(v)
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return &t
+		}
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			t := now.Add(d)
+			return &t
+		}
+		return parseResetTime(v)
+	}
+	return nil
+}
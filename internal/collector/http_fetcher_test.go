@@ -0,0 +1,221 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/config"
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const gatewayResponse = `{
+  "data": {
+    "limits": [
+      {"model": "gpt-4o", "limit": 100, "remaining": 40, "reset_at": "2026-03-01T12:00:00Z"},
+      {"model": "gpt-4o-mini", "limit": 1000, "remaining": 900, "reset_in": "30s"},
+      {"model": "broken"}
+    ]
+  },
+  "budget": {"used": "5.5", "remaining": 14}
+}`
+
+func gatewayFetcherConfig(url string) config.FetcherConfig {
+	return config.FetcherConfig{
+		Name:    "internal-gw",
+		URL:     url + "/v1/quota/{{.ProviderAccountID}}",
+		Headers: map[string]string{"X-Project": "{{.ProjectID}}"},
+		Dimensions: []config.FetcherDimensionConfig{
+			{Type: "rpm", Items: "$.data.limits", NamePath: "model", Limit: "limit", Remaining: "remaining", Reset: "reset_at"},
+			{Type: "BUDGET", Name: "budget", Used: "$.budget.used", Remaining: "$.budget.remaining"},
+		},
+	}
+}
+
+func TestHTTPFetcher_Fetch(t *testing.T) {
+	var gotPath, gotAuth, gotProject string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		gotProject = r.Header.Get("X-Project")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(gatewayResponse))
+	}))
+	defer srv.Close()
+
+	f, err := NewHTTPFetcher(gatewayFetcherConfig(srv.URL), http.DefaultClient)
+	require.NoError(t, err)
+
+	acc := &models.Account{ID: "gw-1", Provider: models.ProviderOpenAI}
+	creds := &models.AccountCredentials{Type: "internal-gw", AccessToken: "tok", ProviderAccountID: "team-7", ProjectID: "proj"}
+	quota, err := f.Fetch(context.Background(), acc, creds)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/v1/quota/team-7", gotPath)
+	assert.Equal(t, "Bearer tok", gotAuth)
+	assert.Equal(t, "proj", gotProject)
+
+	assert.Equal(t, "gw-1", quota.AccountID)
+	assert.Equal(t, models.SourcePolling, quota.Source)
+	require.Len(t, quota.Dimensions, 3)
+
+	first := quota.Dimensions[0]
+	assert.Equal(t, "gpt-4o", first.Name)
+	assert.Equal(t, models.DimensionRPM, first.Type)
+	assert.Equal(t, int64(100), first.Limit)
+	assert.Equal(t, int64(60), first.Used)
+	assert.Equal(t, models.WindowFixed, first.Semantics)
+	require.NotNil(t, first.ResetAt)
+	assert.Equal(t, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), first.ResetAt.UTC())
+	assert.Equal(t, "gpt-4o-mini", quota.Dimensions[1].Name)
+
+	budget := quota.Dimensions[2]
+	assert.Equal(t, models.DimensionBudget, budget.Type)
+	assert.Equal(t, int64(19), budget.Limit)
+	assert.Equal(t, int64(14), budget.Remaining)
+	assert.InDelta(t, 40.0, quota.EffectiveRemainingPct, 0.001)
+}
+
+func TestHTTPFetcher_AuthStyles(t *testing.T) {
+	var gotKey, gotQuery, gotMethod, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("X-Api-Key")
+		gotQuery = r.URL.Query().Get("key")
+		gotMethod = r.Method
+		buf := make([]byte, 64)
+		n, _ := r.Body.Read(buf)
+		gotBody = string(buf[:n])
+		_, _ = w.Write([]byte(`{"limit": 10, "used": 2}`))
+	}))
+	defer srv.Close()
+
+	acc := &models.Account{ID: "acc-1"}
+	creds := &models.AccountCredentials{APIKey: "k-1", Email: "a@example.com"}
+	dims := []config.FetcherDimensionConfig{{Type: "RPD", Limit: "limit", Used: "used"}}
+
+	f, err := NewHTTPFetcher(config.FetcherConfig{
+		Name: "hdr", URL: srv.URL, Method: "post", Body: `{"user":"{{.Email}}"}`,
+		Auth: config.FetcherAuthConfig{Style: "header", Name: "X-Api-Key"}, Dimensions: dims,
+	}, http.DefaultClient)
+	require.NoError(t, err)
+	quota, err := f.Fetch(context.Background(), acc, creds)
+	require.NoError(t, err)
+	assert.Equal(t, "k-1", gotKey)
+	assert.Equal(t, http.MethodPost, gotMethod)
+	assert.Equal(t, `{"user":"a@example.com"}`, gotBody)
+	assert.Equal(t, int64(8), quota.Dimensions[0].Remaining)
+
+	f, err = NewHTTPFetcher(config.FetcherConfig{
+		Name: "qry", URL: srv.URL + "/q?x=1",
+		Auth: config.FetcherAuthConfig{Style: "query", Name: "key"}, Dimensions: dims,
+	}, http.DefaultClient)
+	require.NoError(t, err)
+	_, err = f.Fetch(context.Background(), acc, creds)
+	require.NoError(t, err)
+	assert.Equal(t, "k-1", gotQuery)
+
+	// The configured credential field must be present
+	f, err = NewHTTPFetcher(config.FetcherConfig{Name: "bearer", URL: srv.URL, Dimensions: dims}, http.DefaultClient)
+	require.NoError(t, err)
+	_, err = f.Fetch(context.Background(), acc, creds)
+	assert.ErrorContains(t, err, "missing access_token")
+}
+
+func TestHTTPFetcher_Errors(t *testing.T) {
+	status := http.StatusInternalServerError
+	body := `{}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "12")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	f, err := NewHTTPFetcher(gatewayFetcherConfig(srv.URL), http.DefaultClient)
+	require.NoError(t, err)
+	acc := &models.Account{ID: "gw-1"}
+	creds := &models.AccountCredentials{AccessToken: "tok"}
+
+	_, err = f.Fetch(context.Background(), acc, creds)
+	assert.ErrorContains(t, err, "internal-gw status 500")
+
+	status = http.StatusTooManyRequests
+	_, err = f.Fetch(context.Background(), acc, creds)
+	var rle *RateLimitError
+	require.ErrorAs(t, err, &rle)
+	assert.InDelta(t, 12, rle.RetryAfter.Seconds(), 1)
+
+	status = http.StatusOK
+	_, err = f.Fetch(context.Background(), acc, creds)
+	assert.ErrorContains(t, err, "no quota values")
+
+	body = `not json`
+	_, err = f.Fetch(context.Background(), acc, creds)
+	assert.ErrorContains(t, err, "invalid JSON")
+
+	_, err = NewHTTPFetcher(config.FetcherConfig{Name: "bad", URL: "{{.Nope", Dimensions: []config.FetcherDimensionConfig{{Type: "RPM", Used: "u"}}}, http.DefaultClient)
+	assert.Error(t, err)
+}
+
+func TestProviderFetcher_DeclaredFetcher(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(gatewayResponse))
+	}))
+	defer srv.Close()
+
+	s := store.NewMemoryStore()
+	acc := &models.Account{ID: "gw-1", Provider: models.ProviderOther, Enabled: true}
+	s.SetAccount(acc)
+	require.NoError(t, s.SetAccountCredentials(acc.ID, &models.AccountCredentials{Type: "Internal-GW", AccessToken: "tok"}))
+
+	pf := NewProviderFetcher(s)
+	_, err := pf.FetchQuota(context.Background(), acc.ID)
+	assert.ErrorContains(t, err, "unsupported auth type")
+
+	require.NoError(t, pf.RegisterFetchers([]config.FetcherConfig{gatewayFetcherConfig(srv.URL)}))
+	quota, err := pf.FetchQuota(context.Background(), acc.ID)
+	require.NoError(t, err)
+	assert.Len(t, quota.Dimensions, 3)
+}
+
+func TestLookupPath(t *testing.T) {
+	doc := map[string]interface{}{
+		"a": map[string]interface{}{
+			"list":  []interface{}{1.0, map[string]interface{}{"x-y": "z"}},
+			"empty": nil,
+		},
+	}
+	tests := []struct {
+		path     string
+		expected interface{}
+		ok       bool
+	}{
+		{"$.a.list[0]", 1.0, true},
+		{"a.list[1][\"x-y\"]", "z", true},
+		{"a.list[1]['x-y']", "z", true},
+		{"$.a.list[-1].x-y", "z", true},
+		{"a.empty", nil, true},
+		{"a.list[5]", nil, false},
+		{"a.missing", nil, false},
+		{"a.list.key", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			steps, err := compilePath(tt.path)
+			require.NoError(t, err)
+			value, ok := lookupPath(doc, steps)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+
+	_, err := compilePath("a[1")
+	assert.Error(t, err)
+	_, err = compilePath("a[x]")
+	assert.Error(t, err)
+}
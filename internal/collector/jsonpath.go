@@ -0,0 +1,80 @@
+package collector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// compilePath splits a JSONPath-like expression into object keys and array
+// indexes. Supported forms: $.data.limits[0].remaining, items[2], ["x-key"].
+func compilePath(path string) ([]interface{}, error) {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "$")
+
+	var steps []interface{}
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in path %q", path)
+			}
+			inner := strings.TrimSpace(path[i+1 : i+end])
+			i += end + 1
+			if unquoted, err := strconv.Unquote(inner); err == nil {
+				steps = append(steps, unquoted)
+				continue
+			}
+			if len(inner) >= 2 && inner[0] == '\'' && inner[len(inner)-1] == '\'' {
+				steps = append(steps, inner[1:len(inner)-1])
+				continue
+			}
+			idx, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %q in path", inner)
+			}
+			steps = append(steps, idx)
+		default:
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			steps = append(steps, path[i:i+end])
+			i += end
+		}
+	}
+	return steps, nil
+}
+
+// lookupPath resolves compiled path steps against a decoded JSON value.
+// Negative indexes count from the end of an array.
+func lookupPath(value interface{}, steps []interface{}) (interface{}, bool) {
+	for _, step := range steps {
+		switch key := step.(type) {
+		case string:
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if value, ok = obj[key]; !ok {
+				return nil, false
+			}
+		case int:
+			arr, ok := value.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			if key < 0 {
+				key += len(arr)
+			}
+			if key < 0 || key >= len(arr) {
+				return nil, false
+			}
+			value = arr[key]
+		}
+	}
+	return value, true
+}
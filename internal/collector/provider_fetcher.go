@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -15,24 +14,41 @@ import (
 	"strings"
 	"time"
 
+	"github.com/quotaguard/quotaguard/internal/config"
 	"github.com/quotaguard/quotaguard/internal/models"
 	"github.com/quotaguard/quotaguard/internal/store"
 )
 
 // ProviderFetcher implements QuotaFetcher for multiple providers.
 type ProviderFetcher struct {
-	store  store.Store
-	client *RotatingClient
+	store    store.Store
+	client   *RotatingClient
+	declared map[string]*HTTPFetcher
 }
 
 // NewProviderFetcher creates a new provider-aware fetcher.
 func NewProviderFetcher(s store.Store) *ProviderFetcher {
 	return &ProviderFetcher{
-		store:  s,
-		client: NewRotatingClient(),
+		store:    s,
+		client:   NewRotatingClient(),
+		declared: make(map[string]*HTTPFetcher),
 	}
 }
 
+// RegisterFetchers adds declarative HTTP fetchers. A fetcher is selected
+// when an account's credentials type equals its name, taking precedence
+// over the built-in providers.
+func (pf *ProviderFetcher) RegisterFetchers(cfgs []config.FetcherConfig) error {
+	for _, cfg := range cfgs {
+		f, err := NewHTTPFetcher(cfg, pf.client)
+		if err != nil {
+			return err
+		}
+		pf.declared[f.Name()] = f
+	}
+	return nil
+}
+
 // FetchQuota fetches quota for a given account ID.
 func (pf *ProviderFetcher) FetchQuota(ctx context.Context, accountID string) (*models.QuotaInfo, error) {
 	acc, ok := pf.store.GetAccount(accountID)
@@ -42,7 +58,11 @@ func (pf *ProviderFetcher) FetchQuota(ctx context.Context, accountID string) (*m
 
 	creds, ok := pf.store.GetAccountCredentials(accountID)
 	if !ok {
-		return nil, fmt.Errorf("missing credentials for account: %s", accountID)
+		return nil, credentialsErrorf("missing credentials for account: %s", accountID)
+	}
+
+	if f, ok := pf.declared[strings.ToLower(creds.Type)]; ok {
+		return f.Fetch(ctx, acc, creds)
 	}
 
 	switch strings.ToLower(creds.Type) {
@@ -54,7 +74,7 @@ func (pf *ProviderFetcher) FetchQuota(ctx context.Context, accountID string) (*m
 		return pf.fetchGemini(ctx, acc, creds)
 	case "claude", "claude-code", "claude_code":
 		if strings.TrimSpace(creds.SessionToken) == "" && strings.TrimSpace(creds.AccessToken) == "" {
-			return nil, fmt.Errorf("missing claude auth token")
+			return nil, credentialsErrorf("missing claude auth token")
 		}
 		return claudeEstimatedQuota(acc), nil
 	case "qwen", "dashscope":
@@ -97,7 +117,7 @@ func (pf *ProviderFetcher) fetchOpenAI(ctx context.Context, acc *models.Account,
 		}
 	} else {
 		if jwt == "" || accountID == "" {
-			return nil, fmt.Errorf("missing session_token or access_token/account_id")
+			return nil, credentialsErrorf("missing session_token or access_token/account_id")
 		}
 	}
 
@@ -120,7 +140,7 @@ func (pf *ProviderFetcher) fetchOpenAI(ctx context.Context, acc *models.Account,
 			}
 			return quotaFromNumbers(acc, limit, used, headerReset, 0.6), nil
 		}
-		return nil, fmt.Errorf("codex usage response missing limits")
+		return nil, &ParseError{Message: "codex usage response missing limits"}
 	}
 
 	remaining := limit - used
@@ -144,7 +164,7 @@ func (pf *ProviderFetcher) codexJWT(ctx context.Context, sessionToken string) (s
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return "", "", fmt.Errorf("codex session status %d", resp.StatusCode)
+		return "", "", &StatusError{Operation: "codex session", StatusCode: resp.StatusCode}
 	}
 
 	var parsed codexSessionResponse
@@ -152,7 +172,7 @@ func (pf *ProviderFetcher) codexJWT(ctx context.Context, sessionToken string) (s
 		return "", "", err
 	}
 	if parsed.AccessToken == "" || parsed.User.ID == "" {
-		return "", "", fmt.Errorf("codex session response missing token")
+		return "", "", credentialsErrorf("codex session response missing token")
 	}
 	return parsed.AccessToken, parsed.User.ID, nil
 }
@@ -175,7 +195,7 @@ func (pf *ProviderFetcher) codexUsage(ctx context.Context, jwtToken, accountID s
 		return nil, resp.Header, rateLimitErrorFromHeaders(resp.Header, "codex rate limit")
 	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, resp.Header, fmt.Errorf("codex usage status %d", resp.StatusCode)
+		return nil, resp.Header, &StatusError{Operation: "codex usage", StatusCode: resp.StatusCode}
 	}
 
 	var parsed map[string]interface{}
@@ -319,7 +339,7 @@ func parseProductSurfaceUsage(value interface{}) (limit int, used int) {
 func (pf *ProviderFetcher) fetchAntigravity(ctx context.Context, acc *models.Account, creds *models.AccountCredentials) (*models.QuotaInfo, error) {
 	refreshToken := strings.TrimSpace(creds.RefreshToken)
 	if refreshToken == "" {
-		return nil, fmt.Errorf("missing refresh_token")
+		return nil, credentialsErrorf("missing refresh_token")
 	}
 
 	clientID := strings.TrimSpace(creds.ClientID)
@@ -337,7 +357,7 @@ func (pf *ProviderFetcher) fetchAntigravity(ctx context.Context, acc *models.Acc
 		)
 	}
 	if clientID == "" {
-		return nil, fmt.Errorf("missing Google OAuth client_id")
+		return nil, credentialsErrorf("missing Google OAuth client_id")
 	}
 	creds.ClientID = clientID
 	creds.ClientSecret = clientSecret
@@ -418,7 +438,7 @@ func (pf *ProviderFetcher) fetchAntigravity(ctx context.Context, acc *models.Acc
 				diag = fmt.Sprintf(" tokeninfo(scope=%s exp=%s)", scope, exp)
 			}
 		}
-		return nil, fmt.Errorf("antigravity status %d: %s%s", statusCode, strings.TrimSpace(string(body)), diag)
+		return nil, &StatusError{Operation: "antigravity", StatusCode: statusCode, Detail: strings.TrimSpace(string(body)) + diag}
 	}
 
 	var payload map[string]interface{}
@@ -435,7 +455,7 @@ func (pf *ProviderFetcher) fetchAntigravity(ctx context.Context, acc *models.Acc
 
 	remainingFraction, resetAt, ok := parseQuotaFraction(payload)
 	if !ok {
-		return nil, fmt.Errorf("antigravity quota not found")
+		return nil, &ParseError{Message: "antigravity quota not found"}
 	}
 	remainingPct := remainingFraction * 100
 	if remainingPct < 0 {
@@ -496,7 +516,7 @@ func (pf *ProviderFetcher) refreshGoogleAccessToken(ctx context.Context, clientI
 	}
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
-		return "", fmt.Errorf("oauth status %d: %s", resp.StatusCode, strings.TrimSpace(string(bodyBytes)))
+		return "", &StatusError{Operation: "oauth", StatusCode: resp.StatusCode, Detail: strings.TrimSpace(string(bodyBytes))}
 	}
 
 	var parsed struct {
@@ -506,7 +526,7 @@ func (pf *ProviderFetcher) refreshGoogleAccessToken(ctx context.Context, clientI
 		return "", err
 	}
 	if parsed.AccessToken == "" {
-		return "", errors.New("oauth response missing access_token")
+		return "", &ParseError{Message: "oauth response missing access_token"}
 	}
 	return parsed.AccessToken, nil
 }
@@ -529,7 +549,7 @@ func (pf *ProviderFetcher) fetchGemini(ctx context.Context, acc *models.Account,
 		if fallbackEnabled() {
 			return geminiEstimatedQuota(acc), nil
 		}
-		return nil, fmt.Errorf("missing gemini project_id")
+		return nil, credentialsErrorf("missing gemini project_id")
 	}
 
 	accessToken, err := pf.ensureOAuthToken(ctx, acc.ID, creds, "https://oauth2.googleapis.com/token")
@@ -549,7 +569,7 @@ func (pf *ProviderFetcher) fetchGemini(ctx context.Context, acc *models.Account,
 			}
 		}
 		if len(projectIDs) == 0 {
-			return nil, fmt.Errorf("missing gemini project_id")
+			return nil, credentialsErrorf("missing gemini project_id")
 		}
 	}
 
@@ -584,7 +604,7 @@ func (pf *ProviderFetcher) fetchGemini(ctx context.Context, acc *models.Account,
 			return nil, rateLimitErrorFromHeaders(resp.Header, "gemini rate limit")
 		}
 		if resp.StatusCode != http.StatusOK {
-			lastErr = fmt.Errorf("gemini status %d: %s", resp.StatusCode, strings.TrimSpace(string(bodyBytes)))
+			lastErr = &StatusError{Operation: "gemini", StatusCode: resp.StatusCode, Detail: strings.TrimSpace(string(bodyBytes))}
 			continue
 		}
 
@@ -620,12 +640,12 @@ func (pf *ProviderFetcher) fetchGemini(ctx context.Context, acc *models.Account,
 func (pf *ProviderFetcher) fetchQwen(ctx context.Context, acc *models.Account, creds *models.AccountCredentials) (*models.QuotaInfo, error) {
 	accessToken := strings.TrimSpace(creds.AccessToken)
 	if accessToken == "" {
-		return nil, fmt.Errorf("missing access_token")
+		return nil, credentialsErrorf("missing access_token")
 	}
 	if creds.ExpiryDateMs > 0 {
 		expiry := time.UnixMilli(creds.ExpiryDateMs)
 		if time.Now().After(expiry) {
-			return nil, fmt.Errorf("qwen token expired")
+			return nil, credentialsErrorf("qwen token expired")
 		}
 	}
 
@@ -652,7 +672,7 @@ func (pf *ProviderFetcher) fetchQwen(ctx context.Context, acc *models.Account, c
 			return nil, rateLimitErrorFromHeaders(resp.Header, "qwen rate limit")
 		}
 		if resp.StatusCode != http.StatusOK {
-			lastErr = fmt.Errorf("qwen status %d", resp.StatusCode)
+			lastErr = &StatusError{Operation: "qwen", StatusCode: resp.StatusCode}
 			continue
 		}
 
@@ -1385,11 +1405,11 @@ func claudeEstimatedQuota(acc *models.Account) *models.QuotaInfo {
 
 func (pf *ProviderFetcher) ensureOAuthToken(ctx context.Context, accountID string, creds *models.AccountCredentials, defaultTokenURI string) (string, error) {
 	if creds == nil {
-		return "", fmt.Errorf("missing oauth credentials")
+		return "", credentialsErrorf("missing oauth credentials")
 	}
 	if creds.AccessToken == "" {
 		if creds.RefreshToken == "" {
-			return "", fmt.Errorf("missing access_token")
+			return "", credentialsErrorf("missing access_token")
 		}
 	} else if creds.ExpiryDateMs > 0 {
 		expiry := time.UnixMilli(creds.ExpiryDateMs)
@@ -1430,7 +1450,7 @@ func (pf *ProviderFetcher) ensureOAuthToken(ctx context.Context, accountID strin
 	}
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
-		return "", fmt.Errorf("oauth status %d: %s", resp.StatusCode, strings.TrimSpace(string(bodyBytes)))
+		return "", &StatusError{Operation: "oauth", StatusCode: resp.StatusCode, Detail: strings.TrimSpace(string(bodyBytes))}
 	}
 
 	var parsed struct {
@@ -1441,7 +1461,7 @@ func (pf *ProviderFetcher) ensureOAuthToken(ctx context.Context, accountID strin
 		return "", err
 	}
 	if parsed.AccessToken == "" {
-		return "", errors.New("oauth response missing access_token")
+		return "", &ParseError{Message: "oauth response missing access_token"}
 	}
 	creds.AccessToken = parsed.AccessToken
 	if parsed.ExpiresIn > 0 {
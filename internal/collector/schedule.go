@@ -0,0 +1,225 @@
+package collector
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// Reasons reported with a scheduled poll
+const (
+	ScheduleReasonInitial  = "initial"
+	ScheduleReasonFixed    = "fixed"
+	ScheduleReasonCritical = "critical"
+	ScheduleReasonLow      = "low"
+	ScheduleReasonNormal   = "normal"
+	ScheduleReasonHealthy  = "healthy"
+	ScheduleReasonBurning  = "burning"
+	ScheduleReasonReset    = "reset"
+	ScheduleReasonBackoff  = "backoff"
+	ScheduleReasonBlocked  = "blocked"
+	// ScheduleReasonCircuitOpen means the provider or account breaker is open
+	ScheduleReasonCircuitOpen = "circuit_open"
+)
+
+// activeWindow is how recently an account must have been routed to count
+// as in use; idleWindow is how long without traffic before it is idle.
+const (
+	activeWindow = 10 * time.Minute
+	idleWindow   = time.Hour
+	maxBackoff   = 5
+)
+
+// defaultProviderMinIntervals keep polling of provider usage endpoints polite
+// no matter how low an account's quota gets.
+var defaultProviderMinIntervals = map[models.Provider]time.Duration{
+	models.ProviderOpenAI:    30 * time.Second,
+	models.ProviderAnthropic: 30 * time.Second,
+	models.ProviderGemini:    30 * time.Second,
+	models.ProviderQwen:      time.Minute,
+}
+
+// PollSchedule is the planned next poll of one account.
+type PollSchedule struct {
+	AccountID       string          `json:"account_id"`
+	Provider        models.Provider `json:"provider"`
+	NextPoll        time.Time       `json:"next_poll"`
+	IntervalSeconds float64         `json:"interval_seconds"`
+	Reason          string          `json:"reason"`
+	LastPoll        *time.Time      `json:"last_poll,omitempty"`
+	Failures        int             `json:"failures,omitempty"`
+}
+
+type scheduleItem struct {
+	PollSchedule
+	lastPct float64
+	hasPct  bool
+	index   int
+}
+
+// scheduleQueue is a min-heap of accounts ordered by next poll time
+type scheduleQueue []*scheduleItem
+
+func (q scheduleQueue) Len() int { return len(q) }
+func (q scheduleQueue) Less(i, j int) bool {
+	return q[i].NextPoll.Before(q[j].NextPoll)
+}
+func (q scheduleQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+func (q *scheduleQueue) Push(x interface{}) {
+	item := x.(*scheduleItem)
+	item.index = len(*q)
+	*q = append(*q, item)
+}
+func (q *scheduleQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*q = old[:n-1]
+	return item
+}
+
+// pollScheduler tracks when each account is next due for polling.
+type pollScheduler struct {
+	mu    sync.Mutex
+	queue scheduleQueue
+	items map[string]*scheduleItem
+}
+
+func newPollScheduler() *pollScheduler {
+	return &pollScheduler{items: make(map[string]*scheduleItem)}
+}
+
+// sync adds new accounts as due now and drops accounts that went away.
+func (ps *pollScheduler) sync(accounts []*models.Account, now time.Time) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	seen := make(map[string]bool, len(accounts))
+	for _, acc := range accounts {
+		seen[acc.ID] = true
+		if item, ok := ps.items[acc.ID]; ok {
+			item.Provider = acc.Provider
+			continue
+		}
+		item := &scheduleItem{PollSchedule: PollSchedule{
+			AccountID: acc.ID,
+			Provider:  acc.Provider,
+			NextPoll:  now,
+			Reason:    ScheduleReasonInitial,
+		}}
+		ps.items[acc.ID] = item
+		heap.Push(&ps.queue, item)
+	}
+	for id, item := range ps.items {
+		if !seen[id] {
+			heap.Remove(&ps.queue, item.index)
+			delete(ps.items, id)
+		}
+	}
+}
+
+// due returns the accounts whose next poll is at or before now.
+func (ps *pollScheduler) due(now time.Time) []string {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	var ids []string
+	for _, item := range ps.queue {
+		if !item.NextPoll.After(now) {
+			ids = append(ids, item.AccountID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ps.items[ids[i]].NextPoll.Before(ps.items[ids[j]].NextPoll)
+	})
+	return ids
+}
+
+// nextWake returns the earliest scheduled poll.
+func (ps *pollScheduler) nextWake() (time.Time, bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if len(ps.queue) == 0 {
+		return time.Time{}, false
+	}
+	return ps.queue[0].NextPoll, true
+}
+
+// item returns a copy of an account's scheduling state.
+func (ps *pollScheduler) item(accountID string) (scheduleItem, bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	item, ok := ps.items[accountID]
+	if !ok {
+		return scheduleItem{}, false
+	}
+	return *item, true
+}
+
+// reschedule records a poll outcome and plans the next one.
+func (ps *pollScheduler) reschedule(accountID string, polledAt time.Time, interval time.Duration, reason string, failures int, pct *float64) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	item, ok := ps.items[accountID]
+	if !ok {
+		return
+	}
+	last := polledAt
+	item.LastPoll = &last
+	item.NextPoll = polledAt.Add(interval)
+	item.IntervalSeconds = interval.Seconds()
+	item.Reason = reason
+	item.Failures = failures
+	if pct != nil {
+		item.lastPct = *pct
+		item.hasPct = true
+	}
+	heap.Fix(&ps.queue, item.index)
+}
+
+// postpone moves an account's next poll without recording a poll.
+func (ps *pollScheduler) postpone(accountID string, until time.Time, reason string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	item, ok := ps.items[accountID]
+	if !ok {
+		return
+	}
+	item.NextPoll = until
+	item.Reason = reason
+	heap.Fix(&ps.queue, item.index)
+}
+
+// snapshot returns the schedule ordered by next poll time.
+func (ps *pollScheduler) snapshot() []PollSchedule {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	result := make([]PollSchedule, 0, len(ps.items))
+	for _, item := range ps.items {
+		entry := item.PollSchedule
+		if item.LastPoll != nil {
+			last := *item.LastPoll
+			entry.LastPoll = &last
+		}
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].NextPoll.Equal(result[j].NextPoll) {
+			return result[i].NextPoll.Before(result[j].NextPoll)
+		}
+		return result[i].AccountID < result[j].AccountID
+	})
+	return result
+}
@@ -17,6 +17,7 @@ type Config struct {
 	Health     HealthConfig     `yaml:"health"`
 	Telegram   TelegramConfig   `yaml:"telegram"`
 	Middleware MiddlewareConfig `yaml:"middleware"`
+	CLIProxy   CLIProxyConfig   `yaml:"cliproxy"`
 	Alerts     AlertsConfig     `yaml:"alerts"`
 	Cleanup    CleanupConfig    `yaml:"cleanup"`
 	Accounts   []AccountConfig  `yaml:"accounts,omitempty"`
@@ -47,15 +48,69 @@ type APIConfig struct {
 	Auth      AuthConfig      `yaml:"auth"`
 	RateLimit RateLimitConfig `yaml:"rate_limit"`
 	CORS      CORSConfig      `yaml:"cors"`
+	Snapshot  SnapshotConfig  `yaml:"snapshot"`
+}
+
+// SnapshotConfig contains settings for GET /snapshot.
+type SnapshotConfig struct {
+	// SigningKey signs snapshots with HMAC-SHA256 so clients can verify
+	// them before routing from them. Snapshots are unsigned when empty.
+	SigningKey string `yaml:"signing_key"`
 }
 
 // AuthConfig contains authentication configuration.
 type AuthConfig struct {
-	Enabled    bool     `yaml:"enabled"`
-	Type       string   `yaml:"type"`
-	Secret     string   `yaml:"secret"`
-	APIKeys    []string `yaml:"api_keys"`
-	HeaderName string   `yaml:"header_name"`
+	Enabled bool `yaml:"enabled"`
+	// Type selects the authentication scheme: api_key, jwt or hmac.
+	// Static api_keys are accepted as admin keys with every type.
+	// Default: "api_key"
+	Type string `yaml:"type"`
+	// Secret is the HS256 signing key for jwt and the shared key for hmac.
+	Secret     string     `yaml:"secret"`
+	APIKeys    []string   `yaml:"api_keys"`
+	HeaderName string     `yaml:"header_name"`
+	JWT        JWTConfig  `yaml:"jwt"`
+	HMAC       HMACConfig `yaml:"hmac"`
+	// Audit records authenticated API access in the audit log.
+	// Default: false
+	Audit bool `yaml:"audit"`
+}
+
+// JWTConfig contains JWT bearer token verification settings.
+type JWTConfig struct {
+	// JWKSFile is a local JSON Web Key Set used to verify RS256 tokens.
+	JWKSFile string `yaml:"jwks_file"`
+	// Audience is required in the aud claim when set.
+	Audience string `yaml:"audience"`
+	// Issuer is required in the iss claim when set.
+	Issuer string `yaml:"issuer"`
+	// ClientClaim names the claim mapped to the client identity.
+	// Default: "sub"
+	ClientClaim string `yaml:"client_claim"`
+	// ScopesClaim names the claim holding space-separated or list scopes.
+	// Default: "scope"
+	ScopesClaim string `yaml:"scopes_claim"`
+	// ClockSkew is the tolerance for exp and nbf checks.
+	// Default: 30s
+	ClockSkew time.Duration `yaml:"clock_skew"`
+}
+
+// HMACConfig contains HMAC request signing settings.
+type HMACConfig struct {
+	// MaxSkew is how far the signed timestamp may drift from server time.
+	// Signatures are remembered for this window to reject replays.
+	// Default: 5m
+	MaxSkew time.Duration `yaml:"max_skew"`
+	// Keys lists signing keys by ID. When empty, secret is used as key "default" with full access.
+	Keys []HMACKeyConfig `yaml:"keys"`
+}
+
+// HMACKeyConfig describes one HMAC signing key. The key ID is the client identity;
+// scopes default to those of a named API client key.
+type HMACKeyConfig struct {
+	ID     string   `yaml:"id"`
+	Secret string   `yaml:"secret"`
+	Scopes []string `yaml:"scopes"`
 }
 
 // RateLimitConfig contains rate limiting configuration.
@@ -76,6 +131,9 @@ type CollectorConfig struct {
 	Mode    string                 `yaml:"mode"`
 	Passive PassiveCollectorConfig `yaml:"passive"`
 	Active  ActiveCollectorConfig  `yaml:"active"`
+	// Fetchers declares HTTP quota endpoints polled for accounts whose
+	// credentials type matches the fetcher name.
+	Fetchers []FetcherConfig `yaml:"fetchers,omitempty"`
 }
 
 // PassiveCollectorConfig contains passive collector configuration.
@@ -83,6 +141,26 @@ type PassiveCollectorConfig struct {
 	Enabled       bool          `yaml:"enabled"`
 	BufferSize    int           `yaml:"buffer_size"`
 	FlushInterval time.Duration `yaml:"flush_interval"`
+	// LogTail follows CLIProxy request logs as a passive quota source.
+	LogTail LogTailConfig `yaml:"log_tail"`
+}
+
+// LogTailConfig configures the CLIProxy request log follower.
+type LogTailConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Path of the JSON-lines request log written by CLIProxy.
+	Path string `yaml:"path"`
+	// PollInterval is how often the file is checked for new lines.
+	// Default: 1s
+	PollInterval time.Duration `yaml:"poll_interval"`
+	// StartAtEnd skips existing lines when there is no checkpoint yet.
+	// Default: false
+	StartAtEnd bool `yaml:"start_at_end"`
+	// RecordSpend charges logged token usage to the spend ledger. Leave it
+	// off when clients report usage through /router/feedback, which already
+	// records the same requests.
+	// Default: false
+	RecordSpend bool `yaml:"record_spend"`
 }
 
 // ActiveCollectorConfig contains active collector configuration.
@@ -103,6 +181,65 @@ type CircuitBreakerConfig struct {
 	HalfOpenLimit    int           `yaml:"half_open_limit"` // Number of successes in half-open state to close
 }
 
+// FetcherConfig declares a generic HTTP quota fetcher.
+// String values in url, body and headers are Go templates over the account
+// credentials, e.g. {{.ProjectID}} or {{.ProviderAccountID}}.
+type FetcherConfig struct {
+	// Name is matched against the account credentials type.
+	Name string `yaml:"name"`
+	// URL of the quota endpoint.
+	URL string `yaml:"url"`
+	// Method is the HTTP method.
+	// Default: "GET"
+	Method string `yaml:"method"`
+	// Body is sent as the request body when set.
+	Body string `yaml:"body"`
+	// Headers are added to the request.
+	Headers map[string]string `yaml:"headers"`
+	// Auth controls how the credential is attached.
+	Auth FetcherAuthConfig `yaml:"auth"`
+	// Confidence of the collected quota.
+	// Default: 0.9
+	Confidence float64 `yaml:"confidence"`
+	// Dimensions map the JSON response to quota dimensions.
+	Dimensions []FetcherDimensionConfig `yaml:"dimensions"`
+}
+
+// FetcherAuthConfig describes where a fetcher puts the account credential.
+type FetcherAuthConfig struct {
+	// Style is one of: bearer, header, query, none.
+	// Default: "bearer"
+	Style string `yaml:"style"`
+	// Field is the credentials field used: access_token, api_key or session_token.
+	// Default: "access_token" for bearer, "api_key" otherwise
+	Field string `yaml:"field"`
+	// Name is the header or query parameter name for the header and query styles.
+	Name string `yaml:"name"`
+}
+
+// FetcherDimensionConfig maps part of a fetcher response to dimensions.
+// Paths use a JSONPath-like syntax: $.data.limits[0].remaining.
+type FetcherDimensionConfig struct {
+	// Type is the dimension type (RPM, TPM, RPD, TPD, BUDGET, SUBSCRIPTION).
+	Type string `yaml:"type"`
+	// Name is a fixed dimension name.
+	Name string `yaml:"name"`
+	// Items is a path to an array; each element yields one dimension and
+	// the other paths are resolved relative to it.
+	Items string `yaml:"items"`
+	// NamePath reads the dimension (group) name from the response.
+	NamePath string `yaml:"name_path"`
+	// Limit, Used, Remaining and Reset are paths to the respective values.
+	// At least one of used or remaining is required.
+	Limit     string `yaml:"limit"`
+	Used      string `yaml:"used"`
+	Remaining string `yaml:"remaining"`
+	Reset     string `yaml:"reset"`
+	// Semantics is FIXED_WINDOW or TOKEN_BUCKET.
+	// Default: "FIXED_WINDOW"
+	Semantics string `yaml:"semantics"`
+}
+
 // RouterConfig contains router configuration.
 type RouterConfig struct {
 	Thresholds      ThresholdsConfig     `yaml:"thresholds"`
@@ -113,6 +250,7 @@ type RouterConfig struct {
 	FallbackChains  map[string][]string  `yaml:"fallback_chains"`
 	CircuitBreaker  CircuitBreakerConfig `yaml:"circuit_breaker"`
 	IgnoreEstimated bool                 `yaml:"ignore_estimated"`
+	Affinity        AffinityConfig       `yaml:"affinity"`
 }
 
 // ThresholdsConfig contains threshold configuration.
@@ -128,6 +266,19 @@ type AntiFlappingConfig struct {
 	MinDwellTime        time.Duration `yaml:"min_dwell_time"`
 	CooldownAfterSwitch time.Duration `yaml:"cooldown_after_switch"`
 	HysteresisMargin    float64       `yaml:"hysteresis_margin"`
+	// Scope keys anti-flapping state: global, provider, model, policy or client.
+	// Default: "global"
+	Scope string `yaml:"scope"`
+}
+
+// AffinityConfig contains session affinity configuration.
+type AffinityConfig struct {
+	// TTL is how long a session key stays bound to its account after the last request.
+	// Default: 30m
+	TTL time.Duration `yaml:"ttl"`
+	// MaxSessions caps the number of tracked session bindings.
+	// Default: 10000
+	MaxSessions int `yaml:"max_sessions"`
 }
 
 // ReservationConfig contains reservation configuration.
@@ -186,9 +337,12 @@ type ControlPrompt struct {
 
 // TelegramConfig contains Telegram bot configuration.
 type TelegramConfig struct {
-	Enabled   bool              `yaml:"enabled"`
-	BotToken  string            `yaml:"bot_token"`
-	ChatID    int64             `yaml:"chat_id"`
+	Enabled  bool   `yaml:"enabled"`
+	BotToken string `yaml:"bot_token"`
+	ChatID   int64  `yaml:"chat_id"`
+	// Admins are Telegram user IDs with full access that cannot be revoked
+	// from the bot. Other users are granted roles with /grant.
+	Admins    []int64           `yaml:"admins"`
 	RateLimit TelegramRateLimit `yaml:"rate_limit"`
 	Alerts    TelegramAlerts    `yaml:"alerts"`
 }
@@ -218,6 +372,12 @@ type AlertsConfig struct {
 	// DailyDigestTime is the time of day to send the digest (format: "HH:MM").
 	// Default: "09:00"
 	DailyDigestTime string `yaml:"daily_digest_time"`
+	// WeeklyDigestEnabled enables a digest of the previous seven days,
+	// sent at DailyDigestTime.
+	WeeklyDigestEnabled bool `yaml:"weekly_digest_enabled"`
+	// WeeklyDigestDay is the day of the week to send the weekly digest.
+	// Default: "monday"
+	WeeklyDigestDay string `yaml:"weekly_digest_day"`
 	// Timezone is the timezone for scheduling.
 	// Default: "UTC"
 	Timezone string `yaml:"timezone"`
@@ -227,6 +387,35 @@ type AlertsConfig struct {
 	// ShutdownTimeout is the timeout for graceful shutdown.
 	// Default: 25s
 	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// Prediction configures alerts for quota predicted to run out before
+	// it resets.
+	Prediction AlertPredictionConfig `yaml:"prediction"`
+	// Escalation re-notifies about incidents nobody has acknowledged.
+	Escalation AlertEscalationConfig `yaml:"escalation"`
+}
+
+// AlertPredictionConfig contains predictive alert configuration.
+type AlertPredictionConfig struct {
+	// Enabled enables alerts driven by the burn rate of recent quota
+	// readings, per account and per provider pool.
+	Enabled bool `yaml:"enabled"`
+	// LeadTimes are how long before predicted exhaustion to alert. The
+	// longest one raises a warning, the shortest a critical alert.
+	// Default: [1h, 15m]
+	LeadTimes []time.Duration `yaml:"lead_times"`
+	// Window is how far back burn rate is measured.
+	// Default: 30m
+	Window time.Duration `yaml:"window"`
+}
+
+// AlertEscalationConfig contains alert escalation configuration.
+type AlertEscalationConfig struct {
+	// After is how long an incident may stay unacknowledged before it is
+	// notified again. Zero disables escalation.
+	After time.Duration `yaml:"after"`
+	// ChatID is a second Telegram chat, such as an on-call group, that
+	// also receives escalated incidents. Optional.
+	ChatID int64 `yaml:"chat_id"`
 }
 
 // MiddlewareConfig contains middleware client configuration including fail-open settings.
@@ -257,6 +446,33 @@ type MiddlewareConfig struct {
 	GracefulShutdownTimeout time.Duration `yaml:"graceful_shutdown_timeout"`
 }
 
+// CLIProxyConfig contains settings for the CLIProxy instance QuotaGuard
+// imports accounts from.
+type CLIProxyConfig struct {
+	// StateSync pushes QuotaGuard's enable/disable decisions back to CLIProxy.
+	StateSync StateSyncConfig `yaml:"state_sync"`
+}
+
+// StateSyncConfig configures account state export to CLIProxy.
+type StateSyncConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Mode selects how state is written: "file" sets a disabled marker in
+	// the auth JSON files, "api" uses the CLIProxy management API.
+	// Default: file
+	Mode string `yaml:"mode"`
+	// Interval between sync passes.
+	// Default: 30s
+	Interval time.Duration `yaml:"interval"`
+	// ConflictPolicy decides who wins when an account was changed in CLIProxy
+	// and QuotaGuard disagrees: "cliproxy", "quotaguard" or "skip".
+	// Default: cliproxy
+	ConflictPolicy string `yaml:"conflict_policy"`
+	// ManagementURL is the CLIProxy base URL for mode "api".
+	ManagementURL string `yaml:"management_url"`
+	// ManagementKey authenticates against the management API.
+	ManagementKey string `yaml:"management_key"`
+}
+
 // CleanupConfig contains cleanup/retention configuration.
 type CleanupConfig struct {
 	// Enabled enables or disables the cleanup service.
@@ -297,6 +513,11 @@ type CleanupConfig struct {
 	// BatchSize is the number of records to delete per batch.
 	// Default: 1000
 	BatchSize int `yaml:"batch_size"`
+
+	// RoutingEventsRetention is how long routing decisions are kept. The
+	// weekly digest compares against the previous week, so keep at least 14d.
+	// Default: 14d
+	RoutingEventsRetention time.Duration `yaml:"routing_events_retention"`
 }
 
 // AccountConfig contains account configuration.
@@ -310,6 +531,12 @@ type AccountConfig struct {
 	InputCost        float64 `yaml:"input_cost_per_1k"`
 	OutputCost       float64 `yaml:"output_cost_per_1k"`
 	CredentialsRef   string  `yaml:"credentials_ref"`
+
+	// MonthlyBudget is a hard spend cap in USD per calendar month (UTC),
+	// computed from input/output token costs. The router stops selecting the
+	// account once the cap is reached.
+	// Default: 0 (no cap)
+	MonthlyBudget float64 `yaml:"monthly_budget_usd"`
 }
 
 // Validate validates the configuration.
@@ -342,6 +569,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("telegram: %w", err)
 	}
 
+	if err := c.CLIProxy.Validate(); err != nil {
+		return fmt.Errorf("cliproxy: %w", err)
+	}
+
 	if err := c.Alerts.Validate(); err != nil {
 		return fmt.Errorf("alerts: %w", err)
 	}
@@ -402,8 +633,8 @@ func (a *APIConfig) Validate() error {
 	if a.BasePath == "" {
 		a.BasePath = "/api/v1"
 	}
-	if a.Auth.Enabled && len(a.Auth.APIKeys) == 0 {
-		return fmt.Errorf("auth: api_keys is required when auth is enabled")
+	if err := a.Auth.Validate(); err != nil {
+		return err
 	}
 	if a.RateLimit.RequestsPerMinute <= 0 {
 		a.RateLimit.RequestsPerMinute = 1000
@@ -422,6 +653,49 @@ func (a *APIConfig) Validate() error {
 	return nil
 }
 
+// Validate validates authentication configuration.
+func (a *AuthConfig) Validate() error {
+	a.Type = strings.ToLower(strings.TrimSpace(a.Type))
+	if a.JWT.ClientClaim == "" {
+		a.JWT.ClientClaim = "sub"
+	}
+	if a.JWT.ScopesClaim == "" {
+		a.JWT.ScopesClaim = "scope"
+	}
+	if a.JWT.ClockSkew <= 0 {
+		a.JWT.ClockSkew = 30 * time.Second
+	}
+	if a.HMAC.MaxSkew <= 0 {
+		a.HMAC.MaxSkew = 5 * time.Minute
+	}
+	if !a.Enabled {
+		return nil
+	}
+
+	switch a.Type {
+	case "", "api_key", "bearer":
+		if len(a.APIKeys) == 0 {
+			return fmt.Errorf("auth: api_keys is required when auth is enabled")
+		}
+	case "jwt":
+		if a.Secret == "" && a.JWT.JWKSFile == "" {
+			return fmt.Errorf("auth: jwt requires secret or jwt.jwks_file")
+		}
+	case "hmac":
+		if a.Secret == "" && len(a.HMAC.Keys) == 0 {
+			return fmt.Errorf("auth: hmac requires secret or hmac.keys")
+		}
+		for _, key := range a.HMAC.Keys {
+			if key.ID == "" || key.Secret == "" {
+				return fmt.Errorf("auth: hmac keys require id and secret")
+			}
+		}
+	default:
+		return fmt.Errorf("auth: invalid type %q (expected api_key, jwt or hmac)", a.Type)
+	}
+	return nil
+}
+
 // Validate validates collector configuration.
 func (c *CollectorConfig) Validate() error {
 	if c.Mode == "" {
@@ -436,6 +710,12 @@ func (c *CollectorConfig) Validate() error {
 	if c.Passive.FlushInterval <= 0 {
 		c.Passive.FlushInterval = 2 * time.Second
 	}
+	if c.Passive.LogTail.Enabled && strings.TrimSpace(c.Passive.LogTail.Path) == "" {
+		return fmt.Errorf("passive.log_tail.path is required when log_tail is enabled")
+	}
+	if c.Passive.LogTail.PollInterval <= 0 {
+		c.Passive.LogTail.PollInterval = time.Second
+	}
 	if c.Active.DefaultInterval <= 0 {
 		c.Active.DefaultInterval = 60 * time.Second
 	}
@@ -445,6 +725,87 @@ func (c *CollectorConfig) Validate() error {
 	if c.Active.RetryAttempts < 0 {
 		return fmt.Errorf("retry_attempts cannot be negative")
 	}
+	seen := make(map[string]bool, len(c.Fetchers))
+	for i := range c.Fetchers {
+		f := &c.Fetchers[i]
+		if err := f.Validate(); err != nil {
+			return fmt.Errorf("fetchers[%d]: %w", i, err)
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("fetchers[%d]: duplicate name %q", i, f.Name)
+		}
+		seen[f.Name] = true
+	}
+	return nil
+}
+
+// Validate validates a fetcher definition and applies defaults.
+func (f *FetcherConfig) Validate() error {
+	f.Name = strings.ToLower(strings.TrimSpace(f.Name))
+	if f.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if strings.TrimSpace(f.URL) == "" {
+		return fmt.Errorf("url is required")
+	}
+	f.Method = strings.ToUpper(strings.TrimSpace(f.Method))
+	if f.Method == "" {
+		f.Method = "GET"
+	}
+	if f.Confidence <= 0 {
+		f.Confidence = 0.9
+	}
+	if f.Confidence > 1 {
+		return fmt.Errorf("confidence must be between 0 and 1")
+	}
+
+	f.Auth.Style = strings.ToLower(strings.TrimSpace(f.Auth.Style))
+	switch f.Auth.Style {
+	case "":
+		f.Auth.Style = "bearer"
+	case "bearer", "none":
+	case "header", "query":
+		if f.Auth.Name == "" {
+			return fmt.Errorf("auth.name is required for %s auth", f.Auth.Style)
+		}
+	default:
+		return fmt.Errorf("auth.style must be one of: bearer, header, query, none")
+	}
+	f.Auth.Field = strings.ToLower(strings.TrimSpace(f.Auth.Field))
+	switch f.Auth.Field {
+	case "":
+		f.Auth.Field = "api_key"
+		if f.Auth.Style == "bearer" {
+			f.Auth.Field = "access_token"
+		}
+	case "access_token", "api_key", "session_token":
+	default:
+		return fmt.Errorf("auth.field must be one of: access_token, api_key, session_token")
+	}
+
+	if len(f.Dimensions) == 0 {
+		return fmt.Errorf("at least one dimension is required")
+	}
+	for i := range f.Dimensions {
+		d := &f.Dimensions[i]
+		d.Type = strings.ToUpper(strings.TrimSpace(d.Type))
+		switch d.Type {
+		case "RPM", "TPM", "RPD", "TPD", "BUDGET", "SUBSCRIPTION":
+		default:
+			return fmt.Errorf("dimensions[%d]: unknown type %q", i, d.Type)
+		}
+		if d.Used == "" && d.Remaining == "" {
+			return fmt.Errorf("dimensions[%d]: used or remaining path is required", i)
+		}
+		d.Semantics = strings.ToUpper(strings.TrimSpace(d.Semantics))
+		switch d.Semantics {
+		case "":
+			d.Semantics = "FIXED_WINDOW"
+		case "FIXED_WINDOW", "TOKEN_BUCKET":
+		default:
+			return fmt.Errorf("dimensions[%d]: semantics must be FIXED_WINDOW or TOKEN_BUCKET", i)
+		}
+	}
 	return nil
 }
 
@@ -493,6 +854,20 @@ func (r *RouterConfig) Validate() error {
 	if r.AntiFlapping.CooldownAfterSwitch <= 0 {
 		r.AntiFlapping.CooldownAfterSwitch = 3 * time.Minute
 	}
+	switch strings.ToLower(strings.TrimSpace(r.AntiFlapping.Scope)) {
+	case "":
+		r.AntiFlapping.Scope = "global"
+	case "global", "provider", "model", "policy", "client":
+		r.AntiFlapping.Scope = strings.ToLower(strings.TrimSpace(r.AntiFlapping.Scope))
+	default:
+		return fmt.Errorf("invalid anti_flapping.scope %q (expected global, provider, model, policy or client)", r.AntiFlapping.Scope)
+	}
+	if r.Affinity.TTL <= 0 {
+		r.Affinity.TTL = 30 * time.Minute
+	}
+	if r.Affinity.MaxSessions <= 0 {
+		r.Affinity.MaxSessions = 10000
+	}
 	if r.Reservation.Timeout <= 0 {
 		r.Reservation.Timeout = 30 * time.Second
 	}
@@ -556,6 +931,14 @@ func (a *AlertsConfig) Validate() error {
 		a.DailyDigestTime = "09:00"
 	}
 
+	// Set default weekly digest day
+	if a.WeeklyDigestDay == "" {
+		a.WeeklyDigestDay = "monday"
+	}
+	if _, err := a.WeeklyDigestWeekday(); err != nil {
+		return err
+	}
+
 	// Set default timezone
 	if a.Timezone == "" {
 		a.Timezone = "UTC"
@@ -571,9 +954,41 @@ func (a *AlertsConfig) Validate() error {
 		a.ShutdownTimeout = 25 * time.Second
 	}
 
+	if a.Prediction.Enabled {
+		if len(a.Prediction.LeadTimes) == 0 {
+			a.Prediction.LeadTimes = []time.Duration{time.Hour, 15 * time.Minute}
+		}
+		for _, lead := range a.Prediction.LeadTimes {
+			if lead <= 0 {
+				return fmt.Errorf("prediction lead_times must be positive")
+			}
+		}
+		if a.Prediction.Window <= 0 {
+			a.Prediction.Window = 30 * time.Minute
+		}
+	}
+
+	if a.Escalation.After < 0 {
+		return fmt.Errorf("escalation after must not be negative")
+	}
+
 	return nil
 }
 
+// WeeklyDigestWeekday parses WeeklyDigestDay.
+func (a *AlertsConfig) WeeklyDigestWeekday() (time.Weekday, error) {
+	day := strings.ToLower(strings.TrimSpace(a.WeeklyDigestDay))
+	if day == "" {
+		return time.Monday, nil
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.ToLower(d.String()) == day {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weekly_digest_day %q", a.WeeklyDigestDay)
+}
+
 // Validate validates account configuration.
 func (a *AccountConfig) Validate() error {
 	if a.ID == "" {
@@ -591,6 +1006,9 @@ func (a *AccountConfig) Validate() error {
 	if a.OutputCost < 0 {
 		return fmt.Errorf("output_cost_per_1k cannot be negative")
 	}
+	if a.MonthlyBudget < 0 {
+		return fmt.Errorf("monthly_budget_usd cannot be negative")
+	}
 	return nil
 }
 
@@ -642,6 +1060,33 @@ func (m *MiddlewareConfig) GetFallbackStrategy() string {
 	return m.FallbackStrategy
 }
 
+// Validate validates CLIProxy configuration and applies defaults.
+func (c *CLIProxyConfig) Validate() error {
+	sync := &c.StateSync
+	sync.Mode = strings.ToLower(strings.TrimSpace(sync.Mode))
+	if sync.Mode == "" {
+		sync.Mode = "file"
+	}
+	if sync.Mode != "file" && sync.Mode != "api" {
+		return fmt.Errorf("state_sync.mode must be file or api, got %q", sync.Mode)
+	}
+	if sync.Interval <= 0 {
+		sync.Interval = 30 * time.Second
+	}
+	sync.ConflictPolicy = strings.ToLower(strings.TrimSpace(sync.ConflictPolicy))
+	switch sync.ConflictPolicy {
+	case "":
+		sync.ConflictPolicy = "cliproxy"
+	case "cliproxy", "quotaguard", "skip":
+	default:
+		return fmt.Errorf("state_sync.conflict_policy must be cliproxy, quotaguard or skip, got %q", sync.ConflictPolicy)
+	}
+	if sync.Enabled && sync.Mode == "api" && strings.TrimSpace(sync.ManagementURL) == "" {
+		return fmt.Errorf("state_sync.management_url is required in api mode")
+	}
+	return nil
+}
+
 // Validate validates cleanup configuration and applies defaults.
 func (c *CleanupConfig) Validate() error {
 	// Set default interval
@@ -674,5 +1119,10 @@ func (c *CleanupConfig) Validate() error {
 		c.BatchSize = 1000
 	}
 
+	// Set default routing events retention
+	if c.RoutingEventsRetention <= 0 {
+		c.RoutingEventsRetention = 14 * 24 * time.Hour
+	}
+
 	return nil
 }
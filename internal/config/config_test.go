@@ -168,6 +168,66 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "auth: api_keys is required when auth is enabled",
 		},
+		{
+			name: "jwt auth without secret or jwks",
+			config: Config{
+				Version: "2.1",
+				Server: ServerConfig{
+					Host:            "127.0.0.1",
+					HTTPPort:        8318,
+					ShutdownTimeout: 30 * time.Second,
+				},
+				API: APIConfig{
+					Enabled: true,
+					Auth: AuthConfig{
+						Enabled: true,
+						Type:    "JWT",
+					},
+				},
+			},
+			wantErr: true,
+			errMsg:  "auth: jwt requires secret or jwt.jwks_file",
+		},
+		{
+			name: "hmac auth with secret",
+			config: Config{
+				Version: "2.1",
+				Server: ServerConfig{
+					Host:            "127.0.0.1",
+					HTTPPort:        8318,
+					ShutdownTimeout: 30 * time.Second,
+				},
+				API: APIConfig{
+					Enabled: true,
+					Auth: AuthConfig{
+						Enabled: true,
+						Type:    "hmac",
+						Secret:  "shared",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown auth type",
+			config: Config{
+				Version: "2.1",
+				Server: ServerConfig{
+					Host:            "127.0.0.1",
+					HTTPPort:        8318,
+					ShutdownTimeout: 30 * time.Second,
+				},
+				API: APIConfig{
+					Enabled: true,
+					Auth: AuthConfig{
+						Enabled: true,
+						Type:    "oauth",
+					},
+				},
+			},
+			wantErr: true,
+			errMsg:  `auth: invalid type "oauth" (expected api_key, jwt or hmac)`,
+		},
 		{
 			name: "telegram enabled without token",
 			config: Config{
@@ -442,6 +502,20 @@ func TestRouterConfig_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "provider anti-flapping scope",
+			config: RouterConfig{
+				AntiFlapping: AntiFlappingConfig{Scope: "Provider"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid anti-flapping scope",
+			config: RouterConfig{
+				AntiFlapping: AntiFlappingConfig{Scope: "tenant"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -546,6 +620,44 @@ func TestTelegramConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestAlertsConfig_Prediction(t *testing.T) {
+	cfg := AlertsConfig{Prediction: AlertPredictionConfig{Enabled: true}}
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, []time.Duration{time.Hour, 15 * time.Minute}, cfg.Prediction.LeadTimes)
+	assert.Equal(t, 30*time.Minute, cfg.Prediction.Window)
+
+	// Disabled prediction keeps no lead times
+	cfg = AlertsConfig{}
+	require.NoError(t, cfg.Validate())
+	assert.Empty(t, cfg.Prediction.LeadTimes)
+
+	cfg = AlertsConfig{Prediction: AlertPredictionConfig{Enabled: true, LeadTimes: []time.Duration{-time.Minute}}}
+	require.Error(t, cfg.Validate())
+}
+
+func TestAlertsConfig_Escalation(t *testing.T) {
+	cfg := AlertsConfig{Escalation: AlertEscalationConfig{After: 15 * time.Minute, ChatID: -100}}
+	require.NoError(t, cfg.Validate())
+
+	cfg = AlertsConfig{Escalation: AlertEscalationConfig{After: -time.Minute}}
+	require.Error(t, cfg.Validate())
+}
+
+func TestAlertsConfig_WeeklyDigestDay(t *testing.T) {
+	cfg := AlertsConfig{}
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, "monday", cfg.WeeklyDigestDay)
+
+	cfg = AlertsConfig{WeeklyDigestDay: "Friday"}
+	require.NoError(t, cfg.Validate())
+	day, err := cfg.WeeklyDigestWeekday()
+	require.NoError(t, err)
+	assert.Equal(t, time.Friday, day)
+
+	cfg = AlertsConfig{WeeklyDigestDay: "fri"}
+	require.Error(t, cfg.Validate())
+}
+
 func TestAccountConfig_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -1096,3 +1208,98 @@ func TestConfigsEqual(t *testing.T) {
 	assert.False(t, configsEqual(config1, nil))
 	assert.False(t, configsEqual(nil, config1))
 }
+
+func TestCollectorConfig_Fetchers(t *testing.T) {
+	valid := func() FetcherConfig {
+		return FetcherConfig{
+			Name: " Internal-GW ",
+			URL:  "https://gw.internal/quota/{{.ProviderAccountID}}",
+			Dimensions: []FetcherDimensionConfig{
+				{Type: "rpm", Limit: "limit", Remaining: "remaining"},
+			},
+		}
+	}
+
+	cfg := CollectorConfig{Fetchers: []FetcherConfig{valid()}}
+	require.NoError(t, cfg.Validate())
+	f := cfg.Fetchers[0]
+	assert.Equal(t, "internal-gw", f.Name)
+	assert.Equal(t, "GET", f.Method)
+	assert.Equal(t, "bearer", f.Auth.Style)
+	assert.Equal(t, "access_token", f.Auth.Field)
+	assert.Equal(t, 0.9, f.Confidence)
+	assert.Equal(t, "RPM", f.Dimensions[0].Type)
+	assert.Equal(t, "FIXED_WINDOW", f.Dimensions[0].Semantics)
+
+	header := valid()
+	header.Auth = FetcherAuthConfig{Style: "header", Name: "X-Api-Key"}
+	require.NoError(t, header.Validate())
+	assert.Equal(t, "api_key", header.Auth.Field)
+
+	tests := []struct {
+		name   string
+		modify func(*FetcherConfig)
+		errMsg string
+	}{
+		{"missing name", func(f *FetcherConfig) { f.Name = "" }, "name is required"},
+		{"missing url", func(f *FetcherConfig) { f.URL = "" }, "url is required"},
+		{"unknown auth style", func(f *FetcherConfig) { f.Auth.Style = "cookie" }, "auth.style"},
+		{"header without name", func(f *FetcherConfig) { f.Auth.Style = "header" }, "auth.name is required"},
+		{"unknown field", func(f *FetcherConfig) { f.Auth.Field = "password" }, "auth.field"},
+		{"no dimensions", func(f *FetcherConfig) { f.Dimensions = nil }, "at least one dimension"},
+		{"unknown type", func(f *FetcherConfig) { f.Dimensions[0].Type = "RPS" }, "unknown type"},
+		{"no values", func(f *FetcherConfig) { f.Dimensions[0].Remaining = "" }, "used or remaining"},
+		{"bad semantics", func(f *FetcherConfig) { f.Dimensions[0].Semantics = "sliding" }, "semantics"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := valid()
+			tt.modify(&f)
+			err := f.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+
+	dup := CollectorConfig{Fetchers: []FetcherConfig{valid(), valid()}}
+	err := dup.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate name")
+}
+
+func TestCollectorConfig_LogTail(t *testing.T) {
+	cfg := CollectorConfig{Passive: PassiveCollectorConfig{LogTail: LogTailConfig{Enabled: true, Path: "/var/log/cliproxy/requests.log"}}}
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, time.Second, cfg.Passive.LogTail.PollInterval)
+
+	cfg.Passive.LogTail.Path = " "
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "log_tail.path is required")
+}
+
+func TestCLIProxyConfig_StateSync(t *testing.T) {
+	cfg := CLIProxyConfig{StateSync: StateSyncConfig{Enabled: true}}
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, "file", cfg.StateSync.Mode)
+	assert.Equal(t, 30*time.Second, cfg.StateSync.Interval)
+	assert.Equal(t, "cliproxy", cfg.StateSync.ConflictPolicy)
+
+	tests := []struct {
+		name   string
+		sync   StateSyncConfig
+		errMsg string
+	}{
+		{"unknown mode", StateSyncConfig{Mode: "rsync"}, "mode must be file or api"},
+		{"unknown policy", StateSyncConfig{ConflictPolicy: "newest"}, "conflict_policy"},
+		{"api without url", StateSyncConfig{Enabled: true, Mode: "API"}, "management_url is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := CLIProxyConfig{StateSync: tt.sync}
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
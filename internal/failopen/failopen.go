@@ -7,7 +7,9 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"net"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -18,6 +20,11 @@ import (
 // DefaultFailOpenTimeout is the default timeout for fail-open behavior.
 const DefaultFailOpenTimeout = 50 * time.Millisecond
 
+// ErrUnavailable marks an error meaning QuotaGuard could not be reached,
+// such as a gateway error in front of it. Operations wrap it to trigger the
+// fallback the same way network errors do.
+var ErrUnavailable = errors.New("quotaguard unavailable")
+
 // FallbackStrategy defines the interface for fallback account selection strategies.
 type FallbackStrategy interface {
 	// SelectAccount returns an account from the available candidates.
@@ -167,6 +174,70 @@ func (w *WeightedStrategy) Name() string {
 	return "weighted"
 }
 
+// unknownRemainingPct weights accounts without quota data in QuotaAwareStrategy
+const unknownRemainingPct = 10.0
+
+// QuotaAwareStrategy selects accounts at random weighted by their last
+// known remaining quota, so fallback traffic goes mostly to the fullest
+// accounts instead of spreading evenly.
+type QuotaAwareStrategy struct {
+	mu        sync.Mutex
+	remaining func(accountID string) (float64, bool)
+}
+
+// NewQuotaAwareStrategy creates a quota-aware fallback strategy. remaining
+// returns an account's remaining quota percent and whether it is known.
+func NewQuotaAwareStrategy(remaining func(accountID string) (float64, bool)) *QuotaAwareStrategy {
+	return &QuotaAwareStrategy{remaining: remaining}
+}
+
+// SelectAccount selects an account weighted by remaining quota.
+func (q *QuotaAwareStrategy) SelectAccount(accounts []*models.Account, _ string) *models.Account {
+	if len(accounts) == 0 {
+		return nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// Weights in tenths of a percent; +1 keeps every candidate selectable
+	weights := make([]int, len(accounts))
+	totalWeight := 0
+	for i, acc := range accounts {
+		pct, ok := q.remaining(acc.ID)
+		if !ok {
+			pct = unknownRemainingPct
+		}
+		if pct < 0 {
+			pct = 0
+		}
+		if pct > 100 {
+			pct = 100
+		}
+		weights[i] = int(pct*10) + 1
+		totalWeight += weights[i]
+	}
+
+	target, err := cryptoIntn(totalWeight)
+	if err != nil {
+		return accounts[0]
+	}
+	current := 0
+	for i, acc := range accounts {
+		current += weights[i]
+		if target < current {
+			return acc
+		}
+	}
+
+	return accounts[len(accounts)-1]
+}
+
+// Name returns the strategy name.
+func (q *QuotaAwareStrategy) Name() string {
+	return "quota-aware"
+}
+
 // Config holds fail-open configuration.
 type Config struct {
 	// Timeout is the maximum time to wait for a response from QuotaGuard.
@@ -367,6 +438,16 @@ func NewFailOpenClient(accounts AccountProvider, config Config) *FailOpenClient
 func (c *FailOpenClient) ExecuteWithFailOpen(
 	ctx context.Context,
 	operation func(context.Context) (*Result, error),
+) (*Result, error) {
+	return c.ExecuteWithFilter(ctx, operation, nil)
+}
+
+// ExecuteWithFilter is like ExecuteWithFailOpen, but the fallback only
+// considers accounts for which keep returns true. A nil keep allows all.
+func (c *FailOpenClient) ExecuteWithFilter(
+	ctx context.Context,
+	operation func(context.Context) (*Result, error),
+	keep func(*models.Account) bool,
 ) (*Result, error) {
 	start := time.Now()
 
@@ -407,7 +488,7 @@ func (c *FailOpenClient) ExecuteWithFailOpen(
 				if c.config.EnableMetrics {
 					c.metrics.RecordFallback("network_error", latency)
 				}
-				return c.executeFallback("network_error")
+				return c.executeFallback("network_error", keep)
 			}
 			return nil, res.err
 		}
@@ -428,7 +509,7 @@ func (c *FailOpenClient) ExecuteWithFailOpen(
 		if c.config.EnableMetrics {
 			c.metrics.RecordFallback("timeout", latency)
 		}
-		return c.executeFallback("timeout")
+		return c.executeFallback("timeout", keep)
 
 	case <-ctx.Done():
 		// Parent context canceled
@@ -478,10 +559,19 @@ func (c *FailOpenClient) ExecuteWithRetry(
 }
 
 // executeFallback executes the fallback strategy.
-func (c *FailOpenClient) executeFallback(reason string) (*Result, error) {
+func (c *FailOpenClient) executeFallback(reason string, keep func(*models.Account) bool) (*Result, error) {
 	start := time.Now()
 
 	accounts := c.accounts.ListEnabledAccounts()
+	if keep != nil {
+		filtered := make([]*models.Account, 0, len(accounts))
+		for _, acc := range accounts {
+			if keep(acc) {
+				filtered = append(filtered, acc)
+			}
+		}
+		accounts = filtered
+	}
 	if len(accounts) == 0 {
 		// Record metrics even on failure
 		if c.config.EnableMetrics {
@@ -553,8 +643,10 @@ func isNetworkError(err error) bool {
 	if err == nil {
 		return false
 	}
-	// This is a simple implementation. In production, you'd want to check
-	// for specific network error types (net.Error, url.Error, syscall errors, etc.)
+	var netErr net.Error
+	if errors.Is(err, ErrUnavailable) || errors.As(err, &netErr) {
+		return true
+	}
 	errStr := err.Error()
 	networkErrors := []string{
 		"connection refused",
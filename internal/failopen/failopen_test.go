@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"sync"
 	"testing"
 	"time"
@@ -247,6 +248,28 @@ func TestWeightedStrategy(t *testing.T) {
 	})
 }
 
+func TestQuotaAwareStrategy(t *testing.T) {
+	remaining := map[string]float64{"full": 90, "drained": 0.5}
+	strategy := NewQuotaAwareStrategy(func(id string) (float64, bool) {
+		pct, ok := remaining[id]
+		return pct, ok
+	})
+	assert.Equal(t, "quota-aware", strategy.Name())
+	assert.Nil(t, strategy.SelectAccount(nil, ""))
+
+	accounts := []*models.Account{
+		{ID: "drained", Provider: models.ProviderOpenAI, Enabled: true},
+		{ID: "full", Provider: models.ProviderOpenAI, Enabled: true},
+		{ID: "unknown", Provider: models.ProviderOpenAI, Enabled: true},
+	}
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		counts[strategy.SelectAccount(accounts, "").ID]++
+	}
+	assert.Greater(t, counts["full"], counts["unknown"])
+	assert.Greater(t, counts["unknown"], counts["drained"])
+}
+
 // Test Metrics
 func TestMetrics(t *testing.T) {
 	t.Run("records success correctly", func(t *testing.T) {
@@ -664,6 +687,8 @@ func TestIsNetworkError(t *testing.T) {
 		{"i/o timeout", errors.New("i/o timeout"), true},
 		{"regular error", errors.New("some other error"), false},
 		{"case insensitive", errors.New("CONNECTION REFUSED"), true},
+		{"wrapped unavailable", fmt.Errorf("status 502: %w", ErrUnavailable), true},
+		{"net.Error", &net.OpError{Op: "dial", Err: errors.New("refused")}, true},
 	}
 
 	for _, tt := range tests {
@@ -674,6 +699,23 @@ func TestIsNetworkError(t *testing.T) {
 	}
 }
 
+func TestFailOpenClient_ExecuteWithFilter(t *testing.T) {
+	client := NewFailOpenClient(newMockAccountProvider(), Config{Timeout: time.Second})
+	failing := func(context.Context) (*Result, error) {
+		return nil, fmt.Errorf("select: %w", ErrUnavailable)
+	}
+
+	result, err := client.ExecuteWithFilter(context.Background(), failing, func(acc *models.Account) bool {
+		return acc.Provider == models.ProviderGemini
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Fallback)
+	assert.Equal(t, "acc-3", result.AccountID)
+
+	_, err = client.ExecuteWithFilter(context.Background(), failing, func(*models.Account) bool { return false })
+	assert.Error(t, err)
+}
+
 // Test concurrency and race conditions
 func TestFailOpenClient_Concurrency(t *testing.T) {
 	t.Run("concurrent requests", func(t *testing.T) {
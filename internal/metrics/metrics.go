@@ -15,10 +15,16 @@ type Metrics struct {
 	QuotaUtilization *prometheus.GaugeVec
 	// RouterDecisions counts router decisions by policy and outcome
 	RouterDecisions *prometheus.CounterVec
+	// RouterAffinity counts session affinity hits and misses
+	RouterAffinity *prometheus.CounterVec
 	// ReservationMetrics tracks reservation operations
 	ReservationMetrics *prometheus.CounterVec
 	// CollectorMetrics tracks collector operations
 	CollectorMetrics *prometheus.CounterVec
+	// CollectorErrors counts failed quota polls by provider and error class
+	CollectorErrors *prometheus.CounterVec
+	// CollectorBreakerState tracks collector circuit breakers by scope and key
+	CollectorBreakerState *prometheus.GaugeVec
 	// ErrorCounter counts errors by type and endpoint
 	ErrorCounter *prometheus.CounterVec
 	// AccountHealth tracks health status for account
@@ -72,6 +78,14 @@ func NewMetrics(namespace string) *Metrics {
 			},
 			[]string{"policy", "outcome", "provider"},
 		),
+		RouterAffinity: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "router_affinity_total",
+				Help:      "Total number of session affinity lookups by result",
+			},
+			[]string{"result", "provider"},
+		),
 		ReservationMetrics: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
@@ -88,6 +102,22 @@ func NewMetrics(namespace string) *Metrics {
 			},
 			[]string{"operation", "status", "source"},
 		),
+		CollectorErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "collector_errors_total",
+				Help:      "Total number of failed quota polls by error class",
+			},
+			[]string{"provider", "class"},
+		),
+		CollectorBreakerState: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "collector_circuit_breaker_state",
+				Help:      "State of collector circuit breakers (0=closed, 1=open, 2=half-open)",
+			},
+			[]string{"scope", "key"},
+		),
 		ErrorCounter: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
@@ -166,8 +196,11 @@ func NewMetrics(namespace string) *Metrics {
 		m.RequestLatency,
 		m.QuotaUtilization,
 		m.RouterDecisions,
+		m.RouterAffinity,
 		m.ReservationMetrics,
 		m.CollectorMetrics,
+		m.CollectorErrors,
+		m.CollectorBreakerState,
 		m.ErrorCounter,
 		m.AccountHealth,
 		m.HTTPRequestsTotal,
@@ -202,6 +235,15 @@ func (m *Metrics) RecordRouterDecision(policy, outcome, provider string) {
 	m.RouterDecisions.WithLabelValues(policy, outcome, provider).Inc()
 }
 
+// RecordRouterAffinity records a session affinity hit or miss
+func (m *Metrics) RecordRouterAffinity(hit bool, provider string) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.RouterAffinity.WithLabelValues(result, provider).Inc()
+}
+
 // RecordReservation records a reservation operation
 func (m *Metrics) RecordReservation(operation, status string) {
 	m.ReservationMetrics.WithLabelValues(operation, status).Inc()
@@ -212,6 +254,16 @@ func (m *Metrics) RecordCollector(operation, status, source string) {
 	m.CollectorMetrics.WithLabelValues(operation, status, source).Inc()
 }
 
+// RecordCollectorError records a failed quota poll
+func (m *Metrics) RecordCollectorError(provider, class string) {
+	m.CollectorErrors.WithLabelValues(provider, class).Inc()
+}
+
+// SetCollectorBreakerState sets the state of a collector circuit breaker
+func (m *Metrics) SetCollectorBreakerState(scope, key string, state int) {
+	m.CollectorBreakerState.WithLabelValues(scope, key).Set(float64(state))
+}
+
 // RecordError records an error
 func (m *Metrics) RecordError(errorType, endpoint, method string) {
 	m.ErrorCounter.WithLabelValues(errorType, endpoint, method).Inc()
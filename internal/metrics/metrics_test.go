@@ -12,8 +12,11 @@ func TestMetricsRecordingAndHandler(t *testing.T) {
 	m.RecordRequestLatency("/health", "GET", "200", 0.01)
 	m.RecordQuotaUtilization("acc", "openai", "rpm", 75.5)
 	m.RecordRouterDecision("balanced", "selected", "openai")
+	m.RecordRouterAffinity(true, "openai")
 	m.RecordReservation("create", "success")
 	m.RecordCollector("poll", "success", "cron")
+	m.RecordCollectorError("gemini", "upstream_5xx")
+	m.SetCollectorBreakerState("provider", "gemini", 1)
 	m.RecordError("timeout", "/health", "GET")
 	m.SetAccountHealth("acc", "openai", true)
 	m.RecordHTTPRequest("/health", "GET", "200")
@@ -37,6 +40,9 @@ func TestMetricsRecordingAndHandler(t *testing.T) {
 	if !strings.Contains(body, "test_request_latency_seconds") {
 		t.Fatalf("expected metrics output to contain request latency metric")
 	}
+	if !strings.Contains(body, `test_collector_circuit_breaker_state{key="gemini",scope="provider"} 1`) {
+		t.Fatalf("expected metrics output to contain collector breaker state")
+	}
 
 	if _, err := m.registry.Gather(); err != nil {
 		t.Fatalf("expected gather to succeed: %v", err)
@@ -43,10 +43,19 @@ func AuditMiddleware(auditStore logging.AuditStore) gin.HandlerFunc {
 			"user_agent": c.Request.UserAgent(),
 		}
 
-		// Add user ID if authenticated
+		// Add user ID and auth identity if authenticated
 		if userID, exists := c.Get("user_id"); exists {
 			event.UserID = userID.(string)
 		}
+		if authType := c.GetString("auth_type"); authType != "" {
+			event.Details["auth_type"] = authType
+		}
+		if clientID := c.GetString("client_id"); clientID != "" {
+			event.Details["client_id"] = clientID
+		}
+		if authErr := c.GetString("auth_error"); authErr != "" {
+			event.ErrorMessage = authErr
+		}
 
 		// Save asynchronously to not block the request
 		auditStore.SaveEventAsync(event)
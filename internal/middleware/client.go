@@ -252,6 +252,84 @@ func (c *CLIProxyAPIClient) UpdateQuota(ctx context.Context, accountID string, r
 	return nil
 }
 
+// ManagedAuthFile is an auth file as listed by the CLIProxy management API.
+type ManagedAuthFile struct {
+	Name      string    `json:"name"`
+	Type      string    `json:"type,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	Disabled  bool      `json:"disabled"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// ListAuthFiles lists the auth files CLIProxy rotates through.
+func (c *CLIProxyAPIClient) ListAuthFiles(ctx context.Context) ([]ManagedAuthFile, error) {
+	url := fmt.Sprintf("%s/v0/management/auth-files", c.baseURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setManagementAuth(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("auth files request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("auth files request returned status %d", resp.StatusCode)
+	}
+
+	var list struct {
+		Files []ManagedAuthFile `json:"files"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, MaxResponseBodySize)).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to decode auth files: %w", err)
+	}
+
+	return list.Files, nil
+}
+
+// SetAuthFileDisabled enables or disables an auth file in CLIProxy rotation.
+func (c *CLIProxyAPIClient) SetAuthFileDisabled(ctx context.Context, name string, disabled bool) error {
+	url := fmt.Sprintf("%s/v0/management/auth-files/status", c.baseURL)
+
+	body, err := json.Marshal(map[string]interface{}{"name": name, "disabled": disabled})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.setManagementAuth(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("auth file status request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		// Limit body read to prevent DoS
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, MaxResponseBodySize))
+		return fmt.Errorf("auth file status request returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	return nil
+}
+
+// setManagementAuth authenticates a management API request. CLIProxy
+// expects its management key as a bearer token.
+func (c *CLIProxyAPIClient) setManagementAuth(req *http.Request) {
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+}
+
 // Close closes the client and cleans up resources.
 func (c *CLIProxyAPIClient) Close() error {
 	c.httpClient.CloseIdleConnections()
@@ -946,3 +946,39 @@ func mustEncode(t *testing.T, w http.ResponseWriter, v interface{}) {
 		t.Fatalf("encode response failed: %v", err)
 	}
 }
+
+func TestCLIProxyAPIClient_AuthFiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer mgmt-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			mustWrite(t, w, []byte(`{"files":[{"name":"codex-a@example.com.json","type":"codex","disabled":true}]}`))
+		case http.MethodPatch:
+			w.WriteHeader(http.StatusBadRequest)
+			mustWrite(t, w, []byte(`unknown auth file`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewCLIProxyAPIClient(server.URL, WithAPIKey("mgmt-key"))
+	files, err := client.ListAuthFiles(ctx)
+	if err != nil {
+		t.Fatalf("ListAuthFiles failed: %v", err)
+	}
+	if len(files) != 1 || files[0].Name != "codex-a@example.com.json" || !files[0].Disabled {
+		t.Errorf("unexpected auth files: %+v", files)
+	}
+
+	if err := client.SetAuthFileDisabled(ctx, "missing.json", true); err == nil {
+		t.Error("expected error for non-2xx status, got nil")
+	}
+
+	if _, err := NewCLIProxyAPIClient(server.URL).ListAuthFiles(ctx); err == nil {
+		t.Error("expected error without management key, got nil")
+	}
+}
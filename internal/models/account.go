@@ -10,14 +10,28 @@ import (
 type Provider string
 
 const (
-	ProviderOpenAI    Provider = "openai"
-	ProviderAnthropic Provider = "anthropic"
-	ProviderGemini    Provider = "gemini"
-	ProviderQwen      Provider = "qwen"
-	ProviderAzure     Provider = "azure"
-	ProviderOther     Provider = "other"
+	ProviderOpenAI     Provider = "openai"
+	ProviderAnthropic  Provider = "anthropic"
+	ProviderGemini     Provider = "gemini"
+	ProviderQwen       Provider = "qwen"
+	ProviderAzure      Provider = "azure"
+	ProviderOpenRouter Provider = "openrouter"
+	ProviderGroq       Provider = "groq"
+	ProviderMistral    Provider = "mistral"
+	ProviderDeepSeek   Provider = "deepseek"
+	ProviderOther      Provider = "other"
 )
 
+// IsKnown reports whether p is one of the supported providers.
+func (p Provider) IsKnown() bool {
+	switch p {
+	case ProviderOpenAI, ProviderAnthropic, ProviderGemini, ProviderQwen, ProviderAzure,
+		ProviderOpenRouter, ProviderGroq, ProviderMistral, ProviderDeepSeek, ProviderOther:
+		return true
+	}
+	return false
+}
+
 // Account represents an LLM provider account.
 type Account struct {
 	ID               string     `json:"id"`
@@ -27,15 +41,36 @@ type Account struct {
 	Enabled          bool       `json:"enabled"`
 	Priority         int        `json:"priority"`
 	ConcurrencyLimit int        `json:"concurrency_limit"`
-	InputCost        float64    `json:"input_cost_per_1k"`  // per 1K tokens
-	OutputCost       float64    `json:"output_cost_per_1k"` // per 1K tokens
+	InputCost        float64    `json:"input_cost_per_1k"`            // per 1K tokens
+	OutputCost       float64    `json:"output_cost_per_1k"`           // per 1K tokens
+	MonthlyBudget    float64    `json:"monthly_budget_usd,omitempty"` // hard spend cap, 0 = none
 	CredentialsRef   string     `json:"credentials_ref"`
 	OAuthCredsPath   string     `json:"oauth_creds_path"`
 	BlockedUntil     *time.Time `json:"blocked_until,omitempty"`
+	LastPollError    *PollError `json:"last_poll_error,omitempty"`
 	CreatedAt        time.Time  `json:"created_at"`
 	UpdatedAt        time.Time  `json:"updated_at"`
 }
 
+// PollErrorClass categorizes why the active collector failed to poll an account.
+type PollErrorClass string
+
+const (
+	PollErrorAuth        PollErrorClass = "auth_revoked"
+	PollErrorRateLimited PollErrorClass = "rate_limited"
+	PollErrorUpstream    PollErrorClass = "upstream_5xx"
+	PollErrorNetwork     PollErrorClass = "network"
+	PollErrorParse       PollErrorClass = "parse_error"
+	PollErrorUnknown     PollErrorClass = "unknown"
+)
+
+// PollError is the last failure the active collector hit for an account.
+type PollError struct {
+	Class   PollErrorClass `json:"class"`
+	Message string         `json:"message"`
+	At      time.Time      `json:"at"`
+}
+
 // Validate checks if the account is valid.
 func (a *Account) Validate() error {
 	if a.ID == "" {
@@ -53,6 +88,9 @@ func (a *Account) Validate() error {
 	if a.OutputCost < 0 {
 		return fmt.Errorf("output cost cannot be negative")
 	}
+	if a.MonthlyBudget < 0 {
+		return fmt.Errorf("monthly budget cannot be negative")
+	}
 	return nil
 }
 
@@ -68,6 +106,11 @@ func (a *Account) EstimatedCost(inputTokens, outputTokens int64) float64 {
 	return inputCost + outputCost
 }
 
+// HasBudget reports whether the account has a monthly spend cap.
+func (a *Account) HasBudget() bool {
+	return a.MonthlyBudget > 0
+}
+
 // AccountSlice is a slice of accounts with helper methods.
 type AccountSlice []Account
 
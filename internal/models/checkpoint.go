@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// LogCheckpoint records how far a followed log file has been processed.
+// Fingerprint identifies the file by its first line so a rotated file is
+// not resumed at the old offset.
+type LogCheckpoint struct {
+	Path        string    `json:"path"`
+	Fingerprint string    `json:"fingerprint"`
+	Offset      int64     `json:"offset"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// APIClient is a named consumer of the QuotaGuard API with its own key and limits.
+type APIClient struct {
+	ID                 string     `json:"id"`
+	Name               string     `json:"name"`
+	KeyHash            string     `json:"-"`
+	KeyPrefix          string     `json:"key_prefix"`
+	AllowedProviders   []Provider `json:"allowed_providers,omitempty"`
+	AllowedAccounts    []string   `json:"allowed_accounts,omitempty"`
+	DefaultPolicy      string     `json:"default_policy,omitempty"`
+	RateLimitRPM       int        `json:"rate_limit_rpm,omitempty"`
+	DailyQuotaSharePct float64    `json:"daily_quota_share_percent,omitempty"`
+	Enabled            bool       `json:"enabled"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// Validate checks if the client is valid.
+func (c *APIClient) Validate() error {
+	if c.ID == "" {
+		return fmt.Errorf("client ID is required")
+	}
+	if c.KeyHash == "" {
+		return fmt.Errorf("key hash is required")
+	}
+	if c.RateLimitRPM < 0 {
+		return fmt.Errorf("rate limit cannot be negative")
+	}
+	if c.DailyQuotaSharePct < 0 || c.DailyQuotaSharePct > 100 {
+		return fmt.Errorf("daily quota share must be between 0 and 100")
+	}
+	for _, p := range c.AllowedProviders {
+		if !p.IsKnown() {
+			return fmt.Errorf("unknown provider %q", p)
+		}
+	}
+	return nil
+}
+
+// AllowsProvider reports whether the client may route to the provider.
+// An empty allow list permits every provider.
+func (c *APIClient) AllowsProvider(provider Provider) bool {
+	if len(c.AllowedProviders) == 0 {
+		return true
+	}
+	for _, p := range c.AllowedProviders {
+		if p == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsAccount reports whether the client may use the account.
+// The account must pass both the account and provider allow lists.
+func (c *APIClient) AllowsAccount(acc *Account) bool {
+	if acc == nil {
+		return false
+	}
+	if !c.AllowsProvider(acc.Provider) {
+		return false
+	}
+	if len(c.AllowedAccounts) == 0 {
+		return true
+	}
+	for _, id := range c.AllowedAccounts {
+		if id == acc.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientUsage tracks how much of the pool a client consumed on one day.
+// ConsumedPct is expressed in account-percent units: draining one account
+// from 100% to 0% consumes 100.
+type ClientUsage struct {
+	ClientID    string    `json:"client_id"`
+	Day         string    `json:"day"` // YYYY-MM-DD (UTC)
+	Requests    int64     `json:"requests"`
+	ConsumedPct float64   `json:"consumed_percent"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// APIKeyPrefix marks keys issued to named API clients.
+const APIKeyPrefix = "qg_"
+
+// DailyQuotaBudget returns the client's daily allowance in account-percent
+// units over the pool accounts it may use. Zero means unlimited.
+func (c *APIClient) DailyQuotaBudget(pool []*Account) float64 {
+	if c.DailyQuotaSharePct <= 0 {
+		return 0
+	}
+	allowed := 0
+	for _, acc := range pool {
+		if c.AllowsAccount(acc) {
+			allowed++
+		}
+	}
+	return float64(allowed) * c.DailyQuotaSharePct
+}
+
+// GenerateAPIKey returns a new random client key and its display prefix.
+func GenerateAPIKey() (key, prefix string, err error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key = APIKeyPrefix + hex.EncodeToString(buf)
+	return key, key[:len(APIKeyPrefix)+6], nil
+}
+
+// UsageDay returns the UTC day key used for client usage accounting.
+func UsageDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// HashAPIKey returns the hex-encoded SHA-256 hash used to store API keys.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIClient_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  APIClient
+		wantErr bool
+	}{
+		{"valid", APIClient{ID: "ci", KeyHash: "hash", RateLimitRPM: 60, DailyQuotaSharePct: 20}, false},
+		{"missing ID", APIClient{KeyHash: "hash"}, true},
+		{"missing key hash", APIClient{ID: "ci"}, true},
+		{"negative rate limit", APIClient{ID: "ci", KeyHash: "hash", RateLimitRPM: -1}, true},
+		{"share above 100", APIClient{ID: "ci", KeyHash: "hash", DailyQuotaSharePct: 120}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.client.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAPIClient_ValidateProviders(t *testing.T) {
+	client := &APIClient{ID: "batch", KeyHash: "hash", AllowedProviders: []Provider{ProviderOpenAI, ProviderOther}}
+	assert.NoError(t, client.Validate())
+
+	client.AllowedProviders = append(client.AllowedProviders, "open-ai")
+	assert.ErrorContains(t, client.Validate(), `unknown provider "open-ai"`)
+}
+
+func TestAPIClient_Allows(t *testing.T) {
+	openai := &Account{ID: "codex-1", Provider: ProviderOpenAI}
+	gemini := &Account{ID: "gemini-1", Provider: ProviderGemini}
+
+	unrestricted := &APIClient{}
+	assert.True(t, unrestricted.AllowsProvider(ProviderAnthropic))
+	assert.True(t, unrestricted.AllowsAccount(openai))
+	assert.False(t, unrestricted.AllowsAccount(nil))
+
+	byProvider := &APIClient{AllowedProviders: []Provider{ProviderOpenAI}}
+	assert.True(t, byProvider.AllowsAccount(openai))
+	assert.False(t, byProvider.AllowsAccount(gemini))
+
+	byAccount := &APIClient{AllowedAccounts: []string{"gemini-1"}}
+	assert.False(t, byAccount.AllowsAccount(openai))
+	assert.True(t, byAccount.AllowsAccount(gemini))
+}
+
+func TestAPIClient_DailyQuotaBudget(t *testing.T) {
+	pool := []*Account{
+		{ID: "codex-1", Provider: ProviderOpenAI},
+		{ID: "codex-2", Provider: ProviderOpenAI},
+		{ID: "gemini-1", Provider: ProviderGemini},
+		{ID: "gemini-2", Provider: ProviderGemini},
+	}
+	assert.Equal(t, 0.0, (&APIClient{}).DailyQuotaBudget(pool))
+	assert.Equal(t, 100.0, (&APIClient{DailyQuotaSharePct: 25}).DailyQuotaBudget(pool))
+	assert.Equal(t, 50.0, (&APIClient{DailyQuotaSharePct: 25, AllowedProviders: []Provider{ProviderGemini}}).DailyQuotaBudget(pool))
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, err := GenerateAPIKey()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, APIKeyPrefix))
+	assert.True(t, strings.HasPrefix(key, prefix))
+
+	other, _, err := GenerateAPIKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+	assert.Len(t, HashAPIKey(key), 64)
+	assert.NotEqual(t, HashAPIKey(key), HashAPIKey(other))
+}
@@ -78,6 +78,14 @@ func (d *Dimension) RemainingPercent() float64 {
 	return float64(d.Remaining) / float64(d.Limit) * 100
 }
 
+// Label returns the dimension name, or its type when it has none.
+func (d *Dimension) Label() string {
+	if d.Name != "" {
+		return d.Name
+	}
+	return string(d.Type)
+}
+
 // IsExhausted returns true if the quota is exhausted.
 func (d *Dimension) IsExhausted() bool {
 	return d.Remaining <= 0
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// EffectiveDimension names samples of the effective remaining percent,
+// recorded for quotas that report no usable dimensions.
+const EffectiveDimension = "effective"
+
+// resetJump is the rise in remaining percent between two samples that is
+// taken as a reset even when the quota reports no reset time
+const resetJump = 25.0
+
+// QuotaSample is one recorded reading of a quota dimension.
+type QuotaSample struct {
+	AccountID    string     `json:"account_id"`
+	Provider     Provider   `json:"provider"`
+	Dimension    string     `json:"dimension"`
+	RemainingPct float64    `json:"remaining_percent"`
+	ResetAt      *time.Time `json:"reset_at,omitempty"`
+	CollectedAt  time.Time  `json:"collected_at"`
+}
+
+// QuotaSamples splits a quota into one sample per dimension taken at at.
+// Dimensions without a limit are skipped; when none are left the effective
+// remaining percent is sampled instead.
+func QuotaSamples(accountID string, q *QuotaInfo, at time.Time) []*QuotaSample {
+	samples := make([]*QuotaSample, 0, len(q.Dimensions))
+	for i := range q.Dimensions {
+		d := &q.Dimensions[i]
+		if d.Limit <= 0 {
+			continue
+		}
+		samples = append(samples, &QuotaSample{
+			AccountID:    accountID,
+			Provider:     q.Provider,
+			Dimension:    d.Label(),
+			RemainingPct: d.RemainingPercent(),
+			ResetAt:      d.ResetAt,
+			CollectedAt:  at,
+		})
+	}
+	if len(samples) == 0 {
+		samples = append(samples, &QuotaSample{
+			AccountID:    accountID,
+			Provider:     q.Provider,
+			Dimension:    EffectiveDimension,
+			RemainingPct: q.EffectiveRemainingPct,
+			CollectedAt:  at,
+		})
+	}
+	return samples
+}
+
+// ResetBetween reports whether the quota reset between two consecutive
+// samples of a dimension, and when. The previous sample's reset time is
+// used when it falls between them.
+func ResetBetween(prev, cur *QuotaSample) (time.Time, bool) {
+	if cur.RemainingPct <= prev.RemainingPct {
+		return time.Time{}, false
+	}
+	if prev.ResetAt != nil && !cur.CollectedAt.Before(*prev.ResetAt) {
+		if prev.ResetAt.After(prev.CollectedAt) {
+			return *prev.ResetAt, true
+		}
+		return cur.CollectedAt, true
+	}
+	if cur.RemainingPct-prev.RemainingPct >= resetJump {
+		return cur.CollectedAt, true
+	}
+	return time.Time{}, false
+}
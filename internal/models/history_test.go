@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaSamples(t *testing.T) {
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	resetAt := at.Add(time.Hour)
+	q := &QuotaInfo{
+		Provider:              ProviderOpenAI,
+		EffectiveRemainingPct: 40,
+		Dimensions: DimensionSlice{
+			{Type: DimensionRPM, Limit: 100, Remaining: 40, ResetAt: &resetAt},
+			{Type: DimensionTPD, Name: "daily_tokens", Limit: 1000, Remaining: 900},
+			{Type: DimensionRPD, Limit: 0},
+		},
+	}
+
+	samples := QuotaSamples("acc-1", q, at)
+	require.Len(t, samples, 2)
+	assert.Equal(t, "acc-1", samples[0].AccountID)
+	assert.Equal(t, ProviderOpenAI, samples[0].Provider)
+	assert.Equal(t, string(DimensionRPM), samples[0].Dimension)
+	assert.InDelta(t, 40, samples[0].RemainingPct, 1e-9)
+	assert.Equal(t, &resetAt, samples[0].ResetAt)
+	assert.Equal(t, "daily_tokens", samples[1].Dimension)
+	assert.InDelta(t, 90, samples[1].RemainingPct, 1e-9)
+	assert.Equal(t, at, samples[1].CollectedAt)
+
+	// Without usable dimensions the effective percent is sampled
+	samples = QuotaSamples("acc-1", &QuotaInfo{EffectiveRemainingPct: 55}, at)
+	require.Len(t, samples, 1)
+	assert.Equal(t, EffectiveDimension, samples[0].Dimension)
+	assert.InDelta(t, 55, samples[0].RemainingPct, 1e-9)
+}
@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// IncidentStatus is the lifecycle state of an alert incident
+type IncidentStatus string
+
+const (
+	// IncidentOpen is an incident nobody has acknowledged yet
+	IncidentOpen IncidentStatus = "open"
+	// IncidentAcknowledged is an incident someone is looking at
+	IncidentAcknowledged IncidentStatus = "acknowledged"
+	// IncidentResolved is an incident whose condition has cleared
+	IncidentResolved IncidentStatus = "resolved"
+)
+
+// AlertIncident tracks one alert condition on one account from the first
+// alert until the condition clears. Repeated alerts of the same type for
+// the same account update the incident instead of opening another.
+type AlertIncident struct {
+	ID           string         `json:"id"`
+	AccountID    string         `json:"account_id"`
+	Type         string         `json:"type"`
+	Severity     string         `json:"severity"`
+	Message      string         `json:"message"`
+	Status       IncidentStatus `json:"status"`
+	Count        int            `json:"count"`
+	OpenedAt     time.Time      `json:"opened_at"`
+	LastSeenAt   time.Time      `json:"last_seen_at"`
+	AckedAt      *time.Time     `json:"acked_at,omitempty"`
+	AckedBy      int64          `json:"acked_by,omitempty"`
+	SnoozedUntil *time.Time     `json:"snoozed_until,omitempty"`
+	EscalatedAt  *time.Time     `json:"escalated_at,omitempty"`
+	ResolvedAt   *time.Time     `json:"resolved_at,omitempty"`
+}
+
+// IsActive reports whether the incident has not been resolved
+func (i *AlertIncident) IsActive() bool {
+	return i.Status != IncidentResolved
+}
+
+// IsSnoozed reports whether notifications for the incident are paused at now
+func (i *AlertIncident) IsSnoozed(now time.Time) bool {
+	return i.SnoozedUntil != nil && now.Before(*i.SnoozedUntil)
+}
+
+// AlertMute silences alerts for one account, one alert type, or one alert
+// type on one account until a point in time. An empty field matches any.
+type AlertMute struct {
+	AccountID string    `json:"account_id,omitempty"`
+	Type      string    `json:"type,omitempty"`
+	Until     time.Time `json:"until"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Matches reports whether the mute silences an alert of alertType on
+// accountID at now
+func (m *AlertMute) Matches(accountID, alertType string, now time.Time) bool {
+	if !now.Before(m.Until) {
+		return false
+	}
+	if m.AccountID != "" && m.AccountID != accountID {
+		return false
+	}
+	return m.Type == "" || m.Type == alertType
+}
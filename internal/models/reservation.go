@@ -26,6 +26,7 @@ type Reservation struct {
 	ExpiresAt        time.Time         `json:"expires_at"`
 	ReleasedAt       *time.Time        `json:"released_at,omitempty"`
 	CorrelationID    string            `json:"correlation_id"`
+	ClientID         string            `json:"client_id,omitempty"`
 	ActualCostPct    *float64          `json:"actual_cost_percent,omitempty"`
 }
 
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// RouterScopeState stores anti-flapping state for one routing scope
+// (for example "provider:openai" or "model:gpt-5").
+type RouterScopeState struct {
+	Scope           string
+	CurrentAccount  string
+	PreviousAccount string
+	SelectedAt      time.Time
+	LastSwitchAt    *time.Time
+}
@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// RoutingEvent records one router selection: what was asked for, which
+// account was chosen and why, and, once feedback for the same correlation
+// ID arrives, how the request went.
+type RoutingEvent struct {
+	ID            int64     `json:"id"`
+	CorrelationID string    `json:"correlation_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	ClientID      string    `json:"client_id,omitempty"`
+	SessionID     string    `json:"session_id,omitempty"`
+
+	// Request attributes
+	Model              string   `json:"model,omitempty"`
+	RequestedProvider  string   `json:"requested_provider,omitempty"`
+	RequiredDimensions []string `json:"required_dimensions,omitempty"`
+	EstimatedCostPct   float64  `json:"estimated_cost_percent,omitempty"`
+	EstimatedTokens    int64    `json:"estimated_tokens,omitempty"`
+	Policy             string   `json:"policy"`
+
+	// Decision
+	AccountID      string   `json:"account_id"`
+	Provider       string   `json:"provider"`
+	Score          float64  `json:"score"`
+	Reason         string   `json:"reason"`
+	AlternativeIDs []string `json:"alternative_ids,omitempty"`
+	AffinityHit    bool     `json:"affinity_hit,omitempty"`
+	LatencyMs      float64  `json:"latency_ms"`
+	// Scope is the anti-flapping scope of the decision; SwitchedFrom is the
+	// account the scope was on before, set only when the decision moved it
+	Scope        string `json:"scope,omitempty"`
+	SwitchedFrom string `json:"switched_from,omitempty"`
+
+	Outcome *RoutingOutcome `json:"outcome,omitempty"`
+}
+
+// RoutingOutcome is the feedback reported for a routed request
+type RoutingOutcome struct {
+	Success       bool      `json:"success"`
+	Error         string    `json:"error,omitempty"`
+	ActualCostPct float64   `json:"actual_cost_percent,omitempty"`
+	InputTokens   int64     `json:"input_tokens,omitempty"`
+	OutputTokens  int64     `json:"output_tokens,omitempty"`
+	ReportedAt    time.Time `json:"reported_at"`
+}
+
+// RoutingEventFilter selects routing events. Empty fields match any event.
+type RoutingEventFilter struct {
+	AccountID     string
+	ClientID      string
+	CorrelationID string
+	Since         time.Time
+	Until         time.Time
+	// Limit caps the number of events returned, newest first
+	Limit int
+}
+
+// Matches reports whether the event passes the filter, ignoring Limit
+func (f RoutingEventFilter) Matches(e *RoutingEvent) bool {
+	if f.AccountID != "" && e.AccountID != f.AccountID {
+		return false
+	}
+	if f.ClientID != "" && e.ClientID != f.ClientID {
+		return false
+	}
+	if f.CorrelationID != "" && e.CorrelationID != f.CorrelationID {
+		return false
+	}
+	if !f.Since.IsZero() && e.CreatedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.CreatedAt.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// RoutingEventSummary aggregates the routing events of a time range
+type RoutingEventSummary struct {
+	Events   int `json:"events"`
+	Switches int `json:"switches"`
+	// Failures counts failed outcomes by the reported error
+	Failures map[string]int `json:"failures"`
+}
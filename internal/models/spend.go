@@ -0,0 +1,123 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// SourceLedger marks dimensions derived from the local spend ledger.
+const SourceLedger Source = "LEDGER"
+
+// SpendEntry is the accrued spend of one account for one client on one UTC day.
+// An empty ClientID holds spend from callers that are not named API clients.
+type SpendEntry struct {
+	AccountID    string    `json:"account_id"`
+	ClientID     string    `json:"client_id,omitempty"`
+	Day          string    `json:"day"`
+	Requests     int64     `json:"requests"`
+	InputTokens  int64     `json:"input_tokens"`
+	OutputTokens int64     `json:"output_tokens"`
+	CostUSD      float64   `json:"cost_usd"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// NewSpendEntry prices one request on an account using its per-1K token costs.
+func NewSpendEntry(acc *Account, clientID string, inputTokens, outputTokens int64, at time.Time) *SpendEntry {
+	return &SpendEntry{
+		AccountID:    acc.ID,
+		ClientID:     clientID,
+		Day:          UsageDay(at),
+		Requests:     1,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		CostUSD:      acc.EstimatedCost(inputTokens, outputTokens),
+	}
+}
+
+// Validate checks if the spend entry is valid.
+func (e *SpendEntry) Validate() error {
+	if e.AccountID == "" {
+		return fmt.Errorf("account ID is required")
+	}
+	if e.Day == "" {
+		return fmt.Errorf("day is required")
+	}
+	if e.InputTokens < 0 || e.OutputTokens < 0 {
+		return fmt.Errorf("token counts cannot be negative")
+	}
+	if e.CostUSD < 0 {
+		return fmt.Errorf("cost cannot be negative")
+	}
+	return nil
+}
+
+// MonthStartDay returns the usage day key of the first day of t's UTC month.
+func MonthStartDay(t time.Time) string {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+}
+
+// NextMonthStart returns the start of the UTC month following t.
+func NextMonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// BudgetDimension builds the synthetic BUDGET dimension for a monthly cap.
+// Amounts are tracked in cents so the dimension fits the integer limits.
+func BudgetDimension(monthlyBudget, spent float64, now time.Time) Dimension {
+	limit := int64(math.Round(monthlyBudget * 100))
+	used := int64(math.Round(spent * 100))
+	if used > limit {
+		used = limit
+	}
+	resetAt := NextMonthStart(now)
+	return Dimension{
+		Name:       "monthly_budget_cents",
+		Type:       DimensionBudget,
+		Limit:      limit,
+		Used:       used,
+		Remaining:  limit - used,
+		ResetAt:    &resetAt,
+		Semantics:  WindowFixed,
+		Source:     SourceLedger,
+		Confidence: 1.0,
+	}
+}
+
+// SpendTotal aggregates ledger entries that share a key.
+type SpendTotal struct {
+	Key          string  `json:"key"`
+	Requests     int64   `json:"requests"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// GroupSpend sums entries by key, ordered by cost (highest first).
+func GroupSpend(entries []*SpendEntry, key func(*SpendEntry) string) []SpendTotal {
+	index := make(map[string]int)
+	totals := make([]SpendTotal, 0)
+	for _, entry := range entries {
+		k := key(entry)
+		i, ok := index[k]
+		if !ok {
+			i = len(totals)
+			index[k] = i
+			totals = append(totals, SpendTotal{Key: k})
+		}
+		totals[i].Requests += entry.Requests
+		totals[i].InputTokens += entry.InputTokens
+		totals[i].OutputTokens += entry.OutputTokens
+		totals[i].CostUSD += entry.CostUSD
+	}
+	sort.SliceStable(totals, func(i, j int) bool {
+		if totals[i].CostUSD != totals[j].CostUSD {
+			return totals[i].CostUSD > totals[j].CostUSD
+		}
+		return totals[i].Key < totals[j].Key
+	})
+	return totals
+}
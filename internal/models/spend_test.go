@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSpendEntry(t *testing.T) {
+	acc := &Account{ID: "api-1", InputCost: 0.003, OutputCost: 0.015}
+	at := time.Date(2026, 3, 14, 23, 30, 0, 0, time.UTC)
+
+	entry := NewSpendEntry(acc, "batch", 10000, 2000, at)
+	assert.Equal(t, "2026-03-14", entry.Day)
+	assert.Equal(t, int64(1), entry.Requests)
+	assert.InDelta(t, 0.06, entry.CostUSD, 1e-9)
+	assert.NoError(t, entry.Validate())
+
+	assert.Error(t, (&SpendEntry{Day: "2026-03-14"}).Validate())
+	assert.Error(t, (&SpendEntry{AccountID: "api-1", Day: "2026-03-14", InputTokens: -1}).Validate())
+}
+
+func TestBudgetDimension(t *testing.T) {
+	now := time.Date(2026, 12, 10, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2026-12-01", MonthStartDay(now))
+
+	dim := BudgetDimension(50, 12.345, now)
+	assert.Equal(t, DimensionBudget, dim.Type)
+	assert.Equal(t, int64(5000), dim.Limit)
+	assert.Equal(t, int64(1235), dim.Used)
+	assert.Equal(t, int64(3765), dim.Remaining)
+	require.NotNil(t, dim.ResetAt)
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), *dim.ResetAt)
+	assert.NoError(t, dim.Validate())
+
+	// Overspend clamps to an exhausted dimension
+	dim = BudgetDimension(50, 80, now)
+	assert.Equal(t, int64(0), dim.Remaining)
+	assert.True(t, dim.IsExhausted())
+}
+
+func TestGroupSpend(t *testing.T) {
+	entries := []*SpendEntry{
+		{AccountID: "a", ClientID: "x", Requests: 1, CostUSD: 1},
+		{AccountID: "b", ClientID: "x", Requests: 2, CostUSD: 5},
+		{AccountID: "a", ClientID: "y", Requests: 3, CostUSD: 2},
+	}
+
+	byAccount := GroupSpend(entries, func(e *SpendEntry) string { return e.AccountID })
+	require.Len(t, byAccount, 2)
+	assert.Equal(t, "b", byAccount[0].Key)
+	assert.Equal(t, "a", byAccount[1].Key)
+	assert.Equal(t, int64(4), byAccount[1].Requests)
+	assert.Equal(t, 3.0, byAccount[1].CostUSD)
+
+	byClient := GroupSpend(entries, func(e *SpendEntry) string { return e.ClientID })
+	assert.Equal(t, "x", byClient[0].Key)
+	assert.Equal(t, 6.0, byClient[0].CostUSD)
+}
@@ -54,6 +54,13 @@ func NewManager(s store.Store, cfg Config) *Manager {
 
 // Create creates a new reservation for the given account.
 func (m *Manager) Create(ctx context.Context, accountID string, estimatedCostPct float64, correlationID string) (*models.Reservation, error) {
+	return m.CreateForClient(ctx, accountID, estimatedCostPct, correlationID, "")
+}
+
+// CreateForClient creates a new reservation on behalf of an API client.
+// The estimated cost is charged to the client's daily usage and corrected
+// when the reservation is released, cancelled or expires.
+func (m *Manager) CreateForClient(ctx context.Context, accountID string, estimatedCostPct float64, correlationID, clientID string) (*models.Reservation, error) {
 	m.opMu.Lock()
 	defer m.opMu.Unlock()
 
@@ -81,6 +88,7 @@ func (m *Manager) Create(ctx context.Context, accountID string, estimatedCostPct
 		CreatedAt:        time.Now(),
 		ExpiresAt:        time.Now().Add(m.defaultTTL),
 		CorrelationID:    correlationID,
+		ClientID:         clientID,
 	}
 
 	if err := reservation.Validate(); err != nil {
@@ -93,6 +101,7 @@ func (m *Manager) Create(ctx context.Context, accountID string, estimatedCostPct
 
 	// Store reservation
 	m.store.SetReservation(reservation.ID, reservation)
+	m.chargeClient(reservation, estimatedCostPct)
 
 	// Update metrics
 	m.mu.Lock()
@@ -110,6 +119,12 @@ func (m *Manager) Get(reservationID string) (*models.Reservation, bool) {
 
 // Release releases a reservation with the actual cost.
 func (m *Manager) Release(reservationID string, actualCostPct float64) error {
+	return m.ReleaseWithTokens(reservationID, actualCostPct, 0, 0)
+}
+
+// ReleaseWithTokens releases a reservation and records the request's token
+// usage in the spend ledger, priced with the account's token costs.
+func (m *Manager) ReleaseWithTokens(reservationID string, actualCostPct float64, inputTokens, outputTokens int64) error {
 	m.opMu.Lock()
 	defer m.opMu.Unlock()
 
@@ -132,6 +147,8 @@ func (m *Manager) Release(reservationID string, actualCostPct float64) error {
 		quota.AddVirtualUsed(actualCostPct)
 		m.store.SetQuota(res.AccountID, quota)
 	}
+	m.chargeClient(res, actualCostPct-res.EstimatedCostPct)
+	m.recordSpend(res, inputTokens, outputTokens)
 
 	// Update metrics
 	m.mu.Lock()
@@ -165,6 +182,7 @@ func (m *Manager) Cancel(reservationID string) error {
 		quota.ReleaseVirtualUsed(res.EstimatedCostPct)
 		m.store.SetQuota(res.AccountID, quota)
 	}
+	m.chargeClient(res, -res.EstimatedCostPct)
 
 	// Update metrics
 	m.mu.Lock()
@@ -198,6 +216,7 @@ func (m *Manager) Expire(reservationID string) error {
 		quota.ReleaseVirtualUsed(res.EstimatedCostPct)
 		m.store.SetQuota(res.AccountID, quota)
 	}
+	m.chargeClient(res, -res.EstimatedCostPct)
 
 	// Update metrics
 	m.mu.Lock()
@@ -275,6 +294,27 @@ func (m *Manager) GetTotalReservedPct(accountID string) float64 {
 	return total
 }
 
+// chargeClient adjusts the daily usage of the client that owns a reservation.
+// Usage is attributed to the day the reservation was created.
+func (m *Manager) chargeClient(res *models.Reservation, deltaPct float64) {
+	if res.ClientID == "" || deltaPct == 0 {
+		return
+	}
+	_ = m.store.AddClientUsage(res.ClientID, models.UsageDay(res.CreatedAt), 0, deltaPct)
+}
+
+// recordSpend adds a released request's token usage to the spend ledger.
+func (m *Manager) recordSpend(res *models.Reservation, inputTokens, outputTokens int64) {
+	if inputTokens <= 0 && outputTokens <= 0 {
+		return
+	}
+	acc, ok := m.store.GetAccount(res.AccountID)
+	if !ok {
+		return
+	}
+	_ = m.store.AddSpend(models.NewSpendEntry(acc, res.ClientID, inputTokens, outputTokens, time.Now()))
+}
+
 // GetMetrics returns current metrics.
 func (m *Manager) GetMetrics() Metrics {
 	m.mu.RLock()
@@ -71,6 +71,60 @@ func TestManager_Create(t *testing.T) {
 	})
 }
 
+func TestManager_CreateForClient(t *testing.T) {
+	s := store.NewMemoryStore()
+	m := NewManager(s, DefaultConfig())
+	s.SetQuota("acc-1", &models.QuotaInfo{AccountID: "acc-1", EffectiveRemainingPct: 80.0})
+	ctx := context.Background()
+
+	res, err := m.CreateForClient(ctx, "acc-1", 10.0, "corr-1", "batch")
+	require.NoError(t, err)
+	assert.Equal(t, "batch", res.ClientID)
+
+	day := models.UsageDay(res.CreatedAt)
+	usage, ok := s.GetClientUsage("batch", day)
+	require.True(t, ok)
+	assert.Equal(t, 10.0, usage.ConsumedPct)
+
+	// Release charges the actual cost instead of the estimate
+	require.NoError(t, m.Release(res.ID, 4.0))
+	usage, _ = s.GetClientUsage("batch", day)
+	assert.Equal(t, 4.0, usage.ConsumedPct)
+
+	// Cancel refunds the estimate
+	res, err = m.CreateForClient(ctx, "acc-1", 6.0, "corr-2", "batch")
+	require.NoError(t, err)
+	require.NoError(t, m.Cancel(res.ID))
+	usage, _ = s.GetClientUsage("batch", day)
+	assert.Equal(t, 4.0, usage.ConsumedPct)
+
+	// Reservations without a client are not charged
+	_, err = m.Create(ctx, "acc-1", 5.0, "corr-3")
+	require.NoError(t, err)
+	usage, _ = s.GetClientUsage("batch", day)
+	assert.Equal(t, 4.0, usage.ConsumedPct)
+}
+
+func TestManager_ReleaseWithTokens(t *testing.T) {
+	s := store.NewMemoryStore()
+	m := NewManager(s, DefaultConfig())
+	s.SetAccount(&models.Account{ID: "acc-1", Provider: models.ProviderOpenAI, InputCost: 0.003, OutputCost: 0.015})
+	s.SetQuota("acc-1", &models.QuotaInfo{AccountID: "acc-1", EffectiveRemainingPct: 80.0})
+
+	res, err := m.CreateForClient(context.Background(), "acc-1", 5.0, "corr-1", "batch")
+	require.NoError(t, err)
+	require.NoError(t, m.ReleaseWithTokens(res.ID, 3.0, 2000, 1000))
+
+	day := models.UsageDay(time.Now())
+	entries, err := s.ListSpend(day, day)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "batch", entries[0].ClientID)
+	assert.Equal(t, int64(2000), entries[0].InputTokens)
+	assert.Equal(t, int64(1000), entries[0].OutputTokens)
+	assert.InDelta(t, 0.021, entries[0].CostUSD, 1e-9)
+}
+
 func TestManager_Get(t *testing.T) {
 	s := store.NewMemoryStore()
 	m := NewManager(s, DefaultConfig())
@@ -0,0 +1,122 @@
+package router
+
+import (
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// affinityEntry binds a session key to the account that served it
+type affinityEntry struct {
+	accountID string
+	expiresAt time.Time
+}
+
+// lookupAffinity returns the account bound to a session key if the binding is still live
+func (r *router) lookupAffinity(sessionKey string, now time.Time) (string, bool) {
+	if sessionKey == "" {
+		return "", false
+	}
+
+	r.mu.RLock()
+	entry, ok := r.affinity[sessionKey]
+	r.mu.RUnlock()
+
+	if !ok || now.After(entry.expiresAt) {
+		return "", false
+	}
+	return entry.accountID, true
+}
+
+// bindAffinity binds (or refreshes) a session key to an account
+func (r *router) bindAffinity(sessionKey, accountID string, now time.Time) {
+	if sessionKey == "" || accountID == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ttl := r.config.AffinityTTL
+	if ttl <= 0 {
+		return
+	}
+
+	if _, exists := r.affinity[sessionKey]; !exists && r.config.AffinityMaxSessions > 0 &&
+		len(r.affinity) >= r.config.AffinityMaxSessions {
+		r.evictAffinityLocked(now)
+	}
+
+	r.affinity[sessionKey] = affinityEntry{
+		accountID: accountID,
+		expiresAt: now.Add(ttl),
+	}
+}
+
+// dropAffinity removes a session binding
+func (r *router) dropAffinity(sessionKey string) {
+	if sessionKey == "" {
+		return
+	}
+	r.mu.Lock()
+	delete(r.affinity, sessionKey)
+	r.mu.Unlock()
+}
+
+// evictAffinityLocked removes expired bindings and, if the map is still full,
+// the binding closest to expiry. Caller must hold r.mu.
+func (r *router) evictAffinityLocked(now time.Time) {
+	for key, entry := range r.affinity {
+		if now.After(entry.expiresAt) {
+			delete(r.affinity, key)
+		}
+	}
+	if len(r.affinity) < r.config.AffinityMaxSessions {
+		return
+	}
+
+	oldestKey := ""
+	var oldest time.Time
+	for key, entry := range r.affinity {
+		if oldestKey == "" || entry.expiresAt.Before(oldest) {
+			oldestKey = key
+			oldest = entry.expiresAt
+		}
+	}
+	delete(r.affinity, oldestKey)
+}
+
+// recordAffinityResult updates hit/miss counters for session-keyed selections
+func (r *router) recordAffinityResult(hit bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if hit {
+		r.affinityHits++
+	} else {
+		r.affinityMisses++
+	}
+}
+
+// affinityAllowed checks whether a sticky account may keep serving its session.
+// Affinity breaks when the account crosses the switch threshold, is blocked
+// or its provider circuit is open.
+func (r *router) affinityAllowed(v *quotaView, acc *models.Account, now time.Time) (bool, string) {
+	if acc.BlockedUntil != nil && acc.BlockedUntil.After(now) {
+		return false, "account blocked"
+	}
+
+	if r.GetProviderCircuitState(acc.Provider) == CircuitOpen {
+		return false, "circuit open"
+	}
+
+	quota, ok := v.account(acc)
+	if !ok {
+		return false, "no quota data"
+	}
+	usedPercent := usedPercentFromRemaining(quota.EffectiveRemainingWithVirtual())
+	if quota.IsExhausted() || usedPercent >= r.config.SwitchThreshold {
+		return false, "usage above switch threshold"
+	}
+
+	return true, ""
+}
@@ -0,0 +1,175 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAffinityRouter(t *testing.T) (*store.MemoryStore, *router) {
+	t.Helper()
+
+	s := store.NewMemoryStore()
+	s.SetAccount(&models.Account{ID: "acc-1", Provider: models.ProviderOpenAI, Enabled: true, Priority: 5})
+	s.SetAccount(&models.Account{ID: "acc-2", Provider: models.ProviderAnthropic, Enabled: true, Priority: 5})
+	setRemaining(s, "acc-1", models.ProviderOpenAI, 80.0)
+	setRemaining(s, "acc-2", models.ProviderAnthropic, 50.0)
+
+	r := NewRouter(s, DefaultConfig()).(*router)
+	return s, r
+}
+
+func setRemaining(s *store.MemoryStore, accountID string, provider models.Provider, remaining float64) {
+	s.SetQuota(accountID, &models.QuotaInfo{
+		AccountID:             accountID,
+		Provider:              provider,
+		EffectiveRemainingPct: remaining,
+		Confidence:            1.0,
+	})
+}
+
+func TestRouter_SessionAffinity(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("follow-up request sticks to bound account", func(t *testing.T) {
+		s, r := setupAffinityRouter(t)
+
+		resp, err := r.Select(ctx, SelectRequest{SessionKey: "conv-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "acc-1", resp.AccountID)
+		assert.False(t, resp.AffinityHit)
+
+		// acc-2 now looks better, but the session should stay on acc-1
+		setRemaining(s, "acc-2", models.ProviderAnthropic, 100.0)
+		setRemaining(s, "acc-1", models.ProviderOpenAI, 40.0)
+
+		resp, err = r.Select(ctx, SelectRequest{SessionKey: "conv-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "acc-1", resp.AccountID)
+		assert.True(t, resp.AffinityHit)
+		assert.Contains(t, resp.Reason, "session affinity")
+		assert.Contains(t, resp.AlternativeIDs, "acc-2")
+
+		// A different session is routed independently
+		resp, err = r.Select(ctx, SelectRequest{SessionKey: "conv-2"})
+		require.NoError(t, err)
+		assert.Equal(t, "acc-2", resp.AccountID)
+		assert.False(t, resp.AffinityHit)
+
+		stats := r.GetStats()
+		assert.Equal(t, 2, stats.AffinitySessions)
+		assert.Equal(t, int64(1), stats.AffinityHits)
+		assert.Equal(t, int64(2), stats.AffinityMisses)
+	})
+
+	t.Run("breaks above switch threshold", func(t *testing.T) {
+		s, r := setupAffinityRouter(t)
+
+		resp, err := r.Select(ctx, SelectRequest{SessionKey: "conv-1"})
+		require.NoError(t, err)
+		require.Equal(t, "acc-1", resp.AccountID)
+
+		setRemaining(s, "acc-1", models.ProviderOpenAI, 8.0)
+
+		resp, err = r.Select(ctx, SelectRequest{SessionKey: "conv-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "acc-2", resp.AccountID)
+		assert.False(t, resp.AffinityHit)
+
+		// The session is rebound to the new account
+		setRemaining(s, "acc-1", models.ProviderOpenAI, 80.0)
+		resp, err = r.Select(ctx, SelectRequest{SessionKey: "conv-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "acc-2", resp.AccountID)
+		assert.True(t, resp.AffinityHit)
+	})
+
+	t.Run("breaks when account is blocked", func(t *testing.T) {
+		s, r := setupAffinityRouter(t)
+
+		_, err := r.Select(ctx, SelectRequest{SessionKey: "conv-1"})
+		require.NoError(t, err)
+
+		blockedUntil := time.Now().Add(time.Minute)
+		s.SetAccount(&models.Account{ID: "acc-1", Provider: models.ProviderOpenAI, Enabled: true, Priority: 5, BlockedUntil: &blockedUntil})
+		setRemaining(s, "acc-2", models.ProviderAnthropic, 90.0)
+
+		resp, err := r.Select(ctx, SelectRequest{SessionKey: "conv-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "acc-2", resp.AccountID)
+		assert.False(t, resp.AffinityHit)
+	})
+
+	t.Run("breaks when circuit is open", func(t *testing.T) {
+		s, r := setupAffinityRouter(t)
+
+		_, err := r.Select(ctx, SelectRequest{SessionKey: "conv-1"})
+		require.NoError(t, err)
+
+		for i := 0; i < r.config.CircuitBreaker.FailureThreshold; i++ {
+			r.RecordProviderFailure(models.ProviderOpenAI)
+		}
+		require.Equal(t, CircuitOpen, r.GetProviderCircuitState(models.ProviderOpenAI))
+		setRemaining(s, "acc-2", models.ProviderAnthropic, 90.0)
+
+		resp, err := r.Select(ctx, SelectRequest{SessionKey: "conv-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "acc-2", resp.AccountID)
+		assert.False(t, resp.AffinityHit)
+	})
+
+	t.Run("expires after ttl", func(t *testing.T) {
+		s, r := setupAffinityRouter(t)
+
+		_, err := r.Select(ctx, SelectRequest{SessionKey: "conv-1"})
+		require.NoError(t, err)
+
+		r.mu.Lock()
+		entry := r.affinity["conv-1"]
+		entry.expiresAt = time.Now().Add(-time.Second)
+		r.affinity["conv-1"] = entry
+		r.mu.Unlock()
+
+		setRemaining(s, "acc-2", models.ProviderAnthropic, 100.0)
+		setRemaining(s, "acc-1", models.ProviderOpenAI, 40.0)
+
+		resp, err := r.Select(ctx, SelectRequest{SessionKey: "conv-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "acc-2", resp.AccountID)
+		assert.False(t, resp.AffinityHit)
+	})
+
+	t.Run("requests without session key are not tracked", func(t *testing.T) {
+		_, r := setupAffinityRouter(t)
+
+		_, err := r.Select(ctx, SelectRequest{})
+		require.NoError(t, err)
+
+		stats := r.GetStats()
+		assert.Zero(t, stats.AffinitySessions)
+		assert.Zero(t, stats.AffinityHits)
+		assert.Zero(t, stats.AffinityMisses)
+	})
+}
+
+func TestRouter_AffinityEviction(t *testing.T) {
+	_, r := setupAffinityRouter(t)
+	r.config.AffinityMaxSessions = 2
+
+	now := time.Now()
+	r.bindAffinity("s1", "acc-1", now)
+	r.bindAffinity("s2", "acc-1", now.Add(time.Second))
+	r.bindAffinity("s3", "acc-2", now.Add(2*time.Second))
+
+	assert.Len(t, r.affinity, 2)
+	_, ok := r.lookupAffinity("s1", now)
+	assert.False(t, ok, "oldest binding should be evicted")
+	id, ok := r.lookupAffinity("s3", now)
+	assert.True(t, ok)
+	assert.Equal(t, "acc-2", id)
+}
@@ -0,0 +1,122 @@
+package router
+
+import (
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// quotaView resolves routing quotas for one decision. Each account's
+// month-to-date spend is read from the ledger at most once, however many
+// times a selection looks at the account.
+type quotaView struct {
+	r     *router
+	now   time.Time
+	spent map[string]float64
+
+	// refillScale is the fastest refill rate among the candidates; refill
+	// scores are relative to it
+	refillScale float64
+}
+
+// newQuotaView returns an empty quota view at the router's current time
+func (r *router) newQuotaView() *quotaView {
+	return &quotaView{r: r, now: r.now(), spent: make(map[string]float64)}
+}
+
+// quotaFor returns the quota used for routing decisions about an account.
+func (r *router) quotaFor(accountID string) (*models.QuotaInfo, bool) {
+	return r.newQuotaView().byID(accountID)
+}
+
+// accountQuota returns the quota used for routing decisions about an account.
+func (r *router) accountQuota(acc *models.Account) (*models.QuotaInfo, bool) {
+	return r.newQuotaView().account(acc)
+}
+
+// scaleRefill records the fastest refill rate among the candidates, so an
+// account's refill score compares it with the others in the same selection
+func (v *quotaView) scaleRefill(accounts []*models.Account) {
+	for _, acc := range accounts {
+		quota, ok := v.account(acc)
+		if !ok || quota.CriticalDimension == nil {
+			continue
+		}
+		v.refillScale = max(v.refillScale, quota.CriticalDimension.RefillRate)
+	}
+}
+
+// refillScore maps a refill rate to [0,1] relative to the fastest candidate.
+// A zero rate means it is unknown and scores neutral.
+func (v *quotaView) refillScore(rate float64) float64 {
+	if rate <= 0 {
+		return 0.5
+	}
+	return rate / max(v.refillScale, rate)
+}
+
+// byID looks the account up and returns its routing quota
+func (v *quotaView) byID(accountID string) (*models.QuotaInfo, bool) {
+	acc, ok := v.r.store.GetAccount(accountID)
+	if !ok {
+		return v.r.store.GetQuota(accountID)
+	}
+	return v.account(acc)
+}
+
+// account merges the account's monthly spend cap into its quota as a
+// synthetic BUDGET dimension. Accounts with a cap but no collected quota
+// (pay-as-you-go API keys) are routed on the budget alone.
+func (v *quotaView) account(acc *models.Account) (*models.QuotaInfo, bool) {
+	quota, ok := v.r.store.GetQuota(acc.ID)
+	if !acc.HasBudget() {
+		return quota, ok
+	}
+
+	spent, cached := v.spent[acc.ID]
+	if !cached {
+		var err error
+		spent, err = v.r.store.GetAccountSpend(acc.ID, models.MonthStartDay(v.now), models.UsageDay(v.now))
+		if err != nil {
+			return quota, ok
+		}
+		v.spent[acc.ID] = spent
+	}
+	return withBudget(acc, quota, spent, v.now), true
+}
+
+// withBudget returns a copy of quota that includes the BUDGET dimension.
+func withBudget(acc *models.Account, quota *models.QuotaInfo, spent float64, now time.Time) *models.QuotaInfo {
+	budget := models.BudgetDimension(acc.MonthlyBudget, spent, now)
+
+	if quota == nil {
+		result := &models.QuotaInfo{
+			AccountID:   acc.ID,
+			Provider:    acc.Provider,
+			Tier:        acc.Tier,
+			Dimensions:  models.DimensionSlice{budget},
+			Source:      models.SourceLedger,
+			Confidence:  1.0,
+			CollectedAt: now,
+			UpdatedAt:   now,
+		}
+		result.UpdateEffective()
+		return result
+	}
+
+	result := *quota
+	result.Dimensions = make(models.DimensionSlice, 0, len(quota.Dimensions)+1)
+	for _, dim := range quota.Dimensions {
+		// Provider-reported budgets (credits, token allowances) stay alongside the cap
+		if dim.Type != models.DimensionBudget || dim.Source != models.SourceLedger {
+			result.Dimensions = append(result.Dimensions, dim)
+		}
+	}
+	result.Dimensions = append(result.Dimensions, budget)
+
+	if pct := budget.RemainingPercent(); pct < result.EffectiveRemainingPct {
+		result.EffectiveRemainingPct = pct
+		result.CriticalDimension = &result.Dimensions[len(result.Dimensions)-1]
+	}
+	return &result
+}
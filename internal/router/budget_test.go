@@ -0,0 +1,106 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addSpend(t *testing.T, s *store.MemoryStore, accountID string, cost float64) {
+	t.Helper()
+	require.NoError(t, s.AddSpend(&models.SpendEntry{
+		AccountID: accountID,
+		Day:       models.UsageDay(time.Now()),
+		Requests:  1,
+		CostUSD:   cost,
+	}))
+}
+
+// spendCountingStore counts month-to-date spend lookups
+type spendCountingStore struct {
+	*store.MemoryStore
+	spendCalls map[string]int
+}
+
+func (s *spendCountingStore) GetAccountSpend(accountID, from, to string) (float64, error) {
+	s.spendCalls[accountID]++
+	return s.MemoryStore.GetAccountSpend(accountID, from, to)
+}
+
+func TestRouter_MonthlyBudget(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("exhausted budget removes account from rotation", func(t *testing.T) {
+		s, r := setupAffinityRouter(t)
+		acc, _ := s.GetAccount("acc-1")
+		acc.MonthlyBudget = 10
+		s.SetAccount(acc)
+
+		resp, err := r.Select(ctx, SelectRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, "acc-1", resp.AccountID)
+
+		addSpend(t, s, "acc-1", 10)
+		resp, err = r.Select(ctx, SelectRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, "acc-2", resp.AccountID)
+
+		quota, err := r.GetQuota(ctx, "acc-1")
+		require.NoError(t, err)
+		budget, ok := quota.Dimensions.FindByType(models.DimensionBudget)
+		require.True(t, ok)
+		assert.Equal(t, int64(1000), budget.Limit)
+		assert.Equal(t, int64(0), budget.Remaining)
+		assert.Equal(t, 0.0, quota.EffectiveRemainingPct)
+	})
+
+	t.Run("pay-as-you-go account is routed on its budget alone", func(t *testing.T) {
+		s := store.NewMemoryStore()
+		s.SetAccount(&models.Account{ID: "api-key", Provider: models.ProviderOpenAI, Enabled: true, MonthlyBudget: 20})
+		r := NewRouter(s, DefaultConfig()).(*router)
+
+		resp, err := r.Select(ctx, SelectRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, "api-key", resp.AccountID)
+
+		addSpend(t, s, "api-key", 25)
+		_, err = r.Select(ctx, SelectRequest{})
+		assert.Error(t, err)
+	})
+
+	t.Run("spend is read once per account per selection", func(t *testing.T) {
+		s := &spendCountingStore{MemoryStore: store.NewMemoryStore(), spendCalls: make(map[string]int)}
+		s.SetAccount(&models.Account{ID: "api-1", Provider: models.ProviderOpenAI, Enabled: true, MonthlyBudget: 20})
+		s.SetAccount(&models.Account{ID: "api-2", Provider: models.ProviderOpenAI, Enabled: true, MonthlyBudget: 20})
+		r := NewRouter(s, DefaultConfig()).(*router)
+
+		resp, err := r.Select(ctx, SelectRequest{SessionKey: "s1"})
+		require.NoError(t, err)
+		r.RecordSwitch(resp.AccountID)
+		s.spendCalls = make(map[string]int)
+
+		_, err = r.Select(ctx, SelectRequest{SessionKey: "s1"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"api-1": 1, "api-2": 1}, s.spendCalls)
+	})
+
+	t.Run("spend from previous months does not count", func(t *testing.T) {
+		s := store.NewMemoryStore()
+		s.SetAccount(&models.Account{ID: "api-key", Provider: models.ProviderOpenAI, Enabled: true, MonthlyBudget: 20})
+		require.NoError(t, s.AddSpend(&models.SpendEntry{
+			AccountID: "api-key",
+			Day:       models.UsageDay(time.Now().AddDate(0, -1, 0)),
+			CostUSD:   100,
+		}))
+		r := NewRouter(s, DefaultConfig()).(*router)
+
+		resp, err := r.Select(ctx, SelectRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, "api-key", resp.AccountID)
+	})
+}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"context"
+	"time"
 
 	"github.com/quotaguard/quotaguard/internal/models"
 )
@@ -45,6 +46,12 @@ type Router interface {
 	// RecordSwitch records that we switched to an account
 	RecordSwitch(accountID string)
 
+	// RecordScopedSwitch records that an anti-flapping scope switched to an account
+	RecordScopedSwitch(scope, accountID string)
+
+	// RecentSwitches returns the account switches recorded since a time
+	RecentSwitches(since time.Time) []SwitchEvent
+
 	// GetAccountStatus returns detailed status for an account
 	GetAccountStatus(accountID string) (*AccountStatus, error)
 
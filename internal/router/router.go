@@ -16,14 +16,19 @@ import (
 
 // router selects the best account for routing requests
 type router struct {
-	store      store.Store
-	config     Config
-	mu         sync.RWMutex
-	lastSwitch map[string]time.Time // accountID -> last switch time
+	store  store.Store
+	config Config
+	mu     sync.RWMutex
 
 	// Anti-flapping state
-	currentAccount   string    // currently selected account
-	accountDwellTime time.Time // when current account was selected
+	currentAccount string                              // most recently selected account across scopes
+	scopes         map[string]*models.RouterScopeState // scope key -> dwell/cooldown state
+	switches       []SwitchEvent                       // recent scope switches, oldest first
+
+	// Session affinity state
+	affinity       map[string]affinityEntry // session key -> sticky account
+	affinityHits   int64
+	affinityMisses int64
 
 	// Circuit breakers per provider
 	circuitBreakers map[string]*CircuitBreaker
@@ -42,6 +47,7 @@ type Config struct {
 	MinDwellTime        time.Duration
 	CooldownAfterSwitch time.Duration
 	HysteresisMargin    float64
+	FlapScope           string // global, provider, model, policy or client
 
 	// Weights for scoring
 	Weights Weights
@@ -58,6 +64,14 @@ type Config struct {
 
 	// IgnoreEstimated skips accounts with estimated quotas
 	IgnoreEstimated bool
+
+	// Session affinity
+	AffinityTTL         time.Duration // how long a session stays bound to an account
+	AffinityMaxSessions int           // upper bound on tracked sessions (0 = unbounded)
+
+	// Clock returns the current time; nil means time.Now. The simulator
+	// sets it to replay recorded traffic on its own timeline.
+	Clock func() time.Time
 }
 
 // Weights defines scoring weights
@@ -90,6 +104,7 @@ func DefaultConfig() Config {
 		MinDwellTime:        5 * time.Minute,
 		CooldownAfterSwitch: 3 * time.Minute,
 		HysteresisMargin:    5.0,
+		FlapScope:           ScopeGlobal,
 		Weights:             DefaultWeights(),
 		DefaultPolicy:       "balanced",
 		Policies: map[string]Weights{
@@ -103,7 +118,9 @@ func DefaultConfig() Config {
 			Timeout:          30 * time.Second,
 			HalfOpenLimit:    3,
 		},
-		IgnoreEstimated: true,
+		IgnoreEstimated:     true,
+		AffinityTTL:         30 * time.Minute,
+		AffinityMaxSessions: 10000,
 	}
 }
 
@@ -112,9 +129,11 @@ func NewRouter(s store.Store, cfg Config) Router {
 	r := &router{
 		store:           s,
 		config:          cfg,
-		lastSwitch:      make(map[string]time.Time),
+		scopes:          make(map[string]*models.RouterScopeState),
+		affinity:        make(map[string]affinityEntry),
 		circuitBreakers: make(map[string]*CircuitBreaker),
 	}
+	r.loadScopes()
 
 	// Initialize circuit breakers for each provider
 	accounts := s.ListEnabledAccounts()
@@ -133,6 +152,14 @@ func NewRouter(s store.Store, cfg Config) Router {
 	return r
 }
 
+// now returns the router's current time
+func (r *router) now() time.Time {
+	if r.config.Clock != nil {
+		return r.config.Clock()
+	}
+	return time.Now()
+}
+
 // GetCurrentAccount returns the currently selected account ID
 func (r *router) GetCurrentAccount() string {
 	r.mu.RLock()
@@ -140,15 +167,14 @@ func (r *router) GetCurrentAccount() string {
 	return r.currentAccount
 }
 
-// shouldSwitch checks if we should switch from current account to new one
-// Implements anti-flapping with min dwell time and hysteresis
-func (r *router) shouldSwitch(currentAccountID, newAccountID string, newScore, currentScore float64) bool {
-	r.mu.RLock()
-	currentAccount := r.currentAccount
-	r.mu.RUnlock()
+// shouldSwitch checks if the scope should switch from its current account to a new one
+// Implements anti-flapping with hysteresis against the scope's own current account
+func (r *router) shouldSwitch(v *quotaView, scope, newAccountID string, newScore, currentScore float64) bool {
+	state := r.scopeState(scope)
+	currentAccount := state.CurrentAccount
 
 	// If no current account, always allow switch
-	if currentAccount == "" || currentAccountID == "" {
+	if currentAccount == "" {
 		return true
 	}
 
@@ -158,7 +184,7 @@ func (r *router) shouldSwitch(currentAccountID, newAccountID string, newScore, c
 	}
 
 	// Get current account info to check if it's in a critical state
-	currentQuota, hasQuota := r.store.GetQuota(currentAccount)
+	currentQuota, hasQuota := v.byID(currentAccount)
 
 	// If current account is critical, we SHOULD switch even if dwell time or hysteresis aren't met
 	if hasQuota {
@@ -168,6 +194,16 @@ func (r *router) shouldSwitch(currentAccountID, newAccountID string, newScore, c
 		}
 	}
 
+	// Stay put while the scope is inside its dwell window or cooling down
+	// after its last switch
+	now := r.now()
+	if now.Sub(state.SelectedAt) < r.config.MinDwellTime {
+		return false
+	}
+	if state.LastSwitchAt != nil && now.Sub(*state.LastSwitchAt) < r.config.CooldownAfterSwitch {
+		return false
+	}
+
 	// Apply hysteresis: only switch if new score is significantly better
 	// This prevents oscillation when scores are close
 	scoreDiff := newScore - currentScore
@@ -184,6 +220,8 @@ type SelectRequest struct {
 	ExcludeProviders []models.Provider // Providers to exclude
 	EstimatedTokens  int64             // Estimated number of tokens required
 	Model            string            // Optional model id for model-specific fallbacks
+	SessionKey       string            // Optional session/conversation key for sticky routing
+	ClientID         string            // Optional client identity for client-scoped anti-flapping
 }
 
 // SelectResponse contains the selection result
@@ -193,6 +231,12 @@ type SelectResponse struct {
 	Score          float64
 	Reason         string
 	AlternativeIDs []string
+	AffinityHit    bool   // true when the session's sticky account was reused
+	Scope          string // anti-flapping scope the selection belongs to
+	// PreviousAccountID is the account the scope was on before this
+	// selection; empty for a scope's first selection and for affinity hits,
+	// which do not move the scope
+	PreviousAccountID string
 }
 
 // Select chooses the best account for the request
@@ -221,7 +265,9 @@ func (r *router) Select(ctx context.Context, req SelectRequest) (*SelectResponse
 		return nil, &errors.ErrNoSuitableAccounts{Reason: "no suitable accounts found after filtering"}
 	}
 
-globalLow := r.allAboveThreshold(accounts, r.config.CriticalThreshold)
+	view := r.newQuotaView()
+	view.scaleRefill(accounts)
+	globalLow := r.allAboveThreshold(view, accounts, r.config.CriticalThreshold)
 
 	// Get weights for the policy
 	weights := r.getWeights(req.Policy)
@@ -229,7 +275,7 @@ globalLow := r.allAboveThreshold(accounts, r.config.CriticalThreshold)
 	// Score all accounts
 	scored := make([]scoredAccount, 0, len(accounts))
 	for _, acc := range accounts {
-		score, reason := r.scoreAccount(acc, weights, req, globalLow)
+		score, reason := r.scoreAccount(view, acc, weights, req, globalLow)
 		scored = append(scored, scoredAccount{
 			account: acc,
 			score:   score,
@@ -248,7 +294,8 @@ globalLow := r.allAboveThreshold(accounts, r.config.CriticalThreshold)
 		return nil, &errors.ErrNoSuitableAccounts{Reason: best.reason}
 	}
 
-	currentAccount := r.GetCurrentAccount()
+	scope := r.scopeKey(req)
+	currentAccount := r.scopeState(scope).CurrentAccount
 	currentScore := 0.0
 	var currentEntry *scoredAccount
 	if currentAccount != "" {
@@ -266,7 +313,7 @@ globalLow := r.allAboveThreshold(accounts, r.config.CriticalThreshold)
 		best = *currentEntry
 		best.reason = fmt.Sprintf("%s; global low quota mode", best.reason)
 	} else if currentEntry != nil {
-		if currentQuota, ok := r.store.GetQuota(currentAccount); ok {
+		if currentQuota, ok := view.account(currentEntry.account); ok {
 			usedPercent := usedPercentFromRemaining(currentQuota.EffectiveRemainingWithVirtual())
 			if usedPercent >= r.config.CriticalThreshold {
 				if chain := r.fallbackChainForRequest(currentEntry.account, req); len(chain) > 0 {
@@ -279,8 +326,28 @@ globalLow := r.allAboveThreshold(accounts, r.config.CriticalThreshold)
 		}
 	}
 
+	// Session affinity: keep follow-up requests on the same account
+	now := r.now()
+	affinityHit := false
+	if boundID, ok := r.lookupAffinity(req.SessionKey, now); ok {
+		for i := range scored {
+			if scored[i].account.ID != boundID || scored[i].score <= 0 {
+				continue
+			}
+			if allowed, _ := r.affinityAllowed(view, scored[i].account, now); allowed {
+				best = scored[i]
+				best.reason = fmt.Sprintf("%s; session affinity", best.reason)
+				affinityHit = true
+			}
+			break
+		}
+		if !affinityHit {
+			r.dropAffinity(req.SessionKey)
+		}
+	}
+
 	// Apply anti-flapping: check if we should switch
-	if !r.shouldSwitch(currentAccount, best.account.ID, best.score, currentScore) {
+	if !affinityHit && !r.shouldSwitch(view, scope, best.account.ID, best.score, currentScore) {
 		// Stay with current account if we shouldn't switch
 		if currentAccount != "" {
 			// Find current account in scored list
@@ -295,12 +362,20 @@ globalLow := r.allAboveThreshold(accounts, r.config.CriticalThreshold)
 
 	// Build alternative IDs
 	alternatives := make([]string, 0, min(3, len(scored)-1))
-	for i := 1; i < len(scored) && len(alternatives) < 3; i++ {
+	for i := 0; i < len(scored) && len(alternatives) < 3; i++ {
+		if scored[i].account.ID == best.account.ID {
+			continue
+		}
 		if scored[i].score > 0 {
 			alternatives = append(alternatives, scored[i].account.ID)
 		}
 	}
 
+	if req.SessionKey != "" {
+		r.bindAffinity(req.SessionKey, best.account.ID, now)
+		r.recordAffinityResult(affinityHit)
+	}
+
 	bestReason := best.reason
 	for _, s := range scored {
 		if s.account.ID == best.account.ID {
@@ -312,13 +387,19 @@ globalLow := r.allAboveThreshold(accounts, r.config.CriticalThreshold)
 		}
 	}
 
-	return &SelectResponse{
+	resp := &SelectResponse{
 		AccountID:      best.account.ID,
 		Provider:       best.account.Provider,
 		Score:          best.score,
 		Reason:         bestReason,
 		AlternativeIDs: alternatives,
-	}, nil
+		AffinityHit:    affinityHit,
+		Scope:          scope,
+	}
+	if !affinityHit {
+		resp.PreviousAccountID = currentAccount
+	}
+	return resp, nil
 }
 
 // scoredAccount holds an account with its score
@@ -329,8 +410,8 @@ type scoredAccount struct {
 }
 
 // scoreAccount calculates a score for an account
-func (r *router) scoreAccount(acc *models.Account, weights Weights, req SelectRequest, globalLow bool) (float64, string) {
-	quota, ok := r.store.GetQuota(acc.ID)
+func (r *router) scoreAccount(v *quotaView, acc *models.Account, weights Weights, req SelectRequest, globalLow bool) (float64, string) {
+	quota, ok := v.account(acc)
 	if !ok {
 		return 0, "no quota data"
 	}
@@ -391,13 +472,11 @@ func (r *router) scoreAccount(acc *models.Account, weights Weights, req SelectRe
 		safetyScore = 1.0
 	}
 
-	// Refill score based on critical dimension refill rate
+	// Refill score based on critical dimension refill rate (share of the
+	// limit recovered per minute), relative to the other candidates
 	refillScore := 0.5
 	if crit := quota.CriticalDimension; crit != nil {
-		refillScore = crit.RefillRate
-		if refillScore > 1.0 {
-			refillScore = 1.0
-		}
+		refillScore = v.refillScore(crit.RefillRate)
 	}
 
 	// Tier score (higher priority = higher score)
@@ -428,36 +507,9 @@ func (r *router) scoreAccount(acc *models.Account, weights Weights, req SelectRe
 	return score, reason
 }
 
-// canSwitch checks if we can switch to the given account
-func (r *router) canSwitch(accountID string) bool {
-	r.mu.RLock()
-	lastSwitch, ok := r.lastSwitch[accountID]
-	r.mu.RUnlock()
-
-	if !ok {
-		return true
-	}
-
-	// Check cooldown period
-	if time.Since(lastSwitch) < r.config.CooldownAfterSwitch {
-		return false
-	}
-
-	return true
-}
-
-// RecordSwitch records that we switched to an account
+// RecordSwitch records that we switched to an account in the global scope
 func (r *router) RecordSwitch(accountID string) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	r.lastSwitch[accountID] = time.Now()
-
-	// Update current account tracking for anti-flapping
-	if r.currentAccount != accountID {
-		r.currentAccount = accountID
-		r.accountDwellTime = time.Now()
-	}
+	r.RecordScopedSwitch(ScopeGlobal, accountID)
 }
 
 // getWeights returns weights for a policy
@@ -473,13 +525,13 @@ func (r *router) getWeights(policy string) Weights {
 	return r.config.Weights
 }
 
-func (r *router) allAboveThreshold(accounts []*models.Account, threshold float64) bool {
+func (r *router) allAboveThreshold(v *quotaView, accounts []*models.Account, threshold float64) bool {
 	if len(accounts) == 0 {
 		return false
 	}
 	seen := false
 	for _, acc := range accounts {
-		quota, ok := r.store.GetQuota(acc.ID)
+		quota, ok := v.account(acc)
 		if !ok {
 			continue
 		}
@@ -578,13 +630,22 @@ func (r *router) GetStats() RouterStats {
 	defer r.mu.RUnlock()
 
 	return RouterStats{
-		LastSwitches: len(r.lastSwitch),
+		ScopeCount:       len(r.scopes),
+		AffinitySessions: len(r.affinity),
+		AffinityHits:     r.affinityHits,
+		AffinityMisses:   r.affinityMisses,
+		Scopes:           r.scopeStatsLocked(r.now()),
 	}
 }
 
 // RouterStats contains router statistics
 type RouterStats struct {
-	LastSwitches int
+	// ScopeCount is the number of anti-flapping scopes holding an account
+	ScopeCount       int
+	AffinitySessions int
+	AffinityHits     int64
+	AffinityMisses   int64
+	Scopes           []ScopeStats
 }
 
 // Helper functions
@@ -675,7 +736,7 @@ func (r *router) GetAccounts(ctx context.Context) ([]*models.Account, error) {
 
 // GetQuota returns quota information for an account
 func (r *router) GetQuota(ctx context.Context, accountID string) (*models.QuotaInfo, error) {
-	quota, ok := r.store.GetQuota(accountID)
+	quota, ok := r.quotaFor(accountID)
 	if !ok {
 		return nil, nil
 	}
@@ -689,6 +750,15 @@ func (r *router) GetAllQuotas(ctx context.Context) (map[string]*models.QuotaInfo
 	for _, q := range quotas {
 		result[q.AccountID] = q
 	}
+	view := r.newQuotaView()
+	for _, acc := range r.store.ListAccounts() {
+		if !acc.HasBudget() {
+			continue
+		}
+		if q, ok := view.account(acc); ok {
+			result[acc.ID] = q
+		}
+	}
 	return result, nil
 }
 
@@ -721,11 +791,14 @@ func (r *router) UpdateConfig(cfg Config) {
 	r.config.MinDwellTime = cfg.MinDwellTime
 	r.config.CooldownAfterSwitch = cfg.CooldownAfterSwitch
 	r.config.HysteresisMargin = cfg.HysteresisMargin
+	r.config.FlapScope = cfg.FlapScope
 	r.config.Weights = cfg.Weights
 	r.config.Policies = cfg.Policies
 	r.config.DefaultPolicy = cfg.DefaultPolicy
 	r.config.FallbackChains = cfg.FallbackChains
 	r.config.CircuitBreaker = cfg.CircuitBreaker
+	r.config.AffinityTTL = cfg.AffinityTTL
+	r.config.AffinityMaxSessions = cfg.AffinityMaxSessions
 }
 
 // Close cleans up router resources
@@ -740,7 +813,7 @@ func (r *router) GetAccountStatus(accountID string) (*AccountStatus, error) {
 		return nil, fmt.Errorf("account not found: %s", accountID)
 	}
 
-	quota, hasQuota := r.store.GetQuota(accountID)
+	quota, hasQuota := r.accountQuota(acc)
 
 	status := &AccountStatus{
 		AccountID: acc.ID,
@@ -782,7 +855,9 @@ func (r *router) CalculateOptimalDistribution(ctx context.Context, totalRequests
 
 	distribution := make(map[string]float64)
 	weights := r.config.Weights
-globalLow := r.allAboveThreshold(accounts, r.config.CriticalThreshold)
+	view := r.newQuotaView()
+	view.scaleRefill(accounts)
+	globalLow := r.allAboveThreshold(view, accounts, r.config.CriticalThreshold)
 
 	// Calculate scores for all accounts
 	type accountScore struct {
@@ -792,7 +867,7 @@ globalLow := r.allAboveThreshold(accounts, r.config.CriticalThreshold)
 	scores := make([]accountScore, 0, len(accounts))
 
 	for _, acc := range accounts {
-		score, _ := r.scoreAccount(acc, weights, SelectRequest{}, globalLow)
+		score, _ := r.scoreAccount(view, acc, weights, SelectRequest{}, globalLow)
 		if score > 0 {
 			scores = append(scores, accountScore{id: acc.ID, score: score})
 		}
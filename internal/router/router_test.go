@@ -2,11 +2,13 @@ package router
 
 import (
 	"context"
+	"net/http"
 	"testing"
 	"time"
 
 	"github.com/quotaguard/quotaguard/internal/models"
 	"github.com/quotaguard/quotaguard/internal/store"
+	"github.com/quotaguard/quotaguard/pkg/headers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -44,7 +46,7 @@ func TestNewRouter(t *testing.T) {
 	routerImpl := r.(*router)
 	assert.Equal(t, s, routerImpl.store)
 	assert.Equal(t, cfg, routerImpl.config)
-	assert.NotNil(t, routerImpl.lastSwitch)
+	assert.NotNil(t, routerImpl.scopes)
 }
 
 func TestRouter_Select(t *testing.T) {
@@ -225,7 +227,7 @@ func TestRouter_IgnoresEstimatedQuota(t *testing.T) {
 	cfg.IgnoreEstimated = true
 	r := NewRouter(s, cfg).(*router)
 
-	score, reason := r.scoreAccount(acc, cfg.Weights, SelectRequest{}, false)
+	score, reason := r.scoreAccount(r.newQuotaView(), acc, cfg.Weights, SelectRequest{}, false)
 	assert.Equal(t, 0.0, score)
 	assert.Equal(t, "estimated quota ignored", reason)
 }
@@ -245,7 +247,7 @@ func TestRouter_scoreAccount(t *testing.T) {
 	}
 
 	t.Run("no quota data", func(t *testing.T) {
-		score, reason := routerImpl.scoreAccount(acc, DefaultWeights(), SelectRequest{}, false)
+		score, reason := routerImpl.scoreAccount(routerImpl.newQuotaView(), acc, DefaultWeights(), SelectRequest{}, false)
 		assert.Equal(t, 0.0, score)
 		assert.Equal(t, "no quota data", reason)
 	})
@@ -259,7 +261,7 @@ func TestRouter_scoreAccount(t *testing.T) {
 		}
 		s.SetQuota("acc-1", quota)
 
-		score, reason := routerImpl.scoreAccount(acc, DefaultWeights(), SelectRequest{}, false)
+		score, reason := routerImpl.scoreAccount(routerImpl.newQuotaView(), acc, DefaultWeights(), SelectRequest{}, false)
 		assert.Equal(t, 0.0, score)
 		assert.Equal(t, "quota exhausted", reason)
 	})
@@ -274,7 +276,7 @@ func TestRouter_scoreAccount(t *testing.T) {
 		}
 		s.SetQuota("acc-1", quota)
 
-		score, reason := routerImpl.scoreAccount(acc, DefaultWeights(), SelectRequest{}, false)
+		score, reason := routerImpl.scoreAccount(routerImpl.newQuotaView(), acc, DefaultWeights(), SelectRequest{}, false)
 		assert.Equal(t, 0.1, score)
 		assert.Equal(t, "critical quota level", reason)
 	})
@@ -286,7 +288,7 @@ func TestRouter_scoreAccount(t *testing.T) {
 		}
 		s.SetQuota("acc-1", quota)
 
-		score, reason := routerImpl.scoreAccount(acc, DefaultWeights(), SelectRequest{}, false)
+		score, reason := routerImpl.scoreAccount(routerImpl.newQuotaView(), acc, DefaultWeights(), SelectRequest{}, false)
 		assert.Equal(t, 0.0, score)
 		assert.Equal(t, "usage above switch threshold", reason)
 	})
@@ -298,7 +300,7 @@ func TestRouter_scoreAccount(t *testing.T) {
 		}
 		s.SetQuota("acc-1", quota)
 
-		score, reason := routerImpl.scoreAccount(acc, DefaultWeights(), SelectRequest{}, true)
+		score, reason := routerImpl.scoreAccount(routerImpl.newQuotaView(), acc, DefaultWeights(), SelectRequest{}, true)
 		assert.Greater(t, score, 0.0)
 		assert.Contains(t, reason, "safety=")
 	})
@@ -325,11 +327,66 @@ func TestRouter_scoreAccount(t *testing.T) {
 		}
 		s2.SetQuota("acc-1", quota)
 
-		score, reason := routerImpl2.scoreAccount(acc2, DefaultWeights(), SelectRequest{}, false)
+		score, reason := routerImpl2.scoreAccount(routerImpl2.newQuotaView(), acc2, DefaultWeights(), SelectRequest{}, false)
 		assert.Greater(t, score, 0.0)
 		assert.Contains(t, reason, "safety=")
 	})
 
+	t.Run("refill rate from parsed headers", func(t *testing.T) {
+		s2 := store.NewMemoryStore()
+		routerImpl2 := NewRouter(s2, cfg).(*router)
+		acc2 := &models.Account{ID: "acc-1", Provider: models.ProviderOpenAI, Priority: 5}
+
+		quota, err := (&headers.OpenAIParser{}).Parse(http.Header{
+			"X-Ratelimit-Limit-Tokens":     []string{"1000"},
+			"X-Ratelimit-Remaining-Tokens": []string{"500"},
+			"X-Ratelimit-Reset-Tokens":     []string{"6m0s"},
+		}, "acc-1")
+		require.NoError(t, err)
+		s2.SetQuota("acc-1", quota)
+
+		view := routerImpl2.newQuotaView()
+		view.scaleRefill([]*models.Account{acc2})
+		_, reason := routerImpl2.scoreAccount(view, acc2, DefaultWeights(), SelectRequest{}, false)
+		assert.Contains(t, reason, "refill=1.00", "the only candidate refills fastest")
+
+		// Dimensions without a refill estimate keep the neutral score
+		quota.Dimensions[0].RefillRate = 0
+		quota.UpdateEffective()
+		s2.SetQuota("acc-1", quota)
+		_, reason = routerImpl2.scoreAccount(routerImpl2.newQuotaView(), acc2, DefaultWeights(), SelectRequest{}, false)
+		assert.Contains(t, reason, "refill=0.50")
+	})
+
+	t.Run("refill ranks against the other candidates", func(t *testing.T) {
+		s2 := store.NewMemoryStore()
+		routerImpl2 := NewRouter(s2, cfg).(*router)
+		rates := map[string]float64{"fast": 1.0 / 6, "slow": 1.0 / (24 * 60), "unknown": 0}
+		accounts := make([]*models.Account, 0, len(rates))
+		for id, rate := range rates {
+			acc := &models.Account{ID: id, Provider: models.ProviderOpenAI, Priority: 5}
+			accounts = append(accounts, acc)
+			quota := &models.QuotaInfo{
+				AccountID:  id,
+				Confidence: 0.9,
+				Dimensions: models.DimensionSlice{
+					{Type: models.DimensionTPM, Limit: 1000, Used: 500, Remaining: 500, RefillRate: rate},
+				},
+			}
+			quota.UpdateEffective()
+			s2.SetQuota(id, quota)
+		}
+
+		view := routerImpl2.newQuotaView()
+		view.scaleRefill(accounts)
+		scores := make(map[string]float64, len(accounts))
+		for _, acc := range accounts {
+			scores[acc.ID], _ = routerImpl2.scoreAccount(view, acc, DefaultWeights(), SelectRequest{}, false)
+		}
+		assert.Greater(t, scores["fast"], scores["unknown"], "known fast refill beats no data")
+		assert.Greater(t, scores["unknown"], scores["slow"])
+	})
+
 	t.Run("account with insufficient quota for estimated cost", func(t *testing.T) {
 		// Use fresh store for this test
 		s2 := store.NewMemoryStore()
@@ -356,7 +413,7 @@ func TestRouter_scoreAccount(t *testing.T) {
 		req := SelectRequest{
 			EstimatedCost: 50.0, // Requesting 50%, but only 50% remaining and MinSafeThreshold is 5%
 		}
-		score, reason := routerImpl2.scoreAccount(acc2, DefaultWeights(), req, false)
+		score, reason := routerImpl2.scoreAccount(routerImpl2.newQuotaView(), acc2, DefaultWeights(), req, false)
 		assert.Equal(t, 0.2, score)
 		assert.Equal(t, "insufficient quota for estimated cost", reason)
 	})
@@ -387,35 +444,12 @@ func TestRouter_scoreAccount(t *testing.T) {
 		req := SelectRequest{
 			RequiredDims: []models.DimensionType{models.DimensionTPM},
 		}
-		score, reason := routerImpl2.scoreAccount(acc2, DefaultWeights(), req, false)
+		score, reason := routerImpl2.scoreAccount(routerImpl2.newQuotaView(), acc2, DefaultWeights(), req, false)
 		assert.Equal(t, 0.0, score)
 		assert.Contains(t, reason, "missing required dimension")
 	})
 }
 
-func TestRouter_canSwitch(t *testing.T) {
-	s := store.NewMemoryStore()
-	cfg := DefaultConfig()
-	cfg.CooldownAfterSwitch = 100 * time.Millisecond
-	r := NewRouter(s, cfg)
-	routerImpl := r.(*router)
-
-	t.Run("never switched", func(t *testing.T) {
-		assert.True(t, routerImpl.canSwitch("acc-1"))
-	})
-
-	t.Run("during cooldown", func(t *testing.T) {
-		routerImpl.RecordSwitch("acc-2")
-		assert.False(t, routerImpl.canSwitch("acc-2"))
-	})
-
-	t.Run("after cooldown", func(t *testing.T) {
-		routerImpl.RecordSwitch("acc-3")
-		time.Sleep(150 * time.Millisecond)
-		assert.True(t, routerImpl.canSwitch("acc-3"))
-	})
-}
-
 func TestRouter_RecordSwitch(t *testing.T) {
 	s := store.NewMemoryStore()
 	cfg := DefaultConfig()
@@ -424,7 +458,7 @@ func TestRouter_RecordSwitch(t *testing.T) {
 	r.RecordSwitch("acc-1")
 
 	stats := r.GetStats()
-	assert.Equal(t, 1, stats.LastSwitches)
+	assert.Equal(t, 1, stats.ScopeCount)
 }
 
 func TestRouter_getWeights(t *testing.T) {
@@ -632,7 +666,7 @@ func TestRouter_shouldSwitch(t *testing.T) {
 		s.SetQuota("acc-1", quotaCritical)
 		routerImpl.RecordSwitch("acc-1") // Set acc-1 as current
 
-		assert.True(t, routerImpl.shouldSwitch("acc-1", "acc-2", 0.8, 0.1))
+		assert.True(t, routerImpl.shouldSwitch(routerImpl.newQuotaView(), ScopeGlobal, "acc-2", 0.8, 0.1))
 	})
 
 	t.Run("don't switch if scores are close (hysteresis)", func(t *testing.T) {
@@ -648,7 +682,7 @@ func TestRouter_shouldSwitch(t *testing.T) {
 
 		// acc-1 has 80%, acc-2 has 50%
 		// Score difference should be significant to switch
-		assert.False(t, routerImpl2.shouldSwitch("acc-1", "acc-2", 0.9, 0.88)) // small score gain
+		assert.False(t, routerImpl2.shouldSwitch(routerImpl2.newQuotaView(), ScopeGlobal, "acc-2", 0.9, 0.88)) // small score gain
 	})
 
 	t.Run("don't switch if new account has low score", func(t *testing.T) {
@@ -657,7 +691,7 @@ func TestRouter_shouldSwitch(t *testing.T) {
 		routerImpl.RecordSwitch("acc-1")
 
 		// acc-2 has 50% remaining, which gives lower score
-		assert.False(t, routerImpl.shouldSwitch("acc-1", "acc-2", 0.6, 0.8))
+		assert.False(t, routerImpl.shouldSwitch(routerImpl.newQuotaView(), ScopeGlobal, "acc-2", 0.6, 0.8))
 	})
 
 	t.Run("switch when new account has much better score", func(t *testing.T) {
@@ -675,9 +709,9 @@ func TestRouter_shouldSwitch(t *testing.T) {
 
 		// Reset dwell time for the switch test
 		routerImpl.mu.Lock()
-		routerImpl.accountDwellTime = time.Now().Add(-10 * time.Minute)
+		routerImpl.scopes[ScopeGlobal].SelectedAt = time.Now().Add(-10 * time.Minute)
 		routerImpl.mu.Unlock()
 
-		assert.True(t, routerImpl.shouldSwitch("acc-1", "acc-2", 0.98, 0.5))
+		assert.True(t, routerImpl.shouldSwitch(routerImpl.newQuotaView(), ScopeGlobal, "acc-2", 0.98, 0.5))
 	})
 }
@@ -0,0 +1,187 @@
+package router
+
+import (
+	"sort"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// Anti-flapping scope kinds. Each scope keeps its own current account,
+// dwell time and cooldown so unrelated traffic does not share switch state.
+const (
+	ScopeGlobal   = "global"
+	ScopeProvider = "provider"
+	ScopeModel    = "model"
+	ScopePolicy   = "policy"
+	ScopeClient   = "client"
+)
+
+// ScopeStats describes anti-flapping state for one scope
+type ScopeStats struct {
+	Scope             string
+	CurrentAccount    string
+	PreviousAccount   string
+	SelectedAt        time.Time
+	LastSwitchAt      *time.Time
+	DwellRemaining    time.Duration
+	CooldownRemaining time.Duration
+}
+
+// maxSwitchEvents bounds the in-memory switch log
+const maxSwitchEvents = 1000
+
+// SwitchEvent records a scope moving from one account to another
+type SwitchEvent struct {
+	Scope string
+	From  string
+	To    string
+	At    time.Time
+}
+
+// scopeKey derives the anti-flapping scope key for a request
+func (r *router) scopeKey(req SelectRequest) string {
+	r.mu.RLock()
+	kind := r.config.FlapScope
+	defaultPolicy := r.config.DefaultPolicy
+	r.mu.RUnlock()
+
+	switch kind {
+	case ScopeProvider:
+		if req.Provider == "" {
+			return ScopeProvider + ":any"
+		}
+		return ScopeProvider + ":" + string(req.Provider)
+	case ScopeModel:
+		model := normalizeModelID(req.Model)
+		if model == "" {
+			return ScopeModel + ":any"
+		}
+		return ScopeModel + ":" + model
+	case ScopePolicy:
+		policy := req.Policy
+		if policy == "" {
+			policy = defaultPolicy
+		}
+		return ScopePolicy + ":" + policy
+	case ScopeClient:
+		if req.ClientID == "" {
+			return ScopeClient + ":anonymous"
+		}
+		return ScopeClient + ":" + req.ClientID
+	default:
+		return ScopeGlobal
+	}
+}
+
+// scopeState returns a copy of the anti-flapping state for a scope
+func (r *router) scopeState(scope string) models.RouterScopeState {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if st, ok := r.scopes[scope]; ok {
+		return *st
+	}
+	return models.RouterScopeState{Scope: scope}
+}
+
+// RecordScopedSwitch records that a scope switched to an account
+func (r *router) RecordScopedSwitch(scope, accountID string) {
+	if scope == "" {
+		scope = ScopeGlobal
+	}
+	now := r.now()
+
+	r.mu.Lock()
+	r.currentAccount = accountID
+
+	st, ok := r.scopes[scope]
+	if !ok {
+		st = &models.RouterScopeState{Scope: scope}
+		r.scopes[scope] = st
+	}
+	if st.CurrentAccount == accountID {
+		r.mu.Unlock()
+		return
+	}
+	if st.CurrentAccount != "" {
+		st.PreviousAccount = st.CurrentAccount
+		switchedAt := now
+		st.LastSwitchAt = &switchedAt
+		r.switches = append(r.switches, SwitchEvent{Scope: scope, From: st.CurrentAccount, To: accountID, At: now})
+		if len(r.switches) > maxSwitchEvents {
+			r.switches = r.switches[len(r.switches)-maxSwitchEvents:]
+		}
+	}
+	st.CurrentAccount = accountID
+	st.SelectedAt = now
+	snapshot := *st
+	r.mu.Unlock()
+
+	// Persist so a restart does not reshuffle every scope
+	_ = r.store.SaveRouterScopeState(&snapshot)
+}
+
+// RecentSwitches returns the switches recorded since a time, oldest first.
+// Only the last switches since the router started are kept.
+func (r *router) RecentSwitches(since time.Time) []SwitchEvent {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]SwitchEvent, 0)
+	for _, ev := range r.switches {
+		if !ev.At.Before(since) {
+			result = append(result, ev)
+		}
+	}
+	return result
+}
+
+// loadScopes restores persisted anti-flapping state
+func (r *router) loadScopes() {
+	states, err := r.store.ListRouterScopeStates()
+	if err != nil {
+		return
+	}
+
+	var latest *models.RouterScopeState
+	for _, st := range states {
+		if st == nil || st.Scope == "" || st.CurrentAccount == "" {
+			continue
+		}
+		r.scopes[st.Scope] = st
+		if latest == nil || st.SelectedAt.After(latest.SelectedAt) {
+			latest = st
+		}
+	}
+	if latest != nil {
+		r.currentAccount = latest.CurrentAccount
+	}
+}
+
+// scopeStatsLocked returns anti-flapping state for every known scope. Caller must hold r.mu.
+func (r *router) scopeStatsLocked(now time.Time) []ScopeStats {
+	stats := make([]ScopeStats, 0, len(r.scopes))
+	for scope, st := range r.scopes {
+		entry := ScopeStats{
+			Scope:           scope,
+			CurrentAccount:  st.CurrentAccount,
+			PreviousAccount: st.PreviousAccount,
+			SelectedAt:      st.SelectedAt,
+			LastSwitchAt:    st.LastSwitchAt,
+		}
+		if remaining := r.config.MinDwellTime - now.Sub(st.SelectedAt); remaining > 0 {
+			entry.DwellRemaining = remaining
+		}
+		if st.LastSwitchAt != nil {
+			if remaining := r.config.CooldownAfterSwitch - now.Sub(*st.LastSwitchAt); remaining > 0 {
+				entry.CooldownRemaining = remaining
+			}
+		}
+		stats = append(stats, entry)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Scope < stats[j].Scope
+	})
+	return stats
+}
@@ -0,0 +1,210 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_scopeKey(t *testing.T) {
+	tests := []struct {
+		scope    string
+		req      SelectRequest
+		expected string
+	}{
+		{ScopeGlobal, SelectRequest{Provider: models.ProviderOpenAI}, "global"},
+		{"", SelectRequest{Provider: models.ProviderOpenAI}, "global"},
+		{ScopeProvider, SelectRequest{Provider: models.ProviderGemini}, "provider:gemini"},
+		{ScopeProvider, SelectRequest{}, "provider:any"},
+		{ScopeModel, SelectRequest{Model: "models/GPT-5.1"}, "model:gpt-5.1"},
+		{ScopeModel, SelectRequest{}, "model:any"},
+		{ScopePolicy, SelectRequest{Policy: "cost"}, "policy:cost"},
+		{ScopePolicy, SelectRequest{}, "policy:balanced"},
+		{ScopeClient, SelectRequest{ClientID: "ci-bot"}, "client:ci-bot"},
+		{ScopeClient, SelectRequest{}, "client:anonymous"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.FlapScope = tt.scope
+			r := NewRouter(store.NewMemoryStore(), cfg).(*router)
+			assert.Equal(t, tt.expected, r.scopeKey(tt.req))
+		})
+	}
+}
+
+func TestRouter_ScopedAntiFlapping(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.SetAccount(&models.Account{ID: "codex-1", Provider: models.ProviderOpenAI, Enabled: true, Priority: 5})
+	s.SetAccount(&models.Account{ID: "codex-2", Provider: models.ProviderOpenAI, Enabled: true, Priority: 5})
+	s.SetAccount(&models.Account{ID: "gemini-1", Provider: models.ProviderGemini, Enabled: true, Priority: 5})
+	setRemaining(s, "codex-1", models.ProviderOpenAI, 80.0)
+	setRemaining(s, "codex-2", models.ProviderOpenAI, 78.0)
+	setRemaining(s, "gemini-1", models.ProviderGemini, 60.0)
+
+	cfg := DefaultConfig()
+	cfg.FlapScope = ScopeProvider
+	r := NewRouter(s, cfg).(*router)
+	ctx := context.Background()
+
+	resp, err := r.Select(ctx, SelectRequest{Provider: models.ProviderOpenAI})
+	require.NoError(t, err)
+	assert.Equal(t, "codex-1", resp.AccountID)
+	assert.Equal(t, "provider:openai", resp.Scope)
+	r.RecordScopedSwitch(resp.Scope, resp.AccountID)
+
+	resp, err = r.Select(ctx, SelectRequest{Provider: models.ProviderGemini})
+	require.NoError(t, err)
+	assert.Equal(t, "gemini-1", resp.AccountID)
+	r.RecordScopedSwitch(resp.Scope, resp.AccountID)
+
+	// codex-2 is now slightly better, but hysteresis keeps the openai scope on codex-1
+	// even though the last switch happened in the gemini scope
+	setRemaining(s, "codex-2", models.ProviderOpenAI, 82.0)
+	resp, err = r.Select(ctx, SelectRequest{Provider: models.ProviderOpenAI})
+	require.NoError(t, err)
+	assert.Equal(t, "codex-1", resp.AccountID)
+
+	stats := r.GetStats()
+	require.Len(t, stats.Scopes, 2)
+	assert.Equal(t, "provider:gemini", stats.Scopes[0].Scope)
+	assert.Equal(t, "gemini-1", stats.Scopes[0].CurrentAccount)
+	assert.Equal(t, "provider:openai", stats.Scopes[1].Scope)
+	assert.Equal(t, "codex-1", stats.Scopes[1].CurrentAccount)
+	assert.Greater(t, stats.Scopes[1].DwellRemaining, time.Duration(0))
+	assert.Equal(t, "gemini-1", r.GetCurrentAccount())
+}
+
+func TestRouter_ScopedDwellAndCooldown(t *testing.T) {
+	s := store.NewMemoryStore()
+	s.SetAccount(&models.Account{ID: "codex-1", Provider: models.ProviderOpenAI, Enabled: true, Priority: 5})
+	s.SetAccount(&models.Account{ID: "codex-2", Provider: models.ProviderOpenAI, Enabled: true, Priority: 5})
+	setRemaining(s, "codex-1", models.ProviderOpenAI, 60.0)
+	setRemaining(s, "codex-2", models.ProviderOpenAI, 40.0)
+
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	cfg := DefaultConfig()
+	cfg.FlapScope = ScopeProvider
+	cfg.Clock = func() time.Time { return now }
+	r := NewRouter(s, cfg).(*router)
+	ctx := context.Background()
+	req := SelectRequest{Provider: models.ProviderOpenAI}
+
+	resp, err := r.Select(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, "codex-1", resp.AccountID)
+	r.RecordScopedSwitch(resp.Scope, resp.AccountID)
+
+	// codex-2 is now clearly better, but the scope is inside its dwell window
+	setRemaining(s, "codex-2", models.ProviderOpenAI, 95.0)
+	now = now.Add(cfg.MinDwellTime / 2)
+	resp, err = r.Select(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "codex-1", resp.AccountID)
+
+	// A switch in another scope does not reset this scope's dwell window
+	r.RecordScopedSwitch("provider:gemini", "gemini-1")
+	now = now.Add(cfg.MinDwellTime)
+	resp, err = r.Select(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "codex-2", resp.AccountID)
+	r.RecordScopedSwitch(resp.Scope, resp.AccountID)
+
+	// Cooling down after the switch, even once the dwell time has passed
+	setRemaining(s, "codex-1", models.ProviderOpenAI, 100.0)
+	setRemaining(s, "codex-2", models.ProviderOpenAI, 50.0)
+	r.config.MinDwellTime = time.Minute
+	now = now.Add(2 * time.Minute)
+	resp, err = r.Select(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "codex-2", resp.AccountID)
+
+	now = now.Add(cfg.CooldownAfterSwitch)
+	resp, err = r.Select(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "codex-1", resp.AccountID)
+
+	// A critical current account bypasses dwell and cooldown
+	r.RecordScopedSwitch(resp.Scope, resp.AccountID)
+	setRemaining(s, "codex-1", models.ProviderOpenAI, 2.0)
+	resp, err = r.Select(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "codex-2", resp.AccountID)
+}
+
+func TestRouter_RecordScopedSwitch(t *testing.T) {
+	r := NewRouter(store.NewMemoryStore(), DefaultConfig()).(*router)
+
+	r.RecordScopedSwitch("model:gpt-5", "acc-1")
+	r.RecordScopedSwitch("model:gpt-5", "acc-1")
+
+	state := r.scopeState("model:gpt-5")
+	assert.Equal(t, "acc-1", state.CurrentAccount)
+	assert.Empty(t, state.PreviousAccount)
+	assert.Nil(t, state.LastSwitchAt)
+
+	r.RecordScopedSwitch("model:gpt-5", "acc-2")
+	state = r.scopeState("model:gpt-5")
+	assert.Equal(t, "acc-2", state.CurrentAccount)
+	assert.Equal(t, "acc-1", state.PreviousAccount)
+	require.NotNil(t, state.LastSwitchAt)
+
+	stats := r.GetStats()
+	require.Len(t, stats.Scopes, 1)
+	assert.Greater(t, stats.Scopes[0].CooldownRemaining, time.Duration(0))
+
+	// Empty scope falls back to the global scope
+	r.RecordScopedSwitch("", "acc-3")
+	assert.Equal(t, "acc-3", r.scopeState(ScopeGlobal).CurrentAccount)
+
+	// Only actual switches are logged, not first selections
+	switches := r.RecentSwitches(time.Now().Add(-time.Minute))
+	require.Len(t, switches, 1)
+	assert.Equal(t, "model:gpt-5", switches[0].Scope)
+	assert.Equal(t, "acc-1", switches[0].From)
+	assert.Equal(t, "acc-2", switches[0].To)
+	assert.Empty(t, r.RecentSwitches(time.Now().Add(time.Minute)))
+}
+
+func TestRouter_RecentSwitchesBounded(t *testing.T) {
+	r := NewRouter(store.NewMemoryStore(), DefaultConfig()).(*router)
+
+	for i := 0; i <= maxSwitchEvents+1; i++ {
+		r.RecordScopedSwitch(ScopeGlobal, fmt.Sprintf("acc-%d", i))
+	}
+	switches := r.RecentSwitches(time.Time{})
+	require.Len(t, switches, maxSwitchEvents)
+	assert.Equal(t, fmt.Sprintf("acc-%d", maxSwitchEvents+1), switches[len(switches)-1].To)
+}
+
+func TestRouter_ScopeStatePersistsAcrossRestart(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "router.db")
+
+	s1, err := store.NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	r1 := NewRouter(s1, DefaultConfig())
+	r1.RecordScopedSwitch("provider:openai", "acc-1")
+	r1.RecordScopedSwitch("provider:openai", "acc-2")
+	r1.RecordScopedSwitch("provider:gemini", "acc-3")
+	require.NoError(t, s1.Close())
+
+	s2, err := store.NewSQLiteStore(dbPath)
+	require.NoError(t, err)
+	defer s2.Close()
+	r2 := NewRouter(s2, DefaultConfig()).(*router)
+
+	openai := r2.scopeState("provider:openai")
+	assert.Equal(t, "acc-2", openai.CurrentAccount)
+	assert.Equal(t, "acc-1", openai.PreviousAccount)
+	assert.NotNil(t, openai.LastSwitchAt)
+	assert.Equal(t, "acc-3", r2.scopeState("provider:gemini").CurrentAccount)
+	assert.Equal(t, "acc-3", r2.GetCurrentAccount())
+}
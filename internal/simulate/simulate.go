@@ -0,0 +1,422 @@
+// Package simulate replays recorded routing requests against a simple quota
+// model and the real router, so policies and weights can be compared on the
+// same traffic before they are rolled out.
+package simulate
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/router"
+	"github.com/quotaguard/quotaguard/internal/snapshot"
+	"github.com/quotaguard/quotaguard/internal/store"
+)
+
+// Request is one recorded request to replay.
+type Request struct {
+	At               time.Time
+	Provider         string
+	Model            string
+	EstimatedCostPct float64
+	EstimatedTokens  int64
+	SessionID        string
+	ClientID         string
+}
+
+// requestRecord is the JSON form of a request. The alternative names let
+// routing events exported by `quotaguard route events --json` be replayed
+// as they are.
+type requestRecord struct {
+	At                time.Time `json:"at"`
+	Timestamp         time.Time `json:"timestamp"`
+	CreatedAt         time.Time `json:"created_at"`
+	Provider          string    `json:"provider"`
+	RequestedProvider string    `json:"requested_provider"`
+	Model             string    `json:"model"`
+	EstimatedCostPct  float64   `json:"estimated_cost_percent"`
+	EstimatedTokens   int64     `json:"estimated_tokens"`
+	SessionID         string    `json:"session_id"`
+	ClientID          string    `json:"client_id"`
+}
+
+func (r requestRecord) request() (Request, error) {
+	at := r.At
+	if at.IsZero() {
+		at = r.Timestamp
+	}
+	if at.IsZero() {
+		at = r.CreatedAt
+	}
+	if at.IsZero() {
+		return Request{}, fmt.Errorf("missing time (at, timestamp or created_at)")
+	}
+	// A recorded routing event carries the chosen provider in "provider";
+	// only the requested one constrains the replay.
+	provider := r.RequestedProvider
+	if provider == "" && r.CreatedAt.IsZero() {
+		provider = r.Provider
+	}
+	return Request{
+		At:               at,
+		Provider:         provider,
+		Model:            r.Model,
+		EstimatedCostPct: r.EstimatedCostPct,
+		EstimatedTokens:  r.EstimatedTokens,
+		SessionID:        r.SessionID,
+		ClientID:         r.ClientID,
+	}, nil
+}
+
+// ReadRequests reads requests as JSON lines or as a JSON array and returns
+// them in time order. Requests with the same time keep their input order.
+func ReadRequests(r io.Reader) ([]Request, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read requests: %w", err)
+	}
+
+	var records []requestRecord
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return nil, fmt.Errorf("decode requests: %w", err)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+		line := 0
+		for scanner.Scan() {
+			line++
+			text := bytes.TrimSpace(scanner.Bytes())
+			if len(text) == 0 {
+				continue
+			}
+			var record requestRecord
+			if err := json.Unmarshal(text, &record); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			records = append(records, record)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("read requests: %w", err)
+		}
+	}
+
+	requests := make([]Request, 0, len(records))
+	for i, record := range records {
+		req, err := record.request()
+		if err != nil {
+			return nil, fmt.Errorf("request %d: %w", i+1, err)
+		}
+		requests = append(requests, req)
+	}
+	sort.SliceStable(requests, func(i, j int) bool { return requests[i].At.Before(requests[j].At) })
+	return requests, nil
+}
+
+// ReadSnapshot reads the starting state, either the envelope served by
+// GET /snapshot or a bare snapshot. Signatures are not checked.
+func ReadSnapshot(r io.Reader) (*snapshot.Snapshot, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot: %w", err)
+	}
+
+	var env snapshot.Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("decode snapshot: %w", err)
+	}
+	if len(env.Payload) > 0 {
+		return snapshot.Open(&env, nil)
+	}
+
+	var snap snapshot.Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("decode snapshot: %w", err)
+	}
+	if snap.Version != snapshot.Version {
+		return nil, fmt.Errorf("unsupported snapshot version %d", snap.Version)
+	}
+	return &snap, nil
+}
+
+// Model describes how quota behaves between recorded requests.
+type Model struct {
+	// DefaultCostPct is the quota consumed by a request without an estimate
+	DefaultCostPct float64
+	// ResetEvery restores every account to 100% on a fixed period counted
+	// from the snapshot time; zero disables resets
+	ResetEvery time.Duration
+	// RefillPerHour is quota regained continuously, in percent per hour
+	RefillPerHour float64
+}
+
+// DefaultModel returns a model with 1% per request and daily resets.
+func DefaultModel() Model {
+	return Model{
+		DefaultCostPct: 1,
+		ResetEvery:     24 * time.Hour,
+	}
+}
+
+// Scenario is one policy or weights combination to replay.
+type Scenario struct {
+	Name string
+	// Policy names a policy from the router config; empty uses the default
+	Policy string
+	// Weights, when set, replace the policy's weights
+	Weights *router.Weights
+}
+
+// Exhaustion is the moment an account's simulated quota ran out.
+type Exhaustion struct {
+	AccountID string    `json:"account_id"`
+	At        time.Time `json:"at"`
+}
+
+// AccountResult is the per-account outcome of a replay.
+type AccountResult struct {
+	AccountID    string  `json:"account_id"`
+	Provider     string  `json:"provider"`
+	Requests     int     `json:"requests"`
+	ConsumedPct  float64 `json:"consumed_percent"`
+	Exhaustions  int     `json:"exhaustions"`
+	WastedPct    float64 `json:"wasted_percent"`
+	RemainingPct float64 `json:"remaining_percent"`
+}
+
+// Result summarizes the replay of one scenario.
+type Result struct {
+	Scenario string `json:"scenario"`
+	Policy   string `json:"policy"`
+	Requests int    `json:"requests"`
+	Served   int    `json:"served"`
+	// Rejected counts requests the router had no account for, which the
+	// API answers with 503
+	Rejected    int          `json:"rejected"`
+	Switches    int          `json:"switches"`
+	Exhaustions []Exhaustion `json:"exhaustions"`
+	Resets      int          `json:"resets"`
+	// WastedPct sums the quota left unused when windows reset, in percent
+	// of one account's window
+	WastedPct float64         `json:"wasted_percent"`
+	Accounts  []AccountResult `json:"accounts"`
+}
+
+type simAccount struct {
+	account   *models.Account
+	remaining float64
+	blocked   *time.Time
+	nextReset time.Time
+	result    AccountResult
+}
+
+// Run replays requests in time order starting from snap. Each request goes
+// through a router built from cfg with its clock set to the request time.
+// Accounts without collected quota in the snapshot start full.
+func Run(snap *snapshot.Snapshot, requests []Request, model Model, cfg router.Config, scenario Scenario) (*Result, error) {
+	if snap == nil {
+		return nil, fmt.Errorf("snapshot is required")
+	}
+	if len(snap.Accounts) == 0 {
+		return nil, fmt.Errorf("snapshot has no accounts")
+	}
+
+	start := snap.GeneratedAt
+	if len(requests) > 0 && (start.IsZero() || requests[0].At.Before(start)) {
+		start = requests[0].At
+	}
+	now := start
+
+	policy := scenario.Policy
+	if policy == "" {
+		policy = cfg.DefaultPolicy
+	}
+	if scenario.Weights != nil {
+		policies := make(map[string]router.Weights, len(cfg.Policies)+1)
+		for name, weights := range cfg.Policies {
+			policies[name] = weights
+		}
+		policy = scenario.Name
+		policies[policy] = *scenario.Weights
+		cfg.Policies = policies
+	} else if _, ok := cfg.Policies[policy]; !ok && policy != "" {
+		return nil, fmt.Errorf("unknown policy %q", policy)
+	}
+	cfg.Clock = func() time.Time { return now }
+
+	s := store.NewMemoryStore()
+	accounts := make([]*simAccount, 0, len(snap.Accounts))
+	for _, entry := range snap.Accounts {
+		acc := &simAccount{
+			account: &models.Account{
+				ID:       entry.ID,
+				Provider: entry.Provider,
+				Tier:     entry.Tier,
+				Priority: entry.Priority,
+				Enabled:  true,
+			},
+			remaining: 100,
+			blocked:   entry.BlockedUntil,
+			result:    AccountResult{AccountID: entry.ID, Provider: string(entry.Provider)},
+		}
+		if entry.RemainingPct != nil {
+			acc.remaining = clampPct(*entry.RemainingPct)
+		}
+		if entry.Exhausted {
+			acc.remaining = 0
+		}
+		if model.ResetEvery > 0 {
+			acc.nextReset = start.Add(model.ResetEvery)
+		}
+		s.SetAccount(acc.account)
+		accounts = append(accounts, acc)
+	}
+	byID := make(map[string]*simAccount, len(accounts))
+	for _, acc := range accounts {
+		byID[acc.account.ID] = acc
+	}
+
+	result := &Result{
+		Scenario:    scenario.Name,
+		Policy:      policy,
+		Requests:    len(requests),
+		Exhaustions: []Exhaustion{},
+	}
+
+	r := router.NewRouter(s, cfg)
+	defer r.Close()
+
+	scopeAccounts := make(map[string]string)
+	for _, req := range requests {
+		advance(accounts, model, now, req.At, result)
+		if req.At.After(now) {
+			now = req.At
+		}
+		for _, acc := range accounts {
+			syncQuota(s, acc, now)
+		}
+
+		cost := req.EstimatedCostPct
+		if cost <= 0 {
+			cost = model.DefaultCostPct
+		}
+		selectReq := router.SelectRequest{
+			Provider:        models.Provider(req.Provider),
+			EstimatedCost:   cost,
+			EstimatedTokens: req.EstimatedTokens,
+			Policy:          policy,
+			Model:           req.Model,
+			SessionKey:      req.SessionID,
+			ClientID:        req.ClientID,
+		}
+		for _, acc := range accounts {
+			if acc.blocked != nil && acc.blocked.After(now) {
+				selectReq.Exclude = append(selectReq.Exclude, acc.account.ID)
+			}
+		}
+
+		resp, err := r.Select(context.Background(), selectReq)
+		if err != nil {
+			result.Rejected++
+			continue
+		}
+		result.Served++
+
+		// Like the API, affinity hits do not move the scope
+		if !resp.AffinityHit {
+			r.RecordScopedSwitch(resp.Scope, resp.AccountID)
+			if previous, ok := scopeAccounts[resp.Scope]; ok && previous != resp.AccountID {
+				result.Switches++
+			}
+			scopeAccounts[resp.Scope] = resp.AccountID
+		}
+
+		acc := byID[resp.AccountID]
+		if acc == nil {
+			continue
+		}
+		consumed := cost
+		if consumed > acc.remaining {
+			consumed = acc.remaining
+		}
+		acc.result.Requests++
+		acc.result.ConsumedPct += consumed
+		before := acc.remaining
+		acc.remaining -= consumed
+		if before > 0 && acc.remaining <= 0 {
+			acc.remaining = 0
+			acc.result.Exhaustions++
+			result.Exhaustions = append(result.Exhaustions, Exhaustion{AccountID: acc.account.ID, At: now})
+		}
+	}
+
+	result.Accounts = make([]AccountResult, 0, len(accounts))
+	for _, acc := range accounts {
+		acc.result.RemainingPct = acc.remaining
+		result.Accounts = append(result.Accounts, acc.result)
+	}
+	sort.Slice(result.Accounts, func(i, j int) bool { return result.Accounts[i].AccountID < result.Accounts[j].AccountID })
+	return result, nil
+}
+
+// advance applies refill and resets to every account between from and to.
+// Quota still left when a window resets counts as wasted.
+func advance(accounts []*simAccount, model Model, from, to time.Time, result *Result) {
+	if !to.After(from) {
+		return
+	}
+	for _, acc := range accounts {
+		at := from
+		for model.ResetEvery > 0 && !acc.nextReset.After(to) {
+			acc.refill(model.RefillPerHour, acc.nextReset.Sub(at))
+			acc.result.WastedPct += acc.remaining
+			result.WastedPct += acc.remaining
+			result.Resets++
+			acc.remaining = 100
+			at = acc.nextReset
+			acc.nextReset = acc.nextReset.Add(model.ResetEvery)
+		}
+		acc.refill(model.RefillPerHour, to.Sub(at))
+	}
+}
+
+func (a *simAccount) refill(perHour float64, elapsed time.Duration) {
+	if perHour <= 0 || elapsed <= 0 {
+		return
+	}
+	a.remaining = clampPct(a.remaining + perHour*elapsed.Hours())
+}
+
+// syncQuota publishes the simulated remaining quota to the router's store
+func syncQuota(s *store.MemoryStore, acc *simAccount, now time.Time) {
+	s.SetQuota(acc.account.ID, &models.QuotaInfo{
+		Provider:              acc.account.Provider,
+		AccountID:             acc.account.ID,
+		Tier:                  acc.account.Tier,
+		Dimensions:            models.DimensionSlice{},
+		EffectiveRemainingPct: acc.remaining,
+		Source:                models.SourcePolling,
+		Confidence:            1,
+		CollectedAt:           now,
+		UpdatedAt:             now,
+	})
+}
+
+func clampPct(v float64) float64 {
+	switch {
+	case v < 0:
+		return 0
+	case v > 100:
+		return 100
+	default:
+		return v
+	}
+}
@@ -0,0 +1,199 @@
+package simulate
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/router"
+	"github.com/quotaguard/quotaguard/internal/snapshot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var simStart = time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+func testSnapshot(remaining map[string]float64) *snapshot.Snapshot {
+	snap := &snapshot.Snapshot{Version: snapshot.Version, GeneratedAt: simStart}
+	for _, id := range []string{"a", "b"} {
+		value, ok := remaining[id]
+		if !ok {
+			continue
+		}
+		pct := value
+		snap.Accounts = append(snap.Accounts, snapshot.Account{
+			ID:           id,
+			Provider:     models.ProviderOpenAI,
+			Priority:     5,
+			RemainingPct: &pct,
+		})
+	}
+	return snap
+}
+
+func testRequests(n int, every time.Duration, cost float64) []Request {
+	requests := make([]Request, n)
+	for i := range requests {
+		requests[i] = Request{At: simStart.Add(time.Duration(i+1) * every), EstimatedCostPct: cost}
+	}
+	return requests
+}
+
+func TestReadRequests(t *testing.T) {
+	t.Run("json lines in time order", func(t *testing.T) {
+		input := `{"at":"2026-05-01T10:00:00Z","provider":"openai","model":"gpt-4o","estimated_cost_percent":2}
+
+{"timestamp":"2026-05-01T09:00:00Z","session_id":"s1","client_id":"c1"}
+{"at":"2026-05-01T10:00:00Z","model":"second"}
+`
+		requests, err := ReadRequests(strings.NewReader(input))
+		require.NoError(t, err)
+		require.Len(t, requests, 3)
+
+		assert.Equal(t, "s1", requests[0].SessionID)
+		assert.Equal(t, "c1", requests[0].ClientID)
+		assert.Equal(t, "openai", requests[1].Provider)
+		assert.Equal(t, 2.0, requests[1].EstimatedCostPct)
+		assert.Equal(t, "second", requests[2].Model, "equal times keep input order")
+	})
+
+	t.Run("routing events array", func(t *testing.T) {
+		events := []*models.RoutingEvent{
+			{CreatedAt: simStart.Add(time.Minute), Provider: "openai", AccountID: "a"},
+			{CreatedAt: simStart, RequestedProvider: "gemini", Provider: "gemini", EstimatedTokens: 500},
+		}
+		data, err := json.Marshal(events)
+		require.NoError(t, err)
+
+		requests, err := ReadRequests(strings.NewReader(string(data)))
+		require.NoError(t, err)
+		require.Len(t, requests, 2)
+		assert.Equal(t, "gemini", requests[0].Provider)
+		assert.Equal(t, int64(500), requests[0].EstimatedTokens)
+		assert.Empty(t, requests[1].Provider, "the chosen provider does not constrain the replay")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := ReadRequests(strings.NewReader(`{"model":"gpt-4o"}`))
+		assert.ErrorContains(t, err, "missing time")
+
+		_, err = ReadRequests(strings.NewReader("{\"at\":\"2026-05-01T10:00:00Z\"}\nnot json\n"))
+		assert.ErrorContains(t, err, "line 2")
+	})
+}
+
+func TestReadSnapshot(t *testing.T) {
+	snap := testSnapshot(map[string]float64{"a": 50})
+
+	env, err := snapshot.Seal(snap, []byte("secret"))
+	require.NoError(t, err)
+	data, err := json.Marshal(env)
+	require.NoError(t, err)
+
+	got, err := ReadSnapshot(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	require.Len(t, got.Accounts, 1)
+	assert.Equal(t, "a", got.Accounts[0].ID)
+
+	data, err = json.Marshal(snap)
+	require.NoError(t, err)
+	got, err = ReadSnapshot(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	assert.Equal(t, simStart, got.GeneratedAt)
+
+	_, err = ReadSnapshot(strings.NewReader(`{"version":7,"accounts":[]}`))
+	assert.ErrorContains(t, err, "unsupported snapshot version")
+}
+
+func TestRun_ExhaustionAndRejections(t *testing.T) {
+	snap := testSnapshot(map[string]float64{"a": 30})
+	model := Model{DefaultCostPct: 10}
+
+	result, err := Run(snap, testRequests(6, 10*time.Minute, 0), model, router.DefaultConfig(), Scenario{Name: "balanced"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "balanced", result.Policy)
+	assert.Equal(t, 6, result.Requests)
+	assert.Equal(t, result.Requests, result.Served+result.Rejected)
+	assert.Positive(t, result.Rejected)
+	require.Len(t, result.Accounts, 1)
+	assert.Equal(t, result.Served, result.Accounts[0].Requests)
+	assert.InDelta(t, 30-result.Accounts[0].ConsumedPct, result.Accounts[0].RemainingPct, 1e-9)
+	assert.Zero(t, result.Resets)
+}
+
+func TestRun_GlobalLowDrainsToExhaustion(t *testing.T) {
+	snap := testSnapshot(map[string]float64{"a": 4, "b": 3})
+	model := Model{DefaultCostPct: 2}
+
+	result, err := Run(snap, testRequests(6, 10*time.Minute, 0), model, router.DefaultConfig(), Scenario{Name: "balanced"})
+	require.NoError(t, err)
+
+	require.Len(t, result.Exhaustions, 2)
+	assert.ElementsMatch(t, []string{"a", "b"}, []string{result.Exhaustions[0].AccountID, result.Exhaustions[1].AccountID})
+	assert.Equal(t, 4, result.Served)
+	assert.Equal(t, 2, result.Rejected)
+	assert.Equal(t, 1, result.Switches)
+}
+
+func TestRun_ResetsRefillAndWaste(t *testing.T) {
+	snap := testSnapshot(map[string]float64{"a": 60})
+	model := Model{DefaultCostPct: 5, ResetEvery: time.Hour, RefillPerHour: 10}
+
+	// One request at 30m, then one after the second reset
+	requests := []Request{
+		{At: simStart.Add(30 * time.Minute)},
+		{At: simStart.Add(150 * time.Minute)},
+	}
+	result, err := Run(snap, requests, model, router.DefaultConfig(), Scenario{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "balanced", result.Policy, "empty policy uses the default")
+	assert.Equal(t, 2, result.Served)
+	assert.Equal(t, 2, result.Resets)
+	// 60 + 5 refill - 5 used + 5 refill = 65 wasted at 1h, 100 at 2h
+	assert.InDelta(t, 165, result.WastedPct, 1e-6)
+	assert.InDelta(t, 95, result.Accounts[0].RemainingPct, 1e-6, "refill is capped at 100 before the last request")
+}
+
+func TestRun_Scenarios(t *testing.T) {
+	snap := testSnapshot(map[string]float64{"a": 100, "b": 100})
+
+	weights := router.Weights{Safety: 1}
+	result, err := Run(snap, testRequests(3, time.Minute, 1), DefaultModel(), router.DefaultConfig(), Scenario{Name: "safety-only", Weights: &weights})
+	require.NoError(t, err)
+	assert.Equal(t, "safety-only", result.Policy)
+	assert.Equal(t, 3, result.Served)
+
+	_, err = Run(snap, nil, DefaultModel(), router.DefaultConfig(), Scenario{Policy: "missing"})
+	assert.ErrorContains(t, err, "unknown policy")
+
+	_, err = Run(&snapshot.Snapshot{Version: snapshot.Version}, nil, DefaultModel(), router.DefaultConfig(), Scenario{})
+	assert.ErrorContains(t, err, "no accounts")
+}
+
+func TestRun_DefaultCostReachesRouter(t *testing.T) {
+	snap := testSnapshot(map[string]float64{"a": 14, "b": 60})
+	snap.Accounts[0].Priority = 10
+	snap.Accounts[1].Priority = 3
+	weights := router.Weights{Tier: 1}
+
+	// a is preferred on tier, but cannot fit the default cost plus the safety margin
+	result, err := Run(snap, testRequests(1, time.Minute, 0), Model{DefaultCostPct: 10}, router.DefaultConfig(), Scenario{Name: "tier", Weights: &weights})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Accounts[0].Requests)
+	assert.Equal(t, 1, result.Accounts[1].Requests)
+}
+
+func TestRun_BlockedAccountsAreSkipped(t *testing.T) {
+	snap := testSnapshot(map[string]float64{"a": 100, "b": 50})
+	blockedUntil := simStart.Add(30 * time.Minute)
+	snap.Accounts[0].BlockedUntil = &blockedUntil
+
+	result, err := Run(snap, testRequests(2, 10*time.Minute, 1), DefaultModel(), router.DefaultConfig(), Scenario{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Accounts[0].Requests, "a is blocked for both requests")
+	assert.Equal(t, 2, result.Accounts[1].Requests)
+}
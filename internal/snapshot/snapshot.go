@@ -0,0 +1,143 @@
+// Package snapshot defines the signed account snapshot QuotaGuard serves to
+// clients so they can keep routing from local state while it is down.
+package snapshot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// Version is the snapshot format version. Clients reject snapshots with a
+// different version instead of guessing at their meaning.
+const Version = 1
+
+var (
+	// ErrUnsigned is returned when a signature is required but missing.
+	ErrUnsigned = errors.New("snapshot is not signed")
+	// ErrBadSignature is returned when the signature does not match.
+	ErrBadSignature = errors.New("snapshot signature mismatch")
+)
+
+// Account is the routing state of one account at snapshot time.
+type Account struct {
+	ID           string          `json:"id"`
+	Provider     models.Provider `json:"provider"`
+	Tier         string          `json:"tier,omitempty"`
+	Priority     int             `json:"priority"`
+	BlockedUntil *time.Time      `json:"blocked_until,omitempty"`
+	// RemainingPct is nil when no quota has been collected yet.
+	RemainingPct *float64   `json:"remaining_percent,omitempty"`
+	Exhausted    bool       `json:"exhausted"`
+	Throttled    bool       `json:"throttled,omitempty"`
+	CollectedAt  *time.Time `json:"collected_at,omitempty"`
+}
+
+// Blocked reports whether the account is blocked at now.
+func (a *Account) Blocked(now time.Time) bool {
+	return a.BlockedUntil != nil && a.BlockedUntil.After(now)
+}
+
+// Snapshot lists the routable accounts at GeneratedAt.
+type Snapshot struct {
+	Version     int       `json:"version"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Accounts    []Account `json:"accounts"`
+}
+
+// Envelope is the wire and on-disk form of a snapshot. The signature is an
+// HMAC-SHA256 of the raw payload bytes, so it survives re-encoding by
+// clients that do not know every field.
+type Envelope struct {
+	Payload   json.RawMessage `json:"payload"`
+	Signature string          `json:"signature,omitempty"`
+}
+
+// Build creates a snapshot of accounts ordered by ID. exhausted marks
+// accounts that must not be used regardless of their quota, such as ones
+// over their monthly budget; it may be nil.
+func Build(accounts []*models.Account, quotas map[string]*models.QuotaInfo, exhausted func(*models.Account) bool, now time.Time) *Snapshot {
+	snap := &Snapshot{
+		Version:     Version,
+		GeneratedAt: now.UTC(),
+		Accounts:    make([]Account, 0, len(accounts)),
+	}
+	for _, acc := range accounts {
+		if acc == nil || !acc.Enabled {
+			continue
+		}
+		entry := Account{
+			ID:       acc.ID,
+			Provider: acc.Provider,
+			Tier:     acc.Tier,
+			Priority: acc.Priority,
+		}
+		if acc.BlockedUntil != nil && acc.BlockedUntil.After(now) {
+			blockedUntil := acc.BlockedUntil.UTC()
+			entry.BlockedUntil = &blockedUntil
+		}
+		if q, ok := quotas[acc.ID]; ok && q != nil {
+			remaining := q.EffectiveRemainingWithVirtual()
+			collectedAt := q.CollectedAt.UTC()
+			entry.RemainingPct = &remaining
+			entry.Exhausted = q.IsExhausted() || remaining <= 0
+			entry.Throttled = q.IsThrottled
+			entry.CollectedAt = &collectedAt
+		}
+		if exhausted != nil && exhausted(acc) {
+			entry.Exhausted = true
+		}
+		snap.Accounts = append(snap.Accounts, entry)
+	}
+	sort.Slice(snap.Accounts, func(i, j int) bool { return snap.Accounts[i].ID < snap.Accounts[j].ID })
+	return snap
+}
+
+// Seal encodes snap and signs it with key. An empty key leaves the
+// envelope unsigned.
+func Seal(snap *Snapshot, key []byte) (*Envelope, error) {
+	payload, err := json.Marshal(snap)
+	if err != nil {
+		return nil, fmt.Errorf("encode snapshot: %w", err)
+	}
+	env := &Envelope{Payload: payload}
+	if len(key) > 0 {
+		env.Signature = sign(key, payload)
+	}
+	return env, nil
+}
+
+// Open verifies env with key and decodes it. With an empty key the
+// signature is not checked.
+func Open(env *Envelope, key []byte) (*Snapshot, error) {
+	if len(key) > 0 {
+		if env.Signature == "" {
+			return nil, ErrUnsigned
+		}
+		if !hmac.Equal([]byte(sign(key, env.Payload)), []byte(env.Signature)) {
+			return nil, ErrBadSignature
+		}
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(env.Payload, &snap); err != nil {
+		return nil, fmt.Errorf("decode snapshot: %w", err)
+	}
+	if snap.Version != Version {
+		return nil, fmt.Errorf("unsupported snapshot version %d", snap.Version)
+	}
+	return &snap, nil
+}
+
+func sign(key, payload []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
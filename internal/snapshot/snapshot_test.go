@@ -0,0 +1,93 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	blocked := now.Add(time.Hour)
+	expired := now.Add(-time.Hour)
+
+	accounts := []*models.Account{
+		{ID: "b", Provider: models.ProviderOpenAI, Enabled: true, Priority: 3, BlockedUntil: &blocked},
+		{ID: "a", Provider: models.ProviderGemini, Enabled: true, BlockedUntil: &expired},
+		{ID: "c", Provider: models.ProviderOpenAI, Enabled: true},
+		{ID: "off", Provider: models.ProviderOpenAI, Enabled: false},
+		{ID: "budget", Provider: models.ProviderOpenAI, Enabled: true},
+	}
+	quotas := map[string]*models.QuotaInfo{
+		"a":      {AccountID: "a", EffectiveRemainingPct: 40, CollectedAt: now},
+		"b":      {AccountID: "b", EffectiveRemainingPct: 0},
+		"budget": {AccountID: "budget", EffectiveRemainingPct: 90},
+	}
+
+	snap := Build(accounts, quotas, func(acc *models.Account) bool { return acc.ID == "budget" }, now)
+	assert.Equal(t, Version, snap.Version)
+	assert.Equal(t, now, snap.GeneratedAt)
+	require.Len(t, snap.Accounts, 4)
+
+	a, b, budget, c := snap.Accounts[0], snap.Accounts[1], snap.Accounts[2], snap.Accounts[3]
+	assert.Equal(t, []string{"a", "b", "budget", "c"}, []string{a.ID, b.ID, budget.ID, c.ID})
+
+	assert.Nil(t, a.BlockedUntil, "expired blocks are dropped")
+	require.NotNil(t, a.RemainingPct)
+	assert.Equal(t, 40.0, *a.RemainingPct)
+	assert.False(t, a.Exhausted)
+
+	assert.True(t, b.Blocked(now))
+	assert.True(t, b.Exhausted)
+	assert.Equal(t, 3, b.Priority)
+
+	assert.True(t, budget.Exhausted)
+
+	assert.Nil(t, c.RemainingPct)
+	assert.False(t, c.Exhausted)
+}
+
+func TestSealOpen(t *testing.T) {
+	snap := Build([]*models.Account{{ID: "a", Provider: models.ProviderOpenAI, Enabled: true}}, nil, nil, time.Now())
+	key := []byte("secret")
+
+	env, err := Seal(snap, key)
+	require.NoError(t, err)
+	assert.NotEmpty(t, env.Signature)
+
+	// The envelope survives a round trip through JSON, as on disk
+	data, err := json.Marshal(env)
+	require.NoError(t, err)
+	var decoded Envelope
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	opened, err := Open(&decoded, key)
+	require.NoError(t, err)
+	assert.Equal(t, "a", opened.Accounts[0].ID)
+	assert.True(t, snap.GeneratedAt.Equal(opened.GeneratedAt))
+
+	_, err = Open(&decoded, []byte("other"))
+	assert.ErrorIs(t, err, ErrBadSignature)
+
+	tampered := decoded
+	tampered.Payload = json.RawMessage(`{"version":1,"generated_at":"2030-01-01T00:00:00Z","accounts":[]}`)
+	_, err = Open(&tampered, key)
+	assert.ErrorIs(t, err, ErrBadSignature)
+
+	unsigned, err := Seal(snap, nil)
+	require.NoError(t, err)
+	assert.Empty(t, unsigned.Signature)
+	_, err = Open(unsigned, key)
+	assert.ErrorIs(t, err, ErrUnsigned)
+	_, err = Open(unsigned, nil)
+	assert.NoError(t, err)
+
+	future, err := Seal(&Snapshot{Version: Version + 1}, key)
+	require.NoError(t, err)
+	_, err = Open(future, key)
+	assert.ErrorContains(t, err, "unsupported snapshot version")
+}
@@ -0,0 +1,28 @@
+package store
+
+import (
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// QuotaHistoryInterval is the minimum spacing between recorded history
+// samples of one account. Quota updates in between are not recorded.
+const QuotaHistoryInterval = 5 * time.Minute
+
+// memoryHistoryRetention bounds how far back the memory store keeps samples
+const memoryHistoryRetention = 8 * 24 * time.Hour
+
+// historySampleTime returns when a quota was collected, defaulting to now
+func historySampleTime(quota *models.QuotaInfo) time.Time {
+	if quota.CollectedAt.IsZero() {
+		return time.Now()
+	}
+	return quota.CollectedAt
+}
+
+// historyDue reports whether a sample taken at at should be recorded after
+// the last one recorded at last
+func historyDue(last, at time.Time) bool {
+	return last.IsZero() || at.Sub(last) >= QuotaHistoryInterval
+}
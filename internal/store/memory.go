@@ -1,6 +1,8 @@
 package store
 
 import (
+	"slices"
+	"sort"
 	"sync"
 	"time"
 
@@ -16,6 +18,17 @@ type MemoryStore struct {
 	credentials  map[string]*models.AccountCredentials
 	reservations map[string]*models.Reservation // key: reservationID
 	activities   map[string]*models.AccountActivity
+	scopeStates  map[string]*models.RouterScopeState
+	clients      map[string]*models.APIClient   // key: clientID
+	clientUsage  map[string]*models.ClientUsage // key: clientID|day
+	spend        map[string]*models.SpendEntry  // key: accountID|clientID|day
+	checkpoints  map[string]*models.LogCheckpoint
+	history      map[string][]*models.QuotaSample // key: accountID, oldest first
+	historyAt    map[string]time.Time             // key: accountID, last sample time
+	incidents    map[string]*models.AlertIncident // key: incidentID
+	mutes        map[string]*models.AlertMute     // key: accountID|type
+	routing      []*models.RoutingEvent           // oldest first
+	routingSeq   int64
 	settings     SettingsStore
 
 	// Subscribers for quota changes
@@ -31,6 +44,15 @@ func NewMemoryStore() *MemoryStore {
 		credentials:  make(map[string]*models.AccountCredentials),
 		reservations: make(map[string]*models.Reservation),
 		activities:   make(map[string]*models.AccountActivity),
+		scopeStates:  make(map[string]*models.RouterScopeState),
+		clients:      make(map[string]*models.APIClient),
+		clientUsage:  make(map[string]*models.ClientUsage),
+		spend:        make(map[string]*models.SpendEntry),
+		checkpoints:  make(map[string]*models.LogCheckpoint),
+		history:      make(map[string][]*models.QuotaSample),
+		historyAt:    make(map[string]time.Time),
+		incidents:    make(map[string]*models.AlertIncident),
+		mutes:        make(map[string]*models.AlertMute),
 		subscribers:  make(map[string][]chan models.QuotaEvent),
 		settings:     NewMemorySettingsStore(),
 	}
@@ -72,6 +94,18 @@ func (s *MemoryStore) SetAccountBlockedUntil(id string, blockedUntil *time.Time)
 	return nil
 }
 
+// SetAccountPollError records the last collector failure for an account;
+// nil clears it.
+func (s *MemoryStore) SetAccountPollError(id string, pollErr *models.PollError) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if acc, ok := s.accounts[id]; ok {
+		acc.LastPollError = pollErr
+	}
+	return nil
+}
+
 // DeleteAccount removes an account
 func (s *MemoryStore) DeleteAccount(id string) bool {
 	s.mu.Lock()
@@ -163,6 +197,7 @@ func (s *MemoryStore) SetQuota(accountID string, quota *models.QuotaInfo) {
 	defer s.mu.Unlock()
 
 	s.quotas[accountID] = quota
+	s.recordHistoryLocked(accountID, quota)
 }
 
 // UpdateQuota updates quota information for an account
@@ -172,6 +207,7 @@ func (s *MemoryStore) UpdateQuota(accountID string, quota *models.QuotaInfo) err
 
 	oldQuota, ok := s.quotas[accountID]
 	s.quotas[accountID] = quota
+	s.recordHistoryLocked(accountID, quota)
 
 	// Notify subscribers if dimensions changed
 	if ok && oldQuota != nil {
@@ -253,6 +289,461 @@ func (s *MemoryStore) GetAccountActivity(accountID string) (*models.AccountActiv
 	return copyAct, true
 }
 
+// SaveRouterScopeState stores anti-flapping state for a routing scope.
+func (s *MemoryStore) SaveRouterScopeState(state *models.RouterScopeState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copyState := *state
+	if state.LastSwitchAt != nil {
+		t := *state.LastSwitchAt
+		copyState.LastSwitchAt = &t
+	}
+	s.scopeStates[state.Scope] = &copyState
+	return nil
+}
+
+// ListRouterScopeStates returns anti-flapping state for all routing scopes.
+func (s *MemoryStore) ListRouterScopeStates() ([]*models.RouterScopeState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*models.RouterScopeState, 0, len(s.scopeStates))
+	for _, state := range s.scopeStates {
+		copyState := *state
+		if state.LastSwitchAt != nil {
+			t := *state.LastSwitchAt
+			copyState.LastSwitchAt = &t
+		}
+		result = append(result, &copyState)
+	}
+	return result, nil
+}
+
+// API client operations
+
+// GetAPIClient retrieves an API client by ID
+func (s *MemoryStore) GetAPIClient(id string) (*models.APIClient, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	client, ok := s.clients[id]
+	if !ok {
+		return nil, false
+	}
+	return copyAPIClient(client), true
+}
+
+// GetAPIClientByKeyHash retrieves an API client by the hash of its key
+func (s *MemoryStore) GetAPIClientByKeyHash(hash string) (*models.APIClient, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, client := range s.clients {
+		if client.KeyHash == hash {
+			return copyAPIClient(client), true
+		}
+	}
+	return nil, false
+}
+
+// SetAPIClient stores or updates an API client
+func (s *MemoryStore) SetAPIClient(client *models.APIClient) error {
+	if err := client.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.clients[client.ID] = copyAPIClient(client)
+	return nil
+}
+
+// DeleteAPIClient removes an API client and its usage history
+func (s *MemoryStore) DeleteAPIClient(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.clients[id]; !ok {
+		return false
+	}
+	delete(s.clients, id)
+	for key, usage := range s.clientUsage {
+		if usage.ClientID == id {
+			delete(s.clientUsage, key)
+		}
+	}
+	return true
+}
+
+// ListAPIClients returns all API clients
+func (s *MemoryStore) ListAPIClients() []*models.APIClient {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*models.APIClient, 0, len(s.clients))
+	for _, client := range s.clients {
+		result = append(result, copyAPIClient(client))
+	}
+	return result
+}
+
+// AddClientUsage adds requests and consumed quota to a client's daily usage
+func (s *MemoryStore) AddClientUsage(clientID, day string, requests int64, consumedPct float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := clientID + "|" + day
+	usage, ok := s.clientUsage[key]
+	if !ok {
+		usage = &models.ClientUsage{ClientID: clientID, Day: day}
+		s.clientUsage[key] = usage
+	}
+	usage.Requests += requests
+	usage.ConsumedPct += consumedPct
+	if usage.ConsumedPct < 0 {
+		usage.ConsumedPct = 0
+	}
+	usage.UpdatedAt = time.Now()
+	return nil
+}
+
+// GetClientUsage returns a client's usage for a day
+func (s *MemoryStore) GetClientUsage(clientID, day string) (*models.ClientUsage, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	usage, ok := s.clientUsage[clientID+"|"+day]
+	if !ok {
+		return nil, false
+	}
+	copyUsage := *usage
+	return &copyUsage, true
+}
+
+// AddSpend adds tokens, requests and cost to the spend ledger
+func (s *MemoryStore) AddSpend(entry *models.SpendEntry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := entry.AccountID + "|" + entry.ClientID + "|" + entry.Day
+	current, ok := s.spend[key]
+	if !ok {
+		current = &models.SpendEntry{AccountID: entry.AccountID, ClientID: entry.ClientID, Day: entry.Day}
+		s.spend[key] = current
+	}
+	current.Requests += entry.Requests
+	current.InputTokens += entry.InputTokens
+	current.OutputTokens += entry.OutputTokens
+	current.CostUSD += entry.CostUSD
+	current.UpdatedAt = time.Now()
+	return nil
+}
+
+// ListSpend returns ledger entries for days in [fromDay, toDay]
+func (s *MemoryStore) ListSpend(fromDay, toDay string) ([]*models.SpendEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*models.SpendEntry, 0)
+	for _, entry := range s.spend {
+		if entry.Day < fromDay || entry.Day > toDay {
+			continue
+		}
+		copyEntry := *entry
+		result = append(result, &copyEntry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Day != result[j].Day {
+			return result[i].Day < result[j].Day
+		}
+		if result[i].AccountID != result[j].AccountID {
+			return result[i].AccountID < result[j].AccountID
+		}
+		return result[i].ClientID < result[j].ClientID
+	})
+	return result, nil
+}
+
+// GetAccountSpend returns an account's total cost for days in [fromDay, toDay]
+func (s *MemoryStore) GetAccountSpend(accountID, fromDay, toDay string) (float64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	total := 0.0
+	for _, entry := range s.spend {
+		if entry.AccountID == accountID && entry.Day >= fromDay && entry.Day <= toDay {
+			total += entry.CostUSD
+		}
+	}
+	return total, nil
+}
+
+// recordHistoryLocked samples quota into the history unless the account was
+// sampled less than QuotaHistoryInterval ago. Caller must hold s.mu.
+func (s *MemoryStore) recordHistoryLocked(accountID string, quota *models.QuotaInfo) {
+	if quota == nil {
+		return
+	}
+	at := historySampleTime(quota)
+	if !historyDue(s.historyAt[accountID], at) {
+		return
+	}
+	s.historyAt[accountID] = at
+
+	samples := append(s.history[accountID], models.QuotaSamples(accountID, quota, at)...)
+	cutoff := at.Add(-memoryHistoryRetention)
+	start := 0
+	for start < len(samples) && samples[start].CollectedAt.Before(cutoff) {
+		start++
+	}
+	s.history[accountID] = samples[start:]
+}
+
+// ListQuotaHistory returns an account's samples collected in [from, to],
+// oldest first
+func (s *MemoryStore) ListQuotaHistory(accountID string, from, to time.Time) ([]*models.QuotaSample, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*models.QuotaSample, 0)
+	for _, sample := range s.history[accountID] {
+		if sample.CollectedAt.Before(from) || sample.CollectedAt.After(to) {
+			continue
+		}
+		copySample := *sample
+		result = append(result, &copySample)
+	}
+	return result, nil
+}
+
+// SaveAlertIncident creates or updates an alert incident
+func (s *MemoryStore) SaveAlertIncident(incident *models.AlertIncident) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *incident
+	s.incidents[incident.ID] = &copied
+
+	// Resolved incidents age out like quota history
+	cutoff := time.Now().Add(-memoryHistoryRetention)
+	for id, old := range s.incidents {
+		if old.ResolvedAt != nil && old.ResolvedAt.Before(cutoff) {
+			delete(s.incidents, id)
+		}
+	}
+	return nil
+}
+
+// GetAlertIncident retrieves an alert incident by ID
+func (s *MemoryStore) GetAlertIncident(id string) (*models.AlertIncident, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	incident, ok := s.incidents[id]
+	if !ok {
+		return nil, false
+	}
+	copied := *incident
+	return &copied, true
+}
+
+// FindActiveAlertIncident returns the unresolved incident of an alert type
+// on an account
+func (s *MemoryStore) FindActiveAlertIncident(accountID, alertType string) (*models.AlertIncident, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, incident := range s.incidents {
+		if incident.AccountID == accountID && incident.Type == alertType && incident.IsActive() {
+			copied := *incident
+			return &copied, true
+		}
+	}
+	return nil, false
+}
+
+// ListAlertIncidents returns incidents in any of the given statuses, or
+// all incidents when none are given, oldest first
+func (s *MemoryStore) ListAlertIncidents(statuses ...models.IncidentStatus) ([]*models.AlertIncident, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*models.AlertIncident, 0)
+	for _, incident := range s.incidents {
+		if len(statuses) > 0 && !slices.Contains(statuses, incident.Status) {
+			continue
+		}
+		copied := *incident
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].OpenedAt.Equal(result[j].OpenedAt) {
+			return result[i].OpenedAt.Before(result[j].OpenedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// SetAlertMute creates or replaces the mute of an account and alert type
+func (s *MemoryStore) SetAlertMute(mute *models.AlertMute) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *mute
+	s.mutes[mute.AccountID+"|"+mute.Type] = &copied
+	return nil
+}
+
+// DeleteAlertMute removes the mute of an account and alert type
+func (s *MemoryStore) DeleteAlertMute(accountID, alertType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.mutes, accountID+"|"+alertType)
+	return nil
+}
+
+// ListAlertMutes returns the mutes that have not expired at now
+func (s *MemoryStore) ListAlertMutes(now time.Time) ([]*models.AlertMute, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*models.AlertMute, 0, len(s.mutes))
+	for _, mute := range s.mutes {
+		if !now.Before(mute.Until) {
+			continue
+		}
+		copied := *mute
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].AccountID != result[j].AccountID {
+			return result[i].AccountID < result[j].AccountID
+		}
+		return result[i].Type < result[j].Type
+	})
+	return result, nil
+}
+
+// RecordRoutingEvent stores a router selection and assigns its ID
+func (s *MemoryStore) RecordRoutingEvent(event *models.RoutingEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.routingSeq++
+	event.ID = s.routingSeq
+	copied := *event
+	events := append(s.routing, &copied)
+	cutoff := time.Now().Add(-memoryHistoryRetention)
+	start := 0
+	for start < len(events) && events[start].CreatedAt.Before(cutoff) {
+		start++
+	}
+	s.routing = events[start:]
+	return nil
+}
+
+// SetRoutingOutcome attaches feedback to the latest routing event with the
+// correlation ID and reports whether there was one
+func (s *MemoryStore) SetRoutingOutcome(correlationID string, outcome *models.RoutingOutcome) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.routing) - 1; i >= 0; i-- {
+		if s.routing[i].CorrelationID == correlationID {
+			copied := *outcome
+			s.routing[i].Outcome = &copied
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListRoutingEvents returns the routing events that pass the filter,
+// newest first
+func (s *MemoryStore) ListRoutingEvents(filter models.RoutingEventFilter) ([]*models.RoutingEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*models.RoutingEvent, 0)
+	for i := len(s.routing) - 1; i >= 0; i-- {
+		if filter.Limit > 0 && len(result) >= filter.Limit {
+			break
+		}
+		if !filter.Matches(s.routing[i]) {
+			continue
+		}
+		copied := *s.routing[i]
+		if copied.Outcome != nil {
+			outcome := *copied.Outcome
+			copied.Outcome = &outcome
+		}
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+// SummarizeRoutingEvents counts the routing events created in [from, to),
+// the account switches among them and their failed outcomes
+func (s *MemoryStore) SummarizeRoutingEvents(from, to time.Time) (*models.RoutingEventSummary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	filter := models.RoutingEventFilter{Since: from, Until: to}
+	summary := &models.RoutingEventSummary{Failures: map[string]int{}}
+	for _, event := range s.routing {
+		if !filter.Matches(event) {
+			continue
+		}
+		summary.Events++
+		if event.SwitchedFrom != "" {
+			summary.Switches++
+		}
+		if event.Outcome != nil && !event.Outcome.Success {
+			summary.Failures[event.Outcome.Error]++
+		}
+	}
+	return summary, nil
+}
+
+// GetLogCheckpoint returns the saved position of a followed log file
+func (s *MemoryStore) GetLogCheckpoint(path string) (*models.LogCheckpoint, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	cp, ok := s.checkpoints[path]
+	if !ok {
+		return nil, false
+	}
+	copied := *cp
+	return &copied, true
+}
+
+// SetLogCheckpoint saves the position of a followed log file
+func (s *MemoryStore) SetLogCheckpoint(cp *models.LogCheckpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *cp
+	copied.UpdatedAt = time.Now()
+	s.checkpoints[cp.Path] = &copied
+	return nil
+}
+
+func copyAPIClient(client *models.APIClient) *models.APIClient {
+	copyClient := *client
+	copyClient.AllowedProviders = append([]models.Provider(nil), client.AllowedProviders...)
+	copyClient.AllowedAccounts = append([]string(nil), client.AllowedAccounts...)
+	return &copyClient
+}
+
 // Subscribe creates a subscription for quota changes on an account
 func (s *MemoryStore) Subscribe(accountID string) chan models.QuotaEvent {
 	s.subMu.Lock()
@@ -365,6 +856,15 @@ func (s *MemoryStore) Clear() {
 	s.accounts = make(map[string]*models.Account)
 	s.reservations = make(map[string]*models.Reservation)
 	s.activities = make(map[string]*models.AccountActivity)
+	s.scopeStates = make(map[string]*models.RouterScopeState)
+	s.clients = make(map[string]*models.APIClient)
+	s.clientUsage = make(map[string]*models.ClientUsage)
+	s.spend = make(map[string]*models.SpendEntry)
+	s.history = make(map[string][]*models.QuotaSample)
+	s.historyAt = make(map[string]time.Time)
+	s.incidents = make(map[string]*models.AlertIncident)
+	s.mutes = make(map[string]*models.AlertMute)
+	s.routing = nil
 	if settings, ok := s.settings.(*MemorySettingsStore); ok {
 		settings.Clear()
 	}
@@ -408,6 +908,7 @@ type Store interface {
 	GetAccount(id string) (*models.Account, bool)
 	SetAccount(acc *models.Account)
 	SetAccountBlockedUntil(id string, blockedUntil *time.Time) error
+	SetAccountPollError(id string, pollErr *models.PollError) error
 	DeleteAccount(id string) bool
 	ListAccounts() []*models.Account
 	ListEnabledAccounts() []*models.Account
@@ -426,6 +927,46 @@ type Store interface {
 	RecordAccountActivity(accountID, group string, usedAt time.Time) error
 	GetAccountActivity(accountID string) (*models.AccountActivity, bool)
 
+	// Router state operations
+	SaveRouterScopeState(state *models.RouterScopeState) error
+	ListRouterScopeStates() ([]*models.RouterScopeState, error)
+
+	// API client operations
+	GetAPIClient(id string) (*models.APIClient, bool)
+	GetAPIClientByKeyHash(hash string) (*models.APIClient, bool)
+	SetAPIClient(client *models.APIClient) error
+	DeleteAPIClient(id string) bool
+	ListAPIClients() []*models.APIClient
+	AddClientUsage(clientID, day string, requests int64, consumedPct float64) error
+	GetClientUsage(clientID, day string) (*models.ClientUsage, bool)
+
+	// Spend ledger operations
+	AddSpend(entry *models.SpendEntry) error
+	ListSpend(fromDay, toDay string) ([]*models.SpendEntry, error)
+	GetAccountSpend(accountID, fromDay, toDay string) (float64, error)
+
+	// Quota history, sampled from quota updates
+	ListQuotaHistory(accountID string, from, to time.Time) ([]*models.QuotaSample, error)
+
+	// Alert incidents and mutes
+	SaveAlertIncident(incident *models.AlertIncident) error
+	GetAlertIncident(id string) (*models.AlertIncident, bool)
+	FindActiveAlertIncident(accountID, alertType string) (*models.AlertIncident, bool)
+	ListAlertIncidents(statuses ...models.IncidentStatus) ([]*models.AlertIncident, error)
+	SetAlertMute(mute *models.AlertMute) error
+	DeleteAlertMute(accountID, alertType string) error
+	ListAlertMutes(now time.Time) ([]*models.AlertMute, error)
+
+	// Routing decisions and their feedback
+	RecordRoutingEvent(event *models.RoutingEvent) error
+	SetRoutingOutcome(correlationID string, outcome *models.RoutingOutcome) (bool, error)
+	ListRoutingEvents(filter models.RoutingEventFilter) ([]*models.RoutingEvent, error)
+	SummarizeRoutingEvents(from, to time.Time) (*models.RoutingEventSummary, error)
+
+	// Log follower checkpoints
+	GetLogCheckpoint(path string) (*models.LogCheckpoint, bool)
+	SetLogCheckpoint(cp *models.LogCheckpoint) error
+
 	// Reservation operations
 	GetReservation(id string) (*models.Reservation, bool)
 	SetReservation(id string, res *models.Reservation)
@@ -260,6 +260,109 @@ func TestMemoryStore_Subscription(t *testing.T) {
 	})
 }
 
+func TestMemoryStore_APIClients(t *testing.T) {
+	store := NewMemoryStore()
+
+	client := &models.APIClient{
+		ID:               "batch",
+		KeyHash:          models.HashAPIKey("qg_secret"),
+		AllowedProviders: []models.Provider{models.ProviderOpenAI},
+		Enabled:          true,
+	}
+	require.NoError(t, store.SetAPIClient(client))
+	assert.Error(t, store.SetAPIClient(&models.APIClient{ID: "no-key"}))
+
+	got, ok := store.GetAPIClientByKeyHash(models.HashAPIKey("qg_secret"))
+	require.True(t, ok)
+	assert.Equal(t, "batch", got.ID)
+
+	// Returned clients are copies
+	got.AllowedProviders[0] = models.ProviderGemini
+	got, _ = store.GetAPIClient("batch")
+	assert.Equal(t, models.ProviderOpenAI, got.AllowedProviders[0])
+
+	require.NoError(t, store.AddClientUsage("batch", "2026-01-02", 1, 5))
+	require.NoError(t, store.AddClientUsage("batch", "2026-01-02", 2, -8))
+	usage, ok := store.GetClientUsage("batch", "2026-01-02")
+	require.True(t, ok)
+	assert.Equal(t, int64(3), usage.Requests)
+	assert.Equal(t, 0.0, usage.ConsumedPct)
+
+	assert.Len(t, store.ListAPIClients(), 1)
+	assert.True(t, store.DeleteAPIClient("batch"))
+	assert.False(t, store.DeleteAPIClient("batch"))
+	_, ok = store.GetClientUsage("batch", "2026-01-02")
+	assert.False(t, ok)
+}
+
+func TestMemoryStore_SpendLedger(t *testing.T) {
+	store := NewMemoryStore()
+
+	require.NoError(t, store.AddSpend(&models.SpendEntry{AccountID: "api-1", Day: "2026-01-01", Requests: 1, InputTokens: 1000, CostUSD: 0.5}))
+	require.NoError(t, store.AddSpend(&models.SpendEntry{AccountID: "api-1", Day: "2026-01-01", Requests: 1, OutputTokens: 500, CostUSD: 1.0}))
+	require.NoError(t, store.AddSpend(&models.SpendEntry{AccountID: "api-1", ClientID: "batch", Day: "2026-01-02", Requests: 1, CostUSD: 2.0}))
+	require.NoError(t, store.AddSpend(&models.SpendEntry{AccountID: "api-2", Day: "2026-02-01", Requests: 1, CostUSD: 4.0}))
+	assert.Error(t, store.AddSpend(&models.SpendEntry{AccountID: "api-1", Day: "2026-01-01", CostUSD: -1}))
+
+	entries, err := store.ListSpend("2026-01-01", "2026-01-31")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, int64(2), entries[0].Requests)
+	assert.Equal(t, int64(1000), entries[0].InputTokens)
+	assert.Equal(t, int64(500), entries[0].OutputTokens)
+	assert.Equal(t, "batch", entries[1].ClientID)
+
+	spent, err := store.GetAccountSpend("api-1", "2026-01-01", "2026-01-31")
+	require.NoError(t, err)
+	assert.InDelta(t, 3.5, spent, 0.0001)
+
+	store.Clear()
+	entries, err = store.ListSpend("2026-01-01", "2026-12-31")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestMemoryStore_QuotaHistory(t *testing.T) {
+	store := NewMemoryStore()
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	quotaAt := func(at time.Time, remaining int64) *models.QuotaInfo {
+		return &models.QuotaInfo{
+			AccountID:   "acc-1",
+			Provider:    models.ProviderOpenAI,
+			CollectedAt: at,
+			Dimensions:  models.DimensionSlice{{Type: models.DimensionRPD, Limit: 100, Remaining: remaining}},
+		}
+	}
+
+	store.SetQuota("acc-1", quotaAt(start, 90))
+	require.NoError(t, store.UpdateQuota("acc-1", quotaAt(start.Add(time.Minute), 85)))
+	require.NoError(t, store.UpdateQuota("acc-1", quotaAt(start.Add(QuotaHistoryInterval), 80)))
+
+	// Updates closer than the interval are not sampled
+	samples, err := store.ListQuotaHistory("acc-1", start, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.InDelta(t, 90, samples[0].RemainingPct, 1e-9)
+	assert.InDelta(t, 80, samples[1].RemainingPct, 1e-9)
+	assert.Equal(t, string(models.DimensionRPD), samples[1].Dimension)
+
+	samples, err = store.ListQuotaHistory("acc-1", start.Add(time.Minute), start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, samples, 1)
+
+	// Samples past the retention are dropped
+	store.SetQuota("acc-1", quotaAt(start.Add(memoryHistoryRetention+time.Hour), 70))
+	samples, err = store.ListQuotaHistory("acc-1", start.Add(-time.Hour), start.Add(30*24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.InDelta(t, 70, samples[0].RemainingPct, 1e-9)
+
+	store.Clear()
+	samples, err = store.ListQuotaHistory("acc-1", start.Add(-time.Hour), start.Add(30*24*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, samples)
+}
+
 func TestMemoryStore_Clear(t *testing.T) {
 	store := NewMemoryStore()
 
@@ -335,3 +438,144 @@ func TestMemoryStore_ConcurrentAccess(t *testing.T) {
 	assert.Equal(t, 5, stats.AccountCount)
 	assert.Equal(t, 5, stats.QuotaCount)
 }
+
+// testAlertIncidents exercises the incident and mute operations of a store
+func testAlertIncidents(t *testing.T, s Store) {
+	t.Helper()
+	opened := time.Now().UTC().Truncate(time.Second).Add(-time.Hour)
+
+	first := &models.AlertIncident{
+		ID: "inc-1", AccountID: "acc-1", Type: "threshold", Severity: "warning",
+		Message: "Quota remaining 10%", Status: models.IncidentOpen, Count: 1,
+		OpenedAt: opened, LastSeenAt: opened,
+	}
+	require.NoError(t, s.SaveAlertIncident(first))
+	require.NoError(t, s.SaveAlertIncident(&models.AlertIncident{
+		ID: "inc-2", AccountID: "acc-2", Type: "exhausted", Severity: "critical",
+		Status: models.IncidentOpen, Count: 1, OpenedAt: opened.Add(time.Minute), LastSeenAt: opened.Add(time.Minute),
+	}))
+
+	active, ok := s.FindActiveAlertIncident("acc-1", "threshold")
+	require.True(t, ok)
+	assert.Equal(t, "inc-1", active.ID)
+	_, ok = s.FindActiveAlertIncident("acc-1", "exhausted")
+	assert.False(t, ok)
+
+	ackedAt := opened.Add(5 * time.Minute)
+	active.Status = models.IncidentAcknowledged
+	active.AckedAt = &ackedAt
+	active.AckedBy = 42
+	active.Count = 3
+	require.NoError(t, s.SaveAlertIncident(active))
+
+	got, ok := s.GetAlertIncident("inc-1")
+	require.True(t, ok)
+	assert.Equal(t, models.IncidentAcknowledged, got.Status)
+	assert.Equal(t, int64(42), got.AckedBy)
+	assert.Equal(t, 3, got.Count)
+	require.NotNil(t, got.AckedAt)
+	assert.True(t, got.AckedAt.Equal(ackedAt))
+	assert.Nil(t, got.ResolvedAt)
+
+	resolvedAt := opened.Add(10 * time.Minute)
+	got.Status = models.IncidentResolved
+	got.ResolvedAt = &resolvedAt
+	require.NoError(t, s.SaveAlertIncident(got))
+	_, ok = s.FindActiveAlertIncident("acc-1", "threshold")
+	assert.False(t, ok)
+
+	open, err := s.ListAlertIncidents(models.IncidentOpen, models.IncidentAcknowledged)
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	assert.Equal(t, "inc-2", open[0].ID)
+	all, err := s.ListAlertIncidents()
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "inc-1", all[0].ID)
+
+	now := time.Now()
+	require.NoError(t, s.SetAlertMute(&models.AlertMute{AccountID: "acc-1", Until: now.Add(time.Hour), Reason: "maintenance"}))
+	require.NoError(t, s.SetAlertMute(&models.AlertMute{Type: "threshold", Until: now.Add(-time.Minute)}))
+	mutes, err := s.ListAlertMutes(now)
+	require.NoError(t, err)
+	require.Len(t, mutes, 1)
+	assert.Equal(t, "acc-1", mutes[0].AccountID)
+	assert.Equal(t, "maintenance", mutes[0].Reason)
+
+	require.NoError(t, s.DeleteAlertMute("acc-1", ""))
+	mutes, err = s.ListAlertMutes(now)
+	require.NoError(t, err)
+	assert.Empty(t, mutes)
+}
+
+func TestMemoryStore_AlertIncidents(t *testing.T) {
+	testAlertIncidents(t, NewMemoryStore())
+}
+
+// testRoutingEvents exercises the routing event operations of a store
+func testRoutingEvents(t *testing.T, s Store) {
+	now := time.Now().UTC().Truncate(time.Second)
+	events := []*models.RoutingEvent{
+		{CorrelationID: "c1", CreatedAt: now.Add(-2 * time.Hour), AccountID: "acc1", Provider: "openai", Policy: "balanced", Score: 0.9},
+		{CorrelationID: "c2", CreatedAt: now.Add(-time.Hour), ClientID: "batch", AccountID: "acc2", Model: "gpt-4o",
+			RequiredDimensions: []string{"rpm"}, AlternativeIDs: []string{"acc1"}, LatencyMs: 1.5, Scope: "global", SwitchedFrom: "acc1"},
+		{CorrelationID: "c3", CreatedAt: now, AccountID: "acc1"},
+	}
+	for _, event := range events {
+		require.NoError(t, s.RecordRoutingEvent(event))
+		assert.NotZero(t, event.ID)
+	}
+
+	ok, err := s.SetRoutingOutcome("c2", &models.RoutingOutcome{Success: false, Error: "429", InputTokens: 10, ReportedAt: now})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = s.SetRoutingOutcome("missing", &models.RoutingOutcome{Success: true, ReportedAt: now})
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	all, err := s.ListRoutingEvents(models.RoutingEventFilter{})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, "c3", all[0].CorrelationID, "newest first")
+	assert.Nil(t, all[0].Outcome)
+	assert.Equal(t, []string{"acc1"}, all[1].AlternativeIDs)
+	assert.Equal(t, []string{"rpm"}, all[1].RequiredDimensions)
+	require.NotNil(t, all[1].Outcome)
+	assert.Equal(t, "429", all[1].Outcome.Error)
+	assert.Equal(t, int64(10), all[1].Outcome.InputTokens)
+	assert.Equal(t, "global", all[1].Scope)
+	assert.Equal(t, "acc1", all[1].SwitchedFrom)
+
+	acc1, err := s.ListRoutingEvents(models.RoutingEventFilter{AccountID: "acc1", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, acc1, 1)
+	assert.Equal(t, "c3", acc1[0].CorrelationID)
+
+	ranged, err := s.ListRoutingEvents(models.RoutingEventFilter{Since: now.Add(-90 * time.Minute), Until: now})
+	require.NoError(t, err)
+	require.Len(t, ranged, 1)
+	assert.Equal(t, "batch", ranged[0].ClientID)
+
+	byClient, err := s.ListRoutingEvents(models.RoutingEventFilter{ClientID: "batch", CorrelationID: "c1"})
+	require.NoError(t, err)
+	assert.Empty(t, byClient)
+
+	ok, err = s.SetRoutingOutcome("c1", &models.RoutingOutcome{Success: true, ReportedAt: now})
+	require.NoError(t, err)
+	require.True(t, ok)
+	summary, err := s.SummarizeRoutingEvents(now.Add(-3*time.Hour), now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Events)
+	assert.Equal(t, 1, summary.Switches)
+	assert.Equal(t, map[string]int{"429": 1}, summary.Failures)
+
+	summary, err = s.SummarizeRoutingEvents(now, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Events)
+	assert.Zero(t, summary.Switches)
+	assert.Empty(t, summary.Failures)
+}
+
+func TestMemoryStore_RoutingEvents(t *testing.T) {
+	testRoutingEvents(t, NewMemoryStore())
+}
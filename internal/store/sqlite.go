@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -31,6 +32,9 @@ type SQLiteStore struct {
 	cleanupDone   chan struct{}
 	cleanupOnce   sync.Once
 	retentionDays int
+
+	// Last quota history sample time per account
+	historyAt map[string]time.Time
 }
 
 // NewSQLiteStore creates a new SQLite store with WAL mode enabled
@@ -79,6 +83,7 @@ func NewSQLiteStoreWithRetention(dbPath string, retentionDays int) (*SQLiteStore
 		cleanupDone:   make(chan struct{}),
 		retentionDays: retentionDays,
 		settings:      settingsStore,
+		historyAt:     make(map[string]time.Time),
 	}
 
 	// Start retention cleanup goroutine if retention is enabled
@@ -209,6 +214,182 @@ func runMigrations(db *sql.DB) error {
 				CREATE INDEX IF NOT EXISTS idx_account_activity_last_used_at ON account_activity(last_used_at);
 			`,
 		},
+		{
+			version: 7,
+			up: `
+				CREATE TABLE IF NOT EXISTS router_scope_state (
+					scope TEXT PRIMARY KEY,
+					current_account TEXT NOT NULL,
+					previous_account TEXT NOT NULL DEFAULT '',
+					selected_at DATETIME NOT NULL,
+					last_switch_at DATETIME
+				);
+			`,
+		},
+		{
+			version: 8,
+			up: `
+				CREATE TABLE IF NOT EXISTS api_clients (
+					id TEXT PRIMARY KEY,
+					name TEXT NOT NULL DEFAULT '',
+					key_hash TEXT NOT NULL UNIQUE,
+					key_prefix TEXT NOT NULL DEFAULT '',
+					allowed_providers TEXT,
+					allowed_accounts TEXT,
+					default_policy TEXT NOT NULL DEFAULT '',
+					rate_limit_rpm INTEGER NOT NULL DEFAULT 0,
+					daily_quota_share_pct REAL NOT NULL DEFAULT 0,
+					enabled INTEGER NOT NULL DEFAULT 1,
+					created_at DATETIME NOT NULL,
+					updated_at DATETIME NOT NULL
+				);
+				CREATE TABLE IF NOT EXISTS api_client_usage (
+					client_id TEXT NOT NULL,
+					day TEXT NOT NULL,
+					requests INTEGER NOT NULL DEFAULT 0,
+					consumed_pct REAL NOT NULL DEFAULT 0,
+					updated_at DATETIME NOT NULL,
+					PRIMARY KEY (client_id, day)
+				);
+				ALTER TABLE reservations ADD COLUMN client_id TEXT NOT NULL DEFAULT '';
+			`,
+		},
+		{
+			version: 9,
+			up: `
+				ALTER TABLE accounts ADD COLUMN monthly_budget REAL NOT NULL DEFAULT 0;
+				CREATE TABLE IF NOT EXISTS spend_ledger (
+					account_id TEXT NOT NULL,
+					client_id TEXT NOT NULL DEFAULT '',
+					day TEXT NOT NULL,
+					requests INTEGER NOT NULL DEFAULT 0,
+					input_tokens INTEGER NOT NULL DEFAULT 0,
+					output_tokens INTEGER NOT NULL DEFAULT 0,
+					cost_usd REAL NOT NULL DEFAULT 0,
+					updated_at DATETIME NOT NULL,
+					PRIMARY KEY (account_id, client_id, day)
+				);
+				CREATE INDEX IF NOT EXISTS idx_spend_ledger_day ON spend_ledger(day);
+			`,
+		},
+		{
+			version: 10,
+			up: `
+				ALTER TABLE accounts ADD COLUMN poll_error_class TEXT NOT NULL DEFAULT '';
+				ALTER TABLE accounts ADD COLUMN poll_error TEXT NOT NULL DEFAULT '';
+				ALTER TABLE accounts ADD COLUMN poll_error_at DATETIME;
+			`,
+		},
+		{
+			version: 11,
+			up: `
+				CREATE TABLE IF NOT EXISTS log_checkpoints (
+					path TEXT PRIMARY KEY,
+					fingerprint TEXT NOT NULL DEFAULT '',
+					byte_offset INTEGER NOT NULL DEFAULT 0,
+					updated_at DATETIME NOT NULL
+				);
+			`,
+		},
+		{
+			version: 12,
+			up: `
+				CREATE TABLE IF NOT EXISTS soft_deleted_records (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					table_name TEXT NOT NULL,
+					record_id TEXT NOT NULL,
+					deleted_at DATETIME NOT NULL,
+					original_data TEXT NOT NULL DEFAULT ''
+				);
+				CREATE INDEX IF NOT EXISTS idx_soft_deleted_records_record ON soft_deleted_records(table_name, record_id);
+			`,
+		},
+		{
+			version: 13,
+			up: `
+				CREATE TABLE IF NOT EXISTS quota_history (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					account_id TEXT NOT NULL,
+					provider TEXT NOT NULL DEFAULT '',
+					dimension TEXT NOT NULL,
+					remaining_pct REAL NOT NULL,
+					reset_at DATETIME,
+					collected_at DATETIME NOT NULL
+				);
+				CREATE INDEX IF NOT EXISTS idx_quota_history_account ON quota_history(account_id, collected_at);
+			`,
+		},
+		{
+			version: 14,
+			up: `
+				CREATE TABLE IF NOT EXISTS alert_incidents (
+					id TEXT PRIMARY KEY,
+					account_id TEXT NOT NULL,
+					type TEXT NOT NULL,
+					severity TEXT NOT NULL,
+					message TEXT NOT NULL DEFAULT '',
+					status TEXT NOT NULL,
+					count INTEGER NOT NULL DEFAULT 1,
+					opened_at DATETIME NOT NULL,
+					last_seen_at DATETIME NOT NULL,
+					acked_at DATETIME,
+					acked_by INTEGER NOT NULL DEFAULT 0,
+					snoozed_until DATETIME,
+					escalated_at DATETIME,
+					resolved_at DATETIME
+				);
+				CREATE INDEX IF NOT EXISTS idx_alert_incidents_status ON alert_incidents(status, account_id, type);
+				CREATE TABLE IF NOT EXISTS alert_mutes (
+					account_id TEXT NOT NULL DEFAULT '',
+					type TEXT NOT NULL DEFAULT '',
+					until DATETIME NOT NULL,
+					reason TEXT NOT NULL DEFAULT '',
+					created_at DATETIME NOT NULL,
+					PRIMARY KEY (account_id, type)
+				);
+			`,
+		},
+		{
+			version: 15,
+			up: `
+				CREATE TABLE IF NOT EXISTS routing_events (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					correlation_id TEXT NOT NULL DEFAULT '',
+					created_at DATETIME NOT NULL,
+					client_id TEXT NOT NULL DEFAULT '',
+					session_id TEXT NOT NULL DEFAULT '',
+					model TEXT NOT NULL DEFAULT '',
+					requested_provider TEXT NOT NULL DEFAULT '',
+					required_dimensions TEXT,
+					estimated_cost_pct REAL NOT NULL DEFAULT 0,
+					estimated_tokens INTEGER NOT NULL DEFAULT 0,
+					policy TEXT NOT NULL DEFAULT '',
+					account_id TEXT NOT NULL,
+					provider TEXT NOT NULL DEFAULT '',
+					score REAL NOT NULL DEFAULT 0,
+					reason TEXT NOT NULL DEFAULT '',
+					alternative_ids TEXT,
+					affinity_hit INTEGER NOT NULL DEFAULT 0,
+					latency_ms REAL NOT NULL DEFAULT 0,
+					outcome_at DATETIME,
+					success INTEGER,
+					error TEXT NOT NULL DEFAULT '',
+					actual_cost_pct REAL NOT NULL DEFAULT 0,
+					input_tokens INTEGER NOT NULL DEFAULT 0,
+					output_tokens INTEGER NOT NULL DEFAULT 0
+				);
+				CREATE INDEX IF NOT EXISTS idx_routing_events_created ON routing_events(created_at);
+				CREATE INDEX IF NOT EXISTS idx_routing_events_correlation ON routing_events(correlation_id);
+				CREATE INDEX IF NOT EXISTS idx_routing_events_account ON routing_events(account_id, created_at);
+			`,
+		},
+		{
+			version: 16,
+			up: `
+				ALTER TABLE routing_events ADD COLUMN scope TEXT NOT NULL DEFAULT '';
+				ALTER TABLE routing_events ADD COLUMN switched_from TEXT NOT NULL DEFAULT '';
+			`,
+		},
 	}
 
 	// Run pending migrations
@@ -267,6 +448,24 @@ func (s *SQLiteStore) cleanupOldData() {
 		s.logger.Error("cleanup failed", "table", "quotas", "error", err.Error())
 	}
 
+	_, err = s.db.Exec("DELETE FROM quota_history WHERE collected_at < ?", cutoff.UTC())
+	if err != nil {
+		s.logger.Error("cleanup failed", "table", "quota_history", "error", err.Error())
+	}
+
+	_, err = s.db.Exec("DELETE FROM alert_incidents WHERE status = ? AND resolved_at < ?", models.IncidentResolved, cutoff.UTC())
+	if err != nil {
+		s.logger.Error("cleanup failed", "table", "alert_incidents", "error", err.Error())
+	}
+
+	_, err = s.db.Exec("DELETE FROM alert_mutes WHERE until < ?", time.Now().UTC())
+	if err != nil {
+		s.logger.Error("cleanup failed", "table", "alert_mutes", "error", err.Error())
+	}
+
+	// routing_events follow cleanup.routing_events_retention, applied by the
+	// cleanup manager, so they are not pruned here
+
 	// Cleanup old reservations (released or cancelled)
 	_, err = s.db.Exec(`
 		DELETE FROM reservations
@@ -307,6 +506,12 @@ func (s *SQLiteStore) Close() error {
 	return nil
 }
 
+// DB returns the underlying database for maintenance helpers such as
+// cleanup.SQLiteSoftDeleter.
+func (s *SQLiteStore) DB() *sql.DB {
+	return s.db
+}
+
 // Settings returns the settings store.
 func (s *SQLiteStore) Settings() SettingsStore {
 	return s.settings
@@ -320,11 +525,12 @@ func (s *SQLiteStore) GetAccount(id string) (*models.Account, bool) {
 	defer s.mu.RUnlock()
 
 	var acc models.Account
+	var pollErr pollErrorColumns
 
 	err := s.db.QueryRow(`
-		SELECT id, provider, provider_type, enabled, priority, tier, concurrency_limit, input_cost, output_cost, credentials_ref, oauth_creds_path, blocked_until, created_at, updated_at
+		SELECT id, provider, provider_type, enabled, priority, tier, concurrency_limit, input_cost, output_cost, monthly_budget, credentials_ref, oauth_creds_path, blocked_until, poll_error_class, poll_error, poll_error_at, created_at, updated_at
 		FROM accounts WHERE id = ?
-	`, id).Scan(&acc.ID, &acc.Provider, &acc.ProviderType, &acc.Enabled, &acc.Priority, &acc.Tier, &acc.ConcurrencyLimit, &acc.InputCost, &acc.OutputCost, &acc.CredentialsRef, &acc.OAuthCredsPath, &acc.BlockedUntil, &acc.CreatedAt, &acc.UpdatedAt)
+	`, id).Scan(&acc.ID, &acc.Provider, &acc.ProviderType, &acc.Enabled, &acc.Priority, &acc.Tier, &acc.ConcurrencyLimit, &acc.InputCost, &acc.OutputCost, &acc.MonthlyBudget, &acc.CredentialsRef, &acc.OAuthCredsPath, &acc.BlockedUntil, &pollErr.class, &pollErr.message, &pollErr.at, &acc.CreatedAt, &acc.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, false
@@ -332,6 +538,7 @@ func (s *SQLiteStore) GetAccount(id string) (*models.Account, bool) {
 	if err != nil {
 		return nil, false
 	}
+	acc.LastPollError = pollErr.value()
 
 	return &acc, true
 }
@@ -343,8 +550,8 @@ func (s *SQLiteStore) SetAccount(acc *models.Account) {
 
 	now := time.Now()
 	_, err := s.db.Exec(`
-		INSERT INTO accounts (id, provider, provider_type, enabled, priority, tier, concurrency_limit, input_cost, output_cost, credentials_ref, oauth_creds_path, blocked_until, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO accounts (id, provider, provider_type, enabled, priority, tier, concurrency_limit, input_cost, output_cost, monthly_budget, credentials_ref, oauth_creds_path, blocked_until, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			provider = excluded.provider,
 			provider_type = excluded.provider_type,
@@ -354,11 +561,12 @@ func (s *SQLiteStore) SetAccount(acc *models.Account) {
 			concurrency_limit = excluded.concurrency_limit,
 			input_cost = excluded.input_cost,
 			output_cost = excluded.output_cost,
+			monthly_budget = excluded.monthly_budget,
 			credentials_ref = excluded.credentials_ref,
 			oauth_creds_path = excluded.oauth_creds_path,
 			blocked_until = excluded.blocked_until,
 			updated_at = excluded.updated_at
-	`, acc.ID, acc.Provider, acc.ProviderType, acc.Enabled, acc.Priority, acc.Tier, acc.ConcurrencyLimit, acc.InputCost, acc.OutputCost, acc.CredentialsRef, acc.OAuthCredsPath, acc.BlockedUntil, now, now)
+	`, acc.ID, acc.Provider, acc.ProviderType, acc.Enabled, acc.Priority, acc.Tier, acc.ConcurrencyLimit, acc.InputCost, acc.OutputCost, acc.MonthlyBudget, acc.CredentialsRef, acc.OAuthCredsPath, acc.BlockedUntil, now, now)
 
 	if err != nil {
 		s.logger.Error("failed to set account", "error", err.Error())
@@ -376,6 +584,41 @@ func (s *SQLiteStore) SetAccountBlockedUntil(id string, blockedUntil *time.Time)
 	return err
 }
 
+// SetAccountPollError records the last collector failure for an account;
+// nil clears it.
+func (s *SQLiteStore) SetAccountPollError(id string, pollErr *models.PollError) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var class, message string
+	var at *time.Time
+	if pollErr != nil {
+		class, message, at = string(pollErr.Class), pollErr.Message, &pollErr.At
+	}
+	_, err := s.db.Exec(`
+		UPDATE accounts SET poll_error_class = ?, poll_error = ?, poll_error_at = ? WHERE id = ?
+	`, class, message, at, id)
+	return err
+}
+
+// pollErrorColumns scans the poll_error_* columns of an account row
+type pollErrorColumns struct {
+	class   string
+	message string
+	at      *time.Time
+}
+
+func (c pollErrorColumns) value() *models.PollError {
+	if c.class == "" {
+		return nil
+	}
+	pollErr := &models.PollError{Class: models.PollErrorClass(c.class), Message: c.message}
+	if c.at != nil {
+		pollErr.At = *c.at
+	}
+	return pollErr
+}
+
 // DeleteAccount removes an account
 func (s *SQLiteStore) DeleteAccount(id string) bool {
 	s.mu.Lock()
@@ -396,7 +639,7 @@ func (s *SQLiteStore) ListAccounts() []*models.Account {
 	defer s.mu.RUnlock()
 
 	rows, err := s.db.Query(`
-		SELECT id, provider, provider_type, enabled, priority, tier, concurrency_limit, input_cost, output_cost, credentials_ref, oauth_creds_path, blocked_until, created_at, updated_at
+		SELECT id, provider, provider_type, enabled, priority, tier, concurrency_limit, input_cost, output_cost, monthly_budget, credentials_ref, oauth_creds_path, blocked_until, poll_error_class, poll_error, poll_error_at, created_at, updated_at
 		FROM accounts ORDER BY priority DESC, id
 	`)
 	if err != nil {
@@ -407,10 +650,12 @@ func (s *SQLiteStore) ListAccounts() []*models.Account {
 	var accounts []*models.Account
 	for rows.Next() {
 		var acc models.Account
+		var pollErr pollErrorColumns
 
-		if err := rows.Scan(&acc.ID, &acc.Provider, &acc.ProviderType, &acc.Enabled, &acc.Priority, &acc.Tier, &acc.ConcurrencyLimit, &acc.InputCost, &acc.OutputCost, &acc.CredentialsRef, &acc.OAuthCredsPath, &acc.BlockedUntil, &acc.CreatedAt, &acc.UpdatedAt); err != nil {
+		if err := rows.Scan(&acc.ID, &acc.Provider, &acc.ProviderType, &acc.Enabled, &acc.Priority, &acc.Tier, &acc.ConcurrencyLimit, &acc.InputCost, &acc.OutputCost, &acc.MonthlyBudget, &acc.CredentialsRef, &acc.OAuthCredsPath, &acc.BlockedUntil, &pollErr.class, &pollErr.message, &pollErr.at, &acc.CreatedAt, &acc.UpdatedAt); err != nil {
 			continue
 		}
+		acc.LastPollError = pollErr.value()
 
 		accounts = append(accounts, &acc)
 	}
@@ -424,7 +669,7 @@ func (s *SQLiteStore) ListEnabledAccounts() []*models.Account {
 	defer s.mu.RUnlock()
 
 	rows, err := s.db.Query(`
-		SELECT id, provider, provider_type, enabled, priority, tier, concurrency_limit, input_cost, output_cost, credentials_ref, oauth_creds_path, blocked_until, created_at, updated_at
+		SELECT id, provider, provider_type, enabled, priority, tier, concurrency_limit, input_cost, output_cost, monthly_budget, credentials_ref, oauth_creds_path, blocked_until, poll_error_class, poll_error, poll_error_at, created_at, updated_at
 		FROM accounts WHERE enabled = 1 ORDER BY priority DESC, id
 	`)
 	if err != nil {
@@ -435,10 +680,12 @@ func (s *SQLiteStore) ListEnabledAccounts() []*models.Account {
 	var accounts []*models.Account
 	for rows.Next() {
 		var acc models.Account
+		var pollErr pollErrorColumns
 
-		if err := rows.Scan(&acc.ID, &acc.Provider, &acc.ProviderType, &acc.Enabled, &acc.Priority, &acc.Tier, &acc.ConcurrencyLimit, &acc.InputCost, &acc.OutputCost, &acc.CredentialsRef, &acc.OAuthCredsPath, &acc.BlockedUntil, &acc.CreatedAt, &acc.UpdatedAt); err != nil {
+		if err := rows.Scan(&acc.ID, &acc.Provider, &acc.ProviderType, &acc.Enabled, &acc.Priority, &acc.Tier, &acc.ConcurrencyLimit, &acc.InputCost, &acc.OutputCost, &acc.MonthlyBudget, &acc.CredentialsRef, &acc.OAuthCredsPath, &acc.BlockedUntil, &pollErr.class, &pollErr.message, &pollErr.at, &acc.CreatedAt, &acc.UpdatedAt); err != nil {
 			continue
 		}
+		acc.LastPollError = pollErr.value()
 
 		accounts = append(accounts, &acc)
 	}
@@ -563,7 +810,9 @@ func (s *SQLiteStore) SetQuota(accountID string, quota *models.QuotaInfo) {
 
 	if err != nil {
 		s.logger.Error("failed to set quota", "error", err.Error())
+		return
 	}
+	s.recordHistoryLocked(accountID, quota)
 }
 
 // UpdateQuota updates quota information for an account
@@ -623,6 +872,7 @@ func (s *SQLiteStore) UpdateQuota(accountID string, quota *models.QuotaInfo) err
 	if err != nil {
 		return &errors.ErrDatabaseQuery{Operation: "update quota", Err: err}
 	}
+	s.recordHistoryLocked(accountID, quota)
 
 	// Notify subscribers if dimensions changed
 	if oldQuota != nil {
@@ -755,6 +1005,739 @@ func (s *SQLiteStore) GetAccountActivity(accountID string) (*models.AccountActiv
 	return activity, true
 }
 
+// SaveRouterScopeState stores anti-flapping state for a routing scope.
+func (s *SQLiteStore) SaveRouterScopeState(state *models.RouterScopeState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var lastSwitch interface{}
+	if state.LastSwitchAt != nil {
+		lastSwitch = *state.LastSwitchAt
+	}
+
+	if _, err := s.db.Exec(`
+		INSERT INTO router_scope_state (scope, current_account, previous_account, selected_at, last_switch_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(scope) DO UPDATE SET
+			current_account = excluded.current_account,
+			previous_account = excluded.previous_account,
+			selected_at = excluded.selected_at,
+			last_switch_at = excluded.last_switch_at
+	`, state.Scope, state.CurrentAccount, state.PreviousAccount, state.SelectedAt, lastSwitch); err != nil {
+		return &errors.ErrDatabaseQuery{Operation: "save router scope state", Err: err}
+	}
+	return nil
+}
+
+// ListRouterScopeStates returns anti-flapping state for all routing scopes.
+func (s *SQLiteStore) ListRouterScopeStates() ([]*models.RouterScopeState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`
+		SELECT scope, current_account, previous_account, selected_at, last_switch_at
+		FROM router_scope_state
+	`)
+	if err != nil {
+		return nil, &errors.ErrDatabaseQuery{Operation: "list router scope state", Err: err}
+	}
+	defer rows.Close()
+
+	var states []*models.RouterScopeState
+	for rows.Next() {
+		state := &models.RouterScopeState{}
+		var lastSwitch sql.NullTime
+		if err := rows.Scan(&state.Scope, &state.CurrentAccount, &state.PreviousAccount, &state.SelectedAt, &lastSwitch); err != nil {
+			return nil, &errors.ErrDatabaseQuery{Operation: "scan router scope state", Err: err}
+		}
+		if lastSwitch.Valid {
+			t := lastSwitch.Time
+			state.LastSwitchAt = &t
+		}
+		states = append(states, state)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &errors.ErrDatabaseQuery{Operation: "iterate router scope state", Err: err}
+	}
+	return states, nil
+}
+
+// API client operations
+
+const apiClientColumns = `id, name, key_hash, key_prefix, allowed_providers, allowed_accounts,
+	default_policy, rate_limit_rpm, daily_quota_share_pct, enabled, created_at, updated_at`
+
+// GetAPIClient retrieves an API client by ID
+func (s *SQLiteStore) GetAPIClient(id string) (*models.APIClient, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	client, err := scanAPIClient(s.db.QueryRow("SELECT "+apiClientColumns+" FROM api_clients WHERE id = ?", id))
+	if err != nil {
+		return nil, false
+	}
+	return client, true
+}
+
+// GetAPIClientByKeyHash retrieves an API client by the hash of its key
+func (s *SQLiteStore) GetAPIClientByKeyHash(hash string) (*models.APIClient, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	client, err := scanAPIClient(s.db.QueryRow("SELECT "+apiClientColumns+" FROM api_clients WHERE key_hash = ?", hash))
+	if err != nil {
+		return nil, false
+	}
+	return client, true
+}
+
+// SetAPIClient stores or updates an API client
+func (s *SQLiteStore) SetAPIClient(client *models.APIClient) error {
+	if err := client.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	providersJSON, _ := json.Marshal(client.AllowedProviders)
+	accountsJSON, _ := json.Marshal(client.AllowedAccounts)
+
+	_, err := s.db.Exec(`
+		INSERT INTO api_clients (`+apiClientColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			key_hash = excluded.key_hash,
+			key_prefix = excluded.key_prefix,
+			allowed_providers = excluded.allowed_providers,
+			allowed_accounts = excluded.allowed_accounts,
+			default_policy = excluded.default_policy,
+			rate_limit_rpm = excluded.rate_limit_rpm,
+			daily_quota_share_pct = excluded.daily_quota_share_pct,
+			enabled = excluded.enabled,
+			updated_at = excluded.updated_at
+	`, client.ID, client.Name, client.KeyHash, client.KeyPrefix, string(providersJSON), string(accountsJSON),
+		client.DefaultPolicy, client.RateLimitRPM, client.DailyQuotaSharePct, client.Enabled,
+		client.CreatedAt, client.UpdatedAt)
+	if err != nil {
+		return &errors.ErrDatabaseQuery{Operation: "set api client", Err: err}
+	}
+	return nil
+}
+
+// DeleteAPIClient removes an API client and its usage history
+func (s *SQLiteStore) DeleteAPIClient(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec("DELETE FROM api_clients WHERE id = ?", id)
+	if err != nil {
+		return false
+	}
+	if _, err := s.db.Exec("DELETE FROM api_client_usage WHERE client_id = ?", id); err != nil {
+		s.logger.Error("failed to delete api client usage", "error", err.Error())
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows > 0
+}
+
+// ListAPIClients returns all API clients
+func (s *SQLiteStore) ListAPIClients() []*models.APIClient {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query("SELECT " + apiClientColumns + " FROM api_clients ORDER BY id")
+	if err != nil {
+		return []*models.APIClient{}
+	}
+	defer rows.Close()
+
+	clients := []*models.APIClient{}
+	for rows.Next() {
+		client, err := scanAPIClient(rows)
+		if err != nil {
+			continue
+		}
+		clients = append(clients, client)
+	}
+	return clients
+}
+
+// AddClientUsage adds requests and consumed quota to a client's daily usage
+func (s *SQLiteStore) AddClientUsage(clientID, day string, requests int64, consumedPct float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		INSERT INTO api_client_usage (client_id, day, requests, consumed_pct, updated_at)
+		VALUES (?, ?, ?, MAX(?, 0), ?)
+		ON CONFLICT(client_id, day) DO UPDATE SET
+			requests = requests + excluded.requests,
+			consumed_pct = MAX(consumed_pct + ?, 0),
+			updated_at = excluded.updated_at
+	`, clientID, day, requests, consumedPct, time.Now(), consumedPct)
+	if err != nil {
+		return &errors.ErrDatabaseQuery{Operation: "add client usage", Err: err}
+	}
+	return nil
+}
+
+// GetClientUsage returns a client's usage for a day
+func (s *SQLiteStore) GetClientUsage(clientID, day string) (*models.ClientUsage, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	usage := &models.ClientUsage{}
+	err := s.db.QueryRow(`
+		SELECT client_id, day, requests, consumed_pct, updated_at
+		FROM api_client_usage WHERE client_id = ? AND day = ?
+	`, clientID, day).Scan(&usage.ClientID, &usage.Day, &usage.Requests, &usage.ConsumedPct, &usage.UpdatedAt)
+	if err != nil {
+		return nil, false
+	}
+	return usage, true
+}
+
+// AddSpend adds tokens, requests and cost to the spend ledger
+func (s *SQLiteStore) AddSpend(entry *models.SpendEntry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		INSERT INTO spend_ledger (account_id, client_id, day, requests, input_tokens, output_tokens, cost_usd, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(account_id, client_id, day) DO UPDATE SET
+			requests = requests + excluded.requests,
+			input_tokens = input_tokens + excluded.input_tokens,
+			output_tokens = output_tokens + excluded.output_tokens,
+			cost_usd = cost_usd + excluded.cost_usd,
+			updated_at = excluded.updated_at
+	`, entry.AccountID, entry.ClientID, entry.Day, entry.Requests, entry.InputTokens, entry.OutputTokens, entry.CostUSD, time.Now())
+	if err != nil {
+		return &errors.ErrDatabaseQuery{Operation: "add spend", Err: err}
+	}
+	return nil
+}
+
+// ListSpend returns ledger entries for days in [fromDay, toDay]
+func (s *SQLiteStore) ListSpend(fromDay, toDay string) ([]*models.SpendEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`
+		SELECT account_id, client_id, day, requests, input_tokens, output_tokens, cost_usd, updated_at
+		FROM spend_ledger WHERE day >= ? AND day <= ?
+		ORDER BY day, account_id, client_id
+	`, fromDay, toDay)
+	if err != nil {
+		return nil, &errors.ErrDatabaseQuery{Operation: "list spend", Err: err}
+	}
+	defer rows.Close()
+
+	entries := make([]*models.SpendEntry, 0)
+	for rows.Next() {
+		entry := &models.SpendEntry{}
+		if err := rows.Scan(&entry.AccountID, &entry.ClientID, &entry.Day, &entry.Requests,
+			&entry.InputTokens, &entry.OutputTokens, &entry.CostUSD, &entry.UpdatedAt); err != nil {
+			return nil, &errors.ErrDatabaseQuery{Operation: "scan spend", Err: err}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// GetAccountSpend returns an account's total cost for days in [fromDay, toDay]
+func (s *SQLiteStore) GetAccountSpend(accountID, fromDay, toDay string) (float64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var total float64
+	err := s.db.QueryRow(`
+		SELECT COALESCE(SUM(cost_usd), 0) FROM spend_ledger
+		WHERE account_id = ? AND day >= ? AND day <= ?
+	`, accountID, fromDay, toDay).Scan(&total)
+	if err != nil {
+		return 0, &errors.ErrDatabaseQuery{Operation: "get account spend", Err: err}
+	}
+	return total, nil
+}
+
+// recordHistoryLocked samples quota into quota_history unless the account
+// was sampled less than QuotaHistoryInterval ago. Caller must hold s.mu.
+func (s *SQLiteStore) recordHistoryLocked(accountID string, quota *models.QuotaInfo) {
+	at := historySampleTime(quota)
+	if !historyDue(s.historyAt[accountID], at) {
+		return
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.logger.Error("failed to record quota history", "error", err.Error())
+		return
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	for _, sample := range models.QuotaSamples(accountID, quota, at) {
+		_, err := tx.Exec(`
+			INSERT INTO quota_history (account_id, provider, dimension, remaining_pct, reset_at, collected_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, sample.AccountID, sample.Provider, sample.Dimension, sample.RemainingPct, utcTimePtr(sample.ResetAt), sample.CollectedAt.UTC())
+		if err != nil {
+			s.logger.Error("failed to record quota history", "error", err.Error(), "account_id", accountID)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("failed to record quota history", "error", err.Error(), "account_id", accountID)
+		return
+	}
+	s.historyAt[accountID] = at
+}
+
+// utcTimePtr converts t to UTC so stored times compare in order
+func utcTimePtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// ListQuotaHistory returns an account's samples collected in [from, to],
+// oldest first
+func (s *SQLiteStore) ListQuotaHistory(accountID string, from, to time.Time) ([]*models.QuotaSample, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`
+		SELECT account_id, provider, dimension, remaining_pct, reset_at, collected_at
+		FROM quota_history WHERE account_id = ? AND collected_at >= ? AND collected_at <= ?
+		ORDER BY collected_at, id
+	`, accountID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, &errors.ErrDatabaseQuery{Operation: "list quota history", Err: err}
+	}
+	defer rows.Close()
+
+	samples := make([]*models.QuotaSample, 0)
+	for rows.Next() {
+		sample := &models.QuotaSample{}
+		var resetAt sql.NullTime
+		if err := rows.Scan(&sample.AccountID, &sample.Provider, &sample.Dimension,
+			&sample.RemainingPct, &resetAt, &sample.CollectedAt); err != nil {
+			return nil, &errors.ErrDatabaseQuery{Operation: "scan quota history", Err: err}
+		}
+		if resetAt.Valid {
+			sample.ResetAt = &resetAt.Time
+		}
+		samples = append(samples, sample)
+	}
+	return samples, rows.Err()
+}
+
+const alertIncidentColumns = `id, account_id, type, severity, message, status, count,
+	opened_at, last_seen_at, acked_at, acked_by, snoozed_until, escalated_at, resolved_at`
+
+// SaveAlertIncident creates or updates an alert incident
+func (s *SQLiteStore) SaveAlertIncident(incident *models.AlertIncident) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		INSERT INTO alert_incidents (`+alertIncidentColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			severity = excluded.severity,
+			message = excluded.message,
+			status = excluded.status,
+			count = excluded.count,
+			last_seen_at = excluded.last_seen_at,
+			acked_at = excluded.acked_at,
+			acked_by = excluded.acked_by,
+			snoozed_until = excluded.snoozed_until,
+			escalated_at = excluded.escalated_at,
+			resolved_at = excluded.resolved_at
+	`, incident.ID, incident.AccountID, incident.Type, incident.Severity, incident.Message, incident.Status,
+		incident.Count, incident.OpenedAt.UTC(), incident.LastSeenAt.UTC(), utcTimePtr(incident.AckedAt),
+		incident.AckedBy, utcTimePtr(incident.SnoozedUntil), utcTimePtr(incident.EscalatedAt), utcTimePtr(incident.ResolvedAt))
+	if err != nil {
+		return &errors.ErrDatabaseQuery{Operation: "save alert incident", Err: err}
+	}
+	return nil
+}
+
+// GetAlertIncident retrieves an alert incident by ID
+func (s *SQLiteStore) GetAlertIncident(id string) (*models.AlertIncident, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	incident, err := scanAlertIncident(s.db.QueryRow(`
+		SELECT `+alertIncidentColumns+` FROM alert_incidents WHERE id = ?
+	`, id))
+	if err != nil {
+		return nil, false
+	}
+	return incident, true
+}
+
+// FindActiveAlertIncident returns the unresolved incident of an alert type
+// on an account
+func (s *SQLiteStore) FindActiveAlertIncident(accountID, alertType string) (*models.AlertIncident, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	incident, err := scanAlertIncident(s.db.QueryRow(`
+		SELECT `+alertIncidentColumns+` FROM alert_incidents
+		WHERE account_id = ? AND type = ? AND status != ?
+		ORDER BY opened_at DESC LIMIT 1
+	`, accountID, alertType, models.IncidentResolved))
+	if err != nil {
+		return nil, false
+	}
+	return incident, true
+}
+
+// ListAlertIncidents returns incidents in any of the given statuses, or
+// all incidents when none are given, oldest first
+func (s *SQLiteStore) ListAlertIncidents(statuses ...models.IncidentStatus) ([]*models.AlertIncident, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	query := `SELECT ` + alertIncidentColumns + ` FROM alert_incidents`
+	args := make([]interface{}, 0, len(statuses))
+	if len(statuses) > 0 {
+		query += ` WHERE status IN (?` + strings.Repeat(", ?", len(statuses)-1) + `)`
+		for _, status := range statuses {
+			args = append(args, status)
+		}
+	}
+	rows, err := s.db.Query(query+` ORDER BY opened_at, id`, args...)
+	if err != nil {
+		return nil, &errors.ErrDatabaseQuery{Operation: "list alert incidents", Err: err}
+	}
+	defer rows.Close()
+
+	incidents := make([]*models.AlertIncident, 0)
+	for rows.Next() {
+		incident, err := scanAlertIncident(rows)
+		if err != nil {
+			return nil, &errors.ErrDatabaseQuery{Operation: "scan alert incident", Err: err}
+		}
+		incidents = append(incidents, incident)
+	}
+	return incidents, rows.Err()
+}
+
+func scanAlertIncident(row rowScanner) (*models.AlertIncident, error) {
+	incident := &models.AlertIncident{}
+	var ackedAt, snoozedUntil, escalatedAt, resolvedAt sql.NullTime
+	if err := row.Scan(&incident.ID, &incident.AccountID, &incident.Type, &incident.Severity, &incident.Message,
+		&incident.Status, &incident.Count, &incident.OpenedAt, &incident.LastSeenAt, &ackedAt, &incident.AckedBy,
+		&snoozedUntil, &escalatedAt, &resolvedAt); err != nil {
+		return nil, err
+	}
+	incident.AckedAt = nullTimePtr(ackedAt)
+	incident.SnoozedUntil = nullTimePtr(snoozedUntil)
+	incident.EscalatedAt = nullTimePtr(escalatedAt)
+	incident.ResolvedAt = nullTimePtr(resolvedAt)
+	return incident, nil
+}
+
+// nullTimePtr returns the time of a nullable column, or nil
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// SetAlertMute creates or replaces the mute of an account and alert type
+func (s *SQLiteStore) SetAlertMute(mute *models.AlertMute) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	createdAt := mute.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO alert_mutes (account_id, type, until, reason, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(account_id, type) DO UPDATE SET
+			until = excluded.until,
+			reason = excluded.reason,
+			created_at = excluded.created_at
+	`, mute.AccountID, mute.Type, mute.Until.UTC(), mute.Reason, createdAt.UTC())
+	if err != nil {
+		return &errors.ErrDatabaseQuery{Operation: "set alert mute", Err: err}
+	}
+	return nil
+}
+
+// DeleteAlertMute removes the mute of an account and alert type
+func (s *SQLiteStore) DeleteAlertMute(accountID, alertType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.db.Exec("DELETE FROM alert_mutes WHERE account_id = ? AND type = ?", accountID, alertType); err != nil {
+		return &errors.ErrDatabaseQuery{Operation: "delete alert mute", Err: err}
+	}
+	return nil
+}
+
+// ListAlertMutes returns the mutes that have not expired at now
+func (s *SQLiteStore) ListAlertMutes(now time.Time) ([]*models.AlertMute, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`
+		SELECT account_id, type, until, reason, created_at
+		FROM alert_mutes WHERE until > ?
+		ORDER BY account_id, type
+	`, now.UTC())
+	if err != nil {
+		return nil, &errors.ErrDatabaseQuery{Operation: "list alert mutes", Err: err}
+	}
+	defer rows.Close()
+
+	mutes := make([]*models.AlertMute, 0)
+	for rows.Next() {
+		mute := &models.AlertMute{}
+		if err := rows.Scan(&mute.AccountID, &mute.Type, &mute.Until, &mute.Reason, &mute.CreatedAt); err != nil {
+			return nil, &errors.ErrDatabaseQuery{Operation: "scan alert mute", Err: err}
+		}
+		mutes = append(mutes, mute)
+	}
+	return mutes, rows.Err()
+}
+
+const routingEventColumns = `id, correlation_id, created_at, client_id, session_id, model,
+	requested_provider, required_dimensions, estimated_cost_pct, estimated_tokens, policy,
+	account_id, provider, score, reason, alternative_ids, affinity_hit, latency_ms,
+	scope, switched_from, outcome_at, success, error, actual_cost_pct, input_tokens, output_tokens`
+
+// RecordRoutingEvent stores a router selection and assigns its ID
+func (s *SQLiteStore) RecordRoutingEvent(event *models.RoutingEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dimsJSON, _ := json.Marshal(event.RequiredDimensions)
+	alternativesJSON, _ := json.Marshal(event.AlternativeIDs)
+	result, err := s.db.Exec(`
+		INSERT INTO routing_events (correlation_id, created_at, client_id, session_id, model,
+			requested_provider, required_dimensions, estimated_cost_pct, estimated_tokens, policy,
+			account_id, provider, score, reason, alternative_ids, affinity_hit, latency_ms, scope, switched_from)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, event.CorrelationID, event.CreatedAt.UTC(), event.ClientID, event.SessionID, event.Model,
+		event.RequestedProvider, string(dimsJSON), event.EstimatedCostPct, event.EstimatedTokens, event.Policy,
+		event.AccountID, event.Provider, event.Score, event.Reason, string(alternativesJSON), event.AffinityHit, event.LatencyMs,
+		event.Scope, event.SwitchedFrom)
+	if err != nil {
+		return &errors.ErrDatabaseQuery{Operation: "record routing event", Err: err}
+	}
+	if id, err := result.LastInsertId(); err == nil {
+		event.ID = id
+	}
+	return nil
+}
+
+// SetRoutingOutcome attaches feedback to the latest routing event with the
+// correlation ID and reports whether there was one
+func (s *SQLiteStore) SetRoutingOutcome(correlationID string, outcome *models.RoutingOutcome) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.db.Exec(`
+		UPDATE routing_events SET
+			outcome_at = ?, success = ?, error = ?, actual_cost_pct = ?, input_tokens = ?, output_tokens = ?
+		WHERE id = (SELECT id FROM routing_events WHERE correlation_id = ? ORDER BY id DESC LIMIT 1)
+	`, outcome.ReportedAt.UTC(), outcome.Success, outcome.Error, outcome.ActualCostPct,
+		outcome.InputTokens, outcome.OutputTokens, correlationID)
+	if err != nil {
+		return false, &errors.ErrDatabaseQuery{Operation: "set routing outcome", Err: err}
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// ListRoutingEvents returns the routing events that pass the filter,
+// newest first
+func (s *SQLiteStore) ListRoutingEvents(filter models.RoutingEventFilter) ([]*models.RoutingEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var where []string
+	var args []interface{}
+	for column, value := range map[string]string{
+		"account_id":     filter.AccountID,
+		"client_id":      filter.ClientID,
+		"correlation_id": filter.CorrelationID,
+	} {
+		if value != "" {
+			where = append(where, column+" = ?")
+			args = append(args, value)
+		}
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, "created_at >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, "created_at < ?")
+		args = append(args, filter.Until.UTC())
+	}
+
+	query := `SELECT ` + routingEventColumns + ` FROM routing_events`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, &errors.ErrDatabaseQuery{Operation: "list routing events", Err: err}
+	}
+	defer rows.Close()
+
+	events := make([]*models.RoutingEvent, 0)
+	for rows.Next() {
+		event, err := scanRoutingEvent(rows)
+		if err != nil {
+			return nil, &errors.ErrDatabaseQuery{Operation: "scan routing event", Err: err}
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// SummarizeRoutingEvents counts the routing events created in [from, to),
+// the account switches among them and their failed outcomes
+func (s *SQLiteStore) SummarizeRoutingEvents(from, to time.Time) (*models.RoutingEventSummary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	summary := &models.RoutingEventSummary{Failures: map[string]int{}}
+	err := s.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(CASE WHEN switched_from != '' THEN 1 ELSE 0 END), 0)
+		FROM routing_events WHERE created_at >= ? AND created_at < ?
+	`, from.UTC(), to.UTC()).Scan(&summary.Events, &summary.Switches)
+	if err != nil {
+		return nil, &errors.ErrDatabaseQuery{Operation: "summarize routing events", Err: err}
+	}
+
+	rows, err := s.db.Query(`
+		SELECT error, COUNT(*) FROM routing_events
+		WHERE created_at >= ? AND created_at < ? AND outcome_at IS NOT NULL AND success = 0
+		GROUP BY error
+	`, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, &errors.ErrDatabaseQuery{Operation: "summarize routing failures", Err: err}
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var reported string
+		var count int
+		if err := rows.Scan(&reported, &count); err != nil {
+			return nil, &errors.ErrDatabaseQuery{Operation: "scan routing failures", Err: err}
+		}
+		summary.Failures[reported] = count
+	}
+	return summary, rows.Err()
+}
+
+func scanRoutingEvent(row rowScanner) (*models.RoutingEvent, error) {
+	event := &models.RoutingEvent{}
+	var dimsJSON, alternativesJSON sql.NullString
+	var outcomeAt sql.NullTime
+	var success sql.NullBool
+	outcome := &models.RoutingOutcome{}
+	if err := row.Scan(&event.ID, &event.CorrelationID, &event.CreatedAt, &event.ClientID, &event.SessionID,
+		&event.Model, &event.RequestedProvider, &dimsJSON, &event.EstimatedCostPct, &event.EstimatedTokens,
+		&event.Policy, &event.AccountID, &event.Provider, &event.Score, &event.Reason, &alternativesJSON,
+		&event.AffinityHit, &event.LatencyMs, &event.Scope, &event.SwitchedFrom, &outcomeAt, &success, &outcome.Error, &outcome.ActualCostPct,
+		&outcome.InputTokens, &outcome.OutputTokens); err != nil {
+		return nil, err
+	}
+	if dimsJSON.Valid {
+		_ = json.Unmarshal([]byte(dimsJSON.String), &event.RequiredDimensions)
+	}
+	if alternativesJSON.Valid {
+		_ = json.Unmarshal([]byte(alternativesJSON.String), &event.AlternativeIDs)
+	}
+	if outcomeAt.Valid {
+		outcome.ReportedAt = outcomeAt.Time
+		outcome.Success = success.Bool
+		event.Outcome = outcome
+	}
+	return event, nil
+}
+
+// GetLogCheckpoint returns the saved position of a followed log file
+func (s *SQLiteStore) GetLogCheckpoint(path string) (*models.LogCheckpoint, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	cp := &models.LogCheckpoint{Path: path}
+	err := s.db.QueryRow(`
+		SELECT fingerprint, byte_offset, updated_at FROM log_checkpoints WHERE path = ?
+	`, path).Scan(&cp.Fingerprint, &cp.Offset, &cp.UpdatedAt)
+	if err != nil {
+		return nil, false
+	}
+	return cp, true
+}
+
+// SetLogCheckpoint saves the position of a followed log file
+func (s *SQLiteStore) SetLogCheckpoint(cp *models.LogCheckpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.db.Exec(`
+		INSERT INTO log_checkpoints (path, fingerprint, byte_offset, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(path) DO UPDATE SET
+			fingerprint = excluded.fingerprint,
+			byte_offset = excluded.byte_offset,
+			updated_at = excluded.updated_at
+	`, cp.Path, cp.Fingerprint, cp.Offset, time.Now())
+	if err != nil {
+		return &errors.ErrDatabaseQuery{Operation: "set log checkpoint", Err: err}
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIClient(row rowScanner) (*models.APIClient, error) {
+	client := &models.APIClient{}
+	var providersJSON, accountsJSON sql.NullString
+	if err := row.Scan(&client.ID, &client.Name, &client.KeyHash, &client.KeyPrefix, &providersJSON, &accountsJSON,
+		&client.DefaultPolicy, &client.RateLimitRPM, &client.DailyQuotaSharePct, &client.Enabled,
+		&client.CreatedAt, &client.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if providersJSON.Valid && providersJSON.String != "" {
+		_ = json.Unmarshal([]byte(providersJSON.String), &client.AllowedProviders)
+	}
+	if accountsJSON.Valid && accountsJSON.String != "" {
+		_ = json.Unmarshal([]byte(accountsJSON.String), &client.AllowedAccounts)
+	}
+	return client, nil
+}
+
 // Subscribe creates a subscription for quota changes on an account
 func (s *SQLiteStore) Subscribe(accountID string) chan models.QuotaEvent {
 	s.subMu.Lock()
@@ -824,10 +1807,10 @@ func (s *SQLiteStore) GetReservation(id string) (*models.Reservation, bool) {
 	var releasedAt sql.NullTime
 
 	err := s.db.QueryRow(`
-		SELECT id, account_id, correlation_id, estimated_cost_pct, actual_cost_pct,
+		SELECT id, account_id, correlation_id, client_id, estimated_cost_pct, actual_cost_pct,
 		       status, created_at, expires_at, released_at
 		FROM reservations WHERE id = ?
-	`, id).Scan(&res.ID, &res.AccountID, &res.CorrelationID, &res.EstimatedCostPct,
+	`, id).Scan(&res.ID, &res.AccountID, &res.CorrelationID, &res.ClientID, &res.EstimatedCostPct,
 		&actualCostPct, &res.Status, &res.CreatedAt, &res.ExpiresAt, &releasedAt)
 
 	if err == sql.ErrNoRows {
@@ -865,18 +1848,19 @@ func (s *SQLiteStore) SetReservation(id string, res *models.Reservation) {
 	}
 
 	_, err := s.db.Exec(`
-		INSERT INTO reservations (id, account_id, correlation_id, estimated_cost_pct, actual_cost_pct,
+		INSERT INTO reservations (id, account_id, correlation_id, client_id, estimated_cost_pct, actual_cost_pct,
 		                         status, created_at, expires_at, released_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			account_id = excluded.account_id,
 			correlation_id = excluded.correlation_id,
+			client_id = excluded.client_id,
 			estimated_cost_pct = excluded.estimated_cost_pct,
 			actual_cost_pct = excluded.actual_cost_pct,
 			status = excluded.status,
 			expires_at = excluded.expires_at,
 			released_at = excluded.released_at
-	`, res.ID, res.AccountID, res.CorrelationID, res.EstimatedCostPct, actualCostPct,
+	`, res.ID, res.AccountID, res.CorrelationID, res.ClientID, res.EstimatedCostPct, actualCostPct,
 		res.Status, res.CreatedAt, res.ExpiresAt, releasedAt)
 
 	if err != nil {
@@ -904,7 +1888,7 @@ func (s *SQLiteStore) ListReservations() []*models.Reservation {
 	defer s.mu.RUnlock()
 
 	rows, err := s.db.Query(`
-		SELECT id, account_id, correlation_id, estimated_cost_pct, actual_cost_pct,
+		SELECT id, account_id, correlation_id, client_id, estimated_cost_pct, actual_cost_pct,
 		       status, created_at, expires_at, released_at
 		FROM reservations ORDER BY created_at DESC
 	`)
@@ -919,7 +1903,7 @@ func (s *SQLiteStore) ListReservations() []*models.Reservation {
 		var actualCostPct sql.NullFloat64
 		var releasedAt sql.NullTime
 
-		if err := rows.Scan(&res.ID, &res.AccountID, &res.CorrelationID, &res.EstimatedCostPct,
+		if err := rows.Scan(&res.ID, &res.AccountID, &res.CorrelationID, &res.ClientID, &res.EstimatedCostPct,
 			&actualCostPct, &res.Status, &res.CreatedAt, &res.ExpiresAt, &releasedAt); err != nil {
 			continue
 		}
@@ -953,6 +1937,16 @@ func (s *SQLiteStore) Clear() {
 	if _, err := s.db.Exec("DELETE FROM accounts"); err != nil {
 		s.logger.Error("failed to clear accounts", "error", err.Error())
 	}
+	if _, err := s.db.Exec("DELETE FROM quota_history"); err != nil {
+		s.logger.Error("failed to clear quota history", "error", err.Error())
+	}
+	if _, err := s.db.Exec("DELETE FROM alert_incidents"); err != nil {
+		s.logger.Error("failed to clear alert incidents", "error", err.Error())
+	}
+	if _, err := s.db.Exec("DELETE FROM alert_mutes"); err != nil {
+		s.logger.Error("failed to clear alert mutes", "error", err.Error())
+	}
+	s.historyAt = make(map[string]time.Time)
 }
 
 // Stats returns statistics about the store
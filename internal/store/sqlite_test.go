@@ -185,6 +185,9 @@ func TestSQLiteStoreReservationOperations(t *testing.T) {
 	if retrieved.ID != "res-1" {
 		t.Errorf("Expected reservation ID res-1, got %s", retrieved.ID)
 	}
+	if retrieved.ClientID != "" {
+		t.Errorf("Expected empty client ID, got %s", retrieved.ClientID)
+	}
 
 	// Test ListReservations
 	reservations := store.ListReservations()
@@ -200,6 +203,271 @@ func TestSQLiteStoreReservationOperations(t *testing.T) {
 	}
 }
 
+// TestSQLiteStoreAPIClientOperations tests API client and usage persistence
+func TestSQLiteStoreAPIClientOperations(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "clients.db")
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create SQLite store: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	client := &models.APIClient{
+		ID:                 "batch",
+		Name:               "Batch jobs",
+		KeyHash:            models.HashAPIKey("qg_secret"),
+		KeyPrefix:          "qg_sec",
+		AllowedProviders:   []models.Provider{models.ProviderOpenAI},
+		AllowedAccounts:    []string{"codex-1", "codex-2"},
+		DefaultPolicy:      "cost",
+		RateLimitRPM:       60,
+		DailyQuotaSharePct: 20,
+		Enabled:            true,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if err := store.SetAPIClient(client); err != nil {
+		t.Fatalf("Failed to set client: %v", err)
+	}
+
+	retrieved, ok := store.GetAPIClientByKeyHash(models.HashAPIKey("qg_secret"))
+	if !ok {
+		t.Fatal("Failed to retrieve client by key hash")
+	}
+	if retrieved.ID != "batch" || retrieved.DefaultPolicy != "cost" || retrieved.RateLimitRPM != 60 {
+		t.Errorf("Unexpected client: %+v", retrieved)
+	}
+	if len(retrieved.AllowedProviders) != 1 || retrieved.AllowedProviders[0] != models.ProviderOpenAI {
+		t.Errorf("Expected allowed providers [openai], got %v", retrieved.AllowedProviders)
+	}
+	if len(retrieved.AllowedAccounts) != 2 {
+		t.Errorf("Expected 2 allowed accounts, got %v", retrieved.AllowedAccounts)
+	}
+
+	retrieved.Enabled = false
+	if err := store.SetAPIClient(retrieved); err != nil {
+		t.Fatalf("Failed to update client: %v", err)
+	}
+	if updated, _ := store.GetAPIClient("batch"); updated.Enabled {
+		t.Error("Expected client to be disabled after update")
+	}
+
+	if err := store.AddClientUsage("batch", "2026-01-02", 1, 4.5); err != nil {
+		t.Fatalf("Failed to add usage: %v", err)
+	}
+	if err := store.AddClientUsage("batch", "2026-01-02", 1, -1.5); err != nil {
+		t.Fatalf("Failed to add usage: %v", err)
+	}
+	usage, ok := store.GetClientUsage("batch", "2026-01-02")
+	if !ok {
+		t.Fatal("Failed to retrieve client usage")
+	}
+	if usage.Requests != 2 || usage.ConsumedPct != 3.0 {
+		t.Errorf("Expected 2 requests and 3%% consumed, got %d and %.2f", usage.Requests, usage.ConsumedPct)
+	}
+
+	if len(store.ListAPIClients()) != 1 {
+		t.Errorf("Expected 1 client")
+	}
+	if !store.DeleteAPIClient("batch") {
+		t.Error("Expected client to be deleted")
+	}
+	if _, ok := store.GetClientUsage("batch", "2026-01-02"); ok {
+		t.Error("Expected usage to be removed with the client")
+	}
+}
+
+func TestSQLiteStoreSpendLedger(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "spend.db")
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create SQLite store: %v", err)
+	}
+	defer store.Close()
+
+	store.SetAccount(&models.Account{ID: "api-1", Provider: models.ProviderOpenAI, Enabled: true, InputCost: 0.01, MonthlyBudget: 50})
+	acc, ok := store.GetAccount("api-1")
+	if !ok {
+		t.Fatal("Failed to retrieve account")
+	}
+	if acc.MonthlyBudget != 50 {
+		t.Errorf("Expected monthly budget 50, got %.2f", acc.MonthlyBudget)
+	}
+
+	entries := []*models.SpendEntry{
+		{AccountID: "api-1", Day: "2026-01-01", Requests: 1, InputTokens: 1000, CostUSD: 0.5},
+		{AccountID: "api-1", Day: "2026-01-01", Requests: 1, OutputTokens: 500, CostUSD: 1.0},
+		{AccountID: "api-1", ClientID: "batch", Day: "2026-01-02", Requests: 1, CostUSD: 2.0},
+		{AccountID: "api-1", Day: "2026-02-01", Requests: 1, CostUSD: 4.0},
+	}
+	for _, entry := range entries {
+		if err := store.AddSpend(entry); err != nil {
+			t.Fatalf("Failed to add spend: %v", err)
+		}
+	}
+
+	listed, err := store.ListSpend("2026-01-01", "2026-01-31")
+	if err != nil {
+		t.Fatalf("Failed to list spend: %v", err)
+	}
+	if len(listed) != 2 {
+		t.Fatalf("Expected 2 ledger rows, got %d", len(listed))
+	}
+	if listed[0].Requests != 2 || listed[0].InputTokens != 1000 || listed[0].OutputTokens != 500 {
+		t.Errorf("Unexpected aggregated row: %+v", listed[0])
+	}
+	if listed[1].ClientID != "batch" {
+		t.Errorf("Expected client row for batch, got %+v", listed[1])
+	}
+
+	spent, err := store.GetAccountSpend("api-1", "2026-01-01", "2026-01-31")
+	if err != nil {
+		t.Fatalf("Failed to get account spend: %v", err)
+	}
+	if spent != 3.5 {
+		t.Errorf("Expected 3.5 spent in January, got %.2f", spent)
+	}
+}
+
+func TestSQLiteStoreQuotaHistory(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "history.db")
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create SQLite store: %v", err)
+	}
+	defer store.Close()
+
+	store.SetAccount(&models.Account{ID: "acc-1", Provider: models.ProviderAnthropic, Enabled: true})
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	resetAt := start.Add(time.Hour)
+	for i, remaining := range []int64{90, 85, 80} {
+		quota := &models.QuotaInfo{
+			AccountID:   "acc-1",
+			Provider:    models.ProviderAnthropic,
+			CollectedAt: start.Add(time.Duration(i) * 3 * time.Minute),
+			Dimensions: models.DimensionSlice{
+				{Type: models.DimensionRPD, Limit: 100, Remaining: remaining, ResetAt: &resetAt},
+				{Type: models.DimensionTPD, Name: "tokens", Limit: 1000, Remaining: remaining * 10},
+			},
+		}
+		if err := store.UpdateQuota("acc-1", quota); err != nil {
+			t.Fatalf("Failed to update quota: %v", err)
+		}
+	}
+
+	samples, err := store.ListQuotaHistory("acc-1", start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to list quota history: %v", err)
+	}
+	// The 3-minute update falls inside the sampling interval
+	if len(samples) != 4 {
+		t.Fatalf("Expected 4 samples, got %d", len(samples))
+	}
+	if samples[0].Dimension != string(models.DimensionRPD) || samples[0].RemainingPct != 90 {
+		t.Errorf("Unexpected first sample: %+v", samples[0])
+	}
+	if samples[0].ResetAt == nil || !samples[0].ResetAt.Equal(resetAt) {
+		t.Errorf("Expected reset time %v, got %v", resetAt, samples[0].ResetAt)
+	}
+	if samples[3].Dimension != "tokens" || samples[3].RemainingPct != 80 || !samples[3].CollectedAt.Equal(start.Add(6*time.Minute)) {
+		t.Errorf("Unexpected last sample: %+v", samples[3])
+	}
+	if samples[3].Provider != models.ProviderAnthropic {
+		t.Errorf("Expected provider anthropic, got %s", samples[3].Provider)
+	}
+
+	samples, err = store.ListQuotaHistory("acc-1", start.Add(time.Minute), start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to list quota history: %v", err)
+	}
+	if len(samples) != 2 {
+		t.Errorf("Expected 2 samples after the first minute, got %d", len(samples))
+	}
+}
+
+func TestSQLiteStoreAccountPollError(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "poll_error.db")
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create SQLite store: %v", err)
+	}
+	defer store.Close()
+
+	store.SetAccount(&models.Account{ID: "gem-1", Provider: models.ProviderGemini, Enabled: true})
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	pollErr := &models.PollError{Class: models.PollErrorUpstream, Message: "gemini status 503", At: at}
+	if err := store.SetAccountPollError("gem-1", pollErr); err != nil {
+		t.Fatalf("Failed to set poll error: %v", err)
+	}
+
+	// Re-saving the account from config must not clear the error
+	store.SetAccount(&models.Account{ID: "gem-1", Provider: models.ProviderGemini, Enabled: true, Priority: 2})
+
+	accounts := store.ListEnabledAccounts()
+	if len(accounts) != 1 || accounts[0].LastPollError == nil {
+		t.Fatalf("Expected poll error on listed account, got %+v", accounts)
+	}
+	got := accounts[0].LastPollError
+	if got.Class != models.PollErrorUpstream || got.Message != "gemini status 503" || !got.At.Equal(at) {
+		t.Errorf("Unexpected poll error: %+v", got)
+	}
+
+	if err := store.SetAccountPollError("gem-1", nil); err != nil {
+		t.Fatalf("Failed to clear poll error: %v", err)
+	}
+	acc, _ := store.GetAccount("gem-1")
+	if acc.LastPollError != nil {
+		t.Errorf("Expected cleared poll error, got %+v", acc.LastPollError)
+	}
+}
+
+func TestSQLiteStoreLogCheckpoint(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "checkpoint.db")
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create SQLite store: %v", err)
+	}
+
+	if _, ok := store.GetLogCheckpoint("/var/log/cliproxy/requests.log"); ok {
+		t.Fatalf("Expected no checkpoint before first save")
+	}
+	cp := &models.LogCheckpoint{Path: "/var/log/cliproxy/requests.log", Fingerprint: "0a1b2c3d", Offset: 4096}
+	if err := store.SetLogCheckpoint(cp); err != nil {
+		t.Fatalf("Failed to set checkpoint: %v", err)
+	}
+	cp.Offset = 8192
+	if err := store.SetLogCheckpoint(cp); err != nil {
+		t.Fatalf("Failed to update checkpoint: %v", err)
+	}
+	store.Close()
+
+	// Checkpoints survive a restart
+	store, err = NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen SQLite store: %v", err)
+	}
+	defer store.Close()
+
+	got, ok := store.GetLogCheckpoint("/var/log/cliproxy/requests.log")
+	if !ok {
+		t.Fatalf("Expected checkpoint after reopen")
+	}
+	if got.Fingerprint != "0a1b2c3d" || got.Offset != 8192 || got.UpdatedAt.IsZero() {
+		t.Errorf("Unexpected checkpoint: %+v", got)
+	}
+}
+
 // TestSQLiteStoreCleanupOldData tests the retention cleanup functionality
 func TestSQLiteStoreCleanupOldData(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -314,3 +582,36 @@ func TestSQLiteStoreMigrations(t *testing.T) {
 		t.Errorf("Expected ID migration-test, got %s", retrieved.ID)
 	}
 }
+
+func TestSQLiteStoreAlertIncidents(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "incidents.db"))
+	if err != nil {
+		t.Fatalf("Failed to create SQLite store: %v", err)
+	}
+	defer store.Close()
+
+	testAlertIncidents(t, store)
+}
+
+func TestSQLiteStoreRoutingEvents(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "routing.db"))
+	if err != nil {
+		t.Fatalf("Failed to create SQLite store: %v", err)
+	}
+	defer store.Close()
+
+	testRoutingEvents(t, store)
+
+	// Routing events are pruned by the cleanup manager's policy only
+	if err := store.RecordRoutingEvent(&models.RoutingEvent{CorrelationID: "old", CreatedAt: time.Now().AddDate(0, 0, -31), AccountID: "acc1"}); err != nil {
+		t.Fatalf("RecordRoutingEvent failed: %v", err)
+	}
+	store.cleanupOldData()
+	kept, err := store.ListRoutingEvents(models.RoutingEventFilter{})
+	if err != nil {
+		t.Fatalf("ListRoutingEvents failed: %v", err)
+	}
+	if len(kept) != 4 {
+		t.Errorf("expected 4 routing events after store cleanup, got %d", len(kept))
+	}
+}
@@ -0,0 +1,458 @@
+package telegram
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/store"
+)
+
+// Role is the access level of a Telegram user.
+type Role string
+
+const (
+	// RoleViewer can open menus and read status, quotas and alerts.
+	RoleViewer Role = "viewer"
+	// RoleOperator can also enable and disable accounts.
+	RoleOperator Role = "operator"
+	// RoleAdmin can also change routing settings, log in accounts and
+	// manage members.
+	RoleAdmin Role = "admin"
+)
+
+var roleRank = map[Role]int{RoleViewer: 1, RoleOperator: 2, RoleAdmin: 3}
+
+// ParseRole parses a role name.
+func ParseRole(s string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := roleRank[role]; !ok {
+		return "", fmt.Errorf("unknown role %q (viewer, operator, admin)", s)
+	}
+	return role, nil
+}
+
+// Allows reports whether r grants the access of required.
+func (r Role) Allows(required Role) bool {
+	return roleRank[r] >= roleRank[required]
+}
+
+// Member is a Telegram user allowed to use the bot.
+type Member struct {
+	UserID int64 `json:"user_id"`
+	Role   Role  `json:"role"`
+	// AlertSeverity is the lowest alert severity sent to the member:
+	// info, warning or critical. Empty means no alerts.
+	AlertSeverity string `json:"alert_severity,omitempty"`
+	// AlertChatID receives the member's alerts. Default: the private chat
+	// with the member.
+	AlertChatID int64     `json:"alert_chat_id,omitempty"`
+	GrantedBy   int64     `json:"granted_by,omitempty"`
+	GrantedAt   time.Time `json:"granted_at"`
+}
+
+// alertChat returns where the member's alerts go
+func (m *Member) alertChat() int64 {
+	if m.AlertChatID != 0 {
+		return m.AlertChatID
+	}
+	return m.UserID
+}
+
+var severityRank = map[string]int{"info": 1, "warning": 2, "critical": 3}
+
+// parseAlertSeverity accepts a severity name or "off"
+func parseAlertSeverity(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "off" || s == "none" {
+		return "", nil
+	}
+	if _, ok := severityRank[s]; !ok {
+		return "", fmt.Errorf("unknown severity %q (info, warning, critical, off)", s)
+	}
+	return s, nil
+}
+
+// severityAtLeast reports whether severity reaches min. Unknown
+// severities count as warnings.
+func severityAtLeast(severity, min string) bool {
+	rank, ok := severityRank[strings.ToLower(severity)]
+	if !ok {
+		rank = severityRank["warning"]
+	}
+	return rank >= severityRank[min]
+}
+
+// commandRoles lists the role each command and callback needs. Callbacks
+// match by prefix; commands not listed need RoleViewer.
+var commandRoles = []struct {
+	prefix string
+	role   Role
+}{
+	{"/whoami", ""},
+	{actionAcctEnable, RoleOperator},
+	{actionAcctDisable, RoleOperator},
+	{actionAck, RoleOperator},
+	{actionSnooze, RoleOperator},
+	{actionIncMute, RoleOperator},
+	{actionThresholds, RoleAdmin},
+	{actionPolicy, RoleAdmin},
+	{actionIgnoreEst, RoleAdmin},
+	{actionCheckInt, RoleAdmin},
+	{actionCheckTO, RoleAdmin},
+	{actionLogin, RoleAdmin},
+	{actionReload, RoleAdmin},
+	{actionImport, RoleAdmin},
+	{menuConnect, RoleAdmin},
+	{"/grant", RoleAdmin},
+	{"/revoke", RoleAdmin},
+	{"/members", RoleAdmin},
+	{"/settoken", RoleAdmin},
+	{"/qg_fallback", RoleAdmin},
+	{"/qg_thresholds", RoleAdmin},
+	{"/qg_policy", RoleAdmin},
+	{"/qg_codex_token", RoleAdmin},
+	{"/qg_import", RoleAdmin},
+	{"/qg_export", RoleAdmin},
+	{"/qg_reload", RoleAdmin},
+}
+
+// requiredRole returns the role needed to run command. An empty role means
+// anyone may run it.
+func requiredRole(command string) Role {
+	for _, entry := range commandRoles {
+		if command == entry.prefix || strings.HasPrefix(command, entry.prefix+":") {
+			return entry.role
+		}
+	}
+	return RoleViewer
+}
+
+// isTypedCommand reports whether text is one of the few typed commands:
+// access management and charts. The rest of the bot is driven by buttons.
+func isTypedCommand(text string) bool {
+	command := strings.ToLower(strings.Fields(text)[0])
+	if idx := strings.Index(command, "@"); idx != -1 {
+		command = command[:idx]
+	}
+	switch command {
+	case "/whoami", "/members", "/grant", "/revoke", "/notify", "/chart":
+		return true
+	}
+	return false
+}
+
+// loadMembers reads the member list from settings
+func (b *Bot) loadMembers() {
+	if b.settings == nil {
+		return
+	}
+	raw, ok := b.settings.Get(store.SettingTelegramMembers)
+	if !ok || raw == "" {
+		return
+	}
+	var members []*Member
+	if err := json.Unmarshal([]byte(raw), &members); err != nil {
+		return
+	}
+	for _, m := range members {
+		b.members[m.UserID] = m
+	}
+}
+
+// saveMembersLocked writes the member list to settings; membersMu must be held
+func (b *Bot) saveMembersLocked() error {
+	if b.settings == nil {
+		return nil
+	}
+	data, err := json.Marshal(b.sortedMembersLocked())
+	if err != nil {
+		return err
+	}
+	return b.settings.Set(store.SettingTelegramMembers, string(data))
+}
+
+func (b *Bot) sortedMembersLocked() []*Member {
+	members := make([]*Member, 0, len(b.members))
+	for _, m := range b.members {
+		copied := *m
+		members = append(members, &copied)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+	return members
+}
+
+// Members returns the allowlisted users ordered by ID.
+func (b *Bot) Members() []*Member {
+	b.membersMu.RLock()
+	defer b.membersMu.RUnlock()
+	return b.sortedMembersLocked()
+}
+
+// RoleOf returns the role of a user writing in chatID. Users listed in
+// BotOptions.Admins are always admins. Until admins are configured or the
+// first member is granted, everyone in the configured chat is an admin, or
+// everyone at all when no chat is configured yet.
+func (b *Bot) RoleOf(userID, chatID int64) (Role, bool) {
+	for _, id := range b.admins {
+		if id == userID {
+			return RoleAdmin, true
+		}
+	}
+
+	b.membersMu.RLock()
+	defer b.membersMu.RUnlock()
+	if m, ok := b.members[userID]; ok {
+		return m.Role, true
+	}
+	if len(b.admins) == 0 && len(b.members) == 0 && (b.chatID == 0 || chatID == b.chatID) {
+		return RoleAdmin, true
+	}
+	return "", false
+}
+
+// GrantRole adds a member or changes its role. alertSeverity is kept for an
+// existing member when nil. The first grant also makes grantedBy an admin
+// member, since granting ends the open access it had until then.
+func (b *Bot) GrantRole(userID int64, role Role, alertSeverity *string, grantedBy int64) error {
+	b.membersMu.Lock()
+	defer b.membersMu.Unlock()
+
+	prev, exists := b.members[userID]
+	if exists && prev.Role == RoleAdmin && role != RoleAdmin && b.lastAdminLocked(userID) {
+		return fmt.Errorf("cannot demote the last admin")
+	}
+
+	now := time.Now().UTC()
+	updated := make(map[int64]*Member, len(b.members)+2)
+	for id, m := range b.members {
+		updated[id] = m
+	}
+	if len(b.members) == 0 && len(b.admins) == 0 {
+		if grantedBy == 0 || (grantedBy == userID && role != RoleAdmin) {
+			return fmt.Errorf("the first member must leave an admin")
+		}
+		updated[grantedBy] = &Member{UserID: grantedBy, Role: RoleAdmin, GrantedBy: grantedBy, GrantedAt: now}
+	}
+
+	member := &Member{UserID: userID, Role: role, GrantedBy: grantedBy, GrantedAt: now}
+	if exists {
+		member.AlertSeverity = prev.AlertSeverity
+		member.AlertChatID = prev.AlertChatID
+	}
+	if alertSeverity != nil {
+		member.AlertSeverity = *alertSeverity
+	}
+	updated[userID] = member
+
+	previous := b.members
+	b.members = updated
+	if err := b.saveMembersLocked(); err != nil {
+		b.members = previous
+		return fmt.Errorf("store members: %w", err)
+	}
+	return nil
+}
+
+// RevokeRole removes a member.
+func (b *Bot) RevokeRole(userID int64) error {
+	b.membersMu.Lock()
+	defer b.membersMu.Unlock()
+
+	prev, ok := b.members[userID]
+	if !ok {
+		return fmt.Errorf("user %d is not a member", userID)
+	}
+	if prev.Role == RoleAdmin && b.lastAdminLocked(userID) {
+		return fmt.Errorf("cannot revoke the last admin")
+	}
+	delete(b.members, userID)
+	if err := b.saveMembersLocked(); err != nil {
+		b.members[userID] = prev
+		return fmt.Errorf("store members: %w", err)
+	}
+	return nil
+}
+
+// SetAlertSeverity changes which alerts a member receives.
+func (b *Bot) SetAlertSeverity(userID int64, severity string) error {
+	b.membersMu.Lock()
+	defer b.membersMu.Unlock()
+
+	m, ok := b.members[userID]
+	if !ok {
+		return fmt.Errorf("user %d is not a member", userID)
+	}
+	prev := m.AlertSeverity
+	m.AlertSeverity = severity
+	if err := b.saveMembersLocked(); err != nil {
+		m.AlertSeverity = prev
+		return fmt.Errorf("store members: %w", err)
+	}
+	return nil
+}
+
+// lastAdminLocked reports whether userID is the only admin able to manage
+// members; config admins always can
+func (b *Bot) lastAdminLocked(userID int64) bool {
+	if len(b.admins) > 0 {
+		return false
+	}
+	for id, m := range b.members {
+		if id != userID && m.Role == RoleAdmin {
+			return false
+		}
+	}
+	return true
+}
+
+// alertRecipients returns the chats an alert of severity goes to: the
+// configured chat and every member subscribed at or below severity
+func (b *Bot) alertRecipients(severity string) []int64 {
+	seen := make(map[int64]bool)
+	var chats []int64
+	add := func(chatID int64) {
+		if chatID != 0 && !seen[chatID] {
+			seen[chatID] = true
+			chats = append(chats, chatID)
+		}
+	}
+
+	add(b.chatID)
+	for _, m := range b.Members() {
+		if m.AlertSeverity != "" && severityAtLeast(severity, m.AlertSeverity) {
+			add(m.alertChat())
+		}
+	}
+	return chats
+}
+
+// authorize checks that userID may run command and tells the user why not
+func (b *Bot) authorize(chatID, userID int64, command string) bool {
+	required := requiredRole(command)
+	if required == "" {
+		return true
+	}
+	role, ok := b.RoleOf(userID, chatID)
+	if !ok {
+		b.sendMessageWithParseMode(chatID, fmt.Sprintf(
+			"⛔ Нет доступа.\n\nВаш Telegram ID: <code>%d</code> — передайте его администратору.", userID), "HTML")
+		return false
+	}
+	if !role.Allows(required) {
+		b.sendMessageWithParseMode(chatID, fmt.Sprintf(
+			"⛔ Недостаточно прав: нужна роль <b>%s</b>, у вас <b>%s</b>.", required, role), "HTML")
+		return false
+	}
+	return true
+}
+
+// handleWhoAmI tells users their Telegram ID so an admin can grant them access
+func (b *Bot) handleWhoAmI(chatID, userID int64) {
+	msg := fmt.Sprintf("🪪 Ваш Telegram ID: <code>%d</code>\n", userID)
+	if role, ok := b.RoleOf(userID, chatID); ok {
+		msg += fmt.Sprintf("Роль: <b>%s</b>", role)
+	} else {
+		msg += "Доступа нет — передайте ID администратору."
+	}
+	b.sendMessageWithParseMode(chatID, msg, "HTML")
+}
+
+// handleMembers lists the users with access
+func (b *Bot) handleMembers(chatID int64) {
+	var sb strings.Builder
+	sb.WriteString("👥 <b>Доступ к боту</b>\n\n")
+	for _, id := range b.admins {
+		sb.WriteString(fmt.Sprintf("• <code>%d</code> — admin (config)\n", id))
+	}
+	members := b.Members()
+	for _, m := range members {
+		alerts := "без алёртов"
+		if m.AlertSeverity != "" {
+			alerts = "алёрты от " + m.AlertSeverity
+		}
+		sb.WriteString(fmt.Sprintf("• <code>%d</code> — %s, %s\n", m.UserID, m.Role, alerts))
+	}
+	if len(b.admins) == 0 && len(members) == 0 {
+		sb.WriteString("Список пуст: управлять ботом может любой в основном чате.\n")
+	}
+	sb.WriteString("\n/grant &lt;id&gt; &lt;viewer|operator|admin&gt; [info|warning|critical|off]\n/revoke &lt;id&gt;")
+	b.sendMessageWithParseMode(chatID, sb.String(), "HTML")
+}
+
+// handleGrant handles /grant <user_id> <role> [alert severity]
+func (b *Bot) handleGrant(chatID, grantedBy int64, args []string) {
+	if len(args) < 2 || len(args) > 3 {
+		b.sendMessage(chatID, "Usage: /grant <user_id> <viewer|operator|admin> [info|warning|critical|off]")
+		return
+	}
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || userID == 0 {
+		b.sendErrorMessage(chatID, fmt.Sprintf("Invalid user ID: %s", args[0]))
+		return
+	}
+	role, err := ParseRole(args[1])
+	if err != nil {
+		b.sendErrorMessage(chatID, err.Error())
+		return
+	}
+	var severity *string
+	if len(args) == 3 {
+		parsed, err := parseAlertSeverity(args[2])
+		if err != nil {
+			b.sendErrorMessage(chatID, err.Error())
+			return
+		}
+		severity = &parsed
+	}
+
+	if err := b.GrantRole(userID, role, severity, grantedBy); err != nil {
+		b.sendErrorMessage(chatID, fmt.Sprintf("Failed to grant role: %v", err))
+		return
+	}
+	b.sendMessageWithParseMode(chatID, fmt.Sprintf("✅ <code>%d</code> — <b>%s</b>", userID, role), "HTML")
+}
+
+// handleRevoke handles /revoke <user_id>
+func (b *Bot) handleRevoke(chatID int64, args []string) {
+	if len(args) != 1 {
+		b.sendMessage(chatID, "Usage: /revoke <user_id>")
+		return
+	}
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		b.sendErrorMessage(chatID, fmt.Sprintf("Invalid user ID: %s", args[0]))
+		return
+	}
+	if err := b.RevokeRole(userID); err != nil {
+		b.sendErrorMessage(chatID, fmt.Sprintf("Failed to revoke access: %v", err))
+		return
+	}
+	b.sendMessageWithParseMode(chatID, fmt.Sprintf("✅ Доступ <code>%d</code> отозван", userID), "HTML")
+}
+
+// handleNotify lets members choose which alerts reach them, e.g. when
+// taking over on-call
+func (b *Bot) handleNotify(chatID, userID int64, args []string) {
+	if len(args) != 1 {
+		b.sendMessage(chatID, "Usage: /notify <info|warning|critical|off>")
+		return
+	}
+	severity, err := parseAlertSeverity(args[0])
+	if err != nil {
+		b.sendErrorMessage(chatID, err.Error())
+		return
+	}
+	if err := b.SetAlertSeverity(userID, severity); err != nil {
+		b.sendErrorMessage(chatID, fmt.Sprintf("Failed to update alerts: %v", err))
+		return
+	}
+	if severity == "" {
+		b.sendMessage(chatID, "🔕 Алёрты отключены")
+		return
+	}
+	b.sendMessage(chatID, "🔔 Алёрты от уровня "+severity+" будут приходить в личный чат с ботом")
+}
@@ -0,0 +1,196 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccessTestBot(t *testing.T, settings store.SettingsStore, admins ...int64) (*Bot, *mockBotAPI) {
+	t.Helper()
+	api := &mockBotAPI{}
+	bot := NewBot("token", -100, true, &BotOptions{
+		BotAPI:      api,
+		RateLimiter: NewRateLimiter(1000),
+		Settings:    settings,
+		Admins:      admins,
+	})
+	return bot, api
+}
+
+func lastText(api *mockBotAPI) string {
+	messages := api.GetMessages()
+	if len(messages) == 0 {
+		return ""
+	}
+	return messages[len(messages)-1].text
+}
+
+func TestRequiredRole(t *testing.T) {
+	assert.Equal(t, RoleViewer, requiredRole(menuQuota))
+	assert.Equal(t, RoleOperator, requiredRole(actionAcctDisable+":a1:1h"))
+	assert.Equal(t, RoleAdmin, requiredRole(actionThresholds+":80,88,94"))
+	assert.Equal(t, RoleAdmin, requiredRole(menuConnect))
+	assert.Equal(t, RoleAdmin, requiredRole("/grant"))
+	assert.Equal(t, Role(""), requiredRole("/whoami"))
+}
+
+func TestBot_RolesAndPermissions(t *testing.T) {
+	settings := store.NewMemorySettingsStore()
+	bot, api := newAccessTestBot(t, settings)
+
+	var thresholds []float64
+	bot.SetThresholdsCallback(func(w, s, c float64) error {
+		thresholds = append(thresholds, w)
+		return nil
+	})
+	var toggled []string
+	bot.SetToggleAccountCallback(func(id string, _ time.Duration, _ bool) error {
+		toggled = append(toggled, id)
+		return nil
+	})
+	key := bot.rememberAccountKey("acc-1", 0)
+
+	// Before any grant the configured chat has full access
+	role, ok := bot.RoleOf(7, -100)
+	require.True(t, ok)
+	assert.Equal(t, RoleAdmin, role)
+	_, ok = bot.RoleOf(7, 555)
+	assert.False(t, ok)
+
+	bot.handleMessage(Message{ChatID: -100, UserID: 7, Text: "/grant 8 operator critical"})
+	assert.Contains(t, lastText(api), "operator")
+
+	// The granting user stays admin; the group chat no longer grants access
+	members := bot.Members()
+	require.Len(t, members, 2)
+	assert.Equal(t, RoleAdmin, members[0].Role)
+	assert.Equal(t, RoleOperator, members[1].Role)
+	assert.Equal(t, "critical", members[1].AlertSeverity)
+	_, ok = bot.RoleOf(9, -100)
+	assert.False(t, ok)
+
+	// Members survive a restart
+	restarted, _ := newAccessTestBot(t, settings)
+	assert.Len(t, restarted.Members(), 2)
+
+	bot.handleMessage(Message{ChatID: -100, UserID: 8, Text: actionAcctDisable + ":" + key + ":1h"})
+	assert.Equal(t, []string{"acc-1"}, toggled)
+
+	bot.handleMessage(Message{ChatID: -100, UserID: 8, Text: actionThresholds + ":80,88,94"})
+	assert.Empty(t, thresholds)
+	assert.Contains(t, lastText(api), "нужна роль <b>admin</b>")
+
+	bot.handleMessage(Message{ChatID: -100, UserID: 9, Text: menuQuota})
+	assert.Contains(t, lastText(api), "<code>9</code>")
+
+	bot.handleMessage(Message{ChatID: 9, UserID: 9, Text: "/whoami"})
+	assert.Contains(t, lastText(api), "Доступа нет")
+
+	bot.handleMessage(Message{ChatID: -100, UserID: 7, Text: actionThresholds + ":80,88,94"})
+	assert.Equal(t, []float64{80}, thresholds)
+
+	// The last admin cannot lock everyone out
+	bot.handleMessage(Message{ChatID: -100, UserID: 7, Text: "/revoke 7"})
+	assert.Contains(t, lastText(api), "last admin")
+	bot.handleMessage(Message{ChatID: -100, UserID: 7, Text: "/revoke 8"})
+	assert.Len(t, bot.Members(), 1)
+}
+
+func TestBot_ConfigAdmins(t *testing.T) {
+	bot, _ := newAccessTestBot(t, store.NewMemorySettingsStore(), 42)
+
+	role, ok := bot.RoleOf(42, 999)
+	require.True(t, ok)
+	assert.Equal(t, RoleAdmin, role)
+	_, ok = bot.RoleOf(1, -100)
+	assert.False(t, ok, "config admins replace open access")
+
+	require.NoError(t, bot.GrantRole(5, RoleViewer, nil, 42))
+	assert.Len(t, bot.Members(), 1)
+	require.NoError(t, bot.RevokeRole(5))
+}
+
+func TestBot_AlertFanOut(t *testing.T) {
+	bot, api := newAccessTestBot(t, store.NewMemorySettingsStore(), 1)
+	warning, critical := "warning", "critical"
+	require.NoError(t, bot.GrantRole(10, RoleViewer, &warning, 1))
+	require.NoError(t, bot.GrantRole(11, RoleOperator, &critical, 1))
+	require.NoError(t, bot.GrantRole(12, RoleViewer, nil, 1))
+
+	assert.Equal(t, []int64{-100, 10}, bot.alertRecipients("warning"))
+	assert.Equal(t, []int64{-100, 10, 11}, bot.alertRecipients("critical"))
+	assert.Equal(t, []int64{-100}, bot.alertRecipients("info"))
+
+	bot.handleMessage(Message{ChatID: 12, UserID: 12, Text: "/notify info"})
+	assert.Equal(t, []int64{-100, 12}, bot.alertRecipients("info"))
+
+	bot.handleAlert(Alert{ID: "a1", Severity: "critical", Message: "quota low"})
+	chats := map[int64]bool{}
+	for _, msg := range api.GetMessages() {
+		if msg.text != "" && msg.chatID != 12 {
+			chats[msg.chatID] = true
+		}
+	}
+	assert.True(t, chats[-100] && chats[10] && chats[11])
+}
+
+func TestBot_IncidentButtons(t *testing.T) {
+	api := &mockKeyboardAPI{}
+	bot := NewBot("token", -100, true, &BotOptions{
+		BotAPI:      api,
+		RateLimiter: NewRateLimiter(1000),
+		Settings:    store.NewMemorySettingsStore(),
+		Admins:      []int64{1},
+	})
+	require.NoError(t, bot.GrantRole(10, RoleViewer, nil, 1))
+	require.NoError(t, bot.GrantRole(11, RoleOperator, nil, 1))
+
+	var acked []int64
+	var snoozed time.Duration
+	var muted string
+	bot.SetIncidentCallbacks(
+		func(id string, userID int64) error {
+			assert.Equal(t, "inc-abc", id)
+			acked = append(acked, userID)
+			return nil
+		},
+		func(_ string, d time.Duration) error {
+			snoozed = d
+			return nil
+		},
+		func(_ string, scope string, d time.Duration) error {
+			muted = scope + "/" + d.String()
+			return nil
+		},
+	)
+
+	bot.handleAlert(Alert{ID: "a1", Severity: "critical", Message: "quota low", IncidentID: "inc-abc"})
+	require.Len(t, api.keyboards, 1)
+	assert.Equal(t, actionAck+":inc-abc", api.keyboards[0].Rows[0][0].CallbackData)
+	assert.Equal(t, actionIncMute+":inc-abc:type:4h", api.keyboards[0].Rows[1][1].CallbackData)
+
+	// Alerts without an incident have no buttons
+	bot.handleAlert(Alert{ID: "a2", Severity: "critical", Message: "quota low"})
+	assert.Len(t, api.keyboards, 1)
+
+	// Viewers cannot acknowledge
+	bot.handleMessage(Message{ChatID: 10, UserID: 10, Text: actionAck + ":inc-abc"})
+	assert.Empty(t, acked)
+	assert.Contains(t, api.GetMessages()[len(api.GetMessages())-1].text, "Недостаточно прав")
+
+	bot.handleMessage(Message{ChatID: 11, UserID: 11, Text: actionAck + ":inc-abc"})
+	assert.Equal(t, []int64{11}, acked)
+	bot.handleMessage(Message{ChatID: 11, UserID: 11, Text: actionSnooze + ":inc-abc:1h"})
+	assert.Equal(t, time.Hour, snoozed)
+	bot.handleMessage(Message{ChatID: 11, UserID: 11, Text: actionIncMute + ":inc-abc:account:4h"})
+	assert.Equal(t, "account/4h0m0s", muted)
+	assert.Contains(t, api.GetMessages()[len(api.GetMessages())-1].text, "заглушены на 4")
+
+	// Escalations go to their own chat as well
+	require.NoError(t, bot.SendAlertToChat(-200, Alert{ID: "a3", Severity: "critical", Message: "still low", IncidentID: "inc-abc"}))
+	assert.Equal(t, int64(-200), api.GetMessages()[len(api.GetMessages())-1].chatID)
+}
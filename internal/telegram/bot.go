@@ -11,8 +11,10 @@ import (
 
 // Message represents a message sent by the bot
 type Message struct {
-	ID        int64
-	ChatID    int64
+	ID     int64
+	ChatID int64
+	// UserID is the sender. Zero means the sender is the chat itself.
+	UserID    int64
 	Text      string
 	Timestamp time.Time
 }
@@ -28,6 +30,11 @@ type ParseModeSender interface {
 	SendMessageWithParseMode(chatID int64, text string, parseMode string) error
 }
 
+// PhotoSender allows sending PNG images with a caption and an inline keyboard.
+type PhotoSender interface {
+	SendPhoto(chatID int64, name string, data []byte, caption string, keyboard InlineKeyboard) error
+}
+
 // State represents the FSM state for user conversations
 type State string
 
@@ -138,6 +145,8 @@ type Alert struct {
 	Message   string
 	AccountID string
 	Timestamp time.Time
+	// IncidentID adds Ack, Snooze and Mute buttons to the alert
+	IncidentID string
 }
 
 // BotOptions contains optional configuration for the bot
@@ -146,6 +155,9 @@ type BotOptions struct {
 	DedupLimiter *DedupLimiter
 	BotAPI       BotAPI
 	Settings     store.SettingsStore
+	// Admins are Telegram user IDs that are always admins, regardless of
+	// the members granted through the bot.
+	Admins []int64
 }
 
 // Bot represents the Telegram bot for QuotaGuard
@@ -192,9 +204,21 @@ type Bot struct {
 	onSetAccountCheckConfig func(interval, timeout time.Duration) error
 	onBuildLoginURL         func(provider string, chatID int64) (*LoginURLPayload, error)
 	onCompleteOAuthLogin    func(provider, state, code string, chatID int64) (*LoginResult, error)
+	onRenderChart           func(target string, period time.Duration) (*ChartImage, error)
+	onAckIncident           func(incidentID string, userID int64) error
+	onSnoozeIncident        func(incidentID string, duration time.Duration) error
+	onMuteIncident          func(incidentID, scope string, duration time.Duration) error
 
 	accountKeyMu sync.RWMutex
 	accountKeys  map[string]string
+
+	// callbackPrefix namespaces the callback data of sent keyboards when
+	// the bot is embedded through BotIntegrator. Empty in standalone mode.
+	callbackPrefix string
+
+	admins    []int64
+	membersMu sync.RWMutex
+	members   map[int64]*Member
 }
 
 // SystemStatus represents the system status
@@ -270,6 +294,12 @@ type LoginResult struct {
 	Provider  string
 }
 
+// ChartImage is a rendered quota history chart.
+type ChartImage struct {
+	PNG     []byte
+	Caption string
+}
+
 // ActiveAlert represents an active alert
 type ActiveAlert struct {
 	ID       string
@@ -301,6 +331,7 @@ func NewBot(botToken string, chatID int64, enabled bool, opts *BotOptions) *Bot
 		msgChan:     make(chan Message, 100),
 		alertChan:   make(chan Alert, 100),
 		accountKeys: make(map[string]string),
+		members:     make(map[int64]*Member),
 	}
 
 	if opts != nil {
@@ -316,6 +347,7 @@ func NewBot(botToken string, chatID int64, enabled bool, opts *BotOptions) *Bot
 		if opts.Settings != nil {
 			b.settings = opts.Settings
 		}
+		b.admins = opts.Admins
 	}
 
 	// Set default rate limiter if not provided
@@ -343,6 +375,7 @@ func NewBot(botToken string, chatID int64, enabled bool, opts *BotOptions) *Bot
 				}
 			}
 		}
+		b.loadMembers()
 	}
 
 	return b
@@ -408,6 +441,24 @@ func (b *Bot) SetRouterConfigCallback(cb func() (*RouterConfig, error)) {
 	b.onGetRouterConfig = cb
 }
 
+// SetChartCallback sets the callback for rendering quota history charts.
+// target is an account ID, a provider or empty for all accounts.
+func (b *Bot) SetChartCallback(cb func(target string, period time.Duration) (*ChartImage, error)) {
+	b.onRenderChart = cb
+}
+
+// SetIncidentCallbacks sets the callbacks behind the Ack, Snooze and Mute
+// buttons of incident alerts. scope is "account" or "type".
+func (b *Bot) SetIncidentCallbacks(
+	ack func(incidentID string, userID int64) error,
+	snooze func(incidentID string, duration time.Duration) error,
+	mute func(incidentID, scope string, duration time.Duration) error,
+) {
+	b.onAckIncident = ack
+	b.onSnoozeIncident = snooze
+	b.onMuteIncident = mute
+}
+
 // SetReloadCallback sets the callback for reloading configuration
 func (b *Bot) SetReloadCallback(cb func() error) {
 	b.onReloadConfig = cb
@@ -653,7 +704,7 @@ func (b *Bot) sendMessageWithKeyboard(chatID int64, text, parseMode string, keyb
 		return
 	}
 	if sender, ok := b.api.(InlineKeyboardSender); ok {
-		_ = sender.SendMessageWithInlineKeyboard(chatID, text, parseMode, keyboard)
+		_ = sender.SendMessageWithInlineKeyboard(chatID, text, parseMode, keyboard.withCallbackPrefix(b.callbackPrefix))
 		return
 	}
 	if sender, ok := b.api.(ParseModeSender); ok {
@@ -663,6 +714,26 @@ func (b *Bot) sendMessageWithKeyboard(chatID int64, text, parseMode string, keyb
 	_ = b.api.SendMessage(chatID, text)
 }
 
+func (b *Bot) sendPhoto(chatID int64, name string, data []byte, caption string, keyboard InlineKeyboard) {
+	if !b.enabled {
+		return
+	}
+	if !b.rateLimiter.Allow() {
+		return
+	}
+	if b.api == nil {
+		return
+	}
+	sender, ok := b.api.(PhotoSender)
+	if !ok {
+		_ = b.api.SendMessage(chatID, "❌ Charts are not supported by this bot client")
+		return
+	}
+	if err := sender.SendPhoto(chatID, name, data, caption, keyboard.withCallbackPrefix(b.callbackPrefix)); err != nil {
+		_ = b.api.SendMessage(chatID, fmt.Sprintf("❌ Failed to send chart: %v", err))
+	}
+}
+
 // SendAlert sends an alert with deduplication
 func (b *Bot) SendAlert(alert Alert) error {
 	if !b.enabled {
@@ -683,6 +754,19 @@ func (b *Bot) SendAlert(alert Alert) error {
 	}
 }
 
+// SendAlertToChat sends an alert to a chat besides the usual recipients,
+// such as an escalation channel
+func (b *Bot) SendAlertToChat(chatID int64, alert Alert) error {
+	if !b.enabled {
+		return nil
+	}
+	if chatID == 0 {
+		return fmt.Errorf("chat ID is required")
+	}
+	b.sendAlertMessage(chatID, alert)
+	return nil
+}
+
 // GetSession gets or creates a user session
 func (b *Bot) GetSession(userID int64) *UserSession {
 	b.sessionsMu.Lock()
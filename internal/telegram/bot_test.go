@@ -1095,3 +1095,76 @@ func BenchmarkDedupLimiter(b *testing.B) {
 		dl.CanSend(fmt.Sprintf("msg%d", i))
 	}
 }
+
+// mockPhotoAPI records photos in addition to messages
+type mockPhotoAPI struct {
+	mockBotAPI
+	photos []mockPhoto
+}
+
+type mockPhoto struct {
+	chatID   int64
+	data     []byte
+	caption  string
+	keyboard InlineKeyboard
+}
+
+func (m *mockPhotoAPI) SendPhoto(chatID int64, _ string, data []byte, caption string, keyboard InlineKeyboard) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.photos = append(m.photos, mockPhoto{chatID: chatID, data: data, caption: caption, keyboard: keyboard})
+	return nil
+}
+
+func TestBot_Chart(t *testing.T) {
+	api := &mockPhotoAPI{}
+	bot := NewBot("token", 12345, true, &BotOptions{BotAPI: api, RateLimiter: NewRateLimiter(1000)})
+
+	type chartCall struct {
+		target string
+		period time.Duration
+	}
+	var calls []chartCall
+	bot.SetChartCallback(func(target string, period time.Duration) (*ChartImage, error) {
+		if target == "missing" {
+			return nil, errors.New("unknown account")
+		}
+		calls = append(calls, chartCall{target, period})
+		return &ChartImage{PNG: []byte("png"), Caption: "chart " + target}, nil
+	})
+
+	bot.handleMessage(Message{ChatID: 12345, UserID: 1, Text: "/chart"})
+	bot.handleMessage(Message{ChatID: 12345, UserID: 1, Text: "/chart acc-1 7d"})
+	require.Equal(t, []chartCall{{"", 24 * time.Hour}, {"acc-1", 7 * 24 * time.Hour}}, calls)
+	require.Len(t, api.photos, 2)
+	assert.Equal(t, "chart acc-1", api.photos[1].caption)
+
+	// The period buttons under a chart keep its target
+	periodButton := api.photos[1].keyboard.Rows[0][0].CallbackData
+	bot.handleMessage(Message{ChatID: 12345, UserID: 1, Text: periodButton})
+	require.Len(t, calls, 3)
+	assert.Equal(t, chartCall{"acc-1", 24 * time.Hour}, calls[2])
+
+	// The account menu links every account to its chart
+	keyboard := accountsMenuKeyboard([]AccountControl{{AccountID: "acc-2", Enabled: true}}, []string{bot.rememberAccountKey("acc-2", 0)})
+	bot.handleMessage(Message{ChatID: 12345, UserID: 1, Text: keyboard.Rows[0][1].CallbackData})
+	require.Len(t, calls, 4)
+	assert.Equal(t, chartCall{"acc-2", 24 * time.Hour}, calls[3])
+
+	bot.handleMessage(Message{ChatID: 12345, UserID: 1, Text: "/chart 30d"})
+	assert.Contains(t, lastText(&api.mockBotAPI), "between 1h and 7d")
+	bot.handleMessage(Message{ChatID: 12345, UserID: 1, Text: "/chart missing"})
+	assert.Contains(t, lastText(&api.mockBotAPI), "unknown account")
+	bot.handleMessage(Message{ChatID: 12345, UserID: 1, Text: actionChart + ":stale:24h"})
+	assert.Contains(t, lastText(&api.mockBotAPI), "expired")
+	assert.Len(t, calls, 4)
+
+	// Clients that cannot send photos get a message instead
+	plain := &mockBotAPI{}
+	bot = NewBot("token", 12345, true, &BotOptions{BotAPI: plain, RateLimiter: NewRateLimiter(1000)})
+	bot.SetChartCallback(func(string, time.Duration) (*ChartImage, error) {
+		return &ChartImage{PNG: []byte("png")}, nil
+	})
+	bot.handleMessage(Message{ChatID: 12345, UserID: 1, Text: "/chart"})
+	assert.Contains(t, lastText(plain), "not supported")
+}
@@ -18,15 +18,22 @@ func (b *Bot) handleMessage(msg Message) {
 		return
 	}
 
+	userID := msg.UserID
+	if userID == 0 {
+		userID = msg.ChatID
+	}
+
 	if session := b.GetSession(msg.ChatID); session != nil && session.State == StateWaitingOAuth {
-		b.handleLoginInput(msg.ChatID, text, session)
+		if b.authorize(msg.ChatID, userID, actionLogin) {
+			b.handleLoginInput(msg.ChatID, text, session)
+		}
 		return
 	}
 
 	// Inline callbacks should always be handled as commands.
 	if strings.HasPrefix(text, "menu:") || strings.HasPrefix(text, "action:") {
 		b.SetSessionState(msg.ChatID, StateIdle, nil)
-		b.handleCommand(msg.ChatID, text)
+		b.handleCommand(msg.ChatID, userID, text)
 		return
 	}
 
@@ -36,26 +43,36 @@ func (b *Bot) handleMessage(msg Message) {
 		return
 	}
 
-	// Button-first UX: always guide to menu.
+	// Button-first UX: anything but a typed command opens the menu.
 	b.SetSessionState(msg.ChatID, StateIdle, nil)
-	b.handleMenu(msg.ChatID)
+	if !isTypedCommand(text) {
+		text = "/menu"
+	}
+	b.handleCommand(msg.ChatID, userID, text)
 }
 
-// handleCommand handles commands in idle state
-func (b *Bot) handleCommand(chatID int64, text string) {
+// handleCommand checks the sender's role and handles commands in idle state
+func (b *Bot) handleCommand(chatID, userID int64, text string) {
 	parts := strings.Fields(text)
 	if len(parts) == 0 {
 		return
 	}
 
 	command := strings.ToLower(parts[0])
+	// Strip bot username suffix (e.g., /grant@botname)
+	if idx := strings.Index(command, "@"); idx != -1 {
+		command = command[:idx]
+	}
+	if !b.authorize(chatID, userID, command) {
+		return
+	}
 
 	if strings.HasPrefix(command, "menu:") {
-		b.handleMenuAction(chatID, command)
+		b.handleMenuAction(chatID, userID, command)
 		return
 	}
 	if strings.HasPrefix(command, "action:") {
-		b.handleMenuAction(chatID, command)
+		b.handleMenuAction(chatID, userID, command)
 		return
 	}
 
@@ -64,6 +81,18 @@ func (b *Bot) handleCommand(chatID int64, text string) {
 		b.handleStart(chatID)
 	case "/help", "/menu":
 		b.handleMenu(chatID)
+	case "/whoami":
+		b.handleWhoAmI(chatID, userID)
+	case "/members":
+		b.handleMembers(chatID)
+	case "/grant":
+		b.handleGrant(chatID, userID, parts[1:])
+	case "/revoke":
+		b.handleRevoke(chatID, parts[1:])
+	case "/notify":
+		b.handleNotify(chatID, userID, parts[1:])
+	case "/chart":
+		b.handleChart(chatID, parts[1:])
 	default:
 		// Button-only UX: always guide to menu.
 		b.handleMenu(chatID)
@@ -91,7 +120,7 @@ func (b *Bot) handleMenu(chatID int64) {
 	b.sendMessageWithKeyboard(chatID, msg, "HTML", mainMenuKeyboard())
 }
 
-func (b *Bot) handleMenuAction(chatID int64, data string) {
+func (b *Bot) handleMenuAction(chatID, userID int64, data string) {
 	switch {
 	case data == menuRoot:
 		b.handleMenu(chatID)
@@ -133,6 +162,14 @@ func (b *Bot) handleMenuAction(chatID int64, data string) {
 		b.handleAccountCheckTimeout(chatID, data)
 	case strings.HasPrefix(data, actionLogin):
 		b.handleAccountLogin(chatID, data)
+	case strings.HasPrefix(data, actionChart):
+		b.handleChartAction(chatID, data)
+	case strings.HasPrefix(data, actionAck):
+		b.handleIncidentAck(chatID, userID, data)
+	case strings.HasPrefix(data, actionSnooze):
+		b.handleIncidentSnooze(chatID, data)
+	case strings.HasPrefix(data, actionIncMute):
+		b.handleIncidentMute(chatID, data)
 	case data == actionReload:
 		b.handleReload(chatID)
 	case data == actionImport:
@@ -209,7 +246,7 @@ func (b *Bot) handleQuota(chatID int64) {
 	}
 
 	msg := formatQuotas(quotas)
-	b.sendMessageWithKeyboard(chatID, msg, "HTML", sectionKeyboard(menuQuota))
+	b.sendMessageWithKeyboard(chatID, msg, "HTML", quotaMenuKeyboard())
 }
 
 // handleAlerts handles the /alerts command
@@ -805,6 +842,139 @@ func (b *Bot) handleAccountEnable(chatID int64, data string) {
 	b.handleAccountsMenu(chatID)
 }
 
+// handleChart handles /chart [account|provider] [period]. Arguments that
+// parse as a duration set the period; anything else is the target.
+func (b *Bot) handleChart(chatID int64, args []string) {
+	target, period := "", defaultChartPeriod
+	for _, arg := range args {
+		if d, err := parseDuration(arg); err == nil {
+			period = d
+			continue
+		}
+		target = arg
+	}
+	b.sendChart(chatID, target, period)
+}
+
+// handleChartAction handles chart buttons: action:chart:<key>:<period>,
+// where an empty key charts all accounts
+func (b *Bot) handleChartAction(chatID int64, data string) {
+	parts := strings.SplitN(data, ":", 4)
+	if len(parts) != 4 {
+		b.sendErrorMessage(chatID, "Invalid chart action")
+		return
+	}
+	target := ""
+	if parts[2] != "" {
+		var ok bool
+		if target, ok = b.resolveAccountKey(parts[2]); !ok {
+			b.sendErrorMessage(chatID, "Chart key expired, refresh menu")
+			return
+		}
+	}
+	period, err := parseDuration(parts[3])
+	if err != nil {
+		b.sendErrorMessage(chatID, "Invalid chart period")
+		return
+	}
+	b.sendChart(chatID, target, period)
+}
+
+// sendChart renders a chart and sends it with buttons to switch periods
+func (b *Bot) sendChart(chatID int64, target string, period time.Duration) {
+	if b.onRenderChart == nil {
+		b.sendErrorMessage(chatID, "Chart callback not configured")
+		return
+	}
+	if period < time.Hour || period > maxChartPeriod {
+		b.sendErrorMessage(chatID, "Chart period must be between 1h and 7d")
+		return
+	}
+	img, err := b.onRenderChart(target, period)
+	if err != nil {
+		b.sendErrorMessage(chatID, fmt.Sprintf("Failed to render chart: %v", err))
+		return
+	}
+
+	key := ""
+	if target != "" {
+		key = b.rememberAccountKey(target, 0)
+	}
+	b.sendPhoto(chatID, "quota-chart.png", img.PNG, img.Caption, chartKeyboard(key))
+}
+
+// handleIncidentAck handles action:ack:<incident>
+func (b *Bot) handleIncidentAck(chatID, userID int64, data string) {
+	if b.onAckIncident == nil {
+		b.sendErrorMessage(chatID, "Incident callbacks not configured")
+		return
+	}
+	parts := strings.Split(data, ":")
+	if len(parts) != 3 || parts[2] == "" {
+		b.sendErrorMessage(chatID, "Invalid ack action")
+		return
+	}
+	if err := b.onAckIncident(parts[2], userID); err != nil {
+		b.sendErrorMessage(chatID, fmt.Sprintf("Failed to acknowledge incident: %v", err))
+		return
+	}
+	b.sendMessageWithParseMode(chatID, fmt.Sprintf(
+		"✅ Инцидент <code>%s</code> принят. Повторных уведомлений не будет, пока он не станет серьёзнее.",
+		html.EscapeString(parts[2])), "HTML")
+}
+
+// handleIncidentSnooze handles action:snooze:<incident>:<duration>
+func (b *Bot) handleIncidentSnooze(chatID int64, data string) {
+	if b.onSnoozeIncident == nil {
+		b.sendErrorMessage(chatID, "Incident callbacks not configured")
+		return
+	}
+	parts := strings.Split(data, ":")
+	if len(parts) != 4 || parts[2] == "" {
+		b.sendErrorMessage(chatID, "Invalid snooze action")
+		return
+	}
+	duration, err := parseDuration(parts[3])
+	if err != nil {
+		b.sendErrorMessage(chatID, "Invalid snooze duration")
+		return
+	}
+	if err := b.onSnoozeIncident(parts[2], duration); err != nil {
+		b.sendErrorMessage(chatID, fmt.Sprintf("Failed to snooze incident: %v", err))
+		return
+	}
+	b.sendMessageWithParseMode(chatID, fmt.Sprintf(
+		"💤 Инцидент <code>%s</code> отложен на %s.", html.EscapeString(parts[2]), formatDuration(duration)), "HTML")
+}
+
+// handleIncidentMute handles action:inc_mute:<incident>:<account|type>:<duration>
+func (b *Bot) handleIncidentMute(chatID int64, data string) {
+	if b.onMuteIncident == nil {
+		b.sendErrorMessage(chatID, "Incident callbacks not configured")
+		return
+	}
+	parts := strings.Split(data, ":")
+	if len(parts) != 5 || parts[2] == "" {
+		b.sendErrorMessage(chatID, "Invalid mute action")
+		return
+	}
+	scope := parts[3]
+	duration, err := parseDuration(parts[4])
+	if err != nil {
+		b.sendErrorMessage(chatID, "Invalid mute duration")
+		return
+	}
+	if err := b.onMuteIncident(parts[2], scope, duration); err != nil {
+		b.sendErrorMessage(chatID, fmt.Sprintf("Failed to mute alerts: %v", err))
+		return
+	}
+	what := "этого аккаунта"
+	if scope == "type" {
+		what = "этого типа"
+	}
+	b.sendMessageWithParseMode(chatID, fmt.Sprintf("🔕 Алёрты %s заглушены на %s.", what, formatDuration(duration)), "HTML")
+}
+
 // handleMute handles the /mute command
 func (b *Bot) handleMute(chatID int64, args []string) {
 	if b.onMuteAlerts == nil {
@@ -959,8 +1129,19 @@ func (b *Bot) handleAlert(alert Alert) {
 		return // Silently drop if rate limited
 	}
 
+	for _, chatID := range b.alertRecipients(alert.Severity) {
+		b.sendAlertMessage(chatID, alert)
+	}
+}
+
+// sendAlertMessage sends an alert, with incident buttons when it has one
+func (b *Bot) sendAlertMessage(chatID int64, alert Alert) {
 	msg := formatAlert(alert)
-	b.sendMessageWithParseMode(b.chatID, msg, "HTML")
+	if alert.IncidentID == "" {
+		b.sendMessageWithParseMode(chatID, msg, "HTML")
+		return
+	}
+	b.sendMessageWithKeyboard(chatID, msg, "HTML", incidentKeyboard(alert.IncidentID))
 }
 
 // sendDailyDigest sends the daily digest message
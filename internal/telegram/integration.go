@@ -12,63 +12,119 @@ import (
 	"github.com/quotaguard/quotaguard/internal/store"
 )
 
+// DefaultCallbackPrefix namespaces QuotaGuard buttons in a host bot
+const DefaultCallbackPrefix = "qg"
+
+// maxCallbackPrefixLen keeps namespaced callback data within Telegram's
+// 64-byte limit
+const maxCallbackPrefixLen = 8
+
 // BotIntegrator allows integrating QuotaGuard into existing bots
 // without running its own getUpdates loop
 type BotIntegrator struct {
 	bot      *Bot
 	handlers map[string]CommandHandler
+	prefix   string
+}
+
+// IntegratorOptions contains optional configuration for the integrator
+type IntegratorOptions struct {
+	// CallbackPrefix namespaces the callback data of QuotaGuard buttons as
+	// "<prefix>:<data>" so they do not clash with the host bot's own
+	// buttons. Defaults to DefaultCallbackPrefix.
+	CallbackPrefix string
 }
 
 // CommandHandler is a function type for handling commands
 type CommandHandler func(chatID int64, args []string)
 
 // NewBotIntegrator creates an integrator for existing bots
-func NewBotIntegrator(bot *Bot) *BotIntegrator {
+func NewBotIntegrator(bot *Bot, opts *IntegratorOptions) (*BotIntegrator, error) {
+	if bot == nil {
+		return nil, fmt.Errorf("bot is required")
+	}
+	prefix := DefaultCallbackPrefix
+	if opts != nil && opts.CallbackPrefix != "" {
+		prefix = opts.CallbackPrefix
+	}
+	if len(prefix) > maxCallbackPrefixLen || strings.ContainsAny(prefix, ": \t\n") {
+		return nil, fmt.Errorf("invalid callback prefix %q: use up to %d characters without spaces or ':'", prefix, maxCallbackPrefixLen)
+	}
+	bot.callbackPrefix = prefix
+
 	return &BotIntegrator{
 		bot:      bot,
 		handlers: biRegisterQGBHandlers(bot),
-	}
+		prefix:   prefix,
+	}, nil
 }
 
 // HandleUpdate processes a single update from existing bot's getUpdates loop
-// This way QuotaGuard doesn't run its own getUpdates and won't conflict with existing bot
-func (bi *BotIntegrator) HandleUpdate(update tgbotapi.Update) {
+// This way QuotaGuard doesn't run its own getUpdates and won't conflict with existing bot.
+// It reports whether the update was meant for QuotaGuard; the host bot
+// handles the rest.
+func (bi *BotIntegrator) HandleUpdate(update tgbotapi.Update) bool {
 	if update.Message != nil {
-		bi.handleMessage(update.Message)
-		return
+		return bi.handleMessage(update.Message)
 	}
 	if update.CallbackQuery != nil {
-		bi.handleCallback(update.CallbackQuery)
+		return bi.handleCallback(update.CallbackQuery)
 	}
+	return false
 }
 
-func (bi *BotIntegrator) handleMessage(msg *tgbotapi.Message) {
+func (bi *BotIntegrator) handleMessage(msg *tgbotapi.Message) bool {
 	if msg == nil {
-		return
+		return false
 	}
 
 	text := strings.TrimSpace(msg.Text)
-	if text == "" || !strings.HasPrefix(text, "/") {
-		return
+	if text == "" {
+		return false
 	}
 
 	chatID := msg.Chat.ID
-	bi.storeChatID(chatID)
+	userID := chatID
+	if msg.From != nil {
+		userID = msg.From.ID
+	}
+
+	// A login started from the buttons waits for the callback URL or token
+	// as a plain message in the same chat.
+	if !strings.HasPrefix(text, "/") {
+		session := bi.bot.GetSession(chatID)
+		if session == nil || session.State != StateWaitingOAuth {
+			return false
+		}
+		if bi.bot.authorize(chatID, userID, actionLogin) {
+			bi.bot.handleLoginInput(chatID, text, session)
+		}
+		return true
+	}
 
 	parts := strings.Fields(text)
 	if len(parts) == 0 {
-		return
+		return false
 	}
 
 	command := strings.TrimPrefix(parts[0], "/")
 	if command == "" {
-		return
+		return false
 	}
 
 	// Strip bot username suffix (e.g., /qg_status@botname)
 	if idx := strings.Index(command, "@"); idx != -1 {
 		command = command[:idx]
 	}
+	if command != "settoken" && !strings.HasPrefix(command, "qg_") {
+		// Ignore non-QuotaGuard commands
+		return false
+	}
+
+	if !bi.bot.authorize(chatID, userID, "/"+command) {
+		return true
+	}
+	bi.storeChatID(chatID)
 
 	args := parts[1:]
 
@@ -83,13 +139,42 @@ func (bi *BotIntegrator) handleMessage(msg *tgbotapi.Message) {
 	default:
 		// Ignore non-QuotaGuard commands
 	}
+	return true
 }
 
-func (bi *BotIntegrator) handleCallback(cb *tgbotapi.CallbackQuery) {
-	if cb == nil || cb.Message == nil {
-		return
+// handleCallback routes a namespaced button press to the menu handlers the
+// standalone bot uses. Buttons of the host bot are left alone.
+func (bi *BotIntegrator) handleCallback(cb *tgbotapi.CallbackQuery) bool {
+	if cb == nil || cb.Message == nil || cb.Message.Chat == nil {
+		return false
+	}
+	data, ok := strings.CutPrefix(cb.Data, bi.prefix+":")
+	if !ok {
+		return false
+	}
+	if answerer, ok := bi.bot.api.(CallbackAnswerer); ok {
+		_ = answerer.AnswerCallback(cb.ID)
 	}
-	// Callback handling can be added when interactive UI is implemented.
+
+	data = strings.ToLower(strings.TrimSpace(data))
+	if !strings.HasPrefix(data, "menu:") && !strings.HasPrefix(data, "action:") {
+		return true
+	}
+
+	chatID := cb.Message.Chat.ID
+	userID := chatID
+	if cb.From != nil {
+		userID = cb.From.ID
+	}
+	if !bi.bot.authorize(chatID, userID, data) {
+		return true
+	}
+	bi.storeChatID(chatID)
+
+	// A button press abandons any pending login in this chat
+	bi.bot.SetSessionState(chatID, StateIdle, nil)
+	bi.bot.handleMenuAction(chatID, userID, data)
+	return true
 }
 
 func (bi *BotIntegrator) storeChatID(chatID int64) {
@@ -123,6 +208,7 @@ func biRegisterQGBHandlers(bot *Bot) map[string]CommandHandler {
 		"import":             bot.biHandleImport,
 		"export":             bot.biHandleExport,
 		"reload":             bot.biHandleReload,
+		"menu":               bot.biHandleMenu,
 		"help":               bot.biHandleHelp,
 	}
 }
@@ -422,6 +508,11 @@ func (b *Bot) biHandleReload(chatID int64, args []string) {
 	b.sendMessage(chatID, "🔄 Reload Configuration\n\nConfiguration reloaded successfully.")
 }
 
+// biHandleMenu handles /qg_menu command
+func (b *Bot) biHandleMenu(chatID int64, args []string) {
+	b.handleMenu(chatID)
+}
+
 // biHandleHelp handles /qg_help command
 func (b *Bot) biHandleHelp(chatID int64, args []string) {
 	helpText := "🤖 QuotaGuard Bot Commands\n\n" +
@@ -436,6 +527,7 @@ func (b *Bot) biHandleHelp(chatID int64, args []string) {
 		"/qg_import - Import CLIProxyAPI accounts\n" +
 		"/qg_export - Export configuration\n" +
 		"/qg_reload - Reload config\n" +
+		"/qg_menu - Open the button menu\n" +
 		"/qg_help - Show this help\n\n" +
 		"/settoken - Configure bot token"
 
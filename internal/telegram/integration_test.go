@@ -1,6 +1,7 @@
 package telegram
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -23,7 +24,8 @@ func TestBotIntegratorHandlers(t *testing.T) {
 		return []ActiveAlert{{ID: "1", Severity: "warn", Message: "test", Time: time.Now()}}, nil
 	}
 
-	bi := NewBotIntegrator(bot)
+	bi, err := NewBotIntegrator(bot, nil)
+	require.NoError(t, err)
 	require.NotNil(t, bi)
 	require.NotEmpty(t, bi.handlers)
 
@@ -42,7 +44,119 @@ func TestBotIntegratorHandlers(t *testing.T) {
 
 	require.GreaterOrEqual(t, len(api.GetMessages()), 9)
 
-	bi.HandleUpdate(tgbotapi.Update{})
+	require.False(t, bi.HandleUpdate(tgbotapi.Update{}))
+}
+
+// mockKeyboardAPI records keyboards and answered callbacks
+type mockKeyboardAPI struct {
+	mockBotAPI
+	keyboards []InlineKeyboard
+	answered  []string
+}
+
+func (m *mockKeyboardAPI) SendMessageWithInlineKeyboard(chatID int64, text, _ string, keyboard InlineKeyboard) error {
+	m.mu.Lock()
+	m.keyboards = append(m.keyboards, keyboard)
+	m.mu.Unlock()
+	return m.SendMessage(chatID, text)
+}
+
+func (m *mockKeyboardAPI) AnswerCallback(callbackID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.answered = append(m.answered, callbackID)
+	return nil
+}
+
+func callbackUpdate(chatID, userID int64, data string) tgbotapi.Update {
+	return tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:      "cb-" + data,
+		From:    &tgbotapi.User{ID: userID},
+		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: chatID}},
+		Data:    data,
+	}}
+}
+
+func textUpdate(chatID, userID int64, text string) tgbotapi.Update {
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		From: &tgbotapi.User{ID: userID},
+		Chat: &tgbotapi.Chat{ID: chatID},
+		Text: text,
+	}}
+}
+
+func TestBotIntegratorCallbacks(t *testing.T) {
+	api := &mockKeyboardAPI{}
+	bot := NewBot("token", 100, true, &BotOptions{BotAPI: api, RateLimiter: NewRateLimiter(1000)})
+	bot.onGetStatus = func() (*SystemStatus, error) {
+		return &SystemStatus{AccountsActive: 1, RouterStatus: "ok", LastUpdate: time.Now()}, nil
+	}
+
+	bi, err := NewBotIntegrator(bot, &IntegratorOptions{CallbackPrefix: "quota"})
+	require.NoError(t, err)
+
+	// /qg_menu opens the button menu with namespaced callback data
+	require.True(t, bi.HandleUpdate(textUpdate(100, 1, "/qg_menu")))
+	require.Len(t, api.keyboards, 1)
+	for _, row := range api.keyboards[0].Rows {
+		for _, btn := range row {
+			require.True(t, strings.HasPrefix(btn.CallbackData, "quota:menu:"), btn.CallbackData)
+		}
+	}
+
+	// Namespaced buttons reach the menu handlers and are answered
+	require.True(t, bi.HandleUpdate(callbackUpdate(100, 1, "quota:"+menuStatus)))
+	require.Equal(t, []string{"cb-quota:" + menuStatus}, api.answered)
+	messages := api.GetMessages()
+	require.Contains(t, messages[len(messages)-1].text, "QuotaGuard Status")
+
+	// Buttons and text of the host bot are left to the host
+	sent := len(api.GetMessages())
+	require.False(t, bi.HandleUpdate(callbackUpdate(100, 1, "menu:status")))
+	require.False(t, bi.HandleUpdate(callbackUpdate(100, 1, "host:buy")))
+	require.False(t, bi.HandleUpdate(textUpdate(100, 1, "/start")))
+	require.False(t, bi.HandleUpdate(textUpdate(100, 1, "hello")))
+	require.Len(t, api.GetMessages(), sent)
+	require.Len(t, api.answered, 1)
+
+	// Roles apply to buttons as well
+	require.NoError(t, bot.GrantRole(2, RoleViewer, nil, 1))
+	require.True(t, bi.HandleUpdate(callbackUpdate(100, 2, "quota:"+actionReload)))
+	messages = api.GetMessages()
+	require.Contains(t, messages[len(messages)-1].text, "Недостаточно прав")
+
+	_, err = NewBotIntegrator(bot, &IntegratorOptions{CallbackPrefix: "bad:prefix"})
+	require.Error(t, err)
+	_, err = NewBotIntegrator(nil, nil)
+	require.Error(t, err)
+}
+
+func TestBotIntegratorLoginFlow(t *testing.T) {
+	api := &mockKeyboardAPI{}
+	bot := NewBot("token", 0, true, &BotOptions{BotAPI: api, RateLimiter: NewRateLimiter(1000)})
+
+	var completed []int64
+	bot.SetLoginCallbacks(
+		func(provider string, chatID int64) (*LoginURLPayload, error) {
+			return &LoginURLPayload{Provider: provider, URL: "https://example.com/auth", State: "state-" + provider, Mode: "oauth"}, nil
+		},
+		func(provider, state, code string, chatID int64) (*LoginResult, error) {
+			completed = append(completed, chatID)
+			return &LoginResult{AccountID: provider + "-1", Provider: provider}, nil
+		},
+	)
+
+	bi, err := NewBotIntegrator(bot, nil)
+	require.NoError(t, err)
+
+	require.True(t, bi.HandleUpdate(callbackUpdate(-500, 1, DefaultCallbackPrefix+":"+actionLogin+":gemini")))
+	require.Equal(t, StateWaitingOAuth, bot.GetSession(-500).State)
+	// The pending login belongs to the host chat it was started in
+	require.Equal(t, StateIdle, bot.getSessionState(-600))
+	require.False(t, bi.HandleUpdate(textUpdate(-600, 1, "http://localhost/callback?state=state-gemini&code=abc")))
+
+	require.True(t, bi.HandleUpdate(textUpdate(-500, 1, "http://localhost/callback?state=state-gemini&code=abc")))
+	require.Equal(t, []int64{-500}, completed)
 }
 
 func TestIntegrationSessionHelpers(t *testing.T) {
@@ -21,3 +21,29 @@ type InlineKeyboardSender interface {
 func (k InlineKeyboard) HasButtons() bool {
 	return len(k.Rows) > 0
 }
+
+// withCallbackPrefix returns a copy of the keyboard whose callback data is
+// namespaced as "<prefix>:<data>". URL buttons are left as is.
+func (k InlineKeyboard) withCallbackPrefix(prefix string) InlineKeyboard {
+	if prefix == "" {
+		return k
+	}
+	rows := make([][]InlineButton, 0, len(k.Rows))
+	for _, row := range k.Rows {
+		btnRow := make([]InlineButton, 0, len(row))
+		for _, btn := range row {
+			if btn.CallbackData != "" {
+				btn.CallbackData = prefix + ":" + btn.CallbackData
+			}
+			btnRow = append(btnRow, btn)
+		}
+		rows = append(rows, btnRow)
+	}
+	return InlineKeyboard{Rows: rows}
+}
+
+// CallbackAnswerer acknowledges a callback query so Telegram stops showing
+// the button as loading.
+type CallbackAnswerer interface {
+	AnswerCallback(callbackID string) error
+}
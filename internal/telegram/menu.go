@@ -1,5 +1,7 @@
 package telegram
 
+import "time"
+
 const (
 	menuRoot     = "menu:root"
 	menuStatus   = "menu:status"
@@ -24,6 +26,15 @@ const (
 	actionCheckInt    = "action:check_interval"
 	actionCheckTO     = "action:check_timeout"
 	actionLogin       = "action:login"
+	actionChart       = "action:chart"
+	actionAck         = "action:ack"
+	actionSnooze      = "action:snooze"
+	actionIncMute     = "action:inc_mute"
+)
+
+const (
+	defaultChartPeriod = 24 * time.Hour
+	maxChartPeriod     = 7 * 24 * time.Hour
 )
 
 func mainMenuKeyboard() InlineKeyboard {
@@ -204,6 +215,7 @@ func accountsMenuKeyboard(accounts []AccountControl, callbackKeys []string) Inli
 		}
 		rows = append(rows, []InlineButton{
 			{Text: label, CallbackData: callback},
+			{Text: "📉 График", CallbackData: chartCallback(key, "24h")},
 		})
 	}
 	rows = append(rows, []InlineButton{
@@ -215,6 +227,43 @@ func accountsMenuKeyboard(accounts []AccountControl, callbackKeys []string) Inli
 	return InlineKeyboard{Rows: rows}
 }
 
+func quotaMenuKeyboard() InlineKeyboard {
+	return InlineKeyboard{
+		Rows: [][]InlineButton{
+			{
+				{Text: "📉 График 24ч", CallbackData: chartCallback("", "24h")},
+				{Text: "📉 График 7д", CallbackData: chartCallback("", "7d")},
+			},
+			{
+				{Text: "🔄 Обновить", CallbackData: menuQuota},
+			},
+			{
+				{Text: "⬅️ Меню", CallbackData: menuRoot},
+			},
+		},
+	}
+}
+
+// chartKeyboard switches the period of a chart; key is empty for all accounts
+func chartKeyboard(key string) InlineKeyboard {
+	return InlineKeyboard{
+		Rows: [][]InlineButton{
+			{
+				{Text: "24ч", CallbackData: chartCallback(key, "24h")},
+				{Text: "7д", CallbackData: chartCallback(key, "7d")},
+			},
+			{
+				{Text: "📈 Квоты", CallbackData: menuQuota},
+				{Text: "⬅️ Меню", CallbackData: menuRoot},
+			},
+		},
+	}
+}
+
+func chartCallback(key, period string) string {
+	return actionChart + ":" + key + ":" + period
+}
+
 func fallbackMenuKeyboard() InlineKeyboard {
 	return InlineKeyboard{
 		Rows: [][]InlineButton{
@@ -237,3 +286,19 @@ func sectionKeyboard(refreshCallback string) InlineKeyboard {
 		},
 	}
 }
+
+// incidentKeyboard acknowledges, snoozes or mutes the incident of an alert
+func incidentKeyboard(incidentID string) InlineKeyboard {
+	return InlineKeyboard{
+		Rows: [][]InlineButton{
+			{
+				{Text: "✅ Ack", CallbackData: actionAck + ":" + incidentID},
+				{Text: "💤 1ч", CallbackData: actionSnooze + ":" + incidentID + ":1h"},
+			},
+			{
+				{Text: "🔕 Аккаунт 4ч", CallbackData: actionIncMute + ":" + incidentID + ":account:4h"},
+				{Text: "🔕 Тип 4ч", CallbackData: actionIncMute + ":" + incidentID + ":type:4h"},
+			},
+		},
+	}
+}
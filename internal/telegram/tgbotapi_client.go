@@ -52,24 +52,45 @@ func (c *TGBotAPIClient) SendMessageWithInlineKeyboard(chatID int64, text, parse
 		msg.ParseMode = parseMode
 	}
 	if keyboard.HasButtons() {
-		rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(keyboard.Rows))
-		for _, row := range keyboard.Rows {
-			btnRow := make([]tgbotapi.InlineKeyboardButton, 0, len(row))
-			for _, btn := range row {
-				if btn.URL != "" {
-					btnRow = append(btnRow, tgbotapi.NewInlineKeyboardButtonURL(btn.Text, btn.URL))
-					continue
-				}
-				btnRow = append(btnRow, tgbotapi.NewInlineKeyboardButtonData(btn.Text, btn.CallbackData))
-			}
-			rows = append(rows, btnRow)
-		}
-		msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+		msg.ReplyMarkup = inlineKeyboardMarkup(keyboard)
 	}
 	_, err := c.bot.Send(msg)
 	return err
 }
 
+// SendPhoto uploads a PNG image with a caption and an optional inline keyboard.
+func (c *TGBotAPIClient) SendPhoto(chatID int64, name string, data []byte, caption string, keyboard InlineKeyboard) error {
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: name, Bytes: data})
+	photo.Caption = caption
+	if keyboard.HasButtons() {
+		photo.ReplyMarkup = inlineKeyboardMarkup(keyboard)
+	}
+	_, err := c.bot.Send(photo)
+	return err
+}
+
+// AnswerCallback acknowledges a callback query without showing a notification.
+func (c *TGBotAPIClient) AnswerCallback(callbackID string) error {
+	_, err := c.bot.Request(tgbotapi.NewCallback(callbackID, ""))
+	return err
+}
+
+func inlineKeyboardMarkup(keyboard InlineKeyboard) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(keyboard.Rows))
+	for _, row := range keyboard.Rows {
+		btnRow := make([]tgbotapi.InlineKeyboardButton, 0, len(row))
+		for _, btn := range row {
+			if btn.URL != "" {
+				btnRow = append(btnRow, tgbotapi.NewInlineKeyboardButtonURL(btn.Text, btn.URL))
+				continue
+			}
+			btnRow = append(btnRow, tgbotapi.NewInlineKeyboardButtonData(btn.Text, btn.CallbackData))
+		}
+		rows = append(rows, btnRow)
+	}
+	return tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
 // GetUpdates fetches new updates and converts them to Message.
 func (c *TGBotAPIClient) GetUpdates() ([]Message, error) {
 	c.mu.Lock()
@@ -86,23 +107,35 @@ func (c *TGBotAPIClient) GetUpdates() ([]Message, error) {
 	messages := make([]Message, 0, len(updates))
 	for _, update := range updates {
 		if update.Message != nil {
-			messages = append(messages, Message{
+			msg := Message{
 				ID:        int64(update.Message.MessageID),
 				ChatID:    update.Message.Chat.ID,
 				Text:      update.Message.Text,
 				Timestamp: time.Unix(int64(update.Message.Date), 0),
-			})
+			}
+			if update.Message.From != nil {
+				msg.UserID = update.Message.From.ID
+			}
+			messages = append(messages, msg)
 		} else if update.CallbackQuery != nil && update.CallbackQuery.Message != nil {
-			messages = append(messages, Message{
+			msg := Message{
 				ID:        int64(update.CallbackQuery.Message.MessageID),
 				ChatID:    update.CallbackQuery.Message.Chat.ID,
 				Text:      update.CallbackQuery.Data,
 				Timestamp: time.Unix(int64(update.CallbackQuery.Message.Date), 0),
-			})
+			}
+			if update.CallbackQuery.From != nil {
+				msg.UserID = update.CallbackQuery.From.ID
+			}
+			messages = append(messages, msg)
 		}
 	}
 
 	return messages, nil
 }
 
-var _ BotAPI = (*TGBotAPIClient)(nil)
+var (
+	_ BotAPI           = (*TGBotAPIClient)(nil)
+	_ PhotoSender      = (*TGBotAPIClient)(nil)
+	_ CallbackAnswerer = (*TGBotAPIClient)(nil)
+)
@@ -0,0 +1,365 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// Types shared with the server
+type (
+	Provider      = models.Provider
+	DimensionType = models.DimensionType
+	Account       = models.Account
+	QuotaInfo     = models.QuotaInfo
+	Reservation   = models.Reservation
+	APIClient     = models.APIClient
+	SpendTotal    = models.SpendTotal
+)
+
+// Health is the response of GET /health.
+type Health struct {
+	Status    string          `json:"status"`
+	Timestamp time.Time       `json:"timestamp"`
+	Router    bool            `json:"router"`
+	Collector json.RawMessage `json:"collector,omitempty"`
+}
+
+// SelectRequest asks the router for an account.
+type SelectRequest struct {
+	Provider         string   `json:"provider,omitempty"`
+	RequiredDims     []string `json:"required_dimensions,omitempty"`
+	EstimatedCost    float64  `json:"estimated_cost_percent,omitempty"`
+	EstimatedTokens  int64    `json:"estimated_tokens,omitempty"`
+	Policy           string   `json:"policy,omitempty"`
+	Exclude          []string `json:"exclude_accounts,omitempty"`
+	ExcludeProviders []string `json:"exclude_providers,omitempty"`
+	Model            string   `json:"model,omitempty"`
+	SessionID        string   `json:"session_id,omitempty"`
+	ClientID         string   `json:"client_id,omitempty"`
+	// CorrelationID is sent as X-Correlation-ID and identifies the routing
+	// decision. Default: the server assigns one.
+	CorrelationID string `json:"-"`
+}
+
+// Selection is the account chosen for a request. Fallback is set when it
+// was picked from the account cache because QuotaGuard was unavailable.
+type Selection struct {
+	AccountID      string   `json:"account_id"`
+	Provider       string   `json:"provider"`
+	Score          float64  `json:"score"`
+	Reason         string   `json:"reason"`
+	AlternativeIDs []string `json:"alternative_ids,omitempty"`
+	AffinityHit    bool     `json:"affinity_hit,omitempty"`
+	// CorrelationID identifies the routing decision; pass it back in
+	// FeedbackRequest so the outcome is joined to the decision
+	CorrelationID string `json:"correlation_id,omitempty"`
+	Fallback      bool   `json:"-"`
+}
+
+// FeedbackRequest reports the outcome of a routed request.
+type FeedbackRequest struct {
+	AccountID     string  `json:"account_id"`
+	ReservationID string  `json:"reservation_id,omitempty"`
+	ActualCost    float64 `json:"actual_cost_percent,omitempty"`
+	InputTokens   int64   `json:"input_tokens,omitempty"`
+	OutputTokens  int64   `json:"output_tokens,omitempty"`
+	Success       bool    `json:"success"`
+	Error         string  `json:"error,omitempty"`
+	CorrelationID string  `json:"correlation_id,omitempty"`
+}
+
+// ReservationRequest reserves quota on an account.
+type ReservationRequest struct {
+	AccountID        string  `json:"account_id"`
+	EstimatedCostPct float64 `json:"estimated_cost_percent"`
+	CorrelationID    string  `json:"correlation_id"`
+}
+
+// ReservationCreated is the response of a new reservation.
+type ReservationCreated struct {
+	ReservationID string    `json:"reservation_id"`
+	AccountID     string    `json:"account_id"`
+	Status        string    `json:"status"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// ReleaseRequest settles a reservation with the actual cost.
+type ReleaseRequest struct {
+	ActualCostPct float64 `json:"actual_cost_percent"`
+	InputTokens   int64   `json:"input_tokens,omitempty"`
+	OutputTokens  int64   `json:"output_tokens,omitempty"`
+}
+
+// IngestRequest pushes an account's quota state. Headers may carry raw
+// provider response headers instead of dimensions.
+type IngestRequest struct {
+	AccountID             string              `json:"account_id"`
+	Provider              string              `json:"provider"`
+	EffectiveRemainingPct float64             `json:"effective_remaining_percent"`
+	Dimensions            []models.Dimension  `json:"dimensions,omitempty"`
+	Headers               map[string][]string `json:"headers,omitempty"`
+	IsThrottled           bool                `json:"is_throttled"`
+	Source                string              `json:"source"`
+}
+
+// SpendQuery selects a spend report. Empty fields use the server defaults:
+// month to date, grouped by account.
+type SpendQuery struct {
+	From    string
+	To      string
+	GroupBy string
+}
+
+// SpendReport summarizes the spend ledger for a day range.
+type SpendReport struct {
+	From         string          `json:"from"`
+	To           string          `json:"to"`
+	GroupBy      string          `json:"group_by"`
+	Requests     int64           `json:"requests"`
+	InputTokens  int64           `json:"input_tokens"`
+	OutputTokens int64           `json:"output_tokens"`
+	CostUSD      float64         `json:"cost_usd"`
+	Groups       []SpendTotal    `json:"groups"`
+	Budgets      []AccountBudget `json:"budgets,omitempty"`
+}
+
+// AccountBudget is month-to-date spend against an account's monthly cap.
+type AccountBudget struct {
+	AccountID        string    `json:"account_id"`
+	MonthlyBudgetUSD float64   `json:"monthly_budget_usd"`
+	SpentUSD         float64   `json:"spent_usd"`
+	RemainingUSD     float64   `json:"remaining_usd"`
+	Exhausted        bool      `json:"exhausted"`
+	ResetAt          time.Time `json:"reset_at"`
+}
+
+// CollectorSchedule lists when the active collector polls each account.
+type CollectorSchedule struct {
+	Active   bool           `json:"active"`
+	Schedule []PollSchedule `json:"schedule"`
+}
+
+// PollSchedule is the planned next poll of one account.
+type PollSchedule struct {
+	AccountID       string     `json:"account_id"`
+	Provider        Provider   `json:"provider"`
+	NextPoll        time.Time  `json:"next_poll"`
+	IntervalSeconds float64    `json:"interval_seconds"`
+	Reason          string     `json:"reason"`
+	LastPoll        *time.Time `json:"last_poll,omitempty"`
+	Failures        int        `json:"failures,omitempty"`
+}
+
+// ClientRequest creates or updates an API client. Nil fields keep their
+// current value on update.
+type ClientRequest struct {
+	ID                 string   `json:"id"`
+	Name               *string  `json:"name,omitempty"`
+	AllowedProviders   []string `json:"allowed_providers,omitempty"`
+	AllowedAccounts    []string `json:"allowed_accounts,omitempty"`
+	DefaultPolicy      *string  `json:"default_policy,omitempty"`
+	RateLimitRPM       *int     `json:"rate_limit_rpm,omitempty"`
+	DailyQuotaSharePct *float64 `json:"daily_quota_share_percent,omitempty"`
+	Enabled            *bool    `json:"enabled,omitempty"`
+}
+
+// ClientKey is returned when a key is issued. The key is shown only once.
+type ClientKey struct {
+	Client *APIClient `json:"client"`
+	APIKey string     `json:"api_key"`
+}
+
+// ClientUsage is a client's usage against its daily share.
+type ClientUsage struct {
+	ClientID        string  `json:"client_id"`
+	Day             string  `json:"day"`
+	Requests        int64   `json:"requests"`
+	ConsumedPct     float64 `json:"consumed_percent"`
+	BudgetPct       float64 `json:"budget_percent,omitempty"`
+	RemainingPct    float64 `json:"remaining_percent,omitempty"`
+	BudgetUnlimited bool    `json:"budget_unlimited"`
+}
+
+// Health returns the server status.
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	var resp Health
+	if err := c.do(ctx, http.MethodGet, "/health", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Select asks the router for an account. It does not fall back; see
+// SelectWithFallback.
+func (c *Client) Select(ctx context.Context, req SelectRequest) (*Selection, error) {
+	var header http.Header
+	if req.CorrelationID != "" {
+		header = http.Header{"X-Correlation-ID": []string{req.CorrelationID}}
+	}
+	var resp Selection
+	if err := c.doWithHeader(ctx, http.MethodPost, "/router/select", nil, header, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Feedback reports the outcome of a routed request.
+func (c *Client) Feedback(ctx context.Context, req FeedbackRequest) error {
+	return c.do(ctx, http.MethodPost, "/router/feedback", nil, req, nil)
+}
+
+// Distribution returns the router's target share of traffic per account.
+func (c *Client) Distribution(ctx context.Context) (map[string]float64, error) {
+	resp := make(map[string]float64)
+	if err := c.do(ctx, http.MethodGet, "/router/distribution", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListQuotas returns the quota of every account.
+func (c *Client) ListQuotas(ctx context.Context) ([]QuotaInfo, error) {
+	var resp []QuotaInfo
+	if err := c.do(ctx, http.MethodGet, "/quotas", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetQuota returns the quota of one account.
+func (c *Client) GetQuota(ctx context.Context, accountID string) (*QuotaInfo, error) {
+	var resp QuotaInfo
+	if err := c.do(ctx, http.MethodGet, "/quotas/"+url.PathEscape(accountID), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Spend returns the spend report.
+func (c *Client) Spend(ctx context.Context, q SpendQuery) (*SpendReport, error) {
+	query := url.Values{}
+	if q.From != "" {
+		query.Set("from", q.From)
+	}
+	if q.To != "" {
+		query.Set("to", q.To)
+	}
+	if q.GroupBy != "" {
+		query.Set("group_by", q.GroupBy)
+	}
+	var resp SpendReport
+	if err := c.do(ctx, http.MethodGet, "/spend", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CollectorSchedule returns the active collector's polling plan.
+func (c *Client) CollectorSchedule(ctx context.Context) (*CollectorSchedule, error) {
+	var resp CollectorSchedule
+	if err := c.do(ctx, http.MethodGet, "/collector/schedule", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateReservation reserves quota on an account.
+func (c *Client) CreateReservation(ctx context.Context, req ReservationRequest) (*ReservationCreated, error) {
+	var resp ReservationCreated
+	if err := c.do(ctx, http.MethodPost, "/reservations", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ReleaseReservation settles a reservation with the actual cost.
+func (c *Client) ReleaseReservation(ctx context.Context, id string, req ReleaseRequest) error {
+	return c.do(ctx, http.MethodPost, "/reservations/"+url.PathEscape(id)+"/release", nil, req, nil)
+}
+
+// CancelReservation returns a reservation's quota unused.
+func (c *Client) CancelReservation(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, "/reservations/"+url.PathEscape(id)+"/cancel", nil, nil, nil)
+}
+
+// GetReservation returns a reservation.
+func (c *Client) GetReservation(ctx context.Context, id string) (*Reservation, error) {
+	var resp Reservation
+	if err := c.do(ctx, http.MethodGet, "/reservations/"+url.PathEscape(id), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Ingest pushes an account's quota state.
+func (c *Client) Ingest(ctx context.Context, req IngestRequest) error {
+	return c.do(ctx, http.MethodPost, "/ingest", nil, req, nil)
+}
+
+// ListClients returns all API clients.
+func (c *Client) ListClients(ctx context.Context) ([]APIClient, error) {
+	var resp []APIClient
+	if err := c.do(ctx, http.MethodGet, "/clients", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetClient returns one API client.
+func (c *Client) GetClient(ctx context.Context, id string) (*APIClient, error) {
+	var resp APIClient
+	if err := c.do(ctx, http.MethodGet, "/clients/"+url.PathEscape(id), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateClient registers an API client and returns its key.
+func (c *Client) CreateClient(ctx context.Context, req ClientRequest) (*ClientKey, error) {
+	var resp ClientKey
+	if err := c.do(ctx, http.MethodPost, "/clients", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateClient changes an API client's limits and restrictions.
+func (c *Client) UpdateClient(ctx context.Context, id string, req ClientRequest) (*APIClient, error) {
+	var resp APIClient
+	if err := c.do(ctx, http.MethodPut, "/clients/"+url.PathEscape(id), nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeleteClient removes an API client.
+func (c *Client) DeleteClient(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/clients/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// RotateClientKey issues a new key for an API client.
+func (c *Client) RotateClientKey(ctx context.Context, id string) (*ClientKey, error) {
+	var resp ClientKey
+	if err := c.do(ctx, http.MethodPost, "/clients/"+url.PathEscape(id)+"/rotate", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ClientUsage returns a client's usage for day (YYYY-MM-DD, empty for today).
+func (c *Client) ClientUsage(ctx context.Context, id, day string) (*ClientUsage, error) {
+	query := url.Values{}
+	if day != "" {
+		query.Set("day", day)
+	}
+	var resp ClientUsage
+	if err := c.do(ctx, http.MethodGet, "/clients/"+url.PathEscape(id)+"/usage", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
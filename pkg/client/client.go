@@ -0,0 +1,306 @@
+// Package client is a Go client for the QuotaGuard REST API.
+//
+// Besides typed methods for every endpoint it provides SelectWithFallback,
+// which keeps routing from a local account cache when QuotaGuard is down,
+// and Execute, which wraps a provider call in select, reserve and feedback.
+// The cache is kept current from signed snapshots with StartSnapshotRefresh.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/failopen"
+)
+
+// DefaultAPIKeyHeader is the header QuotaGuard reads API keys from unless
+// the server is configured otherwise.
+const DefaultAPIKeyHeader = "X-API-Key"
+
+// Headers used for HMAC request signing
+const (
+	HMACKeyIDHeader     = "X-QuotaGuard-Key-Id"
+	HMACTimestampHeader = "X-QuotaGuard-Timestamp"
+	HMACSignatureHeader = "X-QuotaGuard-Signature"
+)
+
+// maxErrorBodySize bounds how much of an error response is read
+const maxErrorBodySize = 64 << 10
+
+// ErrUnavailable is wrapped by errors meaning QuotaGuard could not be
+// reached. Such errors make SelectWithFallback use the account cache.
+var ErrUnavailable = failopen.ErrUnavailable
+
+// APIError is a non-2xx response from QuotaGuard.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements error.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("quotaguard: status %d: %s", e.StatusCode, e.Message)
+}
+
+// Unwrap reports gateway errors as ErrUnavailable.
+func (e *APIError) Unwrap() error {
+	if e.StatusCode == http.StatusBadGateway || e.StatusCode == http.StatusGatewayTimeout {
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// Hooks receive client events, for example to export metrics.
+type Hooks struct {
+	// OnRequest is called after every API call. Status is 0 when no
+	// response was received.
+	OnRequest func(method, path string, status int, latency time.Duration, err error)
+	// OnFallback is called when a selection was made from the account cache.
+	OnFallback func(reason string, sel *Selection)
+}
+
+// Client calls the QuotaGuard REST API.
+type Client struct {
+	baseURL      string
+	httpClient   *http.Client
+	apiKey       string
+	apiKeyHeader string
+	bearerToken  string
+	hmacKeyID    string
+	hmacSecret   []byte
+	hooks        Hooks
+
+	failOpenCfg failopen.Config
+	failOpen    *failopen.FailOpenClient
+	cache       *AccountCache
+
+	snapshotKey  []byte
+	snapshotFile string
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets a custom HTTP client.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithTimeout sets the timeout for HTTP requests.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithAPIKey authenticates with an API key.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithAPIKeyHeader sets the header the API key is sent in.
+// Default: X-API-Key.
+func WithAPIKeyHeader(header string) Option {
+	return func(c *Client) {
+		c.apiKeyHeader = header
+	}
+}
+
+// WithBearerToken authenticates with a JWT.
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.bearerToken = token
+	}
+}
+
+// WithHMAC signs every request with the given key.
+func WithHMAC(keyID, secret string) Option {
+	return func(c *Client) {
+		c.hmacKeyID = keyID
+		c.hmacSecret = []byte(secret)
+	}
+}
+
+// WithFailOpen configures SelectWithFallback. Zero fields take the
+// fail-open defaults: a 50ms timeout and quota-aware fallback.
+func WithFailOpen(cfg FailOpenConfig) Option {
+	return func(c *Client) {
+		c.failOpenCfg = cfg
+	}
+}
+
+// WithSnapshotKey requires snapshots to be signed with key, the server's
+// api.snapshot.signing_key.
+func WithSnapshotKey(key string) Option {
+	return func(c *Client) {
+		c.snapshotKey = []byte(key)
+	}
+}
+
+// WithSnapshotFile persists the last snapshot to path so the account cache
+// survives restarts during an outage.
+func WithSnapshotFile(path string) Option {
+	return func(c *Client) {
+		c.snapshotFile = path
+	}
+}
+
+// WithHooks sets callbacks for requests and fallbacks.
+func WithHooks(hooks Hooks) Option {
+	return func(c *Client) {
+		c.hooks = hooks
+	}
+}
+
+// New creates a client for the QuotaGuard server at baseURL.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+		apiKeyHeader: DefaultAPIKeyHeader,
+		failOpenCfg:  failopen.DefaultConfig(),
+		cache:        NewAccountCache(),
+	}
+	// The default strategy reads this client's cache, so it is set after options
+	c.failOpenCfg.FallbackStrategy = nil
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.failOpenCfg.FallbackStrategy == nil {
+		c.failOpenCfg.FallbackStrategy = NewQuotaAwareStrategy(c.cache)
+	}
+	c.failOpen = failopen.NewFailOpenClient(c.cache, c.failOpenCfg)
+	return c
+}
+
+// do sends a request and decodes a JSON response into out when non-nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	return c.doWithHeader(ctx, method, path, query, nil, in, out)
+}
+
+// doWithHeader is do with extra request headers
+func (c *Client) doWithHeader(ctx context.Context, method, path string, query url.Values, header http.Header, in, out interface{}) (err error) {
+	var body []byte
+	if in != nil {
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("quotaguard: encode request: %w", err)
+		}
+	}
+
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("quotaguard: create request: %w", err)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	for name, values := range header {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+	c.authenticate(req, body)
+
+	start := time.Now()
+	status := 0
+	if c.hooks.OnRequest != nil {
+		defer func() {
+			c.hooks.OnRequest(method, path, status, time.Since(start), err)
+		}()
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("quotaguard: %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	status = resp.StatusCode
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return decodeAPIError(resp)
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("quotaguard: decode %s response: %w", path, err)
+	}
+	return nil
+}
+
+// authenticate sets the configured credentials on req.
+func (c *Client) authenticate(req *http.Request, body []byte) {
+	if c.apiKey != "" {
+		req.Header.Set(c.apiKeyHeader, c.apiKey)
+	}
+	if c.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.bearerToken)
+	}
+	if len(c.hmacSecret) > 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		if c.hmacKeyID != "" {
+			req.Header.Set(HMACKeyIDHeader, c.hmacKeyID)
+		}
+		req.Header.Set(HMACTimestampHeader, timestamp)
+		req.Header.Set(HMACSignatureHeader, signRequest(c.hmacSecret, timestamp, req.Method, req.URL.RequestURI(), body))
+	}
+}
+
+// signRequest computes the signature QuotaGuard expects: an HMAC-SHA256 of
+// "<timestamp>\n<METHOD>\n<request URI>\n<hex sha256 of body>".
+func signRequest(secret []byte, timestamp, method, requestURI string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "\n" + method + "\n" + requestURI + "\n" + hex.EncodeToString(bodyHash[:])))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// decodeAPIError builds an APIError from an error response.
+func decodeAPIError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	var payload struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	msg := strings.TrimSpace(string(data))
+	if json.Unmarshal(data, &payload) == nil {
+		switch {
+		case payload.Message != "":
+			msg = payload.Message
+		case payload.Error != "":
+			msg = payload.Error
+		}
+	}
+	if msg == "" {
+		msg = http.StatusText(resp.StatusCode)
+	}
+	return &APIError{StatusCode: resp.StatusCode, Message: msg}
+}
+
+// IsNotFound reports whether err is a 404 response.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quotaguard/quotaguard/internal/api"
+	"github.com/quotaguard/quotaguard/internal/collector"
+	"github.com/quotaguard/quotaguard/internal/config"
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/quotaguard/quotaguard/internal/reservation"
+	"github.com/quotaguard/quotaguard/internal/router"
+	"github.com/quotaguard/quotaguard/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startServer runs a real QuotaGuard API with two accounts
+func startServer(t *testing.T, auth config.AuthConfig) (*httptest.Server, *store.MemoryStore) {
+	t.Helper()
+	return startAPIServer(t, config.APIConfig{Auth: auth})
+}
+
+func startAPIServer(t *testing.T, apiCfg config.APIConfig) (*httptest.Server, *store.MemoryStore) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	s := store.NewMemoryStore()
+	for _, acc := range []*models.Account{
+		{ID: "openai-1", Provider: models.ProviderOpenAI, Enabled: true, Priority: 10},
+		{ID: "anthropic-1", Provider: models.ProviderAnthropic, Enabled: true, Priority: 5},
+	} {
+		s.SetAccount(acc)
+		s.SetQuota(acc.ID, &models.QuotaInfo{
+			AccountID:             acc.ID,
+			Provider:              acc.Provider,
+			EffectiveRemainingPct: 80,
+			Dimensions:            models.DimensionSlice{{Type: models.DimensionRPM, Limit: 100, Used: 20, Remaining: 80}},
+			CollectedAt:           time.Now(),
+		})
+	}
+
+	server := api.NewServer(
+		config.ServerConfig{Host: "localhost", HTTPPort: 8080},
+		apiCfg,
+		s,
+		router.NewRouter(s, router.DefaultConfig()),
+		reservation.NewManager(s, reservation.DefaultConfig()),
+		collector.NewPassiveCollector(s, 100, 0),
+	)
+	ts := httptest.NewServer(server.Router())
+	t.Cleanup(ts.Close)
+	return ts, s
+}
+
+func TestClient_Endpoints(t *testing.T) {
+	ts, _ := startServer(t, config.AuthConfig{Enabled: true, APIKeys: []string{"admin-key"}})
+	ctx := context.Background()
+
+	var requests []string
+	c := New(ts.URL, WithAPIKey("admin-key"), WithHooks(Hooks{
+		OnRequest: func(method, path string, status int, _ time.Duration, _ error) {
+			requests = append(requests, method+" "+path)
+		},
+	}))
+
+	health, err := c.Health(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "healthy", health.Status)
+
+	sel, err := c.Select(ctx, SelectRequest{Provider: string(models.ProviderOpenAI)})
+	require.NoError(t, err)
+	assert.Equal(t, "openai-1", sel.AccountID)
+	assert.False(t, sel.Fallback)
+
+	quotas, err := c.ListQuotas(ctx)
+	require.NoError(t, err)
+	assert.Len(t, quotas, 2)
+
+	_, err = c.GetQuota(ctx, "missing")
+	assert.True(t, IsNotFound(err))
+
+	res, err := c.CreateReservation(ctx, ReservationRequest{AccountID: "openai-1", EstimatedCostPct: 5, CorrelationID: "corr-1"})
+	require.NoError(t, err)
+	got, err := c.GetReservation(ctx, res.ReservationID)
+	require.NoError(t, err)
+	assert.Equal(t, "corr-1", got.CorrelationID)
+	require.NoError(t, c.ReleaseReservation(ctx, res.ReservationID, ReleaseRequest{ActualCostPct: 3}))
+
+	require.NoError(t, c.Feedback(ctx, FeedbackRequest{AccountID: "openai-1", Success: true, InputTokens: 10}))
+	require.NoError(t, c.Ingest(ctx, IngestRequest{AccountID: "openai-1", Provider: "openai", EffectiveRemainingPct: 60}))
+
+	report, err := c.Spend(ctx, SpendQuery{GroupBy: "account"})
+	require.NoError(t, err)
+	assert.Equal(t, "account", report.GroupBy)
+
+	key, err := c.CreateClient(ctx, ClientRequest{ID: "svc"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, key.APIKey)
+	usage, err := c.ClientUsage(ctx, "svc", "")
+	require.NoError(t, err)
+	assert.Equal(t, "svc", usage.ClientID)
+	require.NoError(t, c.DeleteClient(ctx, "svc"))
+
+	assert.Contains(t, requests, "POST /router/select")
+	assert.Contains(t, requests, "DELETE /clients/svc")
+}
+
+func TestClient_AuthErrors(t *testing.T) {
+	ts, _ := startServer(t, config.AuthConfig{Enabled: true, APIKeys: []string{"admin-key"}})
+
+	_, err := New(ts.URL, WithAPIKey("wrong")).ListQuotas(context.Background())
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	assert.NotErrorIs(t, err, ErrUnavailable)
+}
+
+func TestClient_HMACSigning(t *testing.T) {
+	ts, _ := startServer(t, config.AuthConfig{
+		Enabled: true,
+		Type:    "hmac",
+		HMAC:    config.HMACConfig{Keys: []config.HMACKeyConfig{{ID: "svc", Secret: "s3cret"}}},
+	})
+
+	body := []byte(`{"provider":"openai"}`)
+	assert.Equal(t,
+		api.SignRequest([]byte("k"), "1700000000", "POST", "/router/select?x=1", body),
+		signRequest([]byte("k"), "1700000000", "POST", "/router/select?x=1", body))
+
+	sel, err := New(ts.URL, WithHMAC("svc", "s3cret")).Select(context.Background(), SelectRequest{Provider: "openai"})
+	require.NoError(t, err)
+	assert.Equal(t, "openai-1", sel.AccountID)
+
+	_, err = New(ts.URL, WithHMAC("svc", "wrong")).Select(context.Background(), SelectRequest{Provider: "openai"})
+	require.Error(t, err)
+}
+
+func TestAPIError_GatewayIsUnavailable(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	_, err := New(ts.URL).Health(context.Background())
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Contains(t, err.Error(), "Bad Gateway")
+}
+
+var noAuth = config.AuthConfig{}
@@ -0,0 +1,376 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/failopen"
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// Fail-open types shared with the server
+type (
+	FailOpenConfig   = failopen.Config
+	FailOpenStats    = failopen.Stats
+	FallbackStrategy = failopen.FallbackStrategy
+)
+
+// NewRoundRobinStrategy cycles through cached accounts.
+func NewRoundRobinStrategy() FallbackStrategy { return failopen.NewRoundRobinStrategy() }
+
+// NewWeightedStrategy picks cached accounts weighted by priority.
+func NewWeightedStrategy() FallbackStrategy { return failopen.NewWeightedStrategy() }
+
+// NewFirstAvailableStrategy picks a random cached account.
+func NewFirstAvailableStrategy() FallbackStrategy { return failopen.NewFirstAvailableStrategy() }
+
+// NewQuotaAwareStrategy picks cached accounts weighted by their remaining
+// quota in cache. It is the default strategy.
+func NewQuotaAwareStrategy(cache *AccountCache) FallbackStrategy {
+	return failopen.NewQuotaAwareStrategy(cache.RemainingPct)
+}
+
+// AccountCache holds the accounts QuotaGuard routes to, so selection can
+// continue from it while the server is unreachable. Accounts whose last
+// known quota is exhausted or that are blocked are left out of fallback.
+type AccountCache struct {
+	mu          sync.RWMutex
+	accounts    map[string]*cachedAccount
+	generatedAt time.Time
+	now         func() time.Time
+}
+
+// cachedAccount is an account with its last known routing state
+type cachedAccount struct {
+	account      Account
+	remainingPct *float64
+	exhausted    bool
+}
+
+// NewAccountCache creates an empty cache.
+func NewAccountCache() *AccountCache {
+	return &AccountCache{
+		accounts: make(map[string]*cachedAccount),
+		now:      time.Now,
+	}
+}
+
+// UpdateQuotas replaces the cache with the accounts in quotas. Priority and
+// block state already known for an account are kept.
+func (ac *AccountCache) UpdateQuotas(quotas []QuotaInfo) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	accounts := make(map[string]*cachedAccount, len(quotas))
+	for i := range quotas {
+		q := quotas[i]
+		entry := &cachedAccount{
+			account:      Account{ID: q.AccountID, Provider: q.Provider, Tier: q.Tier, Enabled: true},
+			remainingPct: &q.EffectiveRemainingPct,
+			exhausted:    q.IsExhausted() || q.EffectiveRemainingPct <= 0,
+		}
+		if prev, ok := ac.accounts[q.AccountID]; ok {
+			entry.account.Priority = prev.account.Priority
+			entry.account.BlockedUntil = prev.account.BlockedUntil
+		}
+		accounts[q.AccountID] = entry
+	}
+	ac.accounts = accounts
+}
+
+// ApplySnapshot replaces the cache with snap. A snapshot older than the
+// cache contents is ignored and false is returned.
+func (ac *AccountCache) ApplySnapshot(snap *Snapshot) bool {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	if snap.GeneratedAt.Before(ac.generatedAt) {
+		return false
+	}
+	accounts := make(map[string]*cachedAccount, len(snap.Accounts))
+	for _, sa := range snap.Accounts {
+		accounts[sa.ID] = &cachedAccount{
+			account: Account{
+				ID:           sa.ID,
+				Provider:     sa.Provider,
+				Tier:         sa.Tier,
+				Priority:     sa.Priority,
+				Enabled:      true,
+				BlockedUntil: sa.BlockedUntil,
+			},
+			remainingPct: sa.RemainingPct,
+			exhausted:    sa.Exhausted,
+		}
+	}
+	ac.accounts = accounts
+	ac.generatedAt = snap.GeneratedAt
+	return true
+}
+
+// Remember adds an account QuotaGuard selected.
+func (ac *AccountCache) Remember(id string, provider Provider) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	if _, ok := ac.accounts[id]; !ok {
+		ac.accounts[id] = &cachedAccount{account: Account{ID: id, Provider: provider, Enabled: true}}
+	}
+}
+
+// ListEnabledAccounts returns the cached accounts that are neither
+// exhausted nor blocked, ordered by ID.
+func (ac *AccountCache) ListEnabledAccounts() []*Account {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+
+	now := ac.now()
+	result := make([]*Account, 0, len(ac.accounts))
+	for _, entry := range ac.accounts {
+		if entry.exhausted {
+			continue
+		}
+		if entry.account.BlockedUntil != nil && entry.account.BlockedUntil.After(now) {
+			continue
+		}
+		copied := entry.account
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// GetAccount returns a cached account.
+func (ac *AccountCache) GetAccount(id string) (*Account, bool) {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+	entry, ok := ac.accounts[id]
+	if !ok {
+		return nil, false
+	}
+	copied := entry.account
+	return &copied, true
+}
+
+// RemainingPct returns an account's last known remaining quota percent.
+func (ac *AccountCache) RemainingPct(id string) (float64, bool) {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+	entry, ok := ac.accounts[id]
+	if !ok || entry.remainingPct == nil {
+		return 0, false
+	}
+	return *entry.remainingPct, true
+}
+
+// GeneratedAt returns when the last applied snapshot was generated.
+func (ac *AccountCache) GeneratedAt() time.Time {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+	return ac.generatedAt
+}
+
+// Len returns the number of cached accounts.
+func (ac *AccountCache) Len() int {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+	return len(ac.accounts)
+}
+
+// Accounts returns the client's account cache.
+func (c *Client) Accounts() *AccountCache {
+	return c.cache
+}
+
+// RefreshAccounts reloads the account cache from GET /quotas.
+func (c *Client) RefreshAccounts(ctx context.Context) error {
+	quotas, err := c.ListQuotas(ctx)
+	if err != nil {
+		return err
+	}
+	c.cache.UpdateQuotas(quotas)
+	return nil
+}
+
+// StartAccountRefresh refreshes the account cache now and then every
+// interval until ctx is done. Only the first refresh's error is returned.
+func (c *Client) StartAccountRefresh(ctx context.Context, interval time.Duration) error {
+	err := c.RefreshAccounts(ctx)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = c.RefreshAccounts(ctx)
+			}
+		}
+	}()
+	return err
+}
+
+// SelectWithFallback asks the router for an account. When QuotaGuard does
+// not answer within the fail-open timeout or cannot be reached, an account
+// matching the request's provider and exclusions is picked from the cache
+// with the configured strategy instead.
+func (c *Client) SelectWithFallback(ctx context.Context, req SelectRequest) (*Selection, error) {
+	var selected *Selection
+	operation := func(opCtx context.Context) (*failopen.Result, error) {
+		sel, err := c.Select(opCtx, req)
+		if err != nil {
+			return nil, err
+		}
+		selected = sel
+		return &failopen.Result{AccountID: sel.AccountID, Provider: Provider(sel.Provider)}, nil
+	}
+
+	result, err := c.failOpen.ExecuteWithFilter(ctx, operation, selectFilter(req))
+	if err != nil {
+		return nil, err
+	}
+	if !result.Fallback {
+		c.cache.Remember(selected.AccountID, Provider(selected.Provider))
+		return selected, nil
+	}
+
+	sel := &Selection{
+		AccountID: result.AccountID,
+		Provider:  string(result.Provider),
+		Reason:    "fallback: " + result.Reason,
+		Fallback:  true,
+	}
+	if c.hooks.OnFallback != nil {
+		c.hooks.OnFallback(result.Reason, sel)
+	}
+	return sel, nil
+}
+
+// FailOpenStats returns the fail-open counters of SelectWithFallback.
+func (c *Client) FailOpenStats() FailOpenStats {
+	return c.failOpen.GetMetrics()
+}
+
+// selectFilter limits fallback candidates to what req allows
+func selectFilter(req SelectRequest) func(*models.Account) bool {
+	excluded := make(map[string]bool, len(req.Exclude))
+	for _, id := range req.Exclude {
+		excluded[id] = true
+	}
+	excludedProviders := make(map[string]bool, len(req.ExcludeProviders))
+	for _, p := range req.ExcludeProviders {
+		excludedProviders[p] = true
+	}
+	return func(acc *models.Account) bool {
+		if excluded[acc.ID] || excludedProviders[string(acc.Provider)] {
+			return false
+		}
+		return req.Provider == "" || string(acc.Provider) == req.Provider
+	}
+}
+
+// ExecuteRequest describes one routed call made with Execute.
+type ExecuteRequest struct {
+	SelectRequest
+	// EstimatedCostPct reserves this much quota before the call when positive.
+	EstimatedCostPct float64
+	// CorrelationID identifies the routing decision, its reservation and
+	// feedback. Default: the ID the server assigns to the selection.
+	CorrelationID string
+}
+
+// Outcome is what a call made with Execute consumed.
+type Outcome struct {
+	ActualCostPct float64
+	InputTokens   int64
+	OutputTokens  int64
+}
+
+// Execute selects an account with fallback, reserves quota when an
+// estimate is given, runs fn and reports the outcome. A reservation is
+// cancelled if fn fails and settled at the estimate if fn reports no cost.
+// Reservation and feedback are skipped for cached selections; feedback is
+// best effort and its failures only reach Hooks.OnRequest. The error
+// returned is fn's.
+func (c *Client) Execute(ctx context.Context, req ExecuteRequest, fn func(context.Context, *Selection) (*Outcome, error)) (*Selection, error) {
+	selectReq := req.SelectRequest
+	if req.CorrelationID != "" {
+		selectReq.CorrelationID = req.CorrelationID
+	}
+	sel, err := c.SelectWithFallback(ctx, selectReq)
+	if err != nil {
+		return nil, err
+	}
+	correlationID := sel.CorrelationID
+	if correlationID == "" {
+		correlationID = selectReq.CorrelationID
+	}
+
+	reservationID := ""
+	if !sel.Fallback && req.EstimatedCostPct > 0 {
+		if correlationID == "" {
+			correlationID = newCorrelationID()
+		}
+		res, err := c.CreateReservation(ctx, ReservationRequest{
+			AccountID:        sel.AccountID,
+			EstimatedCostPct: req.EstimatedCostPct,
+			CorrelationID:    correlationID,
+		})
+		switch {
+		case err == nil:
+			reservationID = res.ReservationID
+		case !errors.Is(err, ErrUnavailable) && !isTransportError(err):
+			return sel, err
+		}
+	}
+
+	outcome, callErr := fn(ctx, sel)
+	if sel.Fallback {
+		return sel, callErr
+	}
+	if outcome == nil {
+		outcome = &Outcome{}
+	}
+
+	// Report even when ctx was cancelled by the call
+	reportCtx := context.WithoutCancel(ctx)
+	feedback := FeedbackRequest{
+		AccountID:     sel.AccountID,
+		InputTokens:   outcome.InputTokens,
+		OutputTokens:  outcome.OutputTokens,
+		Success:       callErr == nil,
+		CorrelationID: correlationID,
+	}
+	if callErr != nil {
+		feedback.Error = callErr.Error()
+		if reservationID != "" {
+			_ = c.CancelReservation(reportCtx, reservationID)
+		}
+	} else {
+		feedback.ReservationID = reservationID
+		feedback.ActualCost = outcome.ActualCostPct
+		if reservationID != "" && feedback.ActualCost <= 0 {
+			feedback.ActualCost = req.EstimatedCostPct
+		}
+	}
+	_ = c.Feedback(reportCtx, feedback)
+	return sel, callErr
+}
+
+// isTransportError reports whether err happened before a response arrived
+func isTransportError(err error) bool {
+	var apiErr *APIError
+	return !errors.As(err, &apiErr)
+}
+
+func newCorrelationID() string {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().UTC().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(buf)
+}
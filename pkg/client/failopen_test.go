@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectWithFallback_UsesCacheWhenDown(t *testing.T) {
+	ts, _ := startServer(t, noAuth)
+	ctx := context.Background()
+
+	var fallbacks []string
+	c := New(ts.URL, WithFailOpen(FailOpenConfig{Timeout: time.Second, EnableMetrics: true}), WithHooks(Hooks{
+		OnFallback: func(reason string, _ *Selection) { fallbacks = append(fallbacks, reason) },
+	}))
+	require.NoError(t, c.RefreshAccounts(ctx))
+	assert.Equal(t, 2, c.Accounts().Len())
+
+	sel, err := c.SelectWithFallback(ctx, SelectRequest{Provider: "anthropic"})
+	require.NoError(t, err)
+	assert.False(t, sel.Fallback)
+	assert.Equal(t, "anthropic-1", sel.AccountID)
+
+	ts.Close()
+	sel, err = c.SelectWithFallback(ctx, SelectRequest{Provider: "anthropic"})
+	require.NoError(t, err)
+	assert.True(t, sel.Fallback)
+	assert.Equal(t, "anthropic-1", sel.AccountID)
+	assert.Equal(t, []string{"network_error"}, fallbacks)
+
+	// Exclusions narrow the cached candidates
+	_, err = c.SelectWithFallback(ctx, SelectRequest{Exclude: []string{"openai-1"}, ExcludeProviders: []string{"anthropic"}})
+	require.Error(t, err)
+
+	stats := c.FailOpenStats()
+	assert.Equal(t, uint64(1), stats.SuccessfulRequests)
+	assert.GreaterOrEqual(t, stats.FallbackTriggered, uint64(2))
+}
+
+func TestSelectWithFallback_SkipsExhaustedAndTimesOut(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL, WithFailOpen(FailOpenConfig{Timeout: 20 * time.Millisecond}))
+	c.Accounts().UpdateQuotas([]QuotaInfo{
+		{AccountID: "empty", Provider: models.ProviderOpenAI, EffectiveRemainingPct: 0},
+		{AccountID: "full", Provider: models.ProviderOpenAI, EffectiveRemainingPct: 90},
+	})
+
+	for i := 0; i < 3; i++ {
+		sel, err := c.SelectWithFallback(context.Background(), SelectRequest{})
+		require.NoError(t, err)
+		assert.True(t, sel.Fallback)
+		assert.Equal(t, "full", sel.AccountID)
+	}
+}
+
+func TestSelectWithFallback_RejectionIsNotFallback(t *testing.T) {
+	ts, _ := startServer(t, noAuth)
+	c := New(ts.URL, WithFailOpen(FailOpenConfig{Timeout: time.Second}))
+	require.NoError(t, c.RefreshAccounts(context.Background()))
+
+	// The router answering that nothing qualifies is final
+	_, err := c.SelectWithFallback(context.Background(), SelectRequest{Provider: "gemini"})
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+}
+
+func TestExecute_ReservesAndReportsFeedback(t *testing.T) {
+	ts, s := startServer(t, noAuth)
+	c := New(ts.URL, WithFailOpen(FailOpenConfig{Timeout: time.Second}))
+	ctx := context.Background()
+
+	sel, err := c.Execute(ctx, ExecuteRequest{
+		SelectRequest:    SelectRequest{Provider: "openai"},
+		EstimatedCostPct: 5,
+	}, func(_ context.Context, sel *Selection) (*Outcome, error) {
+		assert.Equal(t, "openai-1", sel.AccountID)
+		return &Outcome{ActualCostPct: 2, InputTokens: 100, OutputTokens: 50}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "openai-1", sel.AccountID)
+
+	reservations := s.ListReservations()
+	require.Len(t, reservations, 1)
+	assert.Equal(t, models.ReservationReleased, reservations[0].Status)
+
+	// Feedback is joined to the routing decision
+	require.NotEmpty(t, sel.CorrelationID)
+	assert.Equal(t, sel.CorrelationID, reservations[0].CorrelationID)
+	events, err := s.ListRoutingEvents(models.RoutingEventFilter{CorrelationID: sel.CorrelationID})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.NotNil(t, events[0].Outcome)
+	assert.True(t, events[0].Outcome.Success)
+	assert.Equal(t, int64(100), events[0].Outcome.InputTokens)
+
+	callErr := errors.New("upstream failed")
+	_, err = c.Execute(ctx, ExecuteRequest{
+		SelectRequest:    SelectRequest{Provider: "openai"},
+		EstimatedCostPct: 5,
+		CorrelationID:    "failing",
+	}, func(context.Context, *Selection) (*Outcome, error) {
+		return nil, callErr
+	})
+	assert.ErrorIs(t, err, callErr)
+	for _, res := range s.ListReservations() {
+		if res.CorrelationID == "failing" {
+			assert.Equal(t, models.ReservationCancelled, res.Status)
+		}
+	}
+	events, err = s.ListRoutingEvents(models.RoutingEventFilter{CorrelationID: "failing"})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.NotNil(t, events[0].Outcome)
+	assert.Equal(t, "upstream failed", events[0].Outcome.Error)
+}
+
+func TestExecute_FallbackSkipsReservation(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusGatewayTimeout)
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL, WithFailOpen(FailOpenConfig{Timeout: time.Second}))
+	c.Accounts().Remember("cached", models.ProviderOpenAI)
+
+	sel, err := c.Execute(context.Background(), ExecuteRequest{EstimatedCostPct: 5}, func(context.Context, *Selection) (*Outcome, error) {
+		return &Outcome{}, nil
+	})
+	require.NoError(t, err)
+	assert.True(t, sel.Fallback)
+	assert.Equal(t, "cached", sel.AccountID)
+	assert.Equal(t, int32(1), calls.Load())
+}
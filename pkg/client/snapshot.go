@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/snapshot"
+)
+
+// Snapshot types shared with the server
+type (
+	Snapshot         = snapshot.Snapshot
+	SnapshotAccount  = snapshot.Account
+	SnapshotEnvelope = snapshot.Envelope
+)
+
+// Snapshot verification errors
+var (
+	ErrSnapshotUnsigned     = snapshot.ErrUnsigned
+	ErrSnapshotBadSignature = snapshot.ErrBadSignature
+)
+
+// Snapshot fetches and verifies GET /snapshot.
+func (c *Client) Snapshot(ctx context.Context) (*Snapshot, error) {
+	snap, _, err := c.fetchSnapshot(ctx)
+	return snap, err
+}
+
+// fetchSnapshot returns the verified snapshot with its envelope
+func (c *Client) fetchSnapshot(ctx context.Context) (*Snapshot, *SnapshotEnvelope, error) {
+	var env SnapshotEnvelope
+	if err := c.do(ctx, http.MethodGet, "/snapshot", nil, nil, &env); err != nil {
+		return nil, nil, err
+	}
+	snap, err := snapshot.Open(&env, c.snapshotKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("quotaguard: %w", err)
+	}
+	return snap, &env, nil
+}
+
+// RefreshSnapshot fetches a snapshot into the account cache and writes it
+// to the snapshot file when one is configured.
+func (c *Client) RefreshSnapshot(ctx context.Context) error {
+	snap, env, err := c.fetchSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	if !c.cache.ApplySnapshot(snap) || c.snapshotFile == "" {
+		return nil
+	}
+	return writeSnapshotFile(c.snapshotFile, env)
+}
+
+// LoadSnapshotFile fills the account cache from the snapshot file. A
+// missing file is not an error.
+func (c *Client) LoadSnapshotFile() error {
+	if c.snapshotFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.snapshotFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("quotaguard: read snapshot: %w", err)
+	}
+
+	var env SnapshotEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return fmt.Errorf("quotaguard: decode snapshot file: %w", err)
+	}
+	snap, err := snapshot.Open(&env, c.snapshotKey)
+	if err != nil {
+		return fmt.Errorf("quotaguard: snapshot file: %w", err)
+	}
+	c.cache.ApplySnapshot(snap)
+	return nil
+}
+
+// StartSnapshotRefresh loads the snapshot file, refreshes the snapshot now
+// and then every interval until ctx is done. The file is only consulted
+// at start, so a restart while QuotaGuard is down still has accounts to
+// fall back to. Errors from the file and the first refresh are returned.
+func (c *Client) StartSnapshotRefresh(ctx context.Context, interval time.Duration) error {
+	loadErr := c.LoadSnapshotFile()
+	err := c.RefreshSnapshot(ctx)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = c.RefreshSnapshot(ctx)
+			}
+		}
+	}()
+	return errors.Join(loadErr, err)
+}
+
+// writeSnapshotFile replaces path atomically so readers never see a
+// partial snapshot
+func writeSnapshotFile(path string, env *SnapshotEnvelope) error {
+	data, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("quotaguard: encode snapshot: %w", err)
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("quotaguard: write snapshot: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("quotaguard: write snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("quotaguard: write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("quotaguard: write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("quotaguard: write snapshot: %w", err)
+	}
+	return nil
+}
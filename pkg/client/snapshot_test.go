@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/config"
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot_PersistsAndFallsBackAfterRestart(t *testing.T) {
+	ts, s := startAPIServer(t, config.APIConfig{Snapshot: config.SnapshotConfig{SigningKey: "snap-key"}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blockedUntil := time.Now().Add(time.Hour)
+	require.NoError(t, s.SetAccountBlockedUntil("anthropic-1", &blockedUntil))
+
+	path := filepath.Join(t.TempDir(), "cache", "snapshot.json")
+	c := New(ts.URL, WithSnapshotKey("snap-key"), WithSnapshotFile(path))
+	require.NoError(t, c.StartSnapshotRefresh(ctx, time.Hour))
+	assert.Equal(t, 2, c.Accounts().Len())
+	assert.False(t, c.Accounts().GeneratedAt().IsZero())
+
+	acc, ok := c.Accounts().GetAccount("openai-1")
+	require.True(t, ok)
+	assert.Equal(t, 10, acc.Priority)
+	remaining, ok := c.Accounts().RemainingPct("openai-1")
+	require.True(t, ok)
+	assert.Equal(t, 80.0, remaining)
+	_, err := os.Stat(path)
+	require.NoError(t, err)
+
+	// A new process starting while QuotaGuard is down routes from the file
+	ts.Close()
+	restarted := New(ts.URL, WithSnapshotKey("snap-key"), WithSnapshotFile(path),
+		WithFailOpen(FailOpenConfig{Timeout: time.Second}))
+	require.Error(t, restarted.StartSnapshotRefresh(ctx, time.Hour))
+	assert.Equal(t, 2, restarted.Accounts().Len())
+
+	for i := 0; i < 5; i++ {
+		sel, err := restarted.SelectWithFallback(ctx, SelectRequest{})
+		require.NoError(t, err)
+		assert.True(t, sel.Fallback)
+		assert.Equal(t, "openai-1", sel.AccountID, "blocked account is skipped")
+	}
+}
+
+func TestSnapshot_RejectsUnsignedAndBadSignature(t *testing.T) {
+	ts, _ := startServer(t, noAuth)
+
+	_, err := New(ts.URL, WithSnapshotKey("snap-key")).Snapshot(context.Background())
+	assert.ErrorIs(t, err, ErrSnapshotUnsigned)
+
+	snap, err := New(ts.URL).Snapshot(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, snap.Accounts, 2)
+
+	signed, _ := startAPIServer(t, config.APIConfig{Snapshot: config.SnapshotConfig{SigningKey: "other"}})
+	_, err = New(signed.URL, WithSnapshotKey("snap-key")).Snapshot(context.Background())
+	assert.ErrorIs(t, err, ErrSnapshotBadSignature)
+
+	// A tampered file is refused instead of routed from
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"payload":{"version":1,"accounts":[{"id":"x"}]},"signature":"00"}`), 0o600))
+	c := New(ts.URL, WithSnapshotKey("snap-key"), WithSnapshotFile(path))
+	assert.ErrorIs(t, c.LoadSnapshotFile(), ErrSnapshotBadSignature)
+	assert.Zero(t, c.Accounts().Len())
+}
+
+func TestAccountCache_ApplySnapshotIgnoresOlder(t *testing.T) {
+	cache := NewAccountCache()
+	now := time.Now()
+	remaining := 50.0
+
+	assert.True(t, cache.ApplySnapshot(&Snapshot{GeneratedAt: now, Accounts: []SnapshotAccount{
+		{ID: "new", Provider: models.ProviderOpenAI, RemainingPct: &remaining},
+	}}))
+	assert.False(t, cache.ApplySnapshot(&Snapshot{GeneratedAt: now.Add(-time.Minute), Accounts: []SnapshotAccount{
+		{ID: "old", Provider: models.ProviderOpenAI},
+	}}))
+
+	_, ok := cache.GetAccount("new")
+	assert.True(t, ok)
+	_, ok = cache.GetAccount("old")
+	assert.False(t, ok)
+}
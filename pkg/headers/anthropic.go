@@ -0,0 +1,178 @@
+package headers
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// Unified rate-limit headers sent on Claude subscription traffic:
+//
+//	anthropic-ratelimit-unified-status: allowed | allowed_warning | rejected
+//	anthropic-ratelimit-unified-reset: 1760000000
+//	anthropic-ratelimit-unified-representative-claim: five_hour
+//	anthropic-ratelimit-unified-5h-utilization: 0.42
+//	anthropic-ratelimit-unified-5h-reset: 1760000000
+//	anthropic-ratelimit-unified-5h-status: allowed
+//	anthropic-ratelimit-unified-7d-utilization: 0.1
+//	anthropic-ratelimit-unified-overage-status: rejected
+const (
+	anthropicUnifiedPrefix        = "anthropic-ratelimit-unified-"
+	anthropicUnifiedStatus        = "Anthropic-Ratelimit-Unified-Status"
+	anthropicUnifiedReset         = "Anthropic-Ratelimit-Unified-Reset"
+	anthropicUnifiedClaim         = "Anthropic-Ratelimit-Unified-Representative-Claim"
+	anthropicUnifiedOverageStatus = "Anthropic-Ratelimit-Unified-Overage-Status"
+
+	// unifiedScale converts utilization fractions to integer dimension units
+	// (basis points), so 0.4215 becomes 4215 of 10000.
+	unifiedScale = 10000
+
+	unifiedStatusRejected = "rejected"
+)
+
+// unifiedClaimWindows maps representative-claim values to window header names
+var unifiedClaimWindows = map[string]string{
+	"five_hour":        "5h",
+	"seven_day":        "7d",
+	"seven_day_opus":   "7d_opus",
+	"seven_day_sonnet": "7d_sonnet",
+}
+
+// parseAnthropicClassic reads the per-minute request and token buckets.
+func parseAnthropicClassic(headers http.Header, now time.Time) models.DimensionSlice {
+	var dimensions models.DimensionSlice
+
+	buckets := []struct {
+		header string
+		name   string
+		dim    models.DimensionType
+	}{
+		{"Requests", "", models.DimensionRPM},
+		{"Tokens", "", models.DimensionTPM},
+		{"Input-Tokens", "input_tokens", models.DimensionTPM},
+		{"Output-Tokens", "output_tokens", models.DimensionTPM},
+	}
+
+	for _, b := range buckets {
+		limit := parseIntHeader(headers, "Anthropic-Ratelimit-"+b.header+"-Limit")
+		if limit <= 0 {
+			continue
+		}
+		remaining := parseIntHeader(headers, "Anthropic-Ratelimit-"+b.header+"-Remaining")
+		if remaining > limit {
+			remaining = limit
+		}
+		dimensions = append(dimensions, models.Dimension{
+			Name:       b.name,
+			Type:       b.dim,
+			Limit:      limit,
+			Used:       limit - remaining,
+			Remaining:  remaining,
+			ResetAt:    parseResetHeader(headers, "Anthropic-Ratelimit-"+b.header+"-Reset", now),
+			Semantics:  models.WindowToken,
+			Source:     models.SourceHeaders,
+			Confidence: 1.0,
+		})
+	}
+
+	return dimensions
+}
+
+// parseAnthropicUnified reads the subscription windows. It returns one
+// SUBSCRIPTION dimension per window and whether requests are being rejected.
+func parseAnthropicUnified(headers http.Header, now time.Time) (models.DimensionSlice, bool) {
+	status := strings.ToLower(headers.Get(anthropicUnifiedStatus))
+	claimWindow := unifiedClaimWindows[strings.ToLower(headers.Get(anthropicUnifiedClaim))]
+
+	windows := unifiedWindows(headers)
+	dimensions := make(models.DimensionSlice, 0, len(windows))
+	rejected := status == unifiedStatusRejected
+
+	for _, window := range windows {
+		key := "Anthropic-Ratelimit-Unified-" + window
+		utilization, err := strconv.ParseFloat(strings.TrimSpace(headers.Get(key+"-Utilization")), 64)
+		if err != nil {
+			continue
+		}
+
+		used := int64(math.Round(utilization * unifiedScale))
+		windowRejected := strings.ToLower(headers.Get(key+"-Status")) == unifiedStatusRejected ||
+			(status == unifiedStatusRejected && window == claimWindow)
+		if windowRejected {
+			rejected = true
+			used = unifiedScale
+		}
+		if used > unifiedScale {
+			used = unifiedScale
+		}
+		if used < 0 {
+			used = 0
+		}
+
+		dimensions = append(dimensions, models.Dimension{
+			Name:       "unified_" + window,
+			Type:       models.DimensionSubscription,
+			Limit:      unifiedScale,
+			Used:       used,
+			Remaining:  unifiedScale - used,
+			ResetAt:    parseResetHeader(headers, key+"-Reset", now),
+			Semantics:  models.WindowFixed,
+			Source:     models.SourceHeaders,
+			Confidence: 1.0,
+		})
+	}
+
+	// A rejection without per-window data still tells us when access returns
+	if len(dimensions) == 0 && status == unifiedStatusRejected {
+		dimensions = append(dimensions, models.Dimension{
+			Name:       "unified",
+			Type:       models.DimensionSubscription,
+			Limit:      unifiedScale,
+			Used:       unifiedScale,
+			Remaining:  0,
+			ResetAt:    parseResetHeader(headers, anthropicUnifiedReset, now),
+			Semantics:  models.WindowFixed,
+			Source:     models.SourceHeaders,
+			Confidence: 1.0,
+		})
+	}
+
+	// Requests over the subscription limit keep flowing while overage is allowed
+	if rejected {
+		overage := strings.ToLower(headers.Get(anthropicUnifiedOverageStatus))
+		if strings.HasPrefix(overage, "allowed") {
+			rejected = false
+		}
+	}
+
+	return dimensions, rejected
+}
+
+// unifiedWindows returns the window names that carry a utilization header
+func unifiedWindows(headers http.Header) []string {
+	var windows []string
+	for key := range headers {
+		lower := strings.ToLower(key)
+		if !strings.HasPrefix(lower, anthropicUnifiedPrefix) || !strings.HasSuffix(lower, "-utilization") {
+			continue
+		}
+		window := strings.TrimSuffix(strings.TrimPrefix(lower, anthropicUnifiedPrefix), "-utilization")
+		if window != "" {
+			windows = append(windows, window)
+		}
+	}
+	sort.Strings(windows)
+	return windows
+}
+
+func hasAnthropicUnifiedHeaders(headers http.Header) bool {
+	if headers.Get(anthropicUnifiedStatus) != "" {
+		return true
+	}
+	return len(unifiedWindows(headers)) > 0
+}
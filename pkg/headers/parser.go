@@ -45,33 +45,11 @@ func (p *OpenAIParser) Parse(headers http.Header, accountID string) (*models.Quo
 	// x-ratelimit-reset-requests: 0s
 	// x-ratelimit-reset-tokens: 0s
 
-	reqLimit := parseIntHeader(headers, "X-Ratelimit-Limit-Requests")
-	reqRemaining := parseIntHeader(headers, "X-Ratelimit-Remaining-Requests")
-	tokenLimit := parseIntHeader(headers, "X-Ratelimit-Limit-Tokens")
-	tokenRemaining := parseIntHeader(headers, "X-Ratelimit-Remaining-Tokens")
-
-	// Build dimensions
-	var dimensions models.DimensionSlice
-
-	if reqLimit > 0 {
-		used := reqLimit - reqRemaining
-		dimensions = append(dimensions, models.Dimension{
-			Type:      models.DimensionRPM,
-			Limit:     reqLimit,
-			Used:      used,
-			Remaining: reqRemaining,
-		})
-	}
-
-	if tokenLimit > 0 {
-		used := tokenLimit - tokenRemaining
-		dimensions = append(dimensions, models.Dimension{
-			Type:      models.DimensionTPM,
-			Limit:     tokenLimit,
-			Used:      used,
-			Remaining: tokenRemaining,
-		})
-	}
+	// The buckets refill continuously, and the reset headers give the
+	// time until they are full again.
+	now := quota.CollectedAt
+	dimensions := parseBuckets(headers, accountID, openAIStyleBuckets, now)
+	annotateDimensions(dimensions, now)
 
 	quota.Dimensions = dimensions
 	quota.UpdateEffective()
@@ -104,37 +82,19 @@ func (p *AnthropicParser) Parse(headers http.Header, accountID string) (*models.
 	// Anthropic uses different header format:
 	// anthropic-ratelimit-requests-limit: 1000
 	// anthropic-ratelimit-requests-remaining: 999
+	// anthropic-ratelimit-requests-reset: 2026-01-01T00:00:30Z
 	// anthropic-ratelimit-tokens-limit: 100000
 	// anthropic-ratelimit-tokens-remaining: 99999
+	// Subscription traffic carries anthropic-ratelimit-unified-* headers instead.
 
-	reqLimit := parseIntHeader(headers, "Anthropic-Ratelimit-Requests-Limit")
-	reqRemaining := parseIntHeader(headers, "Anthropic-Ratelimit-Requests-Remaining")
-	tokenLimit := parseIntHeader(headers, "Anthropic-Ratelimit-Tokens-Limit")
-	tokenRemaining := parseIntHeader(headers, "Anthropic-Ratelimit-Tokens-Remaining")
-
-	var dimensions models.DimensionSlice
-
-	if reqLimit > 0 {
-		used := reqLimit - reqRemaining
-		dimensions = append(dimensions, models.Dimension{
-			Type:      models.DimensionRPM,
-			Limit:     reqLimit,
-			Used:      used,
-			Remaining: reqRemaining,
-		})
-	}
-
-	if tokenLimit > 0 {
-		used := tokenLimit - tokenRemaining
-		dimensions = append(dimensions, models.Dimension{
-			Type:      models.DimensionTPM,
-			Limit:     tokenLimit,
-			Used:      used,
-			Remaining: tokenRemaining,
-		})
-	}
+	now := quota.CollectedAt
+	dimensions := parseAnthropicClassic(headers, now)
+	unified, rejected := parseAnthropicUnified(headers, now)
+	dimensions = append(dimensions, unified...)
+	annotateDimensions(dimensions, now)
 
 	quota.Dimensions = dimensions
+	quota.IsThrottled = rejected
 	quota.UpdateEffective()
 
 	if len(dimensions) == 0 {
@@ -165,6 +125,7 @@ func (p *GeminiParser) Parse(headers http.Header, accountID string) (*models.Quo
 	// Gemini uses different header format:
 	// x-goog-quota-limit: requestsPerMinute=1000, tokensPerMinute=100000
 	// x-goog-quota-remaining: requestsPerMinute=999, tokensPerMinute=99999
+	// No reset time is sent, so refill is estimated from the window length.
 
 	quotaMap := parseQuotaHeader(headers.Get("X-Goog-Quota-Limit"))
 	remainingMap := parseQuotaHeader(headers.Get("X-Goog-Quota-Remaining"))
@@ -175,10 +136,13 @@ func (p *GeminiParser) Parse(headers http.Header, accountID string) (*models.Quo
 		reqRemaining := remainingMap["requestsperminute"]
 		used := reqLimit - reqRemaining
 		dimensions = append(dimensions, models.Dimension{
-			Type:      models.DimensionRPM,
-			Limit:     reqLimit,
-			Used:      used,
-			Remaining: reqRemaining,
+			Type:       models.DimensionRPM,
+			Limit:      reqLimit,
+			Used:       used,
+			Remaining:  reqRemaining,
+			Semantics:  models.WindowFixed,
+			Source:     models.SourceHeaders,
+			Confidence: 1.0,
 		})
 	}
 
@@ -186,13 +150,17 @@ func (p *GeminiParser) Parse(headers http.Header, accountID string) (*models.Quo
 		tokenRemaining := remainingMap["tokensperminute"]
 		used := tokenLimit - tokenRemaining
 		dimensions = append(dimensions, models.Dimension{
-			Type:      models.DimensionTPM,
-			Limit:     tokenLimit,
-			Used:      used,
-			Remaining: tokenRemaining,
+			Type:       models.DimensionTPM,
+			Limit:      tokenLimit,
+			Used:       used,
+			Remaining:  tokenRemaining,
+			Semantics:  models.WindowFixed,
+			Source:     models.SourceHeaders,
+			Confidence: 1.0,
 		})
 	}
 
+	annotateDimensions(dimensions, quota.CollectedAt)
 	quota.Dimensions = dimensions
 	quota.UpdateEffective()
 
@@ -218,6 +186,12 @@ func NewRegistry() *Registry {
 	r.Register(&OpenAIParser{})
 	r.Register(&AnthropicParser{})
 	r.Register(&GeminiParser{})
+	r.Register(&QwenParser{})
+	r.Register(&AzureParser{})
+	r.Register(&OpenRouterParser{})
+	r.Register(&GroqParser{})
+	r.Register(&MistralParser{})
+	r.Register(&DeepSeekParser{})
 
 	return r
 }
@@ -242,22 +216,32 @@ func (r *Registry) Parse(provider models.Provider, headers http.Header, accountI
 	return parser.Parse(headers, accountID)
 }
 
+// detectors are checked in order. Providers that reuse the OpenAI header
+// names are identified by their own marker headers first; OpenRouter's
+// unsuffixed x-ratelimit-* headers are the weakest signal and go last.
+var detectors = []struct {
+	provider models.Provider
+	match    func(http.Header) bool
+}{
+	{models.ProviderAzure, hasAzureHeaders},
+	{models.ProviderGroq, hasGroqHeaders},
+	{models.ProviderQwen, hasQwenHeaders},
+	{models.ProviderDeepSeek, hasDeepSeekHeaders},
+	{models.ProviderMistral, hasMistralHeaders},
+	{models.ProviderOpenAI, hasOpenAIHeaders},
+	{models.ProviderAnthropic, hasAnthropicHeaders},
+	{models.ProviderGemini, hasGeminiHeaders},
+	{models.ProviderOpenRouter, hasOpenRouterHeaders},
+}
+
 // AutoDetect attempts to detect the provider from headers and parse accordingly
 func (r *Registry) AutoDetect(headers http.Header, accountID string) (*models.QuotaInfo, models.Provider, error) {
-	// Try to detect provider from headers
-	if hasOpenAIHeaders(headers) {
-		quota, err := r.Parse(models.ProviderOpenAI, headers, accountID)
-		return quota, models.ProviderOpenAI, err
-	}
-
-	if hasAnthropicHeaders(headers) {
-		quota, err := r.Parse(models.ProviderAnthropic, headers, accountID)
-		return quota, models.ProviderAnthropic, err
-	}
-
-	if hasGeminiHeaders(headers) {
-		quota, err := r.Parse(models.ProviderGemini, headers, accountID)
-		return quota, models.ProviderGemini, err
+	for _, d := range detectors {
+		if !d.match(headers) {
+			continue
+		}
+		quota, err := r.Parse(d.provider, headers, accountID)
+		return quota, d.provider, err
 	}
 
 	return nil, "", fmt.Errorf("unable to detect provider from headers")
@@ -321,7 +305,8 @@ func hasOpenAIHeaders(headers http.Header) bool {
 
 func hasAnthropicHeaders(headers http.Header) bool {
 	return headers.Get("Anthropic-Ratelimit-Requests-Limit") != "" ||
-		headers.Get("Anthropic-Ratelimit-Requests-Remaining") != ""
+		headers.Get("Anthropic-Ratelimit-Requests-Remaining") != "" ||
+		hasAnthropicUnifiedHeaders(headers)
 }
 
 func hasGeminiHeaders(headers http.Header) bool {
@@ -2,7 +2,9 @@ package headers
 
 import (
 	"net/http"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/quotaguard/quotaguard/internal/models"
 	"github.com/stretchr/testify/assert"
@@ -114,6 +116,116 @@ func TestAnthropicParser_Parse(t *testing.T) {
 	})
 }
 
+func TestAnthropicParser_ClassicReset(t *testing.T) {
+	parser := &AnthropicParser{}
+	headers := http.Header{
+		"Anthropic-Ratelimit-Requests-Limit":         []string{"50"},
+		"Anthropic-Ratelimit-Requests-Remaining":     []string{"10"},
+		"Anthropic-Ratelimit-Requests-Reset":         []string{"2026-01-01T00:00:30Z"},
+		"Anthropic-Ratelimit-Input-Tokens-Limit":     []string{"40000"},
+		"Anthropic-Ratelimit-Input-Tokens-Remaining": []string{"30000"},
+	}
+
+	quota, err := parser.Parse(headers, "acc-1")
+	require.NoError(t, err)
+	require.Len(t, quota.Dimensions, 2)
+
+	rpm, ok := quota.Dimensions.FindByType(models.DimensionRPM)
+	require.True(t, ok)
+	require.NotNil(t, rpm.ResetAt)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 30, 0, time.UTC), rpm.ResetAt.UTC())
+	assert.Equal(t, models.WindowToken, rpm.Semantics)
+	assert.Equal(t, "input_tokens", quota.Dimensions[1].Name)
+}
+
+func TestAnthropicParser_Unified(t *testing.T) {
+	parser := &AnthropicParser{}
+	reset5h := time.Date(2026, 1, 1, 5, 0, 0, 0, time.UTC)
+	reset7d := time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC)
+
+	t.Run("subscription windows", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("anthropic-ratelimit-unified-status", "allowed_warning")
+		headers.Set("anthropic-ratelimit-unified-representative-claim", "five_hour")
+		headers.Set("anthropic-ratelimit-unified-5h-utilization", "0.8125")
+		headers.Set("anthropic-ratelimit-unified-5h-reset", strconv.FormatInt(reset5h.Unix(), 10))
+		headers.Set("anthropic-ratelimit-unified-5h-status", "allowed_warning")
+		headers.Set("anthropic-ratelimit-unified-7d-utilization", "0.3")
+		headers.Set("anthropic-ratelimit-unified-7d-reset", strconv.FormatInt(reset7d.Unix(), 10))
+
+		quota, err := parser.Parse(headers, "claude-1")
+		require.NoError(t, err)
+		require.Len(t, quota.Dimensions, 2)
+		assert.False(t, quota.IsThrottled)
+
+		fiveHour := quota.Dimensions[0]
+		assert.Equal(t, "unified_5h", fiveHour.Name)
+		assert.Equal(t, models.DimensionSubscription, fiveHour.Type)
+		assert.Equal(t, int64(10000), fiveHour.Limit)
+		assert.Equal(t, int64(8125), fiveHour.Used)
+		assert.Equal(t, models.WindowFixed, fiveHour.Semantics)
+		require.NotNil(t, fiveHour.ResetAt)
+		assert.Equal(t, reset5h, *fiveHour.ResetAt)
+
+		assert.Equal(t, "unified_7d", quota.Dimensions[1].Name)
+		assert.Equal(t, reset7d, *quota.Dimensions[1].ResetAt)
+
+		assert.InDelta(t, 18.75, quota.EffectiveRemainingPct, 0.001)
+		require.NotNil(t, quota.CriticalDimension)
+		assert.Equal(t, "unified_5h", quota.CriticalDimension.Name)
+	})
+
+	t.Run("rejected window throttles the account", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("anthropic-ratelimit-unified-status", "rejected")
+		headers.Set("anthropic-ratelimit-unified-representative-claim", "seven_day")
+		headers.Set("anthropic-ratelimit-unified-5h-utilization", "0.2")
+		headers.Set("anthropic-ratelimit-unified-7d-utilization", "0.97")
+		headers.Set("anthropic-ratelimit-unified-overage-status", "rejected")
+
+		quota, err := parser.Parse(headers, "claude-1")
+		require.NoError(t, err)
+		assert.True(t, quota.IsThrottled)
+		assert.True(t, quota.IsExhausted())
+		assert.Equal(t, int64(0), quota.Dimensions[1].Remaining)
+		assert.Equal(t, int64(8000), quota.Dimensions[0].Remaining)
+	})
+
+	t.Run("overage keeps requests flowing", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("anthropic-ratelimit-unified-status", "rejected")
+		headers.Set("anthropic-ratelimit-unified-5h-utilization", "1.02")
+		headers.Set("anthropic-ratelimit-unified-overage-status", "allowed")
+
+		quota, err := parser.Parse(headers, "claude-1")
+		require.NoError(t, err)
+		assert.False(t, quota.IsThrottled)
+		assert.Equal(t, int64(10000), quota.Dimensions[0].Used)
+	})
+
+	t.Run("rejection without window data", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("anthropic-ratelimit-unified-status", "rejected")
+		headers.Set("anthropic-ratelimit-unified-reset", strconv.FormatInt(reset5h.Unix(), 10))
+
+		quota, err := parser.Parse(headers, "claude-1")
+		require.NoError(t, err)
+		require.Len(t, quota.Dimensions, 1)
+		assert.Equal(t, "unified", quota.Dimensions[0].Name)
+		assert.Equal(t, reset5h, *quota.Dimensions[0].ResetAt)
+		assert.True(t, quota.IsThrottled)
+	})
+
+	t.Run("auto-detected", func(t *testing.T) {
+		headers := http.Header{}
+		headers.Set("anthropic-ratelimit-unified-5h-utilization", "0.5")
+
+		_, provider, err := NewRegistry().AutoDetect(headers, "claude-1")
+		require.NoError(t, err)
+		assert.Equal(t, models.ProviderAnthropic, provider)
+	})
+}
+
 func TestGeminiParser_Provider(t *testing.T) {
 	parser := &GeminiParser{}
 	assert.Equal(t, models.ProviderGemini, parser.Provider())
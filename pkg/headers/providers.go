@@ -0,0 +1,380 @@
+package headers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// rateLimitBucket describes one limit/remaining/reset header triple
+type rateLimitBucket struct {
+	limit     string
+	remaining string
+	reset     string
+	name      string
+	dim       models.DimensionType
+	semantics models.WindowSemantics
+}
+
+// openAIStyleBuckets are the x-ratelimit-* headers shared by OpenAI-compatible APIs
+var openAIStyleBuckets = []rateLimitBucket{
+	{"X-Ratelimit-Limit-Requests", "X-Ratelimit-Remaining-Requests", "X-Ratelimit-Reset-Requests", "", models.DimensionRPM, models.WindowToken},
+	{"X-Ratelimit-Limit-Tokens", "X-Ratelimit-Remaining-Tokens", "X-Ratelimit-Reset-Tokens", "", models.DimensionTPM, models.WindowToken},
+}
+
+// knownLimits remembers the last limit seen per account and bucket, since
+// some responses carry only the remaining count
+var knownLimits = struct {
+	sync.Mutex
+	limits map[string]int64
+}{limits: make(map[string]int64)}
+
+// rememberLimit records a limit reported for key. Estimates from remaining
+// counts only ever raise what is known, since the real limit is at least
+// that large.
+func rememberLimit(key string, limit int64, reported bool) {
+	knownLimits.Lock()
+	defer knownLimits.Unlock()
+	if reported || limit > knownLimits.limits[key] {
+		knownLimits.limits[key] = limit
+	}
+}
+
+// knownLimit returns the last limit remembered for key
+func knownLimit(key string) (int64, bool) {
+	knownLimits.Lock()
+	defer knownLimits.Unlock()
+	limit, ok := knownLimits.limits[key]
+	return limit, ok
+}
+
+// parseBuckets builds one dimension per bucket with a known limit.
+// Buckets that only report remaining capacity are sized by the last limit
+// known for limitKey at low confidence, and skipped until one is known,
+// rather than reported as full.
+func parseBuckets(headers http.Header, limitKey string, buckets []rateLimitBucket, now time.Time) models.DimensionSlice {
+	var dimensions models.DimensionSlice
+
+	for _, b := range buckets {
+		limit := parseIntHeader(headers, b.limit)
+		remaining := parseIntHeader(headers, b.remaining)
+		confidence := 1.0
+		key := limitKey + "\x00" + b.remaining
+		if limit > 0 {
+			rememberLimit(key, limit, true)
+		} else {
+			if headers.Get(b.remaining) == "" {
+				continue
+			}
+			known, ok := knownLimit(key)
+			rememberLimit(key, remaining, false)
+			if !ok || known <= 0 {
+				continue
+			}
+			limit = max(known, remaining)
+			confidence = 0.5
+		}
+		if remaining > limit {
+			remaining = limit
+		}
+		if remaining < 0 {
+			remaining = 0
+		}
+		dimensions = append(dimensions, models.Dimension{
+			Name:       b.name,
+			Type:       b.dim,
+			Limit:      limit,
+			Used:       limit - remaining,
+			Remaining:  remaining,
+			ResetAt:    parseResetHeader(headers, b.reset, now),
+			Semantics:  b.semantics,
+			Source:     models.SourceHeaders,
+			Confidence: confidence,
+		})
+	}
+
+	return dimensions
+}
+
+// newHeaderQuota wraps parsed dimensions into a quota for the provider
+func newHeaderQuota(provider models.Provider, accountID string, dimensions models.DimensionSlice, now time.Time) (*models.QuotaInfo, error) {
+	if len(dimensions) == 0 {
+		return nil, fmt.Errorf("no quota headers found")
+	}
+	annotateDimensions(dimensions, now)
+
+	quota := &models.QuotaInfo{
+		AccountID:   accountID,
+		Provider:    provider,
+		Dimensions:  dimensions,
+		Source:      models.SourceHeaders,
+		CollectedAt: now,
+		Confidence:  1.0,
+	}
+	quota.UpdateEffective()
+	return quota, nil
+}
+
+// QwenParser parses Alibaba DashScope (Qwen) response headers
+type QwenParser struct{}
+
+// Provider returns the provider name
+func (p *QwenParser) Provider() models.Provider {
+	return models.ProviderQwen
+}
+
+// Parse extracts quota information from DashScope response headers
+func (p *QwenParser) Parse(headers http.Header, accountID string) (*models.QuotaInfo, error) {
+	// The OpenAI-compatible endpoint reports per-minute buckets:
+	// x-ratelimit-limit-requests: 600
+	// x-ratelimit-remaining-requests: 599
+	// x-ratelimit-limit-tokens: 1000000
+	// x-ratelimit-remaining-tokens: 999000
+	// x-dashscope-call-gateway: true
+	now := time.Now()
+	return newHeaderQuota(models.ProviderQwen, accountID, parseBuckets(headers, accountID, openAIStyleBuckets, now), now)
+}
+
+// AzureParser parses Azure OpenAI response headers
+type AzureParser struct{}
+
+// Provider returns the provider name
+func (p *AzureParser) Provider() models.Provider {
+	return models.ProviderAzure
+}
+
+// Parse extracts quota information from Azure OpenAI response headers
+func (p *AzureParser) Parse(headers http.Header, accountID string) (*models.QuotaInfo, error) {
+	// Limits are enforced per deployment, and older API versions only send
+	// the remaining counts:
+	// x-ratelimit-remaining-requests: 119
+	// x-ratelimit-remaining-tokens: 119500
+	// x-ratelimit-limit-tokens: 120000
+	// x-ms-deployment-name: gpt-4o-prod
+	// x-ms-region: East US
+	now := time.Now()
+	deployment := azureDeployment(headers)
+	dimensions := parseBuckets(headers, accountID+"/"+deployment, openAIStyleBuckets, now)
+
+	for i := range dimensions {
+		dimensions[i].Name = deployment
+	}
+
+	return newHeaderQuota(models.ProviderAzure, accountID, dimensions, now)
+}
+
+// azureDeployment returns the deployment that served the request
+func azureDeployment(headers http.Header) string {
+	for _, key := range []string{"X-Ms-Deployment-Name", "Azureml-Model-Deployment"} {
+		if v := strings.TrimSpace(headers.Get(key)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// OpenRouterParser parses OpenRouter response headers
+type OpenRouterParser struct{}
+
+// Provider returns the provider name
+func (p *OpenRouterParser) Provider() models.Provider {
+	return models.ProviderOpenRouter
+}
+
+// Parse extracts quota information from OpenRouter response headers
+func (p *OpenRouterParser) Parse(headers http.Header, accountID string) (*models.QuotaInfo, error) {
+	// Request limits come without a bucket suffix, credits are in USD:
+	// x-ratelimit-limit: 200
+	// x-ratelimit-remaining: 199
+	// x-ratelimit-reset: 1760000000000
+	// x-openrouter-credits-limit: 25.00
+	// x-openrouter-credits-remaining: 12.50
+	now := time.Now()
+	dimensions := parseBuckets(headers, accountID, []rateLimitBucket{
+		{"X-Ratelimit-Limit", "X-Ratelimit-Remaining", "X-Ratelimit-Reset", "", models.DimensionRPM, models.WindowFixed},
+	}, now)
+
+	if credits, ok := openRouterCredits(headers); ok {
+		dimensions = append(dimensions, credits)
+	}
+
+	return newHeaderQuota(models.ProviderOpenRouter, accountID, dimensions, now)
+}
+
+// openRouterCredits converts the key's credit balance into a BUDGET
+// dimension tracked in cents. Keys without a credit limit are skipped.
+func openRouterCredits(headers http.Header) (models.Dimension, bool) {
+	limit, ok := parseFloatHeader(headers, "X-Openrouter-Credits-Limit")
+	if !ok || limit <= 0 {
+		return models.Dimension{}, false
+	}
+	remaining, ok := parseFloatHeader(headers, "X-Openrouter-Credits-Remaining")
+	if !ok {
+		usage, hasUsage := parseFloatHeader(headers, "X-Openrouter-Credits-Usage")
+		if !hasUsage {
+			return models.Dimension{}, false
+		}
+		remaining = limit - usage
+	}
+
+	limitCents := int64(math.Round(limit * 100))
+	remainingCents := int64(math.Round(remaining * 100))
+	if remainingCents > limitCents {
+		remainingCents = limitCents
+	}
+	if remainingCents < 0 {
+		remainingCents = 0
+	}
+
+	return models.Dimension{
+		Name:       "credits_cents",
+		Type:       models.DimensionBudget,
+		Limit:      limitCents,
+		Used:       limitCents - remainingCents,
+		Remaining:  remainingCents,
+		Semantics:  models.WindowFixed,
+		Source:     models.SourceHeaders,
+		Confidence: 1.0,
+	}, true
+}
+
+// GroqParser parses Groq response headers
+type GroqParser struct{}
+
+// Provider returns the provider name
+func (p *GroqParser) Provider() models.Provider {
+	return models.ProviderGroq
+}
+
+// Parse extracts quota information from Groq response headers
+func (p *GroqParser) Parse(headers http.Header, accountID string) (*models.QuotaInfo, error) {
+	// Groq reuses the OpenAI names, but the request bucket is per day:
+	// x-ratelimit-limit-requests: 14400
+	// x-ratelimit-remaining-requests: 14370
+	// x-ratelimit-reset-requests: 2m59.56s
+	// x-ratelimit-limit-tokens: 18000
+	// x-ratelimit-remaining-tokens: 17997
+	// x-ratelimit-reset-tokens: 7.66s
+	now := time.Now()
+	dimensions := parseBuckets(headers, accountID, []rateLimitBucket{
+		{"X-Ratelimit-Limit-Requests", "X-Ratelimit-Remaining-Requests", "X-Ratelimit-Reset-Requests", "", models.DimensionRPD, models.WindowToken},
+		{"X-Ratelimit-Limit-Tokens", "X-Ratelimit-Remaining-Tokens", "X-Ratelimit-Reset-Tokens", "", models.DimensionTPM, models.WindowToken},
+	}, now)
+
+	return newHeaderQuota(models.ProviderGroq, accountID, dimensions, now)
+}
+
+// MistralParser parses Mistral response headers
+type MistralParser struct{}
+
+// Provider returns the provider name
+func (p *MistralParser) Provider() models.Provider {
+	return models.ProviderMistral
+}
+
+// Parse extracts quota information from Mistral response headers
+func (p *MistralParser) Parse(headers http.Header, accountID string) (*models.QuotaInfo, error) {
+	// x-ratelimit-limit-tokens-minute: 500000
+	// x-ratelimit-remaining-tokens-minute: 499000
+	// x-ratelimit-limit-tokens-month: 1000000000
+	// x-ratelimit-remaining-tokens-month: 999000000
+	// ratelimitbysize-limit: 500000
+	// ratelimitbysize-remaining: 499000
+	// ratelimitbysize-reset: 12
+	// The month bucket is a token allowance, so it is tracked as a budget
+	// that renews at the start of the next UTC month.
+	now := time.Now()
+	dimensions := parseBuckets(headers, accountID, []rateLimitBucket{
+		{"X-Ratelimit-Limit-Tokens-Minute", "X-Ratelimit-Remaining-Tokens-Minute", "", "", models.DimensionTPM, models.WindowFixed},
+		{"X-Ratelimit-Limit-Tokens-Month", "X-Ratelimit-Remaining-Tokens-Month", "", "tokens_month", models.DimensionBudget, models.WindowFixed},
+	}, now)
+	for i := range dimensions {
+		if dimensions[i].Type == models.DimensionBudget {
+			resetAt := models.NextMonthStart(now)
+			dimensions[i].ResetAt = &resetAt
+		}
+	}
+
+	// Older deployments only send the size-based minute bucket
+	if _, ok := dimensions.FindByType(models.DimensionTPM); !ok {
+		dimensions = append(dimensions, parseBuckets(headers, accountID, []rateLimitBucket{
+			{"Ratelimitbysize-Limit", "Ratelimitbysize-Remaining", "Ratelimitbysize-Reset", "", models.DimensionTPM, models.WindowFixed},
+		}, now)...)
+	}
+
+	return newHeaderQuota(models.ProviderMistral, accountID, dimensions, now)
+}
+
+// DeepSeekParser parses DeepSeek response headers
+type DeepSeekParser struct{}
+
+// Provider returns the provider name
+func (p *DeepSeekParser) Provider() models.Provider {
+	return models.ProviderDeepSeek
+}
+
+// Parse extracts quota information from DeepSeek response headers.
+// DeepSeek throttles dynamically and usually sends no limits, so only the
+// OpenAI-compatible buckets are read when a gateway adds them.
+func (p *DeepSeekParser) Parse(headers http.Header, accountID string) (*models.QuotaInfo, error) {
+	now := time.Now()
+	return newHeaderQuota(models.ProviderDeepSeek, accountID, parseBuckets(headers, accountID, openAIStyleBuckets, now), now)
+}
+
+func parseFloatHeader(headers http.Header, key string) (float64, bool) {
+	val := strings.TrimSpace(headers.Get(key))
+	if val == "" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
+
+// hasHeaderPrefix reports whether any header name starts with prefix
+// (canonical form, e.g. "X-Dashscope-").
+func hasHeaderPrefix(headers http.Header, prefix string) bool {
+	for key := range headers {
+		if strings.HasPrefix(http.CanonicalHeaderKey(key), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasQwenHeaders(headers http.Header) bool {
+	return hasHeaderPrefix(headers, "X-Dashscope-")
+}
+
+func hasAzureHeaders(headers http.Header) bool {
+	return headers.Get("X-Ms-Region") != "" ||
+		headers.Get("Apim-Request-Id") != "" ||
+		azureDeployment(headers) != ""
+}
+
+func hasOpenRouterHeaders(headers http.Header) bool {
+	return hasHeaderPrefix(headers, "X-Openrouter-") ||
+		headers.Get("X-Ratelimit-Limit") != ""
+}
+
+func hasGroqHeaders(headers http.Header) bool {
+	return hasHeaderPrefix(headers, "X-Groq-")
+}
+
+func hasMistralHeaders(headers http.Header) bool {
+	return headers.Get("X-Ratelimit-Limit-Tokens-Minute") != "" ||
+		headers.Get("X-Ratelimit-Limit-Tokens-Month") != "" ||
+		headers.Get("Ratelimitbysize-Limit") != ""
+}
+
+func hasDeepSeekHeaders(headers http.Header) bool {
+	return hasHeaderPrefix(headers, "X-Ds-")
+}
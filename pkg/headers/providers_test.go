@@ -0,0 +1,191 @@
+package headers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// headerFixture is a captured response header set with the expected parse
+type headerFixture struct {
+	Provider   models.Provider    `json:"provider"`
+	Headers    map[string]string  `json:"headers"`
+	Dimensions []fixtureDimension `json:"dimensions"`
+	Error      string             `json:"error"`
+}
+
+// fixtureDimension is an expected dimension. Resets sent as durations are
+// relative to the parse time, so they are given in seconds instead of reset_at.
+// A zero refill_rate is not checked.
+type fixtureDimension struct {
+	models.Dimension
+	ResetInSeconds float64 `json:"reset_in_seconds"`
+}
+
+func loadHeaderFixtures(t *testing.T) map[string]headerFixture {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	fixtures := make(map[string]headerFixture, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		var f headerFixture
+		require.NoError(t, json.Unmarshal(data, &f), path)
+		fixtures[strings.TrimSuffix(filepath.Base(path), ".json")] = f
+	}
+	return fixtures
+}
+
+func (f headerFixture) header() http.Header {
+	h := make(http.Header, len(f.Headers))
+	for k, v := range f.Headers {
+		h.Add(k, v)
+	}
+	return h
+}
+
+// resetKnownLimits forgets the limits remembered by earlier parses
+func resetKnownLimits() {
+	knownLimits.Lock()
+	defer knownLimits.Unlock()
+	knownLimits.limits = make(map[string]int64)
+}
+
+func TestProviderParsers_Golden(t *testing.T) {
+	registry := NewRegistry()
+
+	for name, fixture := range loadHeaderFixtures(t) {
+		t.Run(name, func(t *testing.T) {
+			headers := fixture.header()
+
+			// Detection and an explicit provider must agree, each parsing
+			// the fixture as the first response seen for the account
+			resetKnownLimits()
+			detected, provider, detectErr := registry.AutoDetect(headers, "acc-1")
+			assert.Equal(t, fixture.Provider, provider)
+			resetKnownLimits()
+			explicit, err := registry.Parse(fixture.Provider, headers, "acc-1")
+
+			if fixture.Error != "" {
+				require.Error(t, detectErr)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), fixture.Error)
+				return
+			}
+			require.NoError(t, detectErr)
+			require.NoError(t, err)
+
+			for _, quota := range []*models.QuotaInfo{detected, explicit} {
+				assert.Equal(t, fixture.Provider, quota.Provider)
+				assert.Equal(t, "acc-1", quota.AccountID)
+				assert.Equal(t, models.SourceHeaders, quota.Source)
+				require.Len(t, quota.Dimensions, len(fixture.Dimensions))
+				for i, want := range fixture.Dimensions {
+					got := quota.Dimensions[i]
+					assert.Equal(t, want.Name, got.Name)
+					assert.Equal(t, want.Type, got.Type)
+					assert.Equal(t, want.Limit, got.Limit)
+					assert.Equal(t, want.Used, got.Used)
+					assert.Equal(t, want.Remaining, got.Remaining)
+					assert.Equal(t, want.Semantics, got.Semantics)
+					assert.Equal(t, want.Source, got.Source)
+					assert.Equal(t, want.Confidence, got.Confidence)
+					switch {
+					case want.ResetAt != nil:
+						require.NotNil(t, got.ResetAt)
+						assert.True(t, want.ResetAt.Equal(*got.ResetAt), "reset_at %s", got.ResetAt)
+					case want.ResetInSeconds > 0:
+						require.NotNil(t, got.ResetAt)
+						assert.InDelta(t, want.ResetInSeconds, got.ResetAt.Sub(quota.CollectedAt).Seconds(), 1e-6)
+					}
+					if want.RefillRate != 0 {
+						assert.InDelta(t, want.RefillRate, got.RefillRate, 1e-9)
+					}
+					assert.NoError(t, got.Validate())
+				}
+			}
+		})
+	}
+}
+
+func TestRegistry_AdditionalProviders(t *testing.T) {
+	registry := NewRegistry()
+	for _, provider := range []models.Provider{
+		models.ProviderQwen,
+		models.ProviderAzure,
+		models.ProviderOpenRouter,
+		models.ProviderGroq,
+		models.ProviderMistral,
+		models.ProviderDeepSeek,
+	} {
+		parser, ok := registry.Get(provider)
+		require.True(t, ok, provider)
+		assert.Equal(t, provider, parser.Provider())
+	}
+
+	// Marker headers win over the shared OpenAI names
+	_, provider, err := registry.AutoDetect(http.Header{
+		"X-Groq-Region":              []string{"us-east-1"},
+		"X-Ratelimit-Limit-Requests": []string{"1000"},
+	}, "acc-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.ProviderGroq, provider)
+
+	_, provider, err = registry.AutoDetect(http.Header{
+		"X-Ratelimit-Limit-Requests": []string{"1000"},
+	}, "acc-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.ProviderOpenAI, provider)
+}
+
+func TestAzureParser_RemainingOnlyUsesKnownLimit(t *testing.T) {
+	resetKnownLimits()
+	parser := &AzureParser{}
+	deployment := func(h http.Header) http.Header {
+		h.Set("X-Ms-Deployment-Name", "gpt-4o-prod")
+		return h
+	}
+
+	_, err := parser.Parse(deployment(http.Header{
+		"X-Ratelimit-Remaining-Requests": []string{"119"},
+	}), "acc-1")
+	require.Error(t, err, "a remaining count alone does not size the bucket")
+
+	_, err = parser.Parse(deployment(http.Header{
+		"X-Ratelimit-Limit-Requests":     []string{"120"},
+		"X-Ratelimit-Remaining-Requests": []string{"90"},
+	}), "acc-1")
+	require.NoError(t, err)
+
+	quota, err := parser.Parse(deployment(http.Header{
+		"X-Ratelimit-Remaining-Requests": []string{"0"},
+	}), "acc-1")
+	require.NoError(t, err)
+	require.Len(t, quota.Dimensions, 1)
+	dim := quota.Dimensions[0]
+	assert.Equal(t, int64(120), dim.Limit)
+	assert.Equal(t, int64(0), dim.Remaining)
+	assert.Equal(t, int64(120), dim.Used)
+	assert.Equal(t, 0.5, dim.Confidence)
+
+	// Limits are per deployment and per account
+	_, err = parser.Parse(http.Header{
+		"X-Ms-Deployment-Name":           []string{"gpt-35-turbo-eu"},
+		"X-Ratelimit-Remaining-Requests": []string{"0"},
+	}, "acc-1")
+	assert.Error(t, err)
+	_, err = parser.Parse(deployment(http.Header{
+		"X-Ratelimit-Remaining-Requests": []string{"0"},
+	}), "acc-2")
+	assert.Error(t, err)
+}
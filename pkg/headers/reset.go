@@ -0,0 +1,130 @@
+package headers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+)
+
+// Unix timestamps above these values are treated as absolute times;
+// smaller integers are a number of seconds until reset.
+const (
+	unixSecondsThreshold = 1_000_000_000
+	unixMillisThreshold  = 1_000_000_000_000
+)
+
+// parseResetHeader reads a reset time from the header named key
+func parseResetHeader(headers http.Header, key string, now time.Time) *time.Time {
+	return parseResetValue(headers.Get(key), now)
+}
+
+// parseResetValue understands every reset format providers send:
+//
+//	6m0s, 20ms, 2m59.56s   duration until reset (OpenAI, Groq)
+//	12, 7.5                seconds until reset (Mistral, Retry-After)
+//	1760000000             unix seconds
+//	1760000000000          unix milliseconds (OpenRouter)
+//	2026-01-01T00:00:30Z   RFC 3339 (Anthropic)
+//	Wed, 21 Oct 2026 07:28:00 GMT  HTTP date (Retry-After)
+func parseResetValue(val string, now time.Time) *time.Time {
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return nil
+	}
+
+	if n, err := strconv.ParseInt(val, 10, 64); err == nil {
+		var t time.Time
+		switch {
+		case n >= unixMillisThreshold:
+			t = time.UnixMilli(n).UTC()
+		case n >= unixSecondsThreshold:
+			t = time.Unix(n, 0).UTC()
+		case n >= 0:
+			t = now.Add(time.Duration(n) * time.Second)
+		default:
+			return nil
+		}
+		return &t
+	}
+
+	if secs, err := strconv.ParseFloat(val, 64); err == nil {
+		if !(secs >= 0 && secs < unixSecondsThreshold) {
+			return nil
+		}
+		t := now.Add(time.Duration(secs * float64(time.Second)))
+		return &t
+	}
+
+	if d, err := time.ParseDuration(val); err == nil {
+		if d < 0 {
+			return nil
+		}
+		t := now.Add(d)
+		return &t
+	}
+
+	if t, err := time.Parse(time.RFC3339, val); err == nil {
+		return &t
+	}
+
+	if t, err := http.ParseTime(val); err == nil {
+		return &t
+	}
+
+	return nil
+}
+
+// nominalWindow returns the length of the window a dimension type is
+// counted over, or 0 when the type has no fixed length.
+func nominalWindow(dim models.DimensionType) time.Duration {
+	switch dim {
+	case models.DimensionRPM, models.DimensionTPM:
+		return time.Minute
+	case models.DimensionRPD, models.DimensionTPD:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// estimateRefillRate returns the share of the limit that comes back per
+// minute, so 1.0 means a full window refills within a minute. When the
+// reset time is known the used share is spread over the time left;
+// otherwise the nominal window length is used.
+func estimateRefillRate(d models.Dimension, now time.Time) float64 {
+	if d.Limit <= 0 {
+		return 0
+	}
+
+	var until time.Duration
+	if d.ResetAt != nil {
+		until = d.ResetAt.Sub(now)
+	}
+	window := nominalWindow(d.Type)
+
+	switch {
+	case until > 0 && d.Used > 0:
+		return float64(d.Used) / float64(d.Limit) / until.Minutes()
+	case window > 0:
+		return 1 / window.Minutes()
+	case until > 0:
+		return 1 / until.Minutes()
+	default:
+		return 0
+	}
+}
+
+// annotateDimensions fills in the refill rate of parsed dimensions
+func annotateDimensions(dimensions models.DimensionSlice, now time.Time) {
+	for i := range dimensions {
+		if dimensions[i].Semantics == "" {
+			dimensions[i].Semantics = models.WindowUnknown
+		}
+		if dimensions[i].RefillRate == 0 {
+			dimensions[i].RefillRate = estimateRefillRate(dimensions[i], now)
+		}
+	}
+}
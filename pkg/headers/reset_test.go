@@ -0,0 +1,70 @@
+package headers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quotaguard/quotaguard/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResetValue(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Time
+	}{
+		{"go duration", "6m0s", now.Add(6 * time.Minute)},
+		{"milliseconds", "20ms", now.Add(20 * time.Millisecond)},
+		{"fractional duration", "2m59.56s", now.Add(2*time.Minute + 59560*time.Millisecond)},
+		{"seconds delta", "12", now.Add(12 * time.Second)},
+		{"fractional seconds", "7.5", now.Add(7500 * time.Millisecond)},
+		{"unix seconds", "1760000000", time.Unix(1760000000, 0)},
+		{"unix milliseconds", "1760000000123", time.UnixMilli(1760000000123)},
+		{"rfc3339", "2026-03-01T12:00:30Z", now.Add(30 * time.Second)},
+		{"http date", "Sun, 01 Mar 2026 12:01:00 GMT", now.Add(time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseResetValue(tt.value, now)
+			require.NotNil(t, got)
+			assert.True(t, tt.expected.Equal(*got), "got %s", got)
+		})
+	}
+
+	for _, invalid := range []string{"", "soon", "-5", "-1s", "NaN"} {
+		assert.Nil(t, parseResetValue(invalid, now), invalid)
+	}
+}
+
+func TestEstimateRefillRate(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	in := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	tests := []struct {
+		name     string
+		dim      models.Dimension
+		expected float64
+	}{
+		{"used share over time to reset", models.Dimension{Type: models.DimensionTPM, Limit: 1000, Used: 500, ResetAt: in(2 * time.Minute)}, 0.25},
+		{"nothing used uses window", models.Dimension{Type: models.DimensionRPM, Limit: 100, ResetAt: in(time.Second)}, 1},
+		{"daily window", models.Dimension{Type: models.DimensionRPD, Limit: 100, Used: 10}, 1.0 / 1440},
+		{"reset already passed", models.Dimension{Type: models.DimensionRPM, Limit: 100, Used: 10, ResetAt: in(-time.Minute)}, 1},
+		{"subscription window", models.Dimension{Type: models.DimensionSubscription, Limit: 10000, ResetAt: in(5 * time.Hour)}, 1.0 / 300},
+		{"no window and no reset", models.Dimension{Type: models.DimensionBudget, Limit: 100, Used: 10}, 0},
+		{"no limit", models.Dimension{Type: models.DimensionRPM}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, estimateRefillRate(tt.dim, now), 1e-9)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -29,9 +30,31 @@ type TestServer struct {
 	Router      router.Router
 	Reservation *reservation.Manager
 	Collector   *collector.PassiveCollector
+	Clock       *testClock
 	Cleanup     func()
 }
 
+// testClock is a manually advanced clock for the router, so tests can step
+// past anti-flapping dwell and cooldown windows without sleeping
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// Now returns the clock's current time
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
 // setupTestServerExt creates a test server with SQLite database (extended version)
 func setupTestServerExt(t *testing.T) *TestServer {
 	gin.SetMode(gin.TestMode)
@@ -50,7 +73,10 @@ func setupTestServerExt(t *testing.T) *TestServer {
 			Enabled: false,
 		},
 	}
-	rtr := router.NewRouter(s, router.DefaultConfig())
+	clock := &testClock{now: time.Now()}
+	routerCfg := router.DefaultConfig()
+	routerCfg.Clock = clock.Now
+	rtr := router.NewRouter(s, routerCfg)
 	rm := reservation.NewManager(s, reservation.DefaultConfig())
 	pc := collector.NewPassiveCollector(s, 100, 100*time.Millisecond)
 
@@ -74,6 +100,7 @@ func setupTestServerExt(t *testing.T) *TestServer {
 		Router:      rtr,
 		Reservation: rm,
 		Collector:   pc,
+		Clock:       clock,
 		Cleanup:     cleanup,
 	}
 }
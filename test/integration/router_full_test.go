@@ -2,6 +2,7 @@ package integration
 
 import (
 	"testing"
+	"time"
 
 	"github.com/quotaguard/quotaguard/internal/models"
 	"github.com/stretchr/testify/assert"
@@ -47,6 +48,7 @@ func TestRouter_WithMultipleAccounts(t *testing.T) {
 			createTestAccount(t, ts.Store, acc.id, models.ProviderOpenAI, 5)
 			createTestQuota(t, ts.Store, acc.id, acc.quota, false)
 		}
+		ts.Clock.Advance(10 * time.Minute) // past the dwell window
 
 		resp := requestRouterSelect(t, ts.Engine, "openai", "balanced")
 		assert.Equal(t, "multi-3", resp.AccountID) // Highest quota (90%)
@@ -102,6 +104,7 @@ func TestRouter_Failover(t *testing.T) {
 		ts.Store.SetQuota("failover-primary", quotaPrimary)
 
 		// Router should select primary again (after dwell time)
+		ts.Clock.Advance(10 * time.Minute)
 		resp = requestRouterSelect(t, ts.Engine, "openai", "balanced")
 		assert.Equal(t, "failover-primary", resp.AccountID)
 	})
@@ -174,12 +177,14 @@ func TestRouter_PolicySelection(t *testing.T) {
 	})
 
 	t.Run("cost policy favors lower cost", func(t *testing.T) {
+		ts.Clock.Advance(10 * time.Minute) // past the dwell window
 		resp := requestRouterSelect(t, ts.Engine, "openai", "cost")
 		// policy-high-prio has lower cost (0.01/0.03 vs 0.02/0.06)
 		assert.Equal(t, "policy-high-prio", resp.AccountID)
 	})
 
 	t.Run("performance policy favors higher quota", func(t *testing.T) {
+		ts.Clock.Advance(10 * time.Minute)
 		resp := requestRouterSelect(t, ts.Engine, "openai", "performance")
 		// policy-low-prio has higher quota (90% vs 30%)
 		assert.Equal(t, "policy-low-prio", resp.AccountID)
@@ -224,7 +229,8 @@ func TestRouter_AntiFlapping(t *testing.T) {
 		quota2 := createTestQuota(t, ts.Store, "flap-2", 95.0, false)
 		_ = quota2
 
-		// Should switch to flap-2
+		// Should switch to flap-2 once the dwell window has passed
+		ts.Clock.Advance(10 * time.Minute)
 		resp := requestRouterSelect(t, ts.Engine, "openai", "balanced")
 		assert.Equal(t, "flap-2", resp.AccountID)
 	})
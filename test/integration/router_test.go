@@ -164,7 +164,7 @@ func TestRouter_AntiFlappingBehavior(t *testing.T) {
 		r.RecordSwitch("flap-acc-2")
 
 		stats := r.GetStats()
-		assert.Equal(t, 1, stats.LastSwitches)
+		assert.Equal(t, 1, stats.ScopeCount)
 		assert.Equal(t, "flap-acc-2", r.GetCurrentAccount())
 	})
 